@@ -0,0 +1,90 @@
+package attachment
+
+import (
+	"context"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Blobs are deleted in batches and a single run is bounded like the one of the trash
+// purger, whatever is left over is deleted on the next tick.
+const (
+	janitorBatchSize = 100
+	maxDeletedPerRun = 10 * janitorBatchSize
+)
+
+// BlobJanitor deletes the blobs whose attachments are gone. Every API instance runs
+// one, deleting the same blob twice is harmless so they don't need to coordinate. A
+// blob that fails to be deleted is kept and retried on the next tick.
+type BlobJanitor struct {
+	repo     BlobCleanupRepository
+	blobs    domain.BlobStore
+	logger   domain.Logger
+	interval time.Duration
+}
+
+func NewBlobJanitor(repo BlobCleanupRepository, blobs domain.BlobStore, logger domain.Logger, interval time.Duration) *BlobJanitor {
+	return &BlobJanitor{
+		repo:     repo,
+		blobs:    blobs,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Start runs the janitor until ctx is cancelled.
+func (j *BlobJanitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes the orphaned blobs and returns how many were deleted.
+func (j *BlobJanitor) RunOnce(ctx context.Context) int {
+	deleted := 0
+	for deleted < maxDeletedPerRun {
+		keys, err := j.repo.GetOrphanedBlobKeys(ctx, janitorBatchSize)
+		if err != nil {
+			j.logger.Error("failed to get the orphaned blobs", "error", err)
+			break
+		}
+
+		var done []string
+		for _, key := range keys {
+			if err := j.blobs.Delete(ctx, key); err != nil {
+				j.logger.Error("failed to delete an orphaned blob", "key", key, "error", err)
+				continue
+			}
+			done = append(done, key)
+		}
+
+		if len(done) > 0 {
+			if err := j.repo.ForgetOrphanedBlobKeys(ctx, done); err != nil {
+				j.logger.Error("failed to forget the deleted blobs", "error", err)
+				break
+			}
+		}
+		deleted += len(done)
+
+		// the failed blobs come first in the next batch, so a batch without any progress
+		// ends the run as well
+		if len(keys) < janitorBatchSize || len(done) == 0 {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		j.logger.Info("deleted orphaned blobs", "count", deleted)
+	}
+	return deleted
+}
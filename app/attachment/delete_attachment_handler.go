@@ -0,0 +1,50 @@
+package attachment
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteAttachmentRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid"`
+	Id     uuid.UUID `params:"attachmentId" validate:"required,uuid"`
+}
+
+type DeleteAttachmentResponse struct {
+}
+
+type DeleteAttachmentHandler struct {
+	repo AttachmentRepository
+}
+
+func NewDeleteAttachmentHandler(repo AttachmentRepository) *DeleteAttachmentHandler {
+	return &DeleteAttachmentHandler{repo: repo}
+}
+
+// DeleteAttachmentHandler removes an attachment from a todo.
+//
+//	@Summary		Delete an attachment
+//	@Description	Deletes an attachment of a todo. Its links stop working right away, the file itself is removed from the storage shortly after.
+//	@Tags			Attachment
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string	true	"Todo ID"
+//	@Param			attachmentId	path	string	true	"Attachment ID"
+//	@Success		204				"Attachment deleted successfully"
+//	@Failure		400				"Invalid request"
+//	@Failure		401				"Unauthorized"
+//	@Failure		403				"Todo is read-only for the user"
+//	@Failure		404				"Todo or attachment not found"
+//	@Failure		500				"Internal server error"
+//	@Router			/todos/{id}/attachments/{attachmentId} [delete]
+func (h *DeleteAttachmentHandler) Handle(ctx context.Context, req *DeleteAttachmentRequest) (*DeleteAttachmentResponse, int, error) {
+	if err := h.repo.DeleteAttachment(ctx, req.Id, req.TodoId, domain.GetUserID(ctx)); err != nil {
+		return nil, attachmentErrorStatus(err), err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
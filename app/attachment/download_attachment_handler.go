@@ -0,0 +1,70 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DownloadAttachmentRequest struct {
+	Id    uuid.UUID `params:"id" validate:"required,uuid"`
+	Token string    `query:"token" validate:"required"`
+}
+
+type DownloadAttachmentHandler struct {
+	repo  AttachmentRepository
+	blobs domain.BlobStore
+	ts    TokenService
+}
+
+func NewDownloadAttachmentHandler(repo AttachmentRepository, blobs domain.BlobStore, ts TokenService) *DownloadAttachmentHandler {
+	return &DownloadAttachmentHandler{repo: repo, blobs: blobs, ts: ts}
+}
+
+// DownloadAttachmentHandler serves the content of an attachment through a signed link.
+//
+//	@Summary		Download an attachment
+//	@Description	Downloads an attachment. The link comes from the upload or the list of attachments and needs no authentication, its token expires after a few minutes.
+//	@Tags			Attachment
+//	@Produce		octet-stream
+//	@Param			id		path	string	true	"Attachment ID"
+//	@Param			token	query	string	true	"Token of the signed link"
+//	@Success		200		"Content of the attachment"
+//	@Failure		400		"Invalid request"
+//	@Failure		403		"Invalid or expired link"
+//	@Failure		404		"Attachment not found"
+//	@Failure		500		"Internal server error"
+//	@Router			/attachments/{id} [get]
+func (h *DownloadAttachmentHandler) Handle(ctx context.Context, req *DownloadAttachmentRequest) (*domain.File, int, error) {
+	resource, err := h.ts.ValidateSignedURLToken(req.Token)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+	// a link grants access to the attachment it was signed for only
+	if resource != attachmentResource(req.Id) {
+		return nil, http.StatusForbidden, domain.ErrInvalidToken
+	}
+
+	attachment, err := h.repo.GetAttachment(ctx, req.Id)
+	if err != nil {
+		return nil, attachmentErrorStatus(err), err
+	}
+
+	body, err := h.blobs.Get(ctx, attachment.BlobKey())
+	if err != nil {
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			return nil, http.StatusNotFound, domain.ErrAttachmentNotFound
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &domain.File{
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Body:        body,
+	}, http.StatusOK, nil
+}
@@ -0,0 +1,68 @@
+package attachment
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetAttachmentsRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type GetAttachmentsResponse []Attachment
+
+// UploaderId is null once the uploader deletes their account. URL downloads the file
+// without authentication for a few minutes, list the attachments again for a new one.
+type Attachment struct {
+	Id          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	UploaderId  *uuid.UUID `json:"uploader_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	URL         string     `json:"url"`
+}
+
+type GetAttachmentsHandler struct {
+	repo AttachmentRepository
+	ts   TokenService
+}
+
+func NewGetAttachmentsHandler(repo AttachmentRepository, ts TokenService) *GetAttachmentsHandler {
+	return &GetAttachmentsHandler{repo: repo, ts: ts}
+}
+
+// GetAttachmentsHandler lists the attachments of a todo.
+//
+//	@Summary		Get attachments of a todo
+//	@Description	Retrieves the attachments of a todo, oldest first, each with a signed link that downloads it for a few minutes.
+//	@Tags			Attachment
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Todo ID"
+//	@Success		200	{object}	GetAttachmentsResponse
+//	@Failure		400	"Invalid request"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Todo not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/{id}/attachments [get]
+func (h *GetAttachmentsHandler) Handle(ctx context.Context, req *GetAttachmentsRequest) (*GetAttachmentsResponse, int, error) {
+	attachments, err := h.repo.GetAttachments(ctx, req.TodoId, domain.GetUserID(ctx))
+	if err != nil {
+		return nil, attachmentErrorStatus(err), err
+	}
+
+	for i := range *attachments {
+		a := &(*attachments)[i]
+		if a.URL, err = signedURL(h.ts, a.Id); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	return attachments, http.StatusOK, nil
+}
@@ -0,0 +1,30 @@
+package attachment
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Every user who can view a todo can list and download its attachments, editors can
+// upload and delete them. A todo the user cannot view is reported as
+// domain.ErrTodoNotFound, one the user can only view as domain.ErrTodoReadOnly when
+// writing.
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *domain.Attachment) error
+	GetAttachments(ctx context.Context, todoId, userId uuid.UUID) (*GetAttachmentsResponse, error)
+	DeleteAttachment(ctx context.Context, id, todoId, userId uuid.UUID) error
+
+	// GetAttachment is not scoped to a user, it serves the signed links whose token
+	// already proves access.
+	GetAttachment(ctx context.Context, id uuid.UUID) (*domain.Attachment, error)
+}
+
+// The blob of an attachment outlives its row, whichever way the row is deleted: one by
+// one, with its todo when the trash is emptied or purged, or with the account. The
+// repository keeps the keys of those blobs until the BlobJanitor has deleted them.
+type BlobCleanupRepository interface {
+	GetOrphanedBlobKeys(ctx context.Context, limit int) ([]string, error)
+	ForgetOrphanedBlobKeys(ctx context.Context, keys []string) error
+}
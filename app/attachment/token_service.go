@@ -0,0 +1,26 @@
+package attachment
+
+import (
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+type TokenService interface {
+	GenerateSignedURLToken(resource string) (string, error)
+	ValidateSignedURLToken(tokenString string) (string, error)
+}
+
+func attachmentResource(id uuid.UUID) string {
+	return "attachments/" + id.String()
+}
+
+// signedURL returns the link that downloads the attachment without authentication
+// until its token expires.
+func signedURL(ts TokenService, id uuid.UUID) (string, error) {
+	token, err := ts.GenerateSignedURLToken(attachmentResource(id))
+	if err != nil {
+		return "", err
+	}
+	return "/attachments/" + id.String() + "?token=" + url.QueryEscape(token), nil
+}
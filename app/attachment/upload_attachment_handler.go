@@ -0,0 +1,118 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type UploadAttachmentRequest struct {
+	TodoId uuid.UUID             `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	File   *multipart.FileHeader `form:"file" validate:"required" swaggerignore:"true"`
+}
+
+type UploadAttachmentResponse struct {
+	Id  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+}
+
+type UploadAttachmentHandler struct {
+	repo   AttachmentRepository
+	blobs  domain.BlobStore
+	ts     TokenService
+	logger domain.Logger
+}
+
+func NewUploadAttachmentHandler(repo AttachmentRepository, blobs domain.BlobStore, ts TokenService, logger domain.Logger) *UploadAttachmentHandler {
+	return &UploadAttachmentHandler{repo: repo, blobs: blobs, ts: ts, logger: logger}
+}
+
+// UploadAttachmentHandler attaches a file to a todo.
+//
+//	@Summary		Upload an attachment
+//	@Description	Uploads a file to a todo as `multipart/form-data`. Files are limited to 10 MiB, their type is detected from their content and must be an image (PNG, JPEG, GIF, WebP), a PDF, a ZIP archive or plain text. The response contains a signed link that downloads the file for a few minutes.
+//	@Tags			Attachment
+//	@Security		BearerAuth
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		string	true	"Todo ID"
+//	@Param			file	formData	file	true	"File to attach"
+//	@Success		201		{object}	UploadAttachmentResponse
+//	@Failure		400		"Invalid request"
+//	@Failure		401		"Unauthorized"
+//	@Failure		403		"Todo is read-only for the user"
+//	@Failure		404		"Todo not found"
+//	@Failure		413		"File too large"
+//	@Failure		415		"Unsupported file type"
+//	@Failure		500		"Internal server error"
+//	@Router			/todos/{id}/attachments [post]
+func (h *UploadAttachmentHandler) Handle(ctx context.Context, req *UploadAttachmentRequest) (*UploadAttachmentResponse, int, error) {
+	if req.File == nil {
+		return nil, http.StatusBadRequest, domain.ErrMissingAttachment
+	}
+	if req.File.Size > domain.MaxAttachmentSize {
+		return nil, http.StatusRequestEntityTooLarge, domain.ErrAttachmentTooLarge
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, http.StatusInternalServerError, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	attachment, err := domain.NewAttachment(req.TodoId, domain.GetUserID(ctx), req.File.Filename, domain.DetectAttachmentType(head[:n]), req.File.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAttachmentTooLarge):
+			return nil, http.StatusRequestEntityTooLarge, err
+		case errors.Is(err, domain.ErrUnsupportedAttachmentType):
+			return nil, http.StatusUnsupportedMediaType, err
+		default:
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	if err := h.blobs.Put(ctx, attachment.BlobKey(), file, attachment.Size, attachment.ContentType); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if err := h.repo.CreateAttachment(ctx, attachment); err != nil {
+		// no row refers to the blob, so the janitor would never delete it
+		if err := h.blobs.Delete(ctx, attachment.BlobKey()); err != nil {
+			h.logger.Error("failed to delete the blob of a rejected attachment", "key", attachment.BlobKey(), "error", err)
+		}
+		return nil, attachmentErrorStatus(err), err
+	}
+
+	url, err := signedURL(h.ts, attachment.Id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &UploadAttachmentResponse{Id: attachment.Id, URL: url}, http.StatusCreated, nil
+}
+
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound), errors.Is(err, domain.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTodoReadOnly):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
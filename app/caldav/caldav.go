@@ -0,0 +1,216 @@
+// Package caldav serves the todos of each user as a CalDAV calendar (RFC 4791), so that
+// clients like Apple Reminders and Thunderbird can sync them both ways. Only the subset
+// these clients rely on is implemented: PROPFIND for discovery and listing, the
+// calendar-query and calendar-multiget reports, and GET, PUT and DELETE of the todos,
+// with ETags for optimistic concurrency. Clients sign in with HTTP Basic authentication,
+// the email of the account and one of its app passwords.
+//
+// The resources are
+//
+//	/caldav/                       the root, pointing clients to the principal
+//	/caldav/principal/             the principal of the signed in user
+//	/caldav/calendars/             the calendar home of the user
+//	/caldav/calendars/todos/       the calendar, with every todo the user created
+//	/caldav/calendars/todos/ID.ics a todo, named after its id
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+const (
+	// Prefix is where the handler is mounted, every href it returns starts with it.
+	Prefix        = "/caldav/"
+	principalPath = Prefix + "principal/"
+	homePath      = Prefix + "calendars/"
+	calendarPath  = homePath + "todos/"
+	objectSuffix  = ".ics"
+)
+
+const (
+	methodPropfind = "PROPFIND"
+	methodReport   = "REPORT"
+)
+
+// Methods are the request methods the handler takes beyond those of plain HTTP, the
+// router has to accept them.
+var Methods = []string{methodPropfind, methodReport}
+
+const authRealm = `Basic realm="Advanced Todo API", charset="UTF-8"`
+
+type resource int
+
+const (
+	rootResource resource = iota
+	principalResource
+	homeResource
+	calendarResource
+	objectResource
+)
+
+// allowedMethods is the Allow header of each resource.
+var allowedMethods = map[resource]string{
+	rootResource:      "OPTIONS, PROPFIND",
+	principalResource: "OPTIONS, PROPFIND",
+	homeResource:      "OPTIONS, PROPFIND",
+	calendarResource:  "OPTIONS, PROPFIND, REPORT",
+	objectResource:    "OPTIONS, PROPFIND, REPORT, GET, HEAD, PUT, DELETE",
+}
+
+type Handler struct {
+	todos  todo.TodoRepository
+	auth   AuthRepository
+	logger domain.Logger
+}
+
+func NewHandler(todos todo.TodoRepository, auth AuthRepository, logger domain.Logger) *Handler {
+	return &Handler{todos: todos, auth: auth, logger: logger}
+}
+
+// session is the signed in user of a request.
+type session struct {
+	userId uuid.UUID
+	email  string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	res, id, ok := parsePath(r.URL.Path)
+	if !ok {
+		h.fail(w, r, http.StatusNotFound, errResourceNotFound)
+		return
+	}
+
+	// clients probe the capabilities of the server before they sign in
+	if r.Method == http.MethodOptions {
+		w.Header().Set("DAV", "1, 3, calendar-access")
+		w.Header().Set("Allow", allowedMethods[res])
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s, ctx, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	r = r.WithContext(ctx)
+
+	switch {
+	case r.Method == methodPropfind:
+		h.propfind(w, r, s, res, id)
+	case r.Method == methodReport:
+		h.report(w, r, s, res, id)
+	case res != objectResource:
+		w.Header().Set("Allow", allowedMethods[res])
+		h.fail(w, r, http.StatusMethodNotAllowed, errMethodNotAllowed)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		h.get(w, r, s, id)
+	case r.Method == http.MethodPut:
+		h.put(w, r, s, id)
+	case r.Method == http.MethodDelete:
+		h.delete(w, r, s, id)
+	default:
+		w.Header().Set("Allow", allowedMethods[res])
+		h.fail(w, r, http.StatusMethodNotAllowed, errMethodNotAllowed)
+	}
+}
+
+var (
+	errResourceNotFound = errors.New("resource not found")
+	errMethodNotAllowed = errors.New("method not allowed")
+)
+
+// authenticate returns the context of the request with the signed in user, like the
+// authentication middleware of the API does. It answers the request itself when the
+// credentials are missing or wrong.
+func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (*session, context.Context, bool) {
+	email, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", authRealm)
+		h.fail(w, r, http.StatusUnauthorized, domain.ErrMissingAuthHeader)
+		return nil, nil, false
+	}
+
+	userId, role, err := h.auth.AuthenticateAppPassword(r.Context(), email, domain.HashAppPassword(password))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			w.Header().Set("WWW-Authenticate", authRealm)
+			h.fail(w, r, http.StatusUnauthorized, err)
+			return nil, nil, false
+		}
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+
+	ctx := context.WithValue(r.Context(), domain.RoleKey, role)
+	ctx = context.WithValue(ctx, domain.UserIDKey, userId.String())
+	return &session{userId: userId, email: email}, ctx, true
+}
+
+// parsePath resolves the path of a request, the trailing slash of a collection is
+// optional. The id of an object is uuid.Nil when its name is not a todo id.
+func parsePath(path string) (resource, uuid.UUID, bool) {
+	if !strings.HasPrefix(path+"/", Prefix) {
+		return 0, uuid.Nil, false
+	}
+
+	switch strings.TrimSuffix(path, "/") + "/" {
+	case Prefix:
+		return rootResource, uuid.Nil, true
+	case principalPath:
+		return principalResource, uuid.Nil, true
+	case homePath:
+		return homeResource, uuid.Nil, true
+	case calendarPath:
+		return calendarResource, uuid.Nil, true
+	}
+
+	name, ok := strings.CutPrefix(path, calendarPath)
+	if !ok || strings.Contains(name, "/") || !strings.HasSuffix(name, objectSuffix) {
+		return 0, uuid.Nil, false
+	}
+	id, err := uuid.Parse(strings.TrimSuffix(name, objectSuffix))
+	if err != nil {
+		return objectResource, uuid.Nil, true
+	}
+	return objectResource, id, true
+}
+
+func objectHref(id uuid.UUID) string {
+	return calendarPath + id.String() + objectSuffix
+}
+
+// failCondition answers the request with the precondition of RFC 4791 it failed.
+func (h *Handler) failCondition(w http.ResponseWriter, r *http.Request, condition xml.Name, err error) {
+	h.log(r, http.StatusForbidden, err)
+	writePrecondition(w, http.StatusForbidden, condition)
+}
+
+// fail answers the request with the error as plain text and logs it the way the API
+// logs its errors.
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, code int, err error) {
+	h.log(r, code, err)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(err.Error()))
+}
+
+func (h *Handler) log(r *http.Request, code int, err error) {
+	errorType := "client error"
+	if code >= 500 {
+		errorType = "system error"
+	}
+	h.logger.Error(err.Error(),
+		"type", errorType,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", code,
+	)
+}
@@ -0,0 +1,81 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	davNS            = "DAV:"
+	caldavNS         = "urn:ietf:params:xml:ns:caldav"
+	calendarServerNS = "http://calendarserver.org/ns/"
+)
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []response `xml:"response"`
+}
+
+// response holds either the propstats of a resource or the status of a resource that
+// could not be found.
+type response struct {
+	Href      string     `xml:"href"`
+	Propstats []propstat `xml:"propstat,omitempty"`
+	Status    string     `xml:"status,omitempty"`
+}
+
+type propstat struct {
+	Prop   prop   `xml:"prop"`
+	Status string `xml:"status"`
+}
+
+type prop struct {
+	Properties []property
+}
+
+// property is named by XMLName, its value is either Inner, which is XML, or Text.
+type property struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+	Text    string `xml:",chardata"`
+}
+
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// element returns an empty element, declaring its namespace so that it can be nested
+// in an element of any other namespace.
+func element(name xml.Name) string {
+	return fmt.Sprintf(`<%s xmlns="%s"/>`, name.Local, name.Space)
+}
+
+func hrefElement(href string) string {
+	var sb strings.Builder
+	sb.WriteString(`<href xmlns="DAV:">`)
+	_ = xml.EscapeText(&sb, []byte(href))
+	sb.WriteString("</href>")
+	return sb.String()
+}
+
+func writeMultistatus(w http.ResponseWriter, ms *multistatus) error {
+	body, err := xml.Marshal(ms)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write([]byte(xml.Header))
+	_, err = w.Write(body)
+	return err
+}
+
+// writePrecondition answers the request with the precondition or postcondition the
+// request failed, as RFC 4918 section 16 describes.
+func writePrecondition(w http.ResponseWriter, code int, condition xml.Name) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, `%s<error xmlns="DAV:">%s</error>`, xml.Header, element(condition))
+}
@@ -0,0 +1,279 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// maxObjectSize leaves room for a description of domain.MaxDescriptionLength bytes,
+// even when every character of it is escaped and folded.
+const maxObjectSize = 1 << 20
+
+const objectContentType = "text/calendar; charset=utf-8; component=VTODO"
+
+var (
+	validCalendarData           = xml.Name{Space: caldavNS, Local: "valid-calendar-data"}
+	validCalendarObjectResource = xml.Name{Space: caldavNS, Local: "valid-calendar-object-resource"}
+	supportedCalendarComponent  = xml.Name{Space: caldavNS, Local: "supported-calendar-component"}
+	noUIDConflict               = xml.Name{Space: caldavNS, Local: "no-uid-conflict"}
+	maxResourceSize             = xml.Name{Space: caldavNS, Local: "max-resource-size"}
+)
+
+// object is a todo as a calendar object resource. Its ETag is the one the API sends
+// for the version of the todo, so it changes whenever the todo does, and a write made
+// through the API fails the preconditions of a client holding the old ETag.
+type object struct {
+	todo todo.ExportedTodo
+	data []byte
+	etag string
+}
+
+func newObject(t todo.ExportedTodo) *object {
+	// a todo keeps no modification time, its creation time stands in for DTSTAMP so
+	// that the data only changes together with the todo
+	return &object{todo: t, data: todo.ICalendar(t, t.CreatedAt), etag: domain.VersionETag(t.Version)}
+}
+
+func (o *object) href() string {
+	return objectHref(o.todo.Id)
+}
+
+// objects calls fn with the object of every todo of the calendar, in the manual order
+// of the user.
+func (h *Handler) objects(ctx context.Context, userId uuid.UUID, fn func(*object) error) error {
+	return h.todos.ExportTodos(ctx, userId, func(t todo.ExportedTodo) error {
+		return fn(newObject(t))
+	})
+}
+
+// findObject returns nil when the calendar has no todo with the id. The calendar only
+// holds the todos the user created, like an export.
+func (h *Handler) findObject(ctx context.Context, userId, id uuid.UUID) (*object, error) {
+	if id == uuid.Nil {
+		return nil, nil
+	}
+
+	t, err := h.todos.ExportTodo(ctx, id, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newObject(*t), nil
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, s *session, id uuid.UUID) {
+	obj, err := h.findObject(r.Context(), s.userId, id)
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if obj == nil {
+		h.fail(w, r, http.StatusNotFound, domain.ErrTodoNotFound)
+		return
+	}
+
+	w.Header().Set("ETag", obj.etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, obj.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", objectContentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(obj.data)
+	}
+}
+
+// put creates or replaces the todo. Only the properties the API stores are kept, so no
+// ETag is returned, which tells the client to fetch the todo again.
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, s *session, id uuid.UUID) {
+	ctx := r.Context()
+	if id == uuid.Nil {
+		h.failCondition(w, r, validCalendarObjectResource, errInvalidObjectName)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxObjectSize+1))
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if len(data) > maxObjectSize {
+		h.failCondition(w, r, maxResourceSize, errObjectTooLarge)
+		return
+	}
+
+	vt, err := parseVTodo(data)
+	if err != nil {
+		if errors.Is(err, errUnsupportedComponent) {
+			h.failCondition(w, r, supportedCalendarComponent, err)
+		} else {
+			h.failCondition(w, r, validCalendarData, err)
+		}
+		return
+	}
+	if uid, err := uuid.Parse(vt.uid); err != nil || uid != id {
+		// the UID names the todo, it has to be the id the resource is named after
+		h.failCondition(w, r, noUIDConflict, errUIDMismatch)
+		return
+	}
+
+	current, err := h.findObject(ctx, s.userId, id)
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if !preconditionsHold(r, current) {
+		h.fail(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	}
+
+	t, err := domain.NewTodo(s.userId, vt.summary, vt.description, vt.due, vt.priority)
+	if err != nil {
+		h.failCondition(w, r, validCalendarObjectResource, err)
+		return
+	}
+	t.Id = id
+
+	if current == nil {
+		h.create(w, r, t, vt)
+		return
+	}
+	h.update(w, r, s, t, vt, current)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request, t *domain.Todo, vt *vtodo) {
+	if vt.completed {
+		t.Completed, t.CompletedAt = true, vt.completedAt
+		if t.CompletedAt.IsZero() {
+			t.CompletedAt = t.CreatedAt
+		}
+	}
+
+	// an import creates the todo with its id and completion in one go, at the end of the
+	// manual order
+	if err := h.todos.ImportTodos(r.Context(), t.UserId, []*domain.Todo{t}, nil); err != nil {
+		if errors.Is(err, domain.ErrTodoAlreadyExists) {
+			// the id belongs to a trashed todo or to a todo of another user
+			h.fail(w, r, http.StatusConflict, err)
+			return
+		}
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// update replaces the todo and its completion in one write, which only succeeds while
+// the todo is still at the version the preconditions were checked against.
+func (h *Handler) update(w http.ResponseWriter, r *http.Request, s *session, t *domain.Todo, vt *vtodo, current *object) {
+	ctx := r.Context()
+
+	// recurrence is not part of the calendar, a recurring todo keeps its rule
+	stored, err := h.todos.GetById(ctx, t.Id, s.userId)
+	if err != nil {
+		h.writeTodoError(w, r, err)
+		return
+	}
+	if stored.Recurrence != "" {
+		recurrence, err := domain.NewRecurrence(stored.Recurrence, stored.Timezone)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if err := t.SetRecurrence(recurrence); err != nil {
+			h.failCondition(w, r, validCalendarObjectResource, err)
+			return
+		}
+	}
+
+	t.Completed = vt.completed
+	t.Version = current.todo.Version
+	if err := h.todos.PatchTodo(ctx, t); err != nil {
+		h.writeTodoError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// delete moves the todo to the trash, like the API does.
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, s *session, id uuid.UUID) {
+	current, err := h.findObject(r.Context(), s.userId, id)
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if current == nil {
+		h.fail(w, r, http.StatusNotFound, domain.ErrTodoNotFound)
+		return
+	}
+	if !preconditionsHold(r, current) {
+		h.fail(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	}
+
+	if err := h.todos.Delete(r.Context(), id, s.userId, current.todo.Version); err != nil {
+		h.writeTodoError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var (
+	errInvalidObjectName  = errors.New("a todo must be named after its id, like ID.ics")
+	errObjectTooLarge     = errors.New("a todo cannot exceed 1 MiB")
+	errUIDMismatch        = errors.New("the UID of the todo must be the id it is named after")
+	errPreconditionFailed = errors.New("the todo has changed, fetch it again")
+)
+
+func (h *Handler) writeTodoError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		h.fail(w, r, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrTodoReadOnly):
+		h.fail(w, r, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrTodoVersionMismatch):
+		h.fail(w, r, http.StatusPreconditionFailed, errPreconditionFailed)
+	default:
+		h.fail(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+// preconditionsHold checks the If-Match and If-None-Match headers of a write against
+// the current object, nil when the todo does not exist yet. A client creating a todo
+// sends "If-None-Match: *", one changing it sends the ETag it last saw.
+func preconditionsHold(r *http.Request, current *object) bool {
+	if match := r.Header.Get("If-Match"); match != "" {
+		if current == nil || !etagMatches(match, current.etag) {
+			return false
+		}
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if current != nil && etagMatches(match, current.etag) {
+			return false
+		}
+	}
+	return true
+}
+
+// etagMatches reports whether the list of ETags of a conditional header holds the
+// ETag, "*" matches any.
+func etagMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// calendarName is the display name of the calendar of every user.
+const calendarName = "Todos"
+
+var (
+	resourceTypeProp                  = xml.Name{Space: davNS, Local: "resourcetype"}
+	displayNameProp                   = xml.Name{Space: davNS, Local: "displayname"}
+	currentUserPrincipalProp          = xml.Name{Space: davNS, Local: "current-user-principal"}
+	principalURLProp                  = xml.Name{Space: davNS, Local: "principal-URL"}
+	currentUserPrivilegeSetProp       = xml.Name{Space: davNS, Local: "current-user-privilege-set"}
+	supportedReportSetProp            = xml.Name{Space: davNS, Local: "supported-report-set"}
+	getETagProp                       = xml.Name{Space: davNS, Local: "getetag"}
+	getContentTypeProp                = xml.Name{Space: davNS, Local: "getcontenttype"}
+	calendarHomeSetProp               = xml.Name{Space: caldavNS, Local: "calendar-home-set"}
+	supportedCalendarComponentSetProp = xml.Name{Space: caldavNS, Local: "supported-calendar-component-set"}
+	calendarDataProp                  = xml.Name{Space: caldavNS, Local: "calendar-data"}
+	getCTagProp                       = xml.Name{Space: calendarServerNS, Local: "getctag"}
+)
+
+// resourceProps are the properties of each resource, an allprop request gets them all.
+// The calendar data of a todo is left out of allprop as RFC 4791 asks, it has to be
+// requested by its name.
+var resourceProps = map[resource][]xml.Name{
+	rootResource:      {resourceTypeProp, currentUserPrincipalProp},
+	principalResource: {resourceTypeProp, displayNameProp, currentUserPrincipalProp, principalURLProp, calendarHomeSetProp},
+	homeResource:      {resourceTypeProp, currentUserPrincipalProp},
+	calendarResource: {resourceTypeProp, displayNameProp, currentUserPrincipalProp, currentUserPrivilegeSetProp,
+		supportedReportSetProp, supportedCalendarComponentSetProp, getCTagProp},
+	objectResource: {resourceTypeProp, currentUserPrivilegeSetProp, getETagProp, getContentTypeProp},
+}
+
+func hasProp(res resource, name xml.Name) bool {
+	if res == objectResource && name == calendarDataProp {
+		return true
+	}
+	for _, prop := range resourceProps[res] {
+		if prop == name {
+			return true
+		}
+	}
+	return false
+}
+
+// propRequest is the prop, allprop or propname element of a PROPFIND or a REPORT.
+type propRequest struct {
+	all       bool
+	namesOnly bool
+	names     []xml.Name
+}
+
+type propNames struct {
+	Names []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+func (p *propNames) request() *propRequest {
+	req := &propRequest{}
+	for _, name := range p.Names {
+		req.names = append(req.names, name.XMLName)
+	}
+	return req
+}
+
+type propfindBody struct {
+	XMLName  xml.Name   `xml:"DAV: propfind"`
+	AllProp  *struct{}  `xml:"DAV: allprop"`
+	PropName *struct{}  `xml:"DAV: propname"`
+	Prop     *propNames `xml:"DAV: prop"`
+}
+
+var errInvalidXML = errors.New("the request body must be a valid WebDAV XML document")
+
+// parsePropfind reads the body of a PROPFIND, an empty one stands for allprop.
+func parsePropfind(body []byte) (*propRequest, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &propRequest{all: true}, nil
+	}
+
+	var pf propfindBody
+	if err := xml.Unmarshal(body, &pf); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidXML, err)
+	}
+	switch {
+	case pf.Prop != nil:
+		return pf.Prop.request(), nil
+	case pf.PropName != nil:
+		return &propRequest{namesOnly: true}, nil
+	}
+	return &propRequest{all: true}, nil
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxObjectSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxObjectSize {
+		return nil, errObjectTooLarge
+	}
+	return body, nil
+}
+
+// target is a resource a PROPFIND or a REPORT responds for, obj is only set for a todo.
+type target struct {
+	href string
+	res  resource
+	obj  *object
+}
+
+func collectionTarget(res resource) target {
+	hrefs := map[resource]string{
+		rootResource:      Prefix,
+		principalResource: principalPath,
+		homeResource:      homePath,
+		calendarResource:  calendarPath,
+	}
+	return target{href: hrefs[res], res: res}
+}
+
+func objectTarget(obj *object) target {
+	return target{href: obj.href(), res: objectResource, obj: obj}
+}
+
+// propfind answers with the properties of the resource and, unless the depth is 0, of
+// its members. An infinite depth is answered like a depth of 1, the members of the
+// calendar are as deep as the tree goes.
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, s *session, res resource, id uuid.UUID) {
+	body, err := readBody(r)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	req, err := parsePropfind(body)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	p := &propfinder{h: h, ctx: ctx, s: s}
+	var targets []target
+	if res == objectResource {
+		obj, err := h.findObject(ctx, s.userId, id)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if obj == nil {
+			h.fail(w, r, http.StatusNotFound, domain.ErrTodoNotFound)
+			return
+		}
+		targets = append(targets, objectTarget(obj))
+	} else {
+		targets = append(targets, collectionTarget(res))
+	}
+
+	if r.Header.Get("Depth") != "0" {
+		switch res {
+		case rootResource:
+			targets = append(targets, collectionTarget(principalResource), collectionTarget(homeResource))
+		case homeResource:
+			targets = append(targets, collectionTarget(calendarResource))
+		case calendarResource:
+			objs, err := p.calendarObjects()
+			if err != nil {
+				h.fail(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			for _, obj := range objs {
+				targets = append(targets, objectTarget(obj))
+			}
+		}
+	}
+
+	ms := &multistatus{}
+	for _, t := range targets {
+		resp, err := p.respond(t, req)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		ms.Responses = append(ms.Responses, resp)
+	}
+	if err := writeMultistatus(w, ms); err != nil {
+		h.log(r, http.StatusInternalServerError, err)
+	}
+}
+
+// propfinder computes the properties of the resources of a request. The objects of the
+// calendar are read at most once per request.
+type propfinder struct {
+	h    *Handler
+	ctx  context.Context
+	s    *session
+	objs []*object
+	read bool
+}
+
+func (p *propfinder) calendarObjects() ([]*object, error) {
+	if p.read {
+		return p.objs, nil
+	}
+	err := p.h.objects(p.ctx, p.s.userId, func(obj *object) error {
+		p.objs = append(p.objs, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.read = true
+	return p.objs, nil
+}
+
+// respond lists the properties that were found with 200 and the others with 404.
+func (p *propfinder) respond(t target, req *propRequest) (response, error) {
+	names := req.names
+	if req.all || req.namesOnly {
+		names = resourceProps[t.res]
+	}
+
+	var found, missing []property
+	for _, name := range names {
+		switch {
+		case !hasProp(t.res, name):
+			missing = append(missing, property{XMLName: name})
+		case req.namesOnly:
+			found = append(found, property{XMLName: name})
+		default:
+			prop, err := p.value(t, name)
+			if err != nil {
+				return response{}, err
+			}
+			found = append(found, prop)
+		}
+	}
+
+	resp := response{Href: t.href}
+	if len(found) > 0 || len(missing) == 0 {
+		resp.Propstats = append(resp.Propstats, propstat{Prop: prop{Properties: found}, Status: statusLine(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		resp.Propstats = append(resp.Propstats, propstat{Prop: prop{Properties: missing}, Status: statusLine(http.StatusNotFound)})
+	}
+	return resp, nil
+}
+
+func (p *propfinder) value(t target, name xml.Name) (property, error) {
+	prop := property{XMLName: name}
+	switch name {
+	case resourceTypeProp:
+		switch t.res {
+		case principalResource:
+			prop.Inner = element(xml.Name{Space: davNS, Local: "collection"}) + element(xml.Name{Space: davNS, Local: "principal"})
+		case calendarResource:
+			prop.Inner = element(xml.Name{Space: davNS, Local: "collection"}) + element(xml.Name{Space: caldavNS, Local: "calendar"})
+		case rootResource, homeResource:
+			prop.Inner = element(xml.Name{Space: davNS, Local: "collection"})
+		}
+	case displayNameProp:
+		prop.Text = calendarName
+		if t.res == principalResource {
+			prop.Text = p.s.email
+		}
+	case currentUserPrincipalProp, principalURLProp:
+		prop.Inner = hrefElement(principalPath)
+	case calendarHomeSetProp:
+		prop.Inner = hrefElement(homePath)
+	case currentUserPrivilegeSetProp:
+		// properties cannot be changed, so the user has every privilege but write-properties
+		for _, privilege := range []string{"read", "write-content", "bind", "unbind"} {
+			prop.Inner += `<privilege xmlns="DAV:"><` + privilege + `/></privilege>`
+		}
+	case supportedReportSetProp:
+		for _, report := range []xml.Name{calendarQueryReport, calendarMultigetReport} {
+			prop.Inner += `<supported-report xmlns="DAV:"><report>` + element(report) + `</report></supported-report>`
+		}
+	case supportedCalendarComponentSetProp:
+		prop.Inner = `<comp xmlns="` + caldavNS + `" name="VTODO"/>`
+	case getCTagProp:
+		objs, err := p.calendarObjects()
+		if err != nil {
+			return prop, err
+		}
+		prop.Text = collectionTag(objs)
+	case getETagProp:
+		prop.Text = t.obj.etag
+	case getContentTypeProp:
+		prop.Text = objectContentType
+	case calendarDataProp:
+		prop.Text = string(t.obj.data)
+	}
+	return prop, nil
+}
+
+// collectionTag changes whenever a todo of the calendar is added, changed or removed,
+// clients skip syncing while it stays the same.
+func collectionTag(objs []*object) string {
+	hash := sha256.New()
+	for _, obj := range objs {
+		hash.Write([]byte(obj.href() + obj.etag))
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
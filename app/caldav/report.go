@@ -0,0 +1,381 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	calendarQueryReport    = xml.Name{Space: caldavNS, Local: "calendar-query"}
+	calendarMultigetReport = xml.Name{Space: caldavNS, Local: "calendar-multiget"}
+
+	supportedReport    = xml.Name{Space: davNS, Local: "supported-report"}
+	validFilter        = xml.Name{Space: caldavNS, Local: "valid-filter"}
+	supportedFilter    = xml.Name{Space: caldavNS, Local: "supported-filter"}
+	supportedCollation = xml.Name{Space: caldavNS, Local: "supported-collation"}
+)
+
+var (
+	errUnsupportedReport    = errors.New("only the calendar-query and calendar-multiget reports are supported")
+	errInvalidFilter        = errors.New("invalid calendar-query filter")
+	errUnsupportedFilter    = errors.New("param-filter is not supported")
+	errUnsupportedCollation = errors.New("collation must be one of i;ascii-casemap, i;unicode-casemap, i;octet")
+)
+
+// reportBody is the body of either report, the name of the root element tells them apart.
+type reportBody struct {
+	XMLName  xml.Name
+	AllProp  *struct{}  `xml:"DAV: allprop"`
+	PropName *struct{}  `xml:"DAV: propname"`
+	Prop     *propNames `xml:"DAV: prop"`
+	Filter   *struct {
+		CompFilter *compFilter `xml:"comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	Hrefs []string `xml:"DAV: href"`
+}
+
+func (b *reportBody) propRequest() *propRequest {
+	switch {
+	case b.Prop != nil:
+		return b.Prop.request()
+	case b.PropName != nil:
+		return &propRequest{namesOnly: true}
+	}
+	return &propRequest{all: true}
+}
+
+// report answers a calendar-query with the todos that pass its filter, and a
+// calendar-multiget with the todos it names. Both apply to the calendar or to a todo.
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, s *session, res resource, id uuid.UUID) {
+	body, err := readBody(r)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var rb reportBody
+	if err := xml.Unmarshal(body, &rb); err != nil {
+		h.fail(w, r, http.StatusBadRequest, fmt.Errorf("%w: %v", errInvalidXML, err))
+		return
+	}
+
+	p := &propfinder{h: h, ctx: r.Context(), s: s}
+	var targets []target
+	switch rb.XMLName {
+	case calendarQueryReport:
+		var filter *compFilter
+		if rb.Filter != nil && rb.Filter.CompFilter != nil {
+			filter = rb.Filter.CompFilter
+			if err := filter.validate(true); err != nil {
+				switch {
+				case errors.Is(err, errUnsupportedFilter):
+					h.failCondition(w, r, supportedFilter, err)
+				case errors.Is(err, errUnsupportedCollation):
+					h.failCondition(w, r, supportedCollation, err)
+				default:
+					h.failCondition(w, r, validFilter, err)
+				}
+				return
+			}
+		}
+		objs, err := h.reportObjects(p, res, id)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		for _, obj := range objs {
+			if filter == nil || filter.matchesCalendar(obj) {
+				targets = append(targets, objectTarget(obj))
+			}
+		}
+	case calendarMultigetReport:
+		objs, err := h.reportObjects(p, res, id)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		byHref := make(map[string]*object, len(objs))
+		for _, obj := range objs {
+			byHref[obj.href()] = obj
+		}
+		for _, href := range rb.Hrefs {
+			obj := byHref[hrefPath(href)]
+			if obj == nil {
+				targets = append(targets, target{href: href})
+				continue
+			}
+			targets = append(targets, objectTarget(obj))
+		}
+	default:
+		h.failCondition(w, r, supportedReport, errUnsupportedReport)
+		return
+	}
+
+	req := rb.propRequest()
+	ms := &multistatus{}
+	for _, t := range targets {
+		if t.obj == nil {
+			ms.Responses = append(ms.Responses, response{Href: t.href, Status: statusLine(http.StatusNotFound)})
+			continue
+		}
+		resp, err := p.respond(t, req)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		ms.Responses = append(ms.Responses, resp)
+	}
+	if err := writeMultistatus(w, ms); err != nil {
+		h.log(r, http.StatusInternalServerError, err)
+	}
+}
+
+// reportObjects returns the todos a report on the resource applies to, the other
+// collections hold no todos.
+func (h *Handler) reportObjects(p *propfinder, res resource, id uuid.UUID) ([]*object, error) {
+	switch res {
+	case calendarResource:
+		return p.calendarObjects()
+	case objectResource:
+		obj, err := h.findObject(p.ctx, p.s.userId, id)
+		if err != nil || obj == nil {
+			return nil, err
+		}
+		return []*object{obj}, nil
+	}
+	return nil, nil
+}
+
+// hrefPath returns the path of an href, clients send either a path or a full URL.
+func hrefPath(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return u.Path
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"is-not-defined"`
+	TimeRange    *timeRange   `xml:"time-range"`
+	CompFilters  []compFilter `xml:"comp-filter"`
+	PropFilters  []propFilter `xml:"prop-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"is-not-defined"`
+	TimeRange    *timeRange `xml:"time-range"`
+	TextMatch    *textMatch `xml:"text-match"`
+	ParamFilters []struct{} `xml:"param-filter"`
+}
+
+type textMatch struct {
+	Text            string `xml:",chardata"`
+	Collation       string `xml:"collation,attr"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+// timeRange is the half-open interval [start, end), either bound may be left out.
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+	start time.Time
+	end   time.Time
+}
+
+// endOfTime stands for a time range without an end.
+var endOfTime = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// validate checks the filter and parses its time ranges, the top comp-filter must be
+// the VCALENDAR.
+func (f *compFilter) validate(top bool) error {
+	if top && !strings.EqualFold(f.Name, "VCALENDAR") {
+		return fmt.Errorf("%w: the top comp-filter must be VCALENDAR", errInvalidFilter)
+	}
+	if f.TimeRange != nil {
+		if err := f.TimeRange.parse(); err != nil {
+			return err
+		}
+	}
+	for i := range f.CompFilters {
+		if err := f.CompFilters[i].validate(false); err != nil {
+			return err
+		}
+	}
+	for i := range f.PropFilters {
+		if err := f.PropFilters[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *propFilter) validate() error {
+	if len(f.ParamFilters) > 0 {
+		return errUnsupportedFilter
+	}
+	if f.TextMatch != nil {
+		switch f.TextMatch.Collation {
+		case "", "i;ascii-casemap", "i;unicode-casemap", "i;octet":
+		default:
+			return errUnsupportedCollation
+		}
+	}
+	if f.TimeRange != nil {
+		return f.TimeRange.parse()
+	}
+	return nil
+}
+
+func (r *timeRange) parse() error {
+	r.start, r.end = time.Time{}, endOfTime
+	var err error
+	if r.Start != "" {
+		if r.start, err = time.Parse("20060102T150405Z", r.Start); err != nil {
+			return fmt.Errorf("%w: time-range start must be a UTC date-time", errInvalidFilter)
+		}
+	}
+	if r.End != "" {
+		if r.end, err = time.Parse("20060102T150405Z", r.End); err != nil {
+			return fmt.Errorf("%w: time-range end must be a UTC date-time", errInvalidFilter)
+		}
+	}
+	return nil
+}
+
+func (r *timeRange) contains(t time.Time) bool {
+	return !t.Before(r.start) && t.Before(r.end)
+}
+
+// matchesTodo tells whether a todo overlaps the time range, following the rules of
+// RFC 4791 section 9.9 for a VTODO without DTSTART, which the todos never have.
+func (r *timeRange) matchesTodo(props map[string][]contentLine) bool {
+	due, hasDue := firstTime(props, "DUE")
+	completed, hasCompleted := firstTime(props, "COMPLETED")
+	created, hasCreated := firstTime(props, "CREATED")
+
+	switch {
+	case hasDue:
+		return r.start.Before(due) && !r.end.Before(due)
+	case hasCompleted && hasCreated:
+		return (!created.Before(r.start) || !completed.Before(r.start)) &&
+			(!r.end.Before(created) || !r.end.Before(completed))
+	case hasCompleted:
+		return !completed.Before(r.start) && !r.end.Before(completed)
+	case hasCreated:
+		return r.end.After(created)
+	}
+	return true
+}
+
+func firstTime(props map[string][]contentLine, name string) (time.Time, bool) {
+	for _, line := range props[name] {
+		if t, err := parseDateTime(line); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// matchesCalendar applies the VCALENDAR filter to the object, which holds a single VTODO.
+func (f *compFilter) matchesCalendar(obj *object) bool {
+	if f.IsNotDefined != nil {
+		return false
+	}
+	props := objectProperties(obj)
+	for _, cf := range f.CompFilters {
+		if !cf.matchesTodo(props) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTodo applies a filter one level below the VCALENDAR, a filter on any other
+// component than VTODO only passes when it asks for the component to be missing.
+func (f *compFilter) matchesTodo(props map[string][]contentLine) bool {
+	if !strings.EqualFold(f.Name, "VTODO") {
+		return f.IsNotDefined != nil
+	}
+	if f.IsNotDefined != nil {
+		return false
+	}
+	if f.TimeRange != nil && !f.TimeRange.matchesTodo(props) {
+		return false
+	}
+	for _, pf := range f.PropFilters {
+		if !pf.matches(props) {
+			return false
+		}
+	}
+	// the todos have no nested components, like alarms
+	for _, cf := range f.CompFilters {
+		if cf.IsNotDefined == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *propFilter) matches(props map[string][]contentLine) bool {
+	lines := props[strings.ToUpper(f.Name)]
+	switch {
+	case f.IsNotDefined != nil:
+		return len(lines) == 0
+	case len(lines) == 0:
+		return false
+	case f.TimeRange != nil:
+		for _, line := range lines {
+			if t, err := parseDateTime(line); err == nil && f.TimeRange.contains(t) {
+				return true
+			}
+		}
+		return false
+	case f.TextMatch != nil:
+		for _, line := range lines {
+			if f.TextMatch.matches(unescapeText(line.value)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (m *textMatch) matches(value string) bool {
+	var found bool
+	if m.Collation == "i;octet" {
+		found = strings.Contains(value, m.Text)
+	} else {
+		found = strings.Contains(strings.ToLower(value), strings.ToLower(m.Text))
+	}
+	return found != (m.NegateCondition == "yes")
+}
+
+// objectProperties returns the properties of the VTODO of the object by their name,
+// as the client sees them.
+func objectProperties(obj *object) map[string][]contentLine {
+	props := map[string][]contentLine{}
+	// the data comes from the export, it always parses
+	lines, _ := unfoldLines(string(obj.data))
+	inTodo := false
+	for _, line := range lines {
+		switch {
+		case line.name == "BEGIN" && line.value == "VTODO":
+			inTodo = true
+		case line.name == "END" && line.value == "VTODO":
+			inTodo = false
+		case inTodo:
+			props[line.name] = append(props[line.name], line)
+		}
+	}
+	return props
+}
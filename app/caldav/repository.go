@@ -0,0 +1,15 @@
+package caldav
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// AuthRepository signs CalDAV clients in with the app passwords of the users.
+type AuthRepository interface {
+	// AuthenticateAppPassword returns the id and the role of the user with the email who
+	// has an app password with the hash, see domain.HashAppPassword, and records that the
+	// app password was used. It returns domain.ErrInvalidCredentials when there is none.
+	AuthenticateAppPassword(ctx context.Context, email, hash string) (uuid.UUID, string, error)
+}
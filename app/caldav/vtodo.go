@@ -0,0 +1,229 @@
+package caldav
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// vtodo holds the properties of a VTODO the API stores, the others are dropped.
+type vtodo struct {
+	uid         string
+	summary     string
+	description string
+	due         time.Time
+	priority    domain.Priority
+	completed   bool
+	completedAt time.Time
+}
+
+var (
+	errInvalidCalendarData  = errors.New("invalid iCalendar data")
+	errUnsupportedComponent = errors.New("a calendar object must hold exactly one VTODO")
+)
+
+// contentLine is a property of an iCalendar object, with its name and the names of its
+// parameters in upper case. The value is still escaped.
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseVTodo reads a VCALENDAR holding a single VTODO. Time zones are looked up by
+// their TZID in the time zone database, the VTIMEZONE components are not read, and
+// the components nested in the VTODO, like its alarms, are ignored.
+func parseVTodo(data []byte) (*vtodo, error) {
+	lines, err := unfoldLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var vt *vtodo
+	var status string
+	var stack []string
+	for _, line := range lines {
+		switch line.name {
+		case "BEGIN":
+			component := strings.ToUpper(line.value)
+			switch {
+			case len(stack) == 0 && component != "VCALENDAR":
+				return nil, fmt.Errorf("%w: it must start with BEGIN:VCALENDAR", errInvalidCalendarData)
+			case len(stack) == 1 && component == "VTODO":
+				if vt != nil {
+					return nil, errUnsupportedComponent
+				}
+				vt = &vtodo{priority: domain.PriorityNone}
+			case len(stack) == 1 && component != "VTIMEZONE":
+				return nil, errUnsupportedComponent
+			}
+			stack = append(stack, component)
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(line.value) {
+				return nil, fmt.Errorf("%w: END:%s does not close a component", errInvalidCalendarData, line.value)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if len(stack) != 2 || stack[1] != "VTODO" {
+			continue
+		}
+		if err := vt.set(line, &status); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errInvalidCalendarData, line.name, err)
+		}
+	}
+
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("%w: %s is not closed", errInvalidCalendarData, stack[len(stack)-1])
+	}
+	if vt == nil {
+		return nil, errUnsupportedComponent
+	}
+	if vt.uid == "" {
+		return nil, fmt.Errorf("%w: the VTODO has no UID", errInvalidCalendarData)
+	}
+
+	// clients that reopen a todo may leave its COMPLETED behind, the status decides
+	vt.completed = status == "COMPLETED" || (status == "" && !vt.completedAt.IsZero())
+	return vt, nil
+}
+
+func (vt *vtodo) set(line contentLine, status *string) error {
+	var err error
+	switch line.name {
+	case "UID":
+		vt.uid = unescapeText(line.value)
+	case "SUMMARY":
+		vt.summary = strings.TrimSpace(unescapeText(line.value))
+	case "DESCRIPTION":
+		vt.description = unescapeText(line.value)
+	case "DUE":
+		vt.due, err = parseDateTime(line)
+	case "COMPLETED":
+		vt.completedAt, err = parseDateTime(line)
+	case "STATUS":
+		*status = strings.ToUpper(line.value)
+	case "PRIORITY":
+		vt.priority, err = parsePriority(line.value)
+	}
+	return err
+}
+
+// unfoldLines joins the folded lines and splits every line into its parts. Lines may
+// end in CRLF or, as some clients send them, in LF alone.
+func unfoldLines(data string) ([]contentLine, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	var lines []contentLine
+	for _, raw := range strings.Split(data, "\n") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		line, err := parseContentLine(raw)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value", the colons and semicolons between
+// double quotes belong to the parameter value.
+func parseContentLine(raw string) (contentLine, error) {
+	quoted := false
+	colon := -1
+	for i, r := range raw {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return contentLine{}, fmt.Errorf("%w: %q is not a content line", errInvalidCalendarData, raw)
+	}
+
+	parts := splitUnquoted(raw[:colon], ';')
+	line := contentLine{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  raw[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		line.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return line, nil
+}
+
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, r := range s {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == sep && !quoted {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeText(value string) string {
+	return textUnescaper.Replace(value)
+}
+
+// parseDateTime reads a UTC time, a time in the zone named by TZID or a date, which
+// stands for midnight UTC like a date-only due date of the API. A floating time, bound
+// to no zone, is taken as UTC.
+func parseDateTime(line contentLine) (time.Time, error) {
+	value := strings.TrimSpace(line.value)
+	if line.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.Parse("20060102", value)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+
+	location := time.UTC
+	if tzid := line.params["TZID"]; tzid != "" {
+		var err error
+		if location, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, domain.ErrInvalidTimezone
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, location)
+}
+
+// parsePriority maps the 1 (highest) to 9 (lowest) scale of RFC 5545 onto the
+// priorities, the inverse of the mapping of the export. 0 means undefined.
+func parsePriority(value string) (domain.Priority, error) {
+	priority, err := strconv.Atoi(strings.TrimSpace(value))
+	switch {
+	case err != nil || priority < 0 || priority > 9:
+		return "", errors.New("priority must be between 0 and 9")
+	case priority == 0:
+		return domain.PriorityNone, nil
+	case priority == 1:
+		return domain.PriorityUrgent, nil
+	case priority <= 4:
+		return domain.PriorityHigh, nil
+	case priority == 5:
+		return domain.PriorityMedium, nil
+	}
+	return domain.PriorityLow, nil
+}
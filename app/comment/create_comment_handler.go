@@ -0,0 +1,59 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type CreateCommentRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Body   string    `json:"body" validate:"required,max=2000"`
+}
+
+type CreateCommentResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type CreateCommentHandler struct {
+	repo CommentRepository
+}
+
+func NewCreateCommentHandler(repo CommentRepository) *CreateCommentHandler {
+	return &CreateCommentHandler{repo: repo}
+}
+
+// CreateCommentHandler adds a comment to a todo.
+//
+//	@Summary		Create a comment
+//	@Description	Adds a comment to a todo. Every user who can view the todo can comment on it. The body is trimmed and cannot exceed 2000 characters.
+//	@Tags			Comment
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Todo ID"
+//	@Param			CreateCommentRequest	body		CreateCommentRequest	true	"Comment details"
+//	@Success		201						{object}	CreateCommentResponse
+//	@Failure		400						"Invalid request"
+//	@Failure		401						"Unauthorized"
+//	@Failure		404						"Todo not found"
+//	@Failure		500						"Internal server error"
+//	@Router			/todos/{id}/comments [post]
+func (h *CreateCommentHandler) Handle(ctx context.Context, req *CreateCommentRequest) (*CreateCommentResponse, int, error) {
+	comment, err := domain.NewComment(req.TodoId, domain.GetUserID(ctx), req.Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := h.repo.CreateComment(ctx, comment); err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &CreateCommentResponse{Id: comment.Id}, http.StatusCreated, nil
+}
@@ -0,0 +1,51 @@
+package comment
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteCommentRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid"`
+	Id     uuid.UUID `params:"commentId" validate:"required,uuid"`
+}
+
+type DeleteCommentResponse struct {
+}
+
+type DeleteCommentHandler struct {
+	repo CommentRepository
+}
+
+func NewDeleteCommentHandler(repo CommentRepository) *DeleteCommentHandler {
+	return &DeleteCommentHandler{repo: repo}
+}
+
+// DeleteCommentHandler removes a comment from a todo.
+//
+//	@Summary		Delete a comment
+//	@Description	Deletes a comment. Only the author of the comment or an admin can delete it.
+//	@Tags			Comment
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	string	true	"Todo ID"
+//	@Param			commentId	path	string	true	"Comment ID"
+//	@Success		204			"Comment deleted successfully"
+//	@Failure		400			"Invalid request"
+//	@Failure		401			"Unauthorized"
+//	@Failure		403			"Not the author of the comment"
+//	@Failure		404			"Todo or comment not found"
+//	@Failure		500			"Internal server error"
+//	@Router			/todos/{id}/comments/{commentId} [delete]
+func (h *DeleteCommentHandler) Handle(ctx context.Context, req *DeleteCommentRequest) (*DeleteCommentResponse, int, error) {
+	asAdmin := domain.GetRole(ctx) == domain.AdminRole
+	if err := h.repo.DeleteComment(ctx, req.Id, req.TodoId, domain.GetUserID(ctx), asAdmin); err != nil {
+		return nil, commentErrorStatus(err), err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
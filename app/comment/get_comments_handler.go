@@ -0,0 +1,67 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetCommentsRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type GetCommentsResponse []Comment
+
+// Author is null once the author deletes their account, UpdatedAt is null until the
+// comment is edited.
+type Comment struct {
+	Id        uuid.UUID      `json:"id"`
+	Body      string         `json:"body"`
+	Author    *CommentAuthor `json:"author"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at"`
+}
+
+type CommentAuthor struct {
+	Id       uuid.UUID `json:"id"`
+	FullName string    `json:"full_name"`
+}
+
+type GetCommentsHandler struct {
+	repo CommentRepository
+}
+
+func NewGetCommentsHandler(repo CommentRepository) *GetCommentsHandler {
+	return &GetCommentsHandler{repo: repo}
+}
+
+// GetCommentsHandler lists the comments of a todo.
+//
+//	@Summary		Get comments of a todo
+//	@Description	Retrieves the comments of a todo, oldest first, with their authors.
+//	@Tags			Comment
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Todo ID"
+//	@Success		200	{object}	GetCommentsResponse
+//	@Failure		400	"Invalid request"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Todo not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/{id}/comments [get]
+func (h *GetCommentsHandler) Handle(ctx context.Context, req *GetCommentsRequest) (*GetCommentsResponse, int, error) {
+	comments, err := h.repo.GetComments(ctx, req.TodoId, domain.GetUserID(ctx))
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return comments, http.StatusOK, nil
+}
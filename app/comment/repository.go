@@ -0,0 +1,19 @@
+package comment
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Every user who can view a todo can comment on it and read its comments. A todo the
+// user cannot view is reported as domain.ErrTodoNotFound. Updates and deletes are
+// limited to the author unless asAdmin is set, in which case the todo need not be
+// visible to the user either.
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment *domain.Comment) error
+	GetComments(ctx context.Context, todoId, userId uuid.UUID) (*GetCommentsResponse, error)
+	UpdateComment(ctx context.Context, id, todoId, userId uuid.UUID, asAdmin bool, body string) error
+	DeleteComment(ctx context.Context, id, todoId, userId uuid.UUID, asAdmin bool) error
+}
@@ -0,0 +1,70 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type UpdateCommentRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Id     uuid.UUID `params:"commentId" validate:"required,uuid" swaggerignore:"true"`
+	Body   string    `json:"body" validate:"required,max=2000"`
+}
+
+type UpdateCommentResponse struct {
+}
+
+type UpdateCommentHandler struct {
+	repo CommentRepository
+}
+
+func NewUpdateCommentHandler(repo CommentRepository) *UpdateCommentHandler {
+	return &UpdateCommentHandler{repo: repo}
+}
+
+// UpdateCommentHandler edits a comment.
+//
+//	@Summary		Update a comment
+//	@Description	Replaces the body of a comment. Only the author of the comment or an admin can edit it.
+//	@Tags			Comment
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path	string					true	"Todo ID"
+//	@Param			commentId				path	string					true	"Comment ID"
+//	@Param			UpdateCommentRequest	body	UpdateCommentRequest	true	"Comment details"
+//	@Success		204						"Comment updated successfully"
+//	@Failure		400						"Invalid request"
+//	@Failure		401						"Unauthorized"
+//	@Failure		403						"Not the author of the comment"
+//	@Failure		404						"Todo or comment not found"
+//	@Failure		500						"Internal server error"
+//	@Router			/todos/{id}/comments/{commentId} [put]
+func (h *UpdateCommentHandler) Handle(ctx context.Context, req *UpdateCommentRequest) (*UpdateCommentResponse, int, error) {
+	body, err := domain.NormalizeCommentBody(req.Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	asAdmin := domain.GetRole(ctx) == domain.AdminRole
+	if err := h.repo.UpdateComment(ctx, req.Id, req.TodoId, domain.GetUserID(ctx), asAdmin, body); err != nil {
+		return nil, commentErrorStatus(err), err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
+
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound), errors.Is(err, domain.ErrCommentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotCommentAuthor):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
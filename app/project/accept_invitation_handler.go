@@ -0,0 +1,56 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type AcceptInvitationRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type AcceptInvitationResponse struct {
+	ProjectId uuid.UUID `json:"project_id"`
+}
+
+type AcceptInvitationHandler struct {
+	repo ProjectRepository
+}
+
+func NewAcceptInvitationHandler(repo ProjectRepository) *AcceptInvitationHandler {
+	return &AcceptInvitationHandler{repo: repo}
+}
+
+// AcceptInvitationHandler joins the project of an invitation.
+//
+//	@Summary		Accept an invitation
+//	@Description	Makes the authenticated user a member of the project with the role of the invitation. The invitation must have been sent to the email of the user.
+//	@Tags			Invitation
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Invitation ID"
+//	@Success		200	{object}	AcceptInvitationResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Invitation not found"
+//	@Failure		409	"The user is already a member"
+//	@Failure		500	"Internal server error"
+//	@Router			/invitations/{id}/accept [post]
+func (h *AcceptInvitationHandler) Handle(ctx context.Context, req *AcceptInvitationRequest) (*AcceptInvitationResponse, int, error) {
+	projectId, err := h.repo.AcceptInvitation(ctx, req.Id, domain.GetUserID(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvitationNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrAlreadyProjectMember):
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &AcceptInvitationResponse{ProjectId: projectId}, http.StatusOK, nil
+}
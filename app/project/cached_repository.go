@@ -0,0 +1,90 @@
+package project
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// CachedProjectRepository keeps the cached todo lists consistent with project changes.
+// Deleting a project either deletes its todos or moves them to the inbox, both change
+// the lists of every member. Joining or leaving a project adds or removes its todos
+// from the lists of the member. Todos only carry the project id, so the other writes
+// leave them alone.
+type CachedProjectRepository struct {
+	repo      ProjectRepository
+	todoLists TodoListInvalidator
+}
+
+func NewCachedProjectRepository(repo ProjectRepository, todoLists TodoListInvalidator) *CachedProjectRepository {
+	return &CachedProjectRepository{repo: repo, todoLists: todoLists}
+}
+
+func (r *CachedProjectRepository) CreateProject(ctx context.Context, project *domain.Project) error {
+	return r.repo.CreateProject(ctx, project)
+}
+
+func (r *CachedProjectRepository) GetProjectsByUserID(ctx context.Context, userId uuid.UUID, includeArchived bool) (*GetProjectsResponse, error) {
+	return r.repo.GetProjectsByUserID(ctx, userId, includeArchived)
+}
+
+func (r *CachedProjectRepository) UpdateProject(ctx context.Context, project *domain.Project) error {
+	return r.repo.UpdateProject(ctx, project)
+}
+
+// The members are looked up first, once the project is deleted they no longer share it.
+func (r *CachedProjectRepository) DeleteProject(ctx context.Context, id, userId uuid.UUID, mode domain.ProjectDeleteMode) error {
+	members, err := r.repo.GetMembers(ctx, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repo.DeleteProject(ctx, id, userId, mode); err != nil {
+		return err
+	}
+	for _, member := range members.Members {
+		r.todoLists.InvalidateTodoLists(member.UserId)
+	}
+	return nil
+}
+
+func (r *CachedProjectRepository) InviteMember(ctx context.Context, invitation *domain.ProjectInvitation) error {
+	return r.repo.InviteMember(ctx, invitation)
+}
+
+func (r *CachedProjectRepository) GetMembers(ctx context.Context, projectId, userId uuid.UUID) (*GetMembersResponse, error) {
+	return r.repo.GetMembers(ctx, projectId, userId)
+}
+
+// Every role can read the todos, changing it leaves the lists as they are.
+func (r *CachedProjectRepository) UpdateMemberRole(ctx context.Context, projectId, memberId uuid.UUID, role domain.ProjectRole, userId uuid.UUID) error {
+	return r.repo.UpdateMemberRole(ctx, projectId, memberId, role, userId)
+}
+
+// The access is revoked right away, the removed member no longer sees the todos of the
+// project in a cached list either.
+func (r *CachedProjectRepository) RemoveMember(ctx context.Context, projectId, memberId, userId uuid.UUID) error {
+	if err := r.repo.RemoveMember(ctx, projectId, memberId, userId); err != nil {
+		return err
+	}
+	r.todoLists.InvalidateTodoLists(memberId)
+	return nil
+}
+
+func (r *CachedProjectRepository) GetInvitations(ctx context.Context, userId uuid.UUID) (*GetInvitationsResponse, error) {
+	return r.repo.GetInvitations(ctx, userId)
+}
+
+func (r *CachedProjectRepository) AcceptInvitation(ctx context.Context, id, userId uuid.UUID) (uuid.UUID, error) {
+	projectId, err := r.repo.AcceptInvitation(ctx, id, userId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	r.todoLists.InvalidateTodoLists(userId)
+	return projectId, nil
+}
+
+func (r *CachedProjectRepository) DeleteInvitation(ctx context.Context, id, userId uuid.UUID) error {
+	return r.repo.DeleteInvitation(ctx, id, userId)
+}
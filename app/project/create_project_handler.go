@@ -0,0 +1,58 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type CreateProjectRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Color string `json:"color"`
+}
+
+type CreateProjectResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type CreateProjectHandler struct {
+	repo ProjectRepository
+}
+
+func NewCreateProjectHandler(repo ProjectRepository) *CreateProjectHandler {
+	return &CreateProjectHandler{repo: repo}
+}
+
+// CreateProjectHandler handles the creation of a new project.
+//
+//	@Summary		Create a project
+//	@Description	Creates a project for the authenticated user, it is placed after the existing ones. The color defaults to #3b82f6.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			CreateProjectRequest	body		CreateProjectRequest	true	"Project details"
+//	@Success		201						{object}	CreateProjectResponse
+//	@Failure		400						"Invalid request"
+//	@Failure		401						"Unauthorized"
+//	@Failure		404						"User not found"
+//	@Failure		500						"Internal server error"
+//	@Router			/projects [post]
+func (h *CreateProjectHandler) Handle(ctx context.Context, req *CreateProjectRequest) (*CreateProjectResponse, int, error) {
+	project, err := domain.NewProject(domain.GetUserID(ctx), req.Name, req.Color, false, 0)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err = h.repo.CreateProject(ctx, project); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &CreateProjectResponse{Id: project.Id}, http.StatusCreated, nil
+}
@@ -0,0 +1,50 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteInvitationRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type DeleteInvitationResponse struct {
+}
+
+type DeleteInvitationHandler struct {
+	repo ProjectRepository
+}
+
+func NewDeleteInvitationHandler(repo ProjectRepository) *DeleteInvitationHandler {
+	return &DeleteInvitationHandler{repo: repo}
+}
+
+// DeleteInvitationHandler declines or cancels an invitation.
+//
+//	@Summary		Delete an invitation
+//	@Description	Declines an invitation sent to the authenticated user. The owners of the project can cancel it as well.
+//	@Tags			Invitation
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Invitation ID"
+//	@Success		204	"Invitation deleted successfully"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Invitation not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/invitations/{id} [delete]
+func (h *DeleteInvitationHandler) Handle(ctx context.Context, req *DeleteInvitationRequest) (*DeleteInvitationResponse, int, error) {
+	if err := h.repo.DeleteInvitation(ctx, req.Id, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrInvitationNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,66 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteProjectRequest struct {
+	Id    uuid.UUID `params:"id" validate:"required,uuid"`
+	Todos string    `query:"todos" validate:"omitempty,oneof=inbox cascade"`
+}
+
+type DeleteProjectResponse struct {
+}
+
+type DeleteProjectHandler struct {
+	repo ProjectRepository
+}
+
+func NewDeleteProjectHandler(repo ProjectRepository) *DeleteProjectHandler {
+	return &DeleteProjectHandler{repo: repo}
+}
+
+// DeleteProjectHandler handles the deletion of a project.
+//
+//	@Summary		Delete a project
+//	@Description	Deletes a project the authenticated user owns. Its todos are moved to the inbox by default, todos=cascade deletes them as well.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string	true	"Project ID"
+//	@Param			todos	query	string	false	"What happens to the todos of the project, inbox by default"	Enums(inbox, cascade)
+//	@Success		204		"Project deleted successfully"
+//	@Failure		400		"Invalid request"
+//	@Failure		401		"Unauthorized"
+//	@Failure		403		"Only owners can delete the project"
+//	@Failure		404		"Project not found"
+//	@Failure		500		"Internal server error"
+//	@Router			/projects/{id} [delete]
+func (h *DeleteProjectHandler) Handle(ctx context.Context, req *DeleteProjectRequest) (*DeleteProjectResponse, int, error) {
+	mode := domain.ProjectDeleteMode(req.Todos)
+	switch mode {
+	case "":
+		mode = domain.ProjectDeleteMoveToInbox
+	case domain.ProjectDeleteMoveToInbox, domain.ProjectDeleteCascade:
+	default:
+		return nil, http.StatusBadRequest, domain.ErrInvalidDeleteMode
+	}
+
+	if err := h.repo.DeleteProject(ctx, req.Id, domain.GetUserID(ctx), mode); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProjectNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrNotProjectOwner):
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
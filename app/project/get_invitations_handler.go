@@ -0,0 +1,42 @@
+package project
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetInvitationsRequest struct {
+}
+
+type GetInvitationsResponse []Invitation
+
+type GetInvitationsHandler struct {
+	repo ProjectRepository
+}
+
+func NewGetInvitationsHandler(repo ProjectRepository) *GetInvitationsHandler {
+	return &GetInvitationsHandler{repo: repo}
+}
+
+// GetInvitationsHandler lists the invitations waiting for the authenticated user.
+//
+//	@Summary		Get the invitations of the user
+//	@Description	Lists the pending project invitations sent to the email of the authenticated user, newest first.
+//	@Tags			Invitation
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	GetInvitationsResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/invitations [get]
+func (h *GetInvitationsHandler) Handle(ctx context.Context, req *GetInvitationsRequest) (*GetInvitationsResponse, int, error) {
+	invitations, err := h.repo.GetInvitations(ctx, domain.GetUserID(ctx))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return invitations, http.StatusOK, nil
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetMembersRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+// GetMembersResponse lists the members of a project, owners first, and the invitations
+// that have not been accepted yet.
+type GetMembersResponse struct {
+	Members     []Member     `json:"members"`
+	Invitations []Invitation `json:"invitations"`
+}
+
+type Member struct {
+	UserId   uuid.UUID          `json:"user_id"`
+	FullName string             `json:"full_name"`
+	Email    string             `json:"email"`
+	Role     domain.ProjectRole `json:"role"`
+	JoinedAt time.Time          `json:"joined_at"`
+}
+
+type Invitation struct {
+	Id          uuid.UUID          `json:"id"`
+	ProjectId   uuid.UUID          `json:"project_id"`
+	ProjectName string             `json:"project_name"`
+	Email       string             `json:"email"`
+	Role        domain.ProjectRole `json:"role"`
+	// InvitedBy is nil once the user who sent the invitation has deleted their account.
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type GetMembersHandler struct {
+	repo ProjectRepository
+}
+
+func NewGetMembersHandler(repo ProjectRepository) *GetMembersHandler {
+	return &GetMembersHandler{repo: repo}
+}
+
+// GetMembersHandler lists who a project is shared with.
+//
+//	@Summary		Get the members of a project
+//	@Description	Lists the members of a project the authenticated user is a member of, with their roles, and the pending invitations.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Project ID"
+//	@Success		200	{object}	GetMembersResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Project not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/projects/{id}/members [get]
+func (h *GetMembersHandler) Handle(ctx context.Context, req *GetMembersRequest) (*GetMembersResponse, int, error) {
+	members, err := h.repo.GetMembers(ctx, req.Id, domain.GetUserID(ctx))
+	if err != nil {
+		if errors.Is(err, domain.ErrProjectNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return members, http.StatusOK, nil
+}
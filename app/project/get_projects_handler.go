@@ -0,0 +1,57 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetProjectsRequest struct {
+	IncludeArchived bool `query:"include_archived"`
+}
+
+type GetProjectsResponse []Project
+
+type Project struct {
+	Id        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Archived  bool      `json:"archived"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	// Role is the role of the authenticated user in the project.
+	Role domain.ProjectRole `json:"role"`
+}
+
+type GetProjectsHandler struct {
+	repo ProjectRepository
+}
+
+func NewGetProjectsHandler(repo ProjectRepository) *GetProjectsHandler {
+	return &GetProjectsHandler{repo: repo}
+}
+
+// GetProjectsHandler lists the projects of the authenticated user, shared ones included.
+//
+//	@Summary		Get all projects
+//	@Description	Retrieves the projects the authenticated user is a member of, their own and the ones shared with them, ordered by position. Archived projects are left out unless include_archived is set.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			include_archived	query		bool	false	"Include archived projects"
+//	@Success		200					{object}	GetProjectsResponse
+//	@Failure		401					"Unauthorized"
+//	@Failure		500					"Internal server error"
+//	@Router			/projects [get]
+func (h *GetProjectsHandler) Handle(ctx context.Context, req *GetProjectsRequest) (*GetProjectsResponse, int, error) {
+	projects, err := h.repo.GetProjectsByUserID(ctx, domain.GetUserID(ctx), req.IncludeArchived)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return projects, http.StatusOK, nil
+}
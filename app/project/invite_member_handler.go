@@ -0,0 +1,72 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type InviteMemberRequest struct {
+	Id    uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Email string    `json:"email" validate:"required,email"`
+	Role  string    `json:"role" validate:"required,oneof=viewer editor owner"`
+}
+
+type InviteMemberResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type InviteMemberHandler struct {
+	repo ProjectRepository
+}
+
+func NewInviteMemberHandler(repo ProjectRepository) *InviteMemberHandler {
+	return &InviteMemberHandler{repo: repo}
+}
+
+// InviteMemberHandler shares a project by email.
+//
+//	@Summary		Invite a member into a project
+//	@Description	Invites a user by email into a project of the authenticated user as viewer, editor or owner. The project is shared once the invitation is accepted, the user may sign up in between. Only owners can invite, inviting the same email again replaces the role.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		string				true	"Project ID"
+//	@Param			InviteMemberRequest	body		InviteMemberRequest	true	"Email and role"
+//	@Success		201					{object}	InviteMemberResponse
+//	@Failure		400					"Invalid email or role"
+//	@Failure		401					"Unauthorized"
+//	@Failure		403					"Only owners can invite"
+//	@Failure		404					"Project not found"
+//	@Failure		409					"The user is already a member"
+//	@Failure		500					"Internal server error"
+//	@Router			/projects/{id}/invitations [post]
+func (h *InviteMemberHandler) Handle(ctx context.Context, req *InviteMemberRequest) (*InviteMemberResponse, int, error) {
+	role, err := domain.ParseProjectRole(req.Role)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	invitation, err := domain.NewProjectInvitation(req.Id, domain.GetUserID(ctx), req.Email, role)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := h.repo.InviteMember(ctx, invitation); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProjectNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrNotProjectOwner):
+			return nil, http.StatusForbidden, err
+		case errors.Is(err, domain.ErrAlreadyProjectMember):
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &InviteMemberResponse{Id: invitation.Id}, http.StatusCreated, nil
+}
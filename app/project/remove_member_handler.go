@@ -0,0 +1,50 @@
+package project
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type RemoveMemberRequest struct {
+	Id     uuid.UUID `params:"id" validate:"required,uuid"`
+	UserId uuid.UUID `params:"userId" validate:"required,uuid"`
+}
+
+type RemoveMemberResponse struct {
+}
+
+type RemoveMemberHandler struct {
+	repo ProjectRepository
+}
+
+func NewRemoveMemberHandler(repo ProjectRepository) *RemoveMemberHandler {
+	return &RemoveMemberHandler{repo: repo}
+}
+
+// RemoveMemberHandler revokes the access of a member, or lets a member leave.
+//
+//	@Summary		Remove a member from a project
+//	@Description	Revokes the access of a member to a project right away. Owners can remove any member, every member can remove themselves to leave the project. The last owner cannot leave. The todos the member created stay in the project.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string	true	"Project ID"
+//	@Param			userId	path	string	true	"User ID of the member"
+//	@Success		204		"Member removed successfully"
+//	@Failure		401		"Unauthorized"
+//	@Failure		403		"Only owners can remove other members"
+//	@Failure		404		"Project or member not found"
+//	@Failure		409		"The last owner cannot leave"
+//	@Failure		500		"Internal server error"
+//	@Router			/projects/{id}/members/{userId} [delete]
+func (h *RemoveMemberHandler) Handle(ctx context.Context, req *RemoveMemberRequest) (*RemoveMemberResponse, int, error) {
+	if err := h.repo.RemoveMember(ctx, req.Id, req.UserId, domain.GetUserID(ctx)); err != nil {
+		return nil, memberErrorStatus(err), err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,45 @@
+package project
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// A project the user is not a member of is reported as domain.ErrProjectNotFound. Every
+// member can read a project, changing or deleting it is reserved to its owners and
+// reported as domain.ErrNotProjectOwner otherwise.
+type ProjectRepository interface {
+	// CreateProject appends the project after the existing ones and sets its Position.
+	CreateProject(ctx context.Context, project *domain.Project) error
+	GetProjectsByUserID(ctx context.Context, userId uuid.UUID, includeArchived bool) (*GetProjectsResponse, error)
+	UpdateProject(ctx context.Context, project *domain.Project) error
+	// DeleteProject deletes the todos of the project as well with domain.ProjectDeleteCascade,
+	// with domain.ProjectDeleteMoveToInbox they are kept without a project.
+	DeleteProject(ctx context.Context, id, userId uuid.UUID, mode domain.ProjectDeleteMode) error
+
+	// InviteMember invites the email into the project, inviting the same email again
+	// replaces the role of the pending invitation. Only owners can invite.
+	InviteMember(ctx context.Context, invitation *domain.ProjectInvitation) error
+	GetMembers(ctx context.Context, projectId, userId uuid.UUID) (*GetMembersResponse, error)
+	// UpdateMemberRole and RemoveMember are reserved to owners, except that every member
+	// can leave a project. The last owner can neither leave nor be demoted, that is
+	// reported as domain.ErrLastProjectOwner.
+	UpdateMemberRole(ctx context.Context, projectId, memberId uuid.UUID, role domain.ProjectRole, userId uuid.UUID) error
+	RemoveMember(ctx context.Context, projectId, memberId, userId uuid.UUID) error
+	// GetInvitations returns the pending invitations sent to the email of the user.
+	GetInvitations(ctx context.Context, userId uuid.UUID) (*GetInvitationsResponse, error)
+	// AcceptInvitation makes the user a member with the role of the invitation and
+	// returns the id of the project.
+	AcceptInvitation(ctx context.Context, id, userId uuid.UUID) (uuid.UUID, error)
+	// DeleteInvitation declines an invitation sent to the user, the owners of the project
+	// can cancel it as well.
+	DeleteInvitation(ctx context.Context, id, userId uuid.UUID) error
+}
+
+// TodoListInvalidator drops the cached todo lists of a user. It is implemented by
+// todo.CachedTodoRepository.
+type TodoListInvalidator interface {
+	InvalidateTodoLists(userId uuid.UUID)
+}
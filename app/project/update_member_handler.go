@@ -0,0 +1,71 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type UpdateMemberRequest struct {
+	Id     uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	UserId uuid.UUID `params:"userId" validate:"required,uuid" swaggerignore:"true"`
+	Role   string    `json:"role" validate:"required,oneof=viewer editor owner"`
+}
+
+type UpdateMemberResponse struct {
+}
+
+type UpdateMemberHandler struct {
+	repo ProjectRepository
+}
+
+func NewUpdateMemberHandler(repo ProjectRepository) *UpdateMemberHandler {
+	return &UpdateMemberHandler{repo: repo}
+}
+
+// UpdateMemberHandler changes the role of a member.
+//
+//	@Summary		Change the role of a member
+//	@Description	Changes the role of a member of a project. Only owners can change roles, and the last owner cannot be demoted.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path	string				true	"Project ID"
+//	@Param			userId				path	string				true	"User ID of the member"
+//	@Param			UpdateMemberRequest	body	UpdateMemberRequest	true	"New role"
+//	@Success		204					"Role changed successfully"
+//	@Failure		400					"Invalid role"
+//	@Failure		401					"Unauthorized"
+//	@Failure		403					"Only owners can change roles"
+//	@Failure		404					"Project or member not found"
+//	@Failure		409					"The last owner cannot be demoted"
+//	@Failure		500					"Internal server error"
+//	@Router			/projects/{id}/members/{userId} [put]
+func (h *UpdateMemberHandler) Handle(ctx context.Context, req *UpdateMemberRequest) (*UpdateMemberResponse, int, error) {
+	role, err := domain.ParseProjectRole(req.Role)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := h.repo.UpdateMemberRole(ctx, req.Id, req.UserId, role, domain.GetUserID(ctx)); err != nil {
+		return nil, memberErrorStatus(err), err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
+
+func memberErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound), errors.Is(err, domain.ErrMemberNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotProjectOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrLastProjectOwner):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
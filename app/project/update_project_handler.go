@@ -0,0 +1,66 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type UpdateProjectRequest struct {
+	Id       uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Name     string    `json:"name" validate:"required,max=100"`
+	Color    string    `json:"color"`
+	Archived bool      `json:"archived"`
+	Position int       `json:"position" validate:"min=0"`
+}
+
+type UpdateProjectResponse struct {
+}
+
+type UpdateProjectHandler struct {
+	repo ProjectRepository
+}
+
+func NewUpdateProjectHandler(repo ProjectRepository) *UpdateProjectHandler {
+	return &UpdateProjectHandler{repo: repo}
+}
+
+// UpdateProjectHandler handles renaming, recoloring, archiving and reordering a project.
+//
+//	@Summary		Update a project
+//	@Description	Replaces the name, the color, the archived flag and the position of a project the authenticated user owns. Projects with the same position are ordered by creation time.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path	string					true	"Project ID"
+//	@Param			UpdateProjectRequest	body	UpdateProjectRequest	true	"Project details"
+//	@Success		204						"Project updated successfully"
+//	@Failure		400						"Invalid request"
+//	@Failure		401						"Unauthorized"
+//	@Failure		403						"Only owners can change the project"
+//	@Failure		404						"Project not found"
+//	@Failure		500						"Internal server error"
+//	@Router			/projects/{id} [put]
+func (h *UpdateProjectHandler) Handle(ctx context.Context, req *UpdateProjectRequest) (*UpdateProjectResponse, int, error) {
+	project, err := domain.NewProject(domain.GetUserID(ctx), req.Name, req.Color, req.Archived, req.Position)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	project.Id = req.Id
+
+	if err = h.repo.UpdateProject(ctx, project); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProjectNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrNotProjectOwner):
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,79 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Exactly one of RemindAt and MinutesBeforeDue must be set.
+type CreateReminderRequest struct {
+	TodoId           uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	RemindAt         time.Time `json:"remind_at"`
+	MinutesBeforeDue *int      `json:"minutes_before_due"`
+}
+
+type CreateReminderResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type CreateReminderHandler struct {
+	repo  ReminderRepository
+	clock domain.Clock
+}
+
+func NewCreateReminderHandler(repo ReminderRepository, clock domain.Clock) *CreateReminderHandler {
+	return &CreateReminderHandler{repo: repo, clock: clock}
+}
+
+// CreateReminderHandler attaches a reminder to a todo.
+//
+//	@Summary		Create a reminder
+//	@Description	Attaches a reminder to a todo of the authenticated user. The reminder is either absolute (`remind_at`) or relative to the due date of the todo (`minutes_before_due`). It is sent once by email.
+//	@Tags			Reminder
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Todo ID"
+//	@Param			CreateReminderRequest	body		CreateReminderRequest	true	"Reminder details"
+//	@Success		201						{object}	CreateReminderResponse
+//	@Failure		400						"Invalid request"
+//	@Failure		401						"Unauthorized"
+//	@Failure		404						"Todo not found"
+//	@Failure		500						"Internal server error"
+//	@Router			/todos/{id}/reminders [post]
+func (h *CreateReminderHandler) Handle(ctx context.Context, req *CreateReminderRequest) (*CreateReminderResponse, int, error) {
+	userId := domain.GetUserID(ctx)
+
+	dueAt, err := h.repo.GetTodoDueAt(ctx, req.TodoId, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	now := h.clock.Now()
+	var reminder *domain.Reminder
+	switch {
+	case req.MinutesBeforeDue != nil && req.RemindAt.IsZero():
+		reminder, err = domain.NewRelativeReminder(req.TodoId, time.Duration(*req.MinutesBeforeDue)*time.Minute, dueAt, now)
+	case req.MinutesBeforeDue == nil && !req.RemindAt.IsZero():
+		reminder, err = domain.NewAbsoluteReminder(req.TodoId, req.RemindAt, now)
+	default:
+		err = domain.ErrInvalidReminder
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err = h.repo.CreateReminder(ctx, reminder); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &CreateReminderResponse{Id: reminder.Id}, http.StatusCreated, nil
+}
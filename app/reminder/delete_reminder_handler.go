@@ -0,0 +1,53 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteReminderRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid"`
+	Id     uuid.UUID `params:"reminderId" validate:"required,uuid"`
+}
+
+type DeleteReminderResponse struct {
+}
+
+type DeleteReminderHandler struct {
+	repo ReminderRepository
+}
+
+func NewDeleteReminderHandler(repo ReminderRepository) *DeleteReminderHandler {
+	return &DeleteReminderHandler{repo: repo}
+}
+
+// DeleteReminderHandler removes a reminder from a todo.
+//
+//	@Summary		Delete a reminder
+//	@Description	Deletes a reminder of a todo of the authenticated user.
+//	@Tags			Reminder
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	string	true	"Todo ID"
+//	@Param			reminderId	path	string	true	"Reminder ID"
+//	@Success		204			"Reminder deleted successfully"
+//	@Failure		400			"Invalid request"
+//	@Failure		401			"Unauthorized"
+//	@Failure		404			"Reminder not found"
+//	@Failure		500			"Internal server error"
+//	@Router			/todos/{id}/reminders/{reminderId} [delete]
+func (h *DeleteReminderHandler) Handle(ctx context.Context, req *DeleteReminderRequest) (*DeleteReminderResponse, int, error) {
+	if err := h.repo.DeleteReminder(ctx, req.Id, req.TodoId, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrReminderNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,11 @@
+package reminder
+
+import (
+	"context"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type EmailService interface {
+	SendReminderEmail(ctx context.Context, claims *domain.EmailClaims) error
+}
@@ -0,0 +1,79 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetRemindersRequest struct {
+	TodoId uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type GetRemindersResponse []Reminder
+
+// FireAt is computed from the current due date, it is zero when a relative
+// reminder belongs to a todo that no longer has a due date.
+type Reminder struct {
+	Id               uuid.UUID `json:"id"`
+	RemindAt         time.Time `json:"remind_at"`
+	MinutesBeforeDue *int      `json:"minutes_before_due,omitempty"`
+	FireAt           time.Time `json:"fire_at"`
+	SentAt           time.Time `json:"sent_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type GetRemindersHandler struct {
+	repo ReminderRepository
+}
+
+func NewGetRemindersHandler(repo ReminderRepository) *GetRemindersHandler {
+	return &GetRemindersHandler{repo: repo}
+}
+
+// GetRemindersHandler lists the reminders of a todo.
+//
+//	@Summary		Get reminders of a todo
+//	@Description	Retrieves the reminders of a todo of the authenticated user.
+//	@Tags			Reminder
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Todo ID"
+//	@Success		200	{object}	GetRemindersResponse
+//	@Failure		400	"Invalid request"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Todo not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/{id}/reminders [get]
+func (h *GetRemindersHandler) Handle(ctx context.Context, req *GetRemindersRequest) (*GetRemindersResponse, int, error) {
+	userId := domain.GetUserID(ctx)
+
+	dueAt, err := h.repo.GetTodoDueAt(ctx, req.TodoId, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	reminders, err := h.repo.GetRemindersByTodoID(ctx, req.TodoId, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	for i := range *reminders {
+		r := &(*reminders)[i]
+		reminder := domain.Reminder{RemindAt: r.RemindAt, Relative: r.MinutesBeforeDue != nil}
+		if reminder.Relative {
+			reminder.Offset = time.Duration(*r.MinutesBeforeDue) * time.Minute
+		}
+		r.FireAt = reminder.FireAt(dueAt)
+	}
+
+	return reminders, http.StatusOK, nil
+}
@@ -0,0 +1,33 @@
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Every todo-scoped method reports a todo that belongs to another user as domain.ErrTodoNotFound.
+type ReminderRepository interface {
+	GetTodoDueAt(ctx context.Context, todoId, userId uuid.UUID) (time.Time, error)
+	CreateReminder(ctx context.Context, reminder *domain.Reminder) error
+	GetRemindersByTodoID(ctx context.Context, todoId, userId uuid.UUID) (*GetRemindersResponse, error)
+	DeleteReminder(ctx context.Context, id, todoId, userId uuid.UUID) error
+
+	// ProcessNextDueReminder claims one unsent reminder whose fire time is not after now,
+	// passes it to deliver and records the outcome, all while holding a row lock. The lock
+	// is what keeps several API instances from sending the same reminder. It returns false
+	// when there was nothing to claim, a failed delivery is recorded and is not an error.
+	ProcessNextDueReminder(ctx context.Context, now time.Time, deliver func(ctx context.Context, reminder *DueReminder) error) (bool, error)
+}
+
+type DueReminder struct {
+	Id            uuid.UUID
+	TodoId        uuid.UUID
+	TodoTitle     string
+	TodoCompleted bool
+	DueAt         time.Time
+	UserFullName  string
+	UserEmail     string
+}
@@ -0,0 +1,87 @@
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// maxRemindersPerRun bounds a single run so that a large backlog, e.g. after a long
+// downtime, can't keep one instance busy and starve the next tick.
+const maxRemindersPerRun = 100
+
+// Scheduler fires due reminders by email. Every API instance runs one; the repository
+// hands each reminder to exactly one of them and state lives in the database, so
+// reminders survive restarts.
+type Scheduler struct {
+	repo         ReminderRepository
+	emailService EmailService
+	clock        domain.Clock
+	logger       domain.Logger
+	interval     time.Duration
+}
+
+func NewScheduler(repo ReminderRepository, emailService EmailService, clock domain.Clock, logger domain.Logger, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		repo:         repo,
+		emailService: emailService,
+		clock:        clock,
+		logger:       logger,
+		interval:     interval,
+	}
+}
+
+// Start runs the scheduler until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processes the reminders that are due at the current time of the clock and
+// returns how many were claimed.
+func (s *Scheduler) RunOnce(ctx context.Context) int {
+	now := s.clock.Now()
+
+	processed := 0
+	for processed < maxRemindersPerRun {
+		claimed, err := s.repo.ProcessNextDueReminder(ctx, now, s.deliver)
+		if err != nil {
+			s.logger.Error("failed to process due reminder", "error", err)
+			break
+		}
+		if !claimed {
+			break
+		}
+		processed++
+	}
+	return processed
+}
+
+// Reminders of completed todos are consumed without sending anything.
+func (s *Scheduler) deliver(ctx context.Context, reminder *DueReminder) error {
+	if reminder.TodoCompleted {
+		return nil
+	}
+
+	err := s.emailService.SendReminderEmail(ctx, &domain.EmailClaims{
+		Name:    reminder.UserFullName,
+		To:      reminder.UserEmail,
+		Subject: domain.NewReminderEmailSubject(reminder.TodoTitle),
+		HTML:    domain.NewReminderEmailBody(reminder.TodoTitle, reminder.DueAt),
+	})
+	if err != nil {
+		s.logger.Error("failed to send reminder email", "reminder_id", reminder.Id, "error", err)
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+package tag
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// CachedTagRepository keeps the cached todo lists consistent with tag changes. Todos
+// embed the name and the color of their tags, so renaming or deleting a tag changes
+// every list it appears in.
+type CachedTagRepository struct {
+	repo      TagRepository
+	todoLists TodoListInvalidator
+}
+
+func NewCachedTagRepository(repo TagRepository, todoLists TodoListInvalidator) *CachedTagRepository {
+	return &CachedTagRepository{repo: repo, todoLists: todoLists}
+}
+
+func (r *CachedTagRepository) CreateTag(ctx context.Context, tag *domain.Tag) error {
+	return r.repo.CreateTag(ctx, tag)
+}
+
+func (r *CachedTagRepository) GetTagsByUserID(ctx context.Context, userId uuid.UUID) (*GetTagsResponse, error) {
+	return r.repo.GetTagsByUserID(ctx, userId)
+}
+
+func (r *CachedTagRepository) UpdateTag(ctx context.Context, tag *domain.Tag) error {
+	if err := r.repo.UpdateTag(ctx, tag); err != nil {
+		return err
+	}
+	r.todoLists.InvalidateTodoLists(tag.UserId)
+	return nil
+}
+
+func (r *CachedTagRepository) DeleteTag(ctx context.Context, id, userId uuid.UUID) error {
+	if err := r.repo.DeleteTag(ctx, id, userId); err != nil {
+		return err
+	}
+	r.todoLists.InvalidateTodoLists(userId)
+	return nil
+}
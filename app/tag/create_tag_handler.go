@@ -0,0 +1,58 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type CreateTagRequest struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Color string `json:"color"`
+}
+
+type CreateTagResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type CreateTagHandler struct {
+	repo TagRepository
+}
+
+func NewCreateTagHandler(repo TagRepository) *CreateTagHandler {
+	return &CreateTagHandler{repo: repo}
+}
+
+// CreateTagHandler handles the creation of a new tag.
+//
+//	@Summary		Create a tag
+//	@Description	Creates a tag for the authenticated user. Names are unique per user, ignoring case. The color defaults to #6b7280.
+//	@Tags			Tag
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			CreateTagRequest	body		CreateTagRequest	true	"Tag details"
+//	@Success		201					{object}	CreateTagResponse
+//	@Failure		400					"Invalid request"
+//	@Failure		401					"Unauthorized"
+//	@Failure		409					"Tag already exists"
+//	@Failure		500					"Internal server error"
+//	@Router			/tags [post]
+func (h *CreateTagHandler) Handle(ctx context.Context, req *CreateTagRequest) (*CreateTagResponse, int, error) {
+	tag, err := domain.NewTag(domain.GetUserID(ctx), req.Name, req.Color)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err = h.repo.CreateTag(ctx, tag); err != nil {
+		if errors.Is(err, domain.ErrTagAlreadyExists) {
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &CreateTagResponse{Id: tag.Id}, http.StatusCreated, nil
+}
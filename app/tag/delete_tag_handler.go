@@ -0,0 +1,51 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteTagRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type DeleteTagResponse struct {
+}
+
+type DeleteTagHandler struct {
+	repo TagRepository
+}
+
+func NewDeleteTagHandler(repo TagRepository) *DeleteTagHandler {
+	return &DeleteTagHandler{repo: repo}
+}
+
+// DeleteTagHandler handles the deletion of a tag.
+//
+//	@Summary		Delete a tag
+//	@Description	Deletes a tag of the authenticated user and detaches it from every todo.
+//	@Tags			Tag
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Tag ID"
+//	@Success		204	"Tag deleted successfully"
+//	@Failure		400	"Invalid request"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Tag not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/tags/{id} [delete]
+func (h *DeleteTagHandler) Handle(ctx context.Context, req *DeleteTagRequest) (*DeleteTagResponse, int, error) {
+	if err := h.repo.DeleteTag(ctx, req.Id, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrTagNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,51 @@
+package tag
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetTagsRequest struct {
+}
+
+type GetTagsResponse []Tag
+
+type Tag struct {
+	Id        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetTagsHandler struct {
+	repo TagRepository
+}
+
+func NewGetTagsHandler(repo TagRepository) *GetTagsHandler {
+	return &GetTagsHandler{repo: repo}
+}
+
+// GetTagsHandler lists the tags of the authenticated user.
+//
+//	@Summary		Get all tags
+//	@Description	Retrieves the tags of the authenticated user, ordered by name.
+//	@Tags			Tag
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	GetTagsResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/tags [get]
+func (h *GetTagsHandler) Handle(ctx context.Context, req *GetTagsRequest) (*GetTagsResponse, int, error) {
+	tags, err := h.repo.GetTagsByUserID(ctx, domain.GetUserID(ctx))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return tags, http.StatusOK, nil
+}
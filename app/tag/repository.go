@@ -0,0 +1,22 @@
+package tag
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// A tag that belongs to another user is reported as domain.ErrTagNotFound.
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *domain.Tag) error
+	GetTagsByUserID(ctx context.Context, userId uuid.UUID) (*GetTagsResponse, error)
+	UpdateTag(ctx context.Context, tag *domain.Tag) error
+	DeleteTag(ctx context.Context, id, userId uuid.UUID) error
+}
+
+// TodoListInvalidator drops the cached todo lists of a user. It is implemented by
+// todo.CachedTodoRepository.
+type TodoListInvalidator interface {
+	InvalidateTodoLists(userId uuid.UUID)
+}
@@ -0,0 +1,64 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type UpdateTagRequest struct {
+	Id    uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Name  string    `json:"name" validate:"required,max=50"`
+	Color string    `json:"color"`
+}
+
+type UpdateTagResponse struct {
+}
+
+type UpdateTagHandler struct {
+	repo TagRepository
+}
+
+func NewUpdateTagHandler(repo TagRepository) *UpdateTagHandler {
+	return &UpdateTagHandler{repo: repo}
+}
+
+// UpdateTagHandler handles renaming and recoloring a tag.
+//
+//	@Summary		Update a tag
+//	@Description	Updates the name and the color of a tag of the authenticated user.
+//	@Tags			Tag
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path	string				true	"Tag ID"
+//	@Param			UpdateTagRequest	body	UpdateTagRequest	true	"Tag details"
+//	@Success		204					"Tag updated successfully"
+//	@Failure		400					"Invalid request"
+//	@Failure		401					"Unauthorized"
+//	@Failure		404					"Tag not found"
+//	@Failure		409					"Tag already exists"
+//	@Failure		500					"Internal server error"
+//	@Router			/tags/{id} [put]
+func (h *UpdateTagHandler) Handle(ctx context.Context, req *UpdateTagRequest) (*UpdateTagResponse, int, error) {
+	tag, err := domain.NewTag(domain.GetUserID(ctx), req.Name, req.Color)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	tag.Id = req.Id
+
+	if err = h.repo.UpdateTag(ctx, tag); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTagNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrTagAlreadyExists):
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type AttachTagRequest struct {
+	Id    uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	TagId uuid.UUID `json:"tag_id" validate:"required,uuid"`
+}
+
+type AttachTagResponse struct {
+}
+
+type AttachTagHandler struct {
+	repo TodoRepository
+}
+
+func NewAttachTagHandler(repo TodoRepository) *AttachTagHandler {
+	return &AttachTagHandler{repo: repo}
+}
+
+// AttachTagHandler attaches a tag to a todo.
+//
+//	@Summary		Attach a tag to a todo
+//	@Description	Attaches one of the tags of the authenticated user to one of their todos. Attaching a tag twice has no effect.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path	string				true	"Todo ID"
+//	@Param			AttachTagRequest	body	AttachTagRequest	true	"Tag to attach"
+//	@Success		204					"Tag attached successfully"
+//	@Failure		400					"Invalid request"
+//	@Failure		401					"Unauthorized"
+//	@Failure		403					"The todo can only be viewed"
+//	@Failure		404					"Todo or tag not found"
+//	@Failure		500					"Internal server error"
+//	@Router			/todos/{id}/tags [post]
+func (h *AttachTagHandler) Handle(ctx context.Context, req *AttachTagRequest) (*AttachTagResponse, int, error) {
+	if req.TagId == uuid.Nil {
+		return nil, http.StatusBadRequest, domain.ErrInvalidRequest
+	}
+
+	if err := h.repo.AttachTag(ctx, req.Id, req.TagId, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) || errors.Is(err, domain.ErrTagNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		if errors.Is(err, domain.ErrTodoReadOnly) {
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
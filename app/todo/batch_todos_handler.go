@@ -0,0 +1,262 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type BatchTodosRequest struct {
+	Mode       string                  `json:"mode" validate:"omitempty,oneof=atomic per_item"`
+	Operations []BatchOperationRequest `json:"operations" validate:"required,min=1,max=100"`
+}
+
+// BatchOperationRequest is one operation of a batch, only the fields of its op are used:
+// create takes title, description, due_at, priority and project_id, update_title takes
+// id and title, set_completed takes id and completed, delete takes id and move takes
+// id and project_id, without a project_id the todo is moved to the inbox.
+type BatchOperationRequest struct {
+	Op          string    `json:"op" validate:"required,oneof=create update_title set_completed delete move"`
+	Id          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	DueAt       time.Time `json:"due_at"`
+	Priority    string    `json:"priority"`
+	Completed   *bool     `json:"completed"`
+	ProjectId   uuid.UUID `json:"project_id"`
+}
+
+type BatchMode string
+
+const (
+	// BatchModeAtomic applies every operation or none of them.
+	BatchModeAtomic BatchMode = "atomic"
+	// BatchModePerItem applies the operations that succeed and reports the others.
+	BatchModePerItem BatchMode = "per_item"
+
+	MaxBatchOperations = 100
+)
+
+type TodoOperationType string
+
+const (
+	OpCreate       TodoOperationType = "create"
+	OpUpdateTitle  TodoOperationType = "update_title"
+	OpSetCompleted TodoOperationType = "set_completed"
+	OpDelete       TodoOperationType = "delete"
+	OpMove         TodoOperationType = "move"
+	// OpUpdate sets the title, due date, priority and tags of a todo. The batch endpoint
+	// does not offer it, the todo.txt sync does.
+	OpUpdate TodoOperationType = "update"
+)
+
+// TodoOperation is a validated operation of a batch. Todo is only set for OpCreate,
+// Id is the todo the other operations apply to. Tags are the names of the tags OpCreate
+// attaches to the new todo, and the tags of the user OpUpdate leaves on the todo.
+// Version is the version OpDelete expects the todo to be at, 0 for any.
+type TodoOperation struct {
+	Type      TodoOperationType
+	Todo      *domain.Todo
+	Id        uuid.UUID
+	Title     string
+	Completed bool
+	ProjectId uuid.UUID
+	DueAt     time.Time
+	Priority  domain.Priority
+	Tags      []string
+	Version   int
+}
+
+type BatchTodosResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of the operation at the same index. Status is the status
+// code the operation would have on its own endpoint, 424 when it was rolled back or
+// skipped because another operation of an atomic batch failed.
+type BatchResult struct {
+	Op     TodoOperationType `json:"op"`
+	Id     uuid.UUID         `json:"id"`
+	Status int               `json:"status"`
+	Error  string            `json:"error,omitempty"`
+}
+
+type BatchTodosHandler struct {
+	repo TodoRepository
+}
+
+func NewBatchTodosHandler(repo TodoRepository) *BatchTodosHandler {
+	return &BatchTodosHandler{repo: repo}
+}
+
+// Handle applies several todo operations in one request.
+//
+//	@Summary		Apply todo operations in bulk
+//	@Description	Runs up to 100 create, update_title, set_completed, delete and move operations in order, in one transaction. In atomic mode (default) either every operation is applied and the status is 200, or none is and the status is the one of the failing operation. In per_item mode the failing operations are skipped, the status is 200 when all of them succeeded and 207 otherwise. Every result carries the status of its operation.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			BatchTodosRequest	body		BatchTodosRequest	true	"Operations"
+//	@Success		200					{object}	BatchTodosResponse
+//	@Success		207					{object}	BatchTodosResponse	"Some operations failed in per_item mode"
+//	@Failure		400					{object}	BatchTodosResponse	"Invalid request or operation"
+//	@Failure		401					"Unauthorized"
+//	@Failure		404					{object}	BatchTodosResponse	"A todo or project of an atomic batch was not found"
+//	@Failure		500					"Internal server error"
+//	@Router			/todos/batch [post]
+func (h *BatchTodosHandler) Handle(ctx context.Context, req *BatchTodosRequest) (*BatchTodosResponse, int, error) {
+	mode := BatchMode(req.Mode)
+	switch mode {
+	case "":
+		mode = BatchModeAtomic
+	case BatchModeAtomic, BatchModePerItem:
+	default:
+		return nil, http.StatusBadRequest, domain.ErrInvalidBatchMode
+	}
+
+	if len(req.Operations) == 0 {
+		return nil, http.StatusBadRequest, domain.ErrEmptyBatch
+	}
+	if len(req.Operations) > MaxBatchOperations {
+		return nil, http.StatusBadRequest, domain.ErrTooManyOperations
+	}
+
+	userId := domain.GetUserID(ctx)
+	atomic := mode == BatchModeAtomic
+
+	results := make([]BatchResult, len(req.Operations))
+	var ops []TodoOperation
+	// positions maps the operations sent to the repository back to their results
+	var positions []int
+	for i, opReq := range req.Operations {
+		op, err := newTodoOperation(userId, opReq)
+		results[i] = BatchResult{Op: TodoOperationType(opReq.Op), Id: op.Id}
+		if err != nil {
+			results[i].Status, results[i].Error = http.StatusBadRequest, err.Error()
+			continue
+		}
+		ops = append(ops, op)
+		positions = append(positions, i)
+	}
+
+	if atomic && len(ops) < len(req.Operations) {
+		skipBatch(results)
+		return &BatchTodosResponse{Results: results}, http.StatusBadRequest, nil
+	}
+
+	var errs []error
+	if len(ops) > 0 {
+		var err error
+		if errs, err = h.repo.ApplyBatch(ctx, userId, ops, atomic); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	failedStatus := 0
+	for j, err := range errs {
+		result := &results[positions[j]]
+		if err == nil {
+			result.Status = successStatus(result.Op)
+			continue
+		}
+		result.Status, result.Error = batchErrorStatus(err), err.Error()
+		if result.Status == http.StatusInternalServerError {
+			return nil, http.StatusInternalServerError, err
+		}
+		if failedStatus == 0 {
+			failedStatus = result.Status
+		}
+	}
+
+	switch {
+	case !hasFailures(results):
+		return &BatchTodosResponse{Results: results}, http.StatusOK, nil
+	case atomic:
+		skipBatch(results)
+		return &BatchTodosResponse{Results: results}, failedStatus, nil
+	default:
+		return &BatchTodosResponse{Results: results}, http.StatusMultiStatus, nil
+	}
+}
+
+func newTodoOperation(userId uuid.UUID, req BatchOperationRequest) (TodoOperation, error) {
+	op := TodoOperation{Type: TodoOperationType(req.Op), Id: req.Id, ProjectId: req.ProjectId}
+
+	if op.Type == OpCreate {
+		todo, err := domain.NewTodo(userId, req.Title, req.Description, req.DueAt, domain.Priority(req.Priority))
+		if err != nil {
+			return op, err
+		}
+		todo.ProjectId = req.ProjectId
+		op.Todo, op.Id = todo, todo.Id
+		return op, nil
+	}
+
+	switch op.Type {
+	case OpUpdateTitle, OpSetCompleted, OpDelete, OpMove:
+	default:
+		return op, domain.ErrInvalidBatchOperation
+	}
+	if op.Id == uuid.Nil {
+		return op, domain.ErrMissingTodoId
+	}
+
+	switch op.Type {
+	case OpUpdateTitle:
+		if err := domain.ValidateTitle(req.Title); err != nil {
+			return op, err
+		}
+		op.Title = req.Title
+	case OpSetCompleted:
+		if req.Completed == nil {
+			return op, domain.ErrMissingCompleted
+		}
+		op.Completed = *req.Completed
+	}
+	return op, nil
+}
+
+func successStatus(op TodoOperationType) int {
+	if op == OpCreate {
+		return http.StatusCreated
+	}
+	return http.StatusNoContent
+}
+
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound), errors.Is(err, domain.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrProjectArchived), errors.Is(err, domain.ErrSubtaskWithProject):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrTodoReadOnly), errors.Is(err, domain.ErrProjectReadOnly):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrTodoVersionMismatch):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+func hasFailures(results []BatchResult) bool {
+	for _, result := range results {
+		if result.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// skipBatch reports every operation of a failed atomic batch that did not fail itself
+// as not applied.
+func skipBatch(results []BatchResult) {
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Status, results[i].Error = http.StatusFailedDependency, domain.ErrBatchNotApplied.Error()
+		}
+	}
+}
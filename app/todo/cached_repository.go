@@ -0,0 +1,282 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+const cacheOperationTimeout = 5 * time.Second
+
+// CachedTodoRepository decorates a TodoRepository with the per-user todo list cache.
+// The list is read through the cache and every successful write invalidates it,
+// so handlers never have to deal with the cache themselves.
+//
+// Every method of TodoRepository is implemented explicitly on purpose: adding a new
+// write method to the interface must not silently bypass the invalidation.
+type CachedTodoRepository struct {
+	repo   TodoRepository
+	cache  domain.Cache
+	logger domain.Logger
+	ttl    time.Duration
+}
+
+func NewCachedTodoRepository(repo TodoRepository, cache domain.Cache, logger domain.Logger, ttl time.Duration) *CachedTodoRepository {
+	return &CachedTodoRepository{
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		ttl:    ttl,
+	}
+}
+
+func (r *CachedTodoRepository) CreateTodo(ctx context.Context, todo *domain.Todo) error {
+	if err := r.repo.CreateTodo(ctx, todo); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(todo.UserId)
+	return nil
+}
+
+func (r *CachedTodoRepository) UpdateTodo(ctx context.Context, todo *domain.Todo) error {
+	if err := r.repo.UpdateTodo(ctx, todo); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(todo.UserId)
+	return nil
+}
+
+func (r *CachedTodoRepository) PatchTodo(ctx context.Context, todo *domain.Todo) error {
+	if err := r.repo.PatchTodo(ctx, todo); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(todo.UserId)
+	return nil
+}
+
+func (r *CachedTodoRepository) GetById(ctx context.Context, id, userId uuid.UUID) (*GetTodoByIdResponse, error) {
+	return r.repo.GetById(ctx, id, userId)
+}
+
+func (r *CachedTodoRepository) GetTodoDepth(ctx context.Context, id, userId uuid.UUID) (int, error) {
+	return r.repo.GetTodoDepth(ctx, id, userId)
+}
+
+func (r *CachedTodoRepository) Delete(ctx context.Context, id, userId uuid.UUID, version int) error {
+	if err := r.repo.Delete(ctx, id, userId, version); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+func (r *CachedTodoRepository) GetTrash(ctx context.Context, userId uuid.UUID) ([]TrashedTodo, error) {
+	return r.repo.GetTrash(ctx, userId)
+}
+
+func (r *CachedTodoRepository) RestoreTodo(ctx context.Context, id, userId uuid.UUID) error {
+	if err := r.repo.RestoreTodo(ctx, id, userId); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+// Trashed todos are not part of the lists, deleting them for good leaves the cache as is.
+func (r *CachedTodoRepository) DeleteTrashedTodo(ctx context.Context, id, userId uuid.UUID) error {
+	return r.repo.DeleteTrashedTodo(ctx, id, userId)
+}
+
+func (r *CachedTodoRepository) EmptyTrash(ctx context.Context, userId uuid.UUID) error {
+	return r.repo.EmptyTrash(ctx, userId)
+}
+
+// Only the first page of unfiltered lists is cached, one entry per sort variant. Later
+// pages and filtered lists are cheap thanks to the indexes, and caching every cursor or
+// filter combination would make invalidation much harder.
+func (r *CachedTodoRepository) GetTodosByUserID(ctx context.Context, userID uuid.UUID, query GetTodosQuery) (*GetTodosResponse, error) {
+	if query.HasFilters() || !query.IsFirstPage() {
+		return r.repo.GetTodosByUserID(ctx, userID, query)
+	}
+
+	key := listCacheKey(userID, query)
+
+	if cached, err := r.cache.Get(ctx, key); err == nil && !isCacheEmpty(cached) {
+		var todos GetTodosResponse
+		err := json.Unmarshal(cached, &todos)
+		if err == nil {
+			return &todos, nil
+		}
+		r.logger.Error("failed to unmarshal cached todos", "key", key, "error", err)
+	}
+
+	todos, err := r.repo.GetTodosByUserID(ctx, userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	r.set(key, todos)
+
+	return todos, nil
+}
+
+func (r *CachedTodoRepository) SearchTodos(ctx context.Context, userId uuid.UUID, query SearchTodosQuery) (*SearchTodosResponse, error) {
+	return r.repo.SearchTodos(ctx, userId, query)
+}
+
+func (r *CachedTodoRepository) ToggleCompleted(ctx context.Context, id, userId uuid.UUID, completeSubtasks bool, version int) error {
+	if err := r.repo.ToggleCompleted(ctx, id, userId, completeSubtasks, version); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+func (r *CachedTodoRepository) GetByIdForAdmin(ctx context.Context, id uuid.UUID) (*GetTodoByIdForAdminResponse, error) {
+	return r.repo.GetByIdForAdmin(ctx, id)
+}
+
+func (r *CachedTodoRepository) AttachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error {
+	if err := r.repo.AttachTag(ctx, todoId, tagId, userId); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+func (r *CachedTodoRepository) DetachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error {
+	if err := r.repo.DetachTag(ctx, todoId, tagId, userId); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+func (r *CachedTodoRepository) MoveTodo(ctx context.Context, id, userId, projectId uuid.UUID) error {
+	if err := r.repo.MoveTodo(ctx, id, userId, projectId); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+func (r *CachedTodoRepository) ReorderTodo(ctx context.Context, id, userId, after, before uuid.UUID) (string, error) {
+	position, err := r.repo.ReorderTodo(ctx, id, userId, after, before)
+	if err != nil {
+		return "", err
+	}
+	r.InvalidateTodoLists(userId)
+	return position, nil
+}
+
+func (r *CachedTodoRepository) GetTodoHistory(ctx context.Context, id, userId uuid.UUID) (*GetTodoHistoryResponse, error) {
+	return r.repo.GetTodoHistory(ctx, id, userId)
+}
+
+func (r *CachedTodoRepository) RevertTodo(ctx context.Context, id, revisionId, userId uuid.UUID) error {
+	if err := r.repo.RevertTodo(ctx, id, revisionId, userId); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+// A batch invalidates the lists once, however many operations it has.
+func (r *CachedTodoRepository) ApplyBatch(ctx context.Context, userId uuid.UUID, ops []TodoOperation, atomic bool) ([]error, error) {
+	errs, err := r.repo.ApplyBatch(ctx, userId, ops, atomic)
+	if err != nil {
+		return nil, err
+	}
+	r.InvalidateTodoLists(userId)
+	return errs, nil
+}
+
+func (r *CachedTodoRepository) ExportTodos(ctx context.Context, userId uuid.UUID, fn func(ExportedTodo) error) error {
+	return r.repo.ExportTodos(ctx, userId, fn)
+}
+
+func (r *CachedTodoRepository) ExportTodo(ctx context.Context, id, userId uuid.UUID) (*ExportedTodo, error) {
+	return r.repo.ExportTodo(ctx, id, userId)
+}
+
+func (r *CachedTodoRepository) ImportTodos(ctx context.Context, userId uuid.UUID, todos []*domain.Todo, tags map[uuid.UUID][]string) error {
+	if err := r.repo.ImportTodos(ctx, userId, todos, tags); err != nil {
+		return err
+	}
+	r.InvalidateTodoLists(userId)
+	return nil
+}
+
+func (r *CachedTodoRepository) GetProjectNames(ctx context.Context, userId uuid.UUID) ([]ProjectName, error) {
+	return r.repo.GetProjectNames(ctx, userId)
+}
+
+func (r *CachedTodoRepository) GetCollaboratorIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	return r.repo.GetCollaboratorIds(ctx, userId)
+}
+
+// InvalidateTodoLists drops every cached list of the user and of everyone who shares a
+// project with the user, since their lists show the same shared todos. It is exported
+// for writes outside of this package that change what the lists contain, e.g. renaming
+// a tag or deleting a project.
+//
+// The cache calls use their own context so that a client that disconnects right
+// after a write cannot leave a stale list behind.
+func (r *CachedTodoRepository) InvalidateTodoLists(userId uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOperationTimeout)
+	defer cancel()
+
+	userIds, err := r.repo.GetCollaboratorIds(ctx, userId)
+	if err != nil {
+		r.logger.Error("failed to get collaborators", "userId", userId, "error", err)
+		userIds = []uuid.UUID{userId}
+	}
+
+	var keys []string
+	for _, id := range userIds {
+		keys = append(keys, listCacheKeys(id)...)
+	}
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		r.logger.Error("failed to delete cache keys", "keys", keys, "error", err)
+	}
+}
+
+func (r *CachedTodoRepository) set(key string, value any) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOperationTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.logger.Error("failed to marshal cache value", "key", key, "error", err)
+		return
+	}
+	if err := r.cache.Set(ctx, key, data, r.ttl); err != nil {
+		r.logger.Error("failed to set cache key", "key", key, "error", err)
+	}
+}
+
+// The default sort keeps the plain NewTodoCacheKey entry, every other sort variant
+// is stored next to it.
+func listCacheKey(userId uuid.UUID, query GetTodosQuery) string {
+	if query.IsDefaultSort() {
+		return domain.NewTodoCacheKey(userId)
+	}
+	return domain.NewTodoVariantCacheKey(userId, string(query.Sort)+":"+string(query.Order))
+}
+
+func listCacheKeys(userId uuid.UUID) []string {
+	keys := []string{domain.NewTodoCacheKey(userId)}
+	for _, variant := range SortVariants() {
+		if !variant.IsDefaultSort() {
+			keys = append(keys, listCacheKey(userId, variant))
+		}
+	}
+	return keys
+}
+
+func isCacheEmpty(cached []byte) bool {
+	return len(cached) == 0
+}
@@ -0,0 +1,36 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeedRepository keeps the id of the calendar feed of each user. Feed links
+// carry the id of the feed they were minted for, so replacing it revokes every link
+// handed out before.
+type CalendarFeedRepository interface {
+	// GetCalendarFeedId returns uuid.Nil when the user has no feed.
+	GetCalendarFeedId(ctx context.Context, userId uuid.UUID) (uuid.UUID, error)
+	// SetCalendarFeedId replaces the feed of the user, uuid.Nil turns it off.
+	SetCalendarFeedId(ctx context.Context, userId, feedId uuid.UUID) error
+}
+
+// FeedTokenService mints the tokens of the feed links, they do not expire.
+type FeedTokenService interface {
+	GenerateFeedToken(userId, feedId uuid.UUID) (string, error)
+	ValidateFeedToken(tokenString string) (uuid.UUID, uuid.UUID, error)
+}
+
+type FeedURLResponse struct {
+	URL string `json:"url"`
+}
+
+// feedURL returns the link calendar apps subscribe to, it works without authentication.
+func feedURL(ts FeedTokenService, userId, feedId uuid.UUID) (*FeedURLResponse, error) {
+	token, err := ts.GenerateFeedToken(userId, feedId)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedURLResponse{URL: "/feeds/" + token + ".ics"}, nil
+}
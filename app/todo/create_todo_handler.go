@@ -4,32 +4,40 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
 type CreateTodoRequest struct {
-	Title string `json:"title" validate:"required,min=1,max=100"`
+	Title       string    `json:"title" validate:"required,min=1,max=100"`
+	Description string    `json:"description" validate:"max=10000"`
+	DueAt       time.Time `json:"due_at"`
+	Priority    string    `json:"priority" validate:"omitempty,oneof=none low medium high urgent"`
+	ProjectId   uuid.UUID `json:"project_id" validate:"omitempty,uuid"`
+	ParentId    uuid.UUID `json:"parent_id" validate:"omitempty,uuid"`
+	// Recurrence is an RFC 5545 RRULE such as FREQ=WEEKLY;BYDAY=MO,WE, it is expanded
+	// in Timezone, UTC by default.
+	Recurrence string `json:"recurrence"`
+	Timezone   string `json:"timezone"`
 }
 
 type CreateTodoResponse struct {
 }
 
 type CreateTodoHandler struct {
-	repo   TodoRepository
-	cache  domain.Cache
-	logger domain.Logger
+	repo TodoRepository
 }
 
-func NewCreateTodoHandler(repo TodoRepository, cache domain.Cache, logger domain.Logger) *CreateTodoHandler {
-	return &CreateTodoHandler{repo: repo, cache: cache, logger: logger}
+func NewCreateTodoHandler(repo TodoRepository) *CreateTodoHandler {
+	return &CreateTodoHandler{repo: repo}
 }
 
 // CreateTodoHandler handles the creation of a new todo item.
 //
 //	@Summary		Create a new todo
-//	@Description	Creates a new todo item for the authenticated user.
+//	@Description	Creates a new todo item for the authenticated user. Without a project_id the todo is created in the inbox. With a parent_id it is created as a subtask in the project of its parent. A todo with a recurrence rule needs a due date, completing it creates the next occurrence.
 //	@Tags			Todo
 //
 //	@Security		BearerAuth
@@ -40,30 +48,61 @@ func NewCreateTodoHandler(repo TodoRepository, cache domain.Cache, logger domain
 //	@Success		201					"Todo created successfully"
 //	@Failure		400					"Invalid request"
 //	@Failure		401					"Unauthorized"
+//	@Failure		403					"The project or the parent todo can only be viewed"
+//	@Failure		404					"Project or parent todo not found"
 //	@Failure		500					"Internal server error"
 //	@Router			/todos [post]
 func (h *CreateTodoHandler) Handle(ctx context.Context, req *CreateTodoRequest) (*CreateTodoResponse, int, error) {
 	userId := domain.GetUserID(ctx)
 
-	todo, err := domain.NewTodo(userId, req.Title)
+	todo, err := domain.NewTodo(userId, req.Title, req.Description, req.DueAt, domain.Priority(req.Priority))
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
 
+	todo.ProjectId = req.ProjectId
+
+	if req.Recurrence != "" {
+		recurrence, err := domain.NewRecurrence(req.Recurrence, req.Timezone)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if err := todo.SetRecurrence(recurrence); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	if req.ParentId != uuid.Nil {
+		if req.ProjectId != uuid.Nil {
+			return nil, http.StatusBadRequest, domain.ErrSubtaskWithProject
+		}
+
+		parentDepth, err := h.repo.GetTodoDepth(ctx, req.ParentId, userId)
+		if err != nil {
+			if errors.Is(err, domain.ErrTodoNotFound) {
+				return nil, http.StatusNotFound, domain.ErrParentTodoNotFound
+			}
+			return nil, http.StatusInternalServerError, err
+		}
+		if err := domain.ValidateSubtaskDepth(parentDepth + 1); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		todo.ParentId = req.ParentId
+	}
+
 	if err = h.repo.CreateTodo(ctx, todo); err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
 			return nil, http.StatusNotFound, domain.ErrUserNotFound
+		case errors.Is(err, domain.ErrProjectNotFound), errors.Is(err, domain.ErrParentTodoNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrProjectArchived):
+			return nil, http.StatusBadRequest, err
+		case errors.Is(err, domain.ErrProjectReadOnly), errors.Is(err, domain.ErrTodoReadOnly):
+			return nil, http.StatusForbidden, err
 		}
 		return nil, http.StatusInternalServerError, err
 	}
 
-	go h.DeleteCacheKey(userId)
-
 	return nil, http.StatusCreated, nil
 }
-
-func (h *CreateTodoHandler) DeleteCacheKey(userId uuid.UUID) {
-	if err := h.cache.Delete(context.Background(), domain.NewTodoCacheKey(userId)); err != nil {
-		h.logger.Error("failed to delete cache key", "key", domain.NewTodoCacheKey(userId), "error", err)
-	}
-}
@@ -0,0 +1,77 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// TodoCursor is the position right after the last todo of a page. It holds the sort
+// key of that todo rather than an offset, so that the next page neither skips nor
+// repeats todos when todos are created or deleted in between.
+//
+// Only the value of the requested sort field is set, DueAt is nil for a todo without
+// a due date and Position for a todo without a position. Clients only ever see the
+// encoded token.
+type TodoCursor struct {
+	Sort      TodoSortField `json:"s"`
+	Order     SortOrder     `json:"o"`
+	Priority  int           `json:"p,omitempty"`
+	Title     string        `json:"t,omitempty"`
+	DueAt     *time.Time    `json:"d,omitempty"`
+	Position  *string       `json:"k,omitempty"`
+	CreatedAt time.Time     `json:"c"`
+	Id        uuid.UUID     `json:"i"`
+}
+
+func newTodoCursor(query GetTodosQuery, last Todo) *TodoCursor {
+	cursor := &TodoCursor{
+		Sort:      query.Sort,
+		Order:     query.Order,
+		CreatedAt: last.CreatedAt,
+		Id:        last.Id,
+	}
+	switch query.Sort {
+	case SortByPriority:
+		cursor.Priority = last.Priority.Rank()
+	case SortByTitle:
+		cursor.Title = last.Title
+	case SortByDueAt:
+		if !last.DueAt.IsZero() {
+			dueAt := last.DueAt
+			cursor.DueAt = &dueAt
+		}
+	case SortByPosition:
+		if last.Position != "" {
+			position := last.Position
+			cursor.Position = &position
+		}
+	}
+	return cursor
+}
+
+func (c *TodoCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTodoCursor decodes a token created by Encode. The cursor only makes sense for
+// the sort it was created with, so it must match the requested one.
+func decodeTodoCursor(token string, sort TodoSortField, order SortOrder) (*TodoCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var cursor TodoCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.Order != order || cursor.Id == uuid.Nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -2,13 +2,16 @@ package todo
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
 type DeleteTodoRequest struct {
-	Id uuid.UUID `params:"id"`
+	Id      uuid.UUID `params:"id"`
+	IfMatch string    `reqHeader:"If-Match" swaggerignore:"true"`
 }
 
 type DeleteTodoResponse struct {
@@ -24,22 +27,41 @@ func NewDeleteTodoHandler(repo TodoRepository) *DeleteTodoHandler {
 	}
 }
 
-// DeleteTodoHandler handles the deletion of a todo item.
+// DeleteTodoHandler moves a todo to the trash.
 //
 //	@Summary		Delete a todo
-//	@Description	Deletes a todo item for the authenticated user.
+//	@Description	Moves a todo of the authenticated user to the trash together with all of its subtasks. It can be restored until it is purged. With If-Match the todo is only deleted while it is at one of the listed versions.
 //	@Tags			Todo
 //	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path	string	true	"Todo ID"
-//	@Success		204	"Todo deleted successfully"
-//	@Failure		401	"Unauthorized"
-//	@Failure		500	"Internal server error"
+//	@Param			id			path	string	true	"Todo ID"
+//	@Param			If-Match	header	string	false	"ETags of the versions the todo may be at"
+//	@Success		204			"Todo moved to the trash"
+//	@Failure		400			"Invalid If-Match header"
+//	@Failure		401			"Unauthorized"
+//	@Failure		403			"The todo can only be viewed"
+//	@Failure		404			"Todo not found"
+//	@Failure		412			"The todo has changed since it was read"
+//	@Failure		500			"Internal server error"
 //	@Router			/todos/{id} [delete]
 func (h *DeleteTodoHandler) Handle(ctx context.Context, req *DeleteTodoRequest) (*DeleteTodoResponse, int, error) {
-	err := h.repo.Delete(ctx, req.Id)
+	userId := domain.GetUserID(ctx)
+	version, code, err := ifMatchVersion(ctx, h.repo, req.Id, userId, req.IfMatch)
 	if err != nil {
+		return nil, code, err
+	}
+
+	err = h.repo.Delete(ctx, req.Id, userId, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrTodoReadOnly):
+			return nil, http.StatusForbidden, err
+		case errors.Is(err, domain.ErrTodoVersionMismatch):
+			return nil, http.StatusPreconditionFailed, err
+		}
 		return nil, http.StatusInternalServerError, err
 	}
 	return nil, http.StatusNoContent, nil
@@ -0,0 +1,84 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteTrashedTodoRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type DeleteTrashedTodoResponse struct {
+}
+
+type DeleteTrashedTodoHandler struct {
+	repo TodoRepository
+}
+
+func NewDeleteTrashedTodoHandler(repo TodoRepository) *DeleteTrashedTodoHandler {
+	return &DeleteTrashedTodoHandler{repo: repo}
+}
+
+// DeleteTrashedTodoHandler permanently deletes a todo from the trash.
+//
+//	@Summary		Permanently delete a todo
+//	@Description	Deletes a trashed todo of the authenticated user for good, together with its subtasks. Todos that are not in the trash have to be deleted first.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Todo ID"
+//	@Success		204	"Todo permanently deleted"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Todo not found in the trash"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/trash/{id} [delete]
+func (h *DeleteTrashedTodoHandler) Handle(ctx context.Context, req *DeleteTrashedTodoRequest) (*DeleteTrashedTodoResponse, int, error) {
+	if err := h.repo.DeleteTrashedTodo(ctx, req.Id, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
+
+type EmptyTrashRequest struct {
+}
+
+type EmptyTrashResponse struct {
+}
+
+type EmptyTrashHandler struct {
+	repo TodoRepository
+}
+
+func NewEmptyTrashHandler(repo TodoRepository) *EmptyTrashHandler {
+	return &EmptyTrashHandler{repo: repo}
+}
+
+// EmptyTrashHandler permanently deletes every todo in the trash.
+//
+//	@Summary		Empty the trash
+//	@Description	Deletes every trashed todo of the authenticated user for good.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Success		204	"Trash emptied"
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/trash [delete]
+func (h *EmptyTrashHandler) Handle(ctx context.Context, req *EmptyTrashRequest) (*EmptyTrashResponse, int, error) {
+	if err := h.repo.EmptyTrash(ctx, domain.GetUserID(ctx)); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
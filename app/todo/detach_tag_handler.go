@@ -0,0 +1,53 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DetachTagRequest struct {
+	Id    uuid.UUID `params:"id" validate:"required,uuid"`
+	TagId uuid.UUID `params:"tagId" validate:"required,uuid"`
+}
+
+type DetachTagResponse struct {
+}
+
+type DetachTagHandler struct {
+	repo TodoRepository
+}
+
+func NewDetachTagHandler(repo TodoRepository) *DetachTagHandler {
+	return &DetachTagHandler{repo: repo}
+}
+
+// DetachTagHandler detaches a tag from a todo.
+//
+//	@Summary		Detach a tag from a todo
+//	@Description	Detaches a tag from a todo of the authenticated user.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string	true	"Todo ID"
+//	@Param			tagId	path	string	true	"Tag ID"
+//	@Success		204		"Tag detached successfully"
+//	@Failure		400		"Invalid request"
+//	@Failure		401		"Unauthorized"
+//	@Failure		404		"Tag is not attached to the todo"
+//	@Failure		500		"Internal server error"
+//	@Router			/todos/{id}/tags/{tagId} [delete]
+func (h *DetachTagHandler) Handle(ctx context.Context, req *DetachTagRequest) (*DetachTagResponse, int, error) {
+	if err := h.repo.DetachTag(ctx, req.Id, req.TagId, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrTagNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
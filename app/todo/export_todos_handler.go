@@ -0,0 +1,93 @@
+package todo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type ExportTodosRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=json csv ndjson txt todotxt ics"`
+}
+
+type ExportTodosHandler struct {
+	repo   TodoRepository
+	clock  domain.Clock
+	logger domain.Logger
+}
+
+func NewExportTodosHandler(repo TodoRepository, clock domain.Clock, logger domain.Logger) *ExportTodosHandler {
+	return &ExportTodosHandler{repo: repo, clock: clock, logger: logger}
+}
+
+// Handle exports the todos of the user.
+//
+//	@Summary		Export todos
+//	@Description	Downloads every todo the user created that is not in the trash, in their manual order, as a JSON array (default), CSV with a header row, NDJSON, todo.txt or an iCalendar file with a VTODO for each todo. The file is streamed while the todos are read, so its size is not known upfront. Every format but iCalendar can be imported back with `POST /todos/import`.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		text/plain
+//	@Produce		text/calendar
+//	@Param			format	query		string	false	"File format"	Enums(json, csv, ndjson, txt, ics)
+//	@Success		200		{array}		ExportedTodo
+//	@Header			200		{string}	ETag	"ETag of a todo.txt file, for PUT /todos/todotxt"
+//	@Failure		400		"Invalid format"
+//	@Failure		401		"Unauthorized"
+//	@Failure		500		"Internal server error"
+//	@Router			/todos/export [get]
+func (h *ExportTodosHandler) Handle(ctx context.Context, req *ExportTodosRequest) (*domain.File, int, error) {
+	format := TodoFormatJSON
+	if req.Format != "" {
+		var err error
+		if format, err = ParseTodoFormat(req.Format); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	userId := domain.GetUserID(ctx)
+	var etag string
+	if format == TodoFormatTodoTxt {
+		// the ETag lets PUT /todos/todotxt delete the todos left out of the file
+		var err error
+		if etag, err = loadTodoTxtETag(ctx, h.repo, userId); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	file := streamTodos(ctx, h.repo, h.logger, userId, format, h.clock.Now())
+	file.ETag = etag
+	return file, http.StatusOK, nil
+}
+
+// streamTodos returns the export of the todos of the user as a file whose content is
+// written while it is sent.
+func streamTodos(ctx context.Context, repo TodoRepository, logger domain.Logger, userId uuid.UUID, format TodoFormat, now time.Time) *domain.File {
+	r, w := io.Pipe()
+	// A failure past this point cuts the response short, the status has been sent
+	// already. When the client goes away the pipe is closed and the export stops at the
+	// next todo.
+	go func() {
+		enc := newTodoEncoder(format, w, now)
+		err := repo.ExportTodos(ctx, userId, enc.Encode)
+		if err == nil {
+			err = enc.Close()
+		}
+		if err != nil && err != io.ErrClosedPipe {
+			logger.Error("failed to export todos", "user_id", userId, "error", err)
+		}
+		w.CloseWithError(err)
+	}()
+
+	return &domain.File{
+		Name:        "todos." + string(format),
+		ContentType: format.ContentType(),
+		Size:        -1,
+		Body:        r,
+	}
+}
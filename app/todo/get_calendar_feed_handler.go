@@ -0,0 +1,53 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetCalendarFeedRequest struct {
+	Token string `params:"token" validate:"required"`
+}
+
+type GetCalendarFeedHandler struct {
+	repo   TodoRepository
+	feeds  CalendarFeedRepository
+	ts     FeedTokenService
+	clock  domain.Clock
+	logger domain.Logger
+}
+
+func NewGetCalendarFeedHandler(repo TodoRepository, feeds CalendarFeedRepository, ts FeedTokenService, clock domain.Clock, logger domain.Logger) *GetCalendarFeedHandler {
+	return &GetCalendarFeedHandler{repo: repo, feeds: feeds, ts: ts, clock: clock, logger: logger}
+}
+
+// Handle serves the calendar feed of a user to calendar apps.
+//
+//	@Summary		Calendar feed
+//	@Description	Returns the todos of the user the link was minted for as an iCalendar file with a VTODO for each todo, the same as `GET /todos/export?format=ics`. No bearer token is needed, the link itself grants access until the feed is regenerated or revoked.
+//	@Tags			Todo
+//	@Produce		text/calendar
+//	@Param			token	path	string	true	"Feed token"
+//	@Success		200		"iCalendar file"
+//	@Failure		404		"Feed not found, revoked or replaced"
+//	@Failure		500		"Internal server error"
+//	@Router			/feeds/{token}.ics [get]
+func (h *GetCalendarFeedHandler) Handle(ctx context.Context, req *GetCalendarFeedRequest) (*domain.File, int, error) {
+	userId, feedId, err := h.ts.ValidateFeedToken(req.Token)
+	if err != nil {
+		return nil, http.StatusNotFound, domain.ErrFeedNotFound
+	}
+
+	current, err := h.feeds.GetCalendarFeedId(ctx, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if current == uuid.Nil || current != feedId {
+		return nil, http.StatusNotFound, domain.ErrFeedNotFound
+	}
+
+	return streamTodos(ctx, h.repo, h.logger, userId, TodoFormatICS, h.clock.Now()), http.StatusOK, nil
+}
@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetFeedURLRequest struct{}
+
+type GetFeedURLHandler struct {
+	repo CalendarFeedRepository
+	ts   FeedTokenService
+}
+
+func NewGetFeedURLHandler(repo CalendarFeedRepository, ts FeedTokenService) *GetFeedURLHandler {
+	return &GetFeedURLHandler{repo: repo, ts: ts}
+}
+
+// Handle returns a link to the calendar feed of the user.
+//
+//	@Summary		Get the calendar feed link
+//	@Description	Returns a link to the iCalendar feed of the user's todos. Every call mints a new link, the links of a feed stay valid until the feed is regenerated or revoked.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	FeedURLResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"The user has no calendar feed"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/feed [get]
+func (h *GetFeedURLHandler) Handle(ctx context.Context, req *GetFeedURLRequest) (*FeedURLResponse, int, error) {
+	userId := domain.GetUserID(ctx)
+
+	feedId, err := h.repo.GetCalendarFeedId(ctx, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if feedId == uuid.Nil {
+		return nil, http.StatusNotFound, domain.ErrFeedNotFound
+	}
+
+	resp, err := feedURL(h.ts, userId, feedId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return resp, http.StatusOK, nil
+}
@@ -0,0 +1,77 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetProjectTodosRequest struct {
+	Id          uuid.UUID `params:"id" validate:"required,uuid"`
+	IncludeHTML bool      `query:"include_html"`
+	Sort        string    `query:"sort"`
+	Order       string    `query:"order"`
+	Limit       int       `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor      string    `query:"cursor"`
+}
+
+type GetProjectTodosHandler struct {
+	repo     TodoRepository
+	renderer domain.MarkdownRenderer
+}
+
+func NewGetProjectTodosHandler(repo TodoRepository, renderer domain.MarkdownRenderer) *GetProjectTodosHandler {
+	return &GetProjectTodosHandler{
+		repo:     repo,
+		renderer: renderer,
+	}
+}
+
+// Handle retrieves the todos of a project.
+//
+//	@Summary		Get the todos of a project
+//	@Description	Retrieves the todos of a project the authenticated user is a member of, with any role. Archived projects can still be read.
+//	@Tags			Project
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string	true	"Project ID"
+//	@Param			include_html	query		bool	false	"Include the descriptions rendered as sanitized HTML"
+//	@Param			sort			query		string	false	"Sort field, position (the manual order) by default"	Enums(position, priority, created_at, due_at, title)
+//	@Param			order			query		string	false	"Sort order, defaults to desc for priority and created_at, asc otherwise"	Enums(asc, desc)
+//	@Param			limit			query		int		false	"Page size, 50 by default and at most 100"
+//	@Param			cursor			query		string	false	"The next_cursor of the previous page"
+//	@Success		200				{object}	GetTodosResponse
+//	@Failure		400				"Invalid request"
+//	@Failure		401				"Unauthorized"
+//	@Failure		404				"Project not found"
+//	@Failure		500				"Internal server error"
+//	@Router			/projects/{id}/todos [get]
+func (h *GetProjectTodosHandler) Handle(ctx context.Context, req *GetProjectTodosRequest) (*GetTodosResponse, int, error) {
+	if req.Id == uuid.Nil {
+		return nil, http.StatusBadRequest, domain.ErrInvalidRequest
+	}
+
+	query, err := newGetTodosQuery(&GetTodosRequest{Sort: req.Sort, Order: req.Order, Limit: req.Limit, Cursor: req.Cursor})
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	query.ProjectId = req.Id
+
+	todos, err := h.repo.GetTodosByUserID(ctx, domain.GetUserID(ctx), query)
+	if err != nil {
+		if errors.Is(err, domain.ErrProjectNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if err := completeTodos(todos, h.renderer, req.IncludeHTML); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return todos, http.StatusOK, nil
+}
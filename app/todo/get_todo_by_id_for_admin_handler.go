@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetTodoByIdForAdminRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type GetTodoByIdForAdminResponse struct {
+	Id          uuid.UUID       `json:"id"`
+	UserId      uuid.UUID       `json:"user_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Completed   bool            `json:"completed"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt time.Time       `json:"completed_at"`
+	DueAt       time.Time       `json:"due_at"`
+	Priority    domain.Priority `json:"priority"`
+	ProjectId   *uuid.UUID      `json:"project_id"`
+	ParentId    *uuid.UUID      `json:"parent_id"`
+}
+
+type GetTodoByIdForAdminHandler struct {
+	repo TodoRepository
+}
+
+func NewGetTodoByIdForAdminHandler(repo TodoRepository) *GetTodoByIdForAdminHandler {
+	return &GetTodoByIdForAdminHandler{repo: repo}
+}
+
+// Handle retrieves any user's todo by its ID. It is only mounted under the admin routes.
+//
+//	@Summary		Get a todo by ID for admin
+//	@Description	Retrieves a todo item by its ID regardless of its owner.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Todo ID"
+//	@Success		200	{object}	GetTodoByIdForAdminResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		403	"Forbidden"
+//	@Failure		404	"Todo not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/admin/todos/{id} [get]
+func (h *GetTodoByIdForAdminHandler) Handle(ctx context.Context, req *GetTodoByIdForAdminRequest) (*GetTodoByIdForAdminResponse, int, error) {
+	todo, err := h.repo.GetByIdForAdmin(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return todo, http.StatusOK, nil
+}
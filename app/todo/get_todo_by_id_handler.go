@@ -11,42 +11,65 @@ import (
 )
 
 type GetTodoByIdRequest struct {
-	Id uuid.UUID `params:"id" validate:"required,uuid"`
+	Id          uuid.UUID `params:"id" validate:"required,uuid"`
+	IncludeHTML bool      `query:"include_html"`
 }
 
 type GetTodoByIdResponse struct {
-	Id          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	CompletedAt time.Time `json:"completed_at"`
+	Id              uuid.UUID       `json:"id"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	DescriptionHTML string          `json:"description_html,omitempty"`
+	Completed       bool            `json:"completed"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CompletedAt     time.Time       `json:"completed_at"`
+	DueAt           time.Time       `json:"due_at"`
+	Overdue         bool            `json:"overdue"`
+	Priority        domain.Priority `json:"priority"`
+	ProjectId       *uuid.UUID      `json:"project_id"`
+	ParentId        *uuid.UUID      `json:"parent_id"`
+	Recurrence      string          `json:"recurrence,omitempty"`
+	Timezone        string          `json:"timezone,omitempty"`
+	OccurrenceAt    time.Time       `json:"occurrence_at"`
+	Progress        domain.Progress `json:"progress"`
+	Subtasks        []Subtask       `json:"subtasks"`
+	Tags            []TodoTag       `json:"tags"`
+	// Version is sent as the ETag of the todo rather than in the body. It changes with
+	// the tags and the subtasks of the todo as well.
+	Version int `json:"-"`
 }
 
 type GetTodoByIdHandler struct {
-	repo TodoRepository
+	repo     TodoRepository
+	renderer domain.MarkdownRenderer
 }
 
-func NewGetTodoByIdHandler(repo TodoRepository) *GetTodoByIdHandler {
-	return &GetTodoByIdHandler{repo: repo}
+func NewGetTodoByIdHandler(repo TodoRepository, renderer domain.MarkdownRenderer) *GetTodoByIdHandler {
+	return &GetTodoByIdHandler{repo: repo, renderer: renderer}
 }
 
 // GetTodoByIdHandler handles the retrieval of a todo item by its ID.
 //
 //	@Summary		Get a todo by ID
-//	@Description	Retrieves a todo item by its ID for the authenticated user.
+//	@Description	Retrieves a todo item by its ID for the authenticated user, together with its nested subtasks and how many of them are done.
+//	@Description	The ETag header holds the version of the todo, send it back in If-Match to update or delete the todo only while nobody else has changed it. The ETag also tells the rendered HTML and an overdue todo apart, with If-None-Match the todo is only sent when it or the response have changed.
 //	@Tags			Todo
 //	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"Todo ID"
-//	@Success		200	{object}	GetTodoByIdResponse
+//	@Param			id				path		string	true	"Todo ID"
+//	@Param			include_html	query		bool	false	"Include the description rendered as sanitized HTML"
+//	@Param			If-None-Match	header		string	false	"ETag of the version the client has"
+//	@Success		200				{object}	GetTodoByIdResponse
+//	@Header			200				{string}	ETag	"Version of the todo"
+//	@Success		304	"The todo has not changed"
 //	@Failure		400	"Invalid request"
 //	@Failure		401	"Unauthorized"
 //	@Failure		404	"Todo not found"
 //	@Failure		500	"Internal server error"
 //	@Router			/todos/{id} [get]
 func (h *GetTodoByIdHandler) Handle(ctx context.Context, req *GetTodoByIdRequest) (*GetTodoByIdResponse, int, error) {
-	todo, err := h.repo.GetById(ctx, req.Id)
+	todo, err := h.repo.GetById(ctx, req.Id, domain.GetUserID(ctx))
 	if err != nil {
 		if errors.Is(err, domain.ErrTodoNotFound) {
 			return nil, http.StatusNotFound, err
@@ -54,5 +77,35 @@ func (h *GetTodoByIdHandler) Handle(ctx context.Context, req *GetTodoByIdRequest
 		return nil, http.StatusInternalServerError, err
 	}
 
+	todo.Overdue = domain.IsOverdue(todo.DueAt, todo.Completed, time.Now())
+
+	if req.IncludeHTML {
+		if todo.DescriptionHTML, err = renderDescription(h.renderer, todo.Description); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
 	return todo, http.StatusOK, nil
 }
+
+// ETag is the version of the todo together with the fields of the response that do not
+// change with it, so that a client never keeps a body of another variant or one that
+// was sent before the todo became overdue.
+func (r *GetTodoByIdResponse) ETag() string {
+	var variants []string
+	if r.DescriptionHTML != "" {
+		variants = append(variants, "html")
+	}
+	if r.Overdue {
+		variants = append(variants, "overdue")
+	}
+	return domain.VersionETag(r.Version, variants...)
+}
+
+// recurrence returns the recurrence stored for the todo, or nil for a one-off todo.
+func (r *GetTodoByIdResponse) recurrence() (*domain.Recurrence, error) {
+	if r.Recurrence == "" {
+		return nil, nil
+	}
+	return domain.NewRecurrence(r.Recurrence, r.Timezone)
+}
@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetTodoHistoryRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type GetTodoHistoryResponse struct {
+	Revisions []TodoRevision `json:"revisions"`
+}
+
+// TodoRevision is one change of a todo. Changes is keyed by field: title, description,
+// completed, due_at, priority or project_id.
+type TodoRevision struct {
+	Id uuid.UUID `json:"id"`
+	// ActorId is the user who made the change, null once that user is deleted.
+	ActorId   *uuid.UUID                    `json:"actor_id"`
+	CreatedAt time.Time                     `json:"created_at"`
+	Changes   map[string]domain.FieldChange `json:"changes"`
+}
+
+type GetTodoHistoryHandler struct {
+	repo TodoRepository
+}
+
+func NewGetTodoHistoryHandler(repo TodoRepository) *GetTodoHistoryHandler {
+	return &GetTodoHistoryHandler{repo: repo}
+}
+
+// GetTodoHistoryHandler lists the changes made to a todo.
+//
+//	@Summary		Get the history of a todo
+//	@Description	Retrieves the revisions of a todo of the authenticated user, newest first. Every revision has the values of the changed fields before and after the change.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Todo ID"
+//	@Success		200	{object}	GetTodoHistoryResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Todo not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/{id}/history [get]
+func (h *GetTodoHistoryHandler) Handle(ctx context.Context, req *GetTodoHistoryRequest) (*GetTodoHistoryResponse, int, error) {
+	history, err := h.repo.GetTodoHistory(ctx, req.Id, domain.GetUserID(ctx))
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return history, http.StatusOK, nil
+}
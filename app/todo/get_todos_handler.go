@@ -2,85 +2,359 @@ package todo
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
 type GetTodosRequest struct {
+	IncludeHTML   bool     `query:"include_html"`
+	DueBefore     string   `query:"due_before"`
+	DueAfter      string   `query:"due_after"`
+	CreatedBefore string   `query:"created_before"`
+	CreatedAfter  string   `query:"created_after"`
+	Completed     string   `query:"completed"`
+	Overdue       bool     `query:"overdue"`
+	Q             string   `query:"q" validate:"max=100"`
+	Sort          string   `query:"sort"`
+	Order         string   `query:"order"`
+	Tags          []string `query:"tag"`
+	TagMode       string   `query:"tag_mode"`
+	Limit         int      `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor        string   `query:"cursor"`
 }
 
-type GetTodosResponse []Todo
+const (
+	DefaultTodoPageSize = 50
+	MaxTodoPageSize     = 100
+	MaxSearchLength     = 100
+)
+
+type TodoSortField string
+
+const (
+	SortByPriority  TodoSortField = "priority"
+	SortByCreatedAt TodoSortField = "created_at"
+	SortByDueAt     TodoSortField = "due_at"
+	SortByTitle     TodoSortField = "title"
+	// SortByPosition is the manual order set with ReorderTodoHandler.
+	SortByPosition TodoSortField = "position"
+
+	DefaultTodoSort = SortByPosition
+)
+
+type TagMode string
+
+const (
+	// TagModeAnd matches todos that have every requested tag.
+	TagModeAnd TagMode = "and"
+	// TagModeOr matches todos that have at least one of the requested tags.
+	TagModeOr TagMode = "or"
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// The order used when a sort field is requested without one: the most urgent, the
+// newest and the soonest due todos come first, titles are alphabetical and the manual
+// order goes from top to bottom.
+var defaultSortOrders = map[TodoSortField]SortOrder{
+	SortByPriority:  SortDesc,
+	SortByCreatedAt: SortDesc,
+	SortByDueAt:     SortAsc,
+	SortByTitle:     SortAsc,
+	SortByPosition:  SortAsc,
+}
+
+// GetTodosQuery describes which todos are listed and in which order. DueAfter and
+// CreatedAfter are inclusive, DueBefore and CreatedBefore are exclusive, so consecutive
+// ranges never overlap. Completed is nil when both open and completed todos are listed.
+// Tags holds distinct lowercase tag names, they are matched case-insensitively, and
+// Search is matched case-insensitively against the title and the description.
+// ProjectId limits the list to one project, it is only set by GetProjectTodosHandler.
+// Sort, Order, TagMode and Limit are always set, the handler fills in the defaults.
+// After is nil for the first page.
+type GetTodosQuery struct {
+	ProjectId     uuid.UUID
+	DueBefore     time.Time
+	DueAfter      time.Time
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+	Completed     *bool
+	Overdue       bool
+	Search        string
+	Tags          []string
+	TagMode       TagMode
+	Sort          TodoSortField
+	Order         SortOrder
+	Limit         int
+	After         *TodoCursor
+}
+
+func (q GetTodosQuery) HasFilters() bool {
+	return q.ProjectId != uuid.Nil || !q.DueBefore.IsZero() || !q.DueAfter.IsZero() ||
+		!q.CreatedBefore.IsZero() || !q.CreatedAfter.IsZero() || q.Completed != nil ||
+		q.Overdue || q.Search != "" || len(q.Tags) > 0
+}
+
+// IsFirstPage reports whether the query asks for the first page of the default size.
+func (q GetTodosQuery) IsFirstPage() bool {
+	return q.After == nil && q.Limit == DefaultTodoPageSize
+}
+
+// NextCursor returns the token of the page that follows last.
+func (q GetTodosQuery) NextCursor(last Todo) string {
+	return newTodoCursor(q, last).Encode()
+}
+
+func (q GetTodosQuery) IsDefaultSort() bool {
+	return q.Sort == DefaultTodoSort && q.Order == defaultSortOrders[DefaultTodoSort]
+}
+
+// SortVariants returns every sort field and order combination.
+func SortVariants() []GetTodosQuery {
+	var variants []GetTodosQuery
+	for _, field := range []TodoSortField{SortByPriority, SortByCreatedAt, SortByDueAt, SortByTitle, SortByPosition} {
+		for _, order := range []SortOrder{SortAsc, SortDesc} {
+			variants = append(variants, GetTodosQuery{Sort: field, Order: order})
+		}
+	}
+	return variants
+}
+
+// GetTodosResponse is one page of todos. NextCursor is empty on the last page, Total
+// counts every todo that matches the filters, on all pages.
+type GetTodosResponse struct {
+	Todos      []Todo `json:"todos"`
+	NextCursor string `json:"next_cursor"`
+	Total      int    `json:"total"`
+}
 
 type Todo struct {
-	Id          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	CompletedAt time.Time `json:"completed_at"`
+	Id              uuid.UUID       `json:"id"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	DescriptionHTML string          `json:"description_html,omitempty"`
+	Completed       bool            `json:"completed"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CompletedAt     time.Time       `json:"completed_at"`
+	DueAt           time.Time       `json:"due_at"`
+	Overdue         bool            `json:"overdue"`
+	Priority        domain.Priority `json:"priority"`
+	ProjectId       *uuid.UUID      `json:"project_id"`
+	ParentId        *uuid.UUID      `json:"parent_id"`
+	Recurrence      string          `json:"recurrence,omitempty"`
+	Timezone        string          `json:"timezone,omitempty"`
+	Position        string          `json:"position"`
+	Tags            []TodoTag       `json:"tags"`
+}
+
+type TodoTag struct {
+	Id    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Color string    `json:"color"`
 }
 
-// TODO add domain.Logger
 type GetTodosHandler struct {
-	repo  TodoRepository
-	cache domain.Cache
-	ttl   time.Duration
+	repo     TodoRepository
+	renderer domain.MarkdownRenderer
 }
 
-func NewGetTodosHandler(repo TodoRepository, cache domain.Cache, ttl time.Duration) *GetTodosHandler {
+// The list is cached by CachedTodoRepository, so repo is expected to be wrapped by it.
+func NewGetTodosHandler(repo TodoRepository, renderer domain.MarkdownRenderer) *GetTodosHandler {
 	return &GetTodosHandler{
-		repo:  repo,
-		cache: cache,
-		ttl:   ttl,
+		repo:     repo,
+		renderer: renderer,
 	}
 }
 
-// Handle retrieves all todos for the authenticated user.
+// Handle retrieves the todos of the authenticated user, one page at a time.
 //
-//	@Summary		Get all todos
-//	@Description	Retrieves all todos for the authenticated user.
+//	@Summary		Get todos
+//	@Description	Retrieves the todos of the authenticated user, together with the todos of the projects shared with them, one page at a time. Pass the next_cursor of a page as cursor, with the same filters and sort, to get the next one.
 //	@Tags			Todo
 //	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	GetTodosResponse
+//	@Param			include_html	query		bool	false	"Include the descriptions rendered as sanitized HTML"
+//	@Param			due_before		query		string	false	"Only todos due before this RFC 3339 timestamp (exclusive)"
+//	@Param			due_after		query		string	false	"Only todos due at or after this RFC 3339 timestamp (inclusive)"
+//	@Param			created_before	query		string	false	"Only todos created before this RFC 3339 timestamp (exclusive)"
+//	@Param			created_after	query		string	false	"Only todos created at or after this RFC 3339 timestamp (inclusive)"
+//	@Param			completed		query		bool	false	"Only completed (true) or only open (false) todos"
+//	@Param			overdue			query		bool	false	"Only uncompleted todos whose due date has passed, a due date at midnight in its zone stands for the whole day and passes when the day ends there"
+//	@Param			q				query		string	false	"Only todos whose title or description contains this text, case-insensitive"
+//	@Param			tag				query		[]string	false	"Only todos with these tag names, repeat the parameter or separate names with commas"	collectionFormat(multi)
+//	@Param			tag_mode		query		string	false	"and (default) requires every tag, or requires at least one"	Enums(and, or)
+//	@Param			sort			query		string	false	"Sort field, position (the manual order) by default"	Enums(position, priority, created_at, due_at, title)
+//	@Param			order			query		string	false	"Sort order, defaults to desc for priority and created_at, asc otherwise"	Enums(asc, desc)
+//	@Param			limit			query		int		false	"Page size, 50 by default and at most 100"
+//	@Param			cursor			query		string	false	"The next_cursor of the previous page"
+//	@Success		200				{object}	GetTodosResponse
+//	@Failure		400	"Invalid request"
 //	@Failure		401	"Unauthorized"
 //	@Failure		500	"Internal server error"
 //	@Router			/todos [get]
 func (h *GetTodosHandler) Handle(ctx context.Context, req *GetTodosRequest) (*GetTodosResponse, int, error) {
-	userID := domain.GetUserID(ctx)
-	cacheKey := domain.NewTodoCacheKey(userID)
-
-	if cached, err := h.cache.Get(ctx, cacheKey); err == nil && !isCacheEmpty(cached) {
-		var todos GetTodosResponse
-		if err := json.Unmarshal(cached, &todos); err == nil {
-			return &todos, http.StatusOK, nil
-		}
+	query, err := newGetTodosQuery(req)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
 	}
 
-	todos, err := h.repo.GetTodosByUserID(ctx, userID)
+	todos, err := h.repo.GetTodosByUserID(ctx, domain.GetUserID(ctx), query)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
 
-	go h.SetCache(cacheKey, todos)
+	if err := completeTodos(todos, h.renderer, req.IncludeHTML); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
 
 	return todos, http.StatusOK, nil
 }
 
-func (h *GetTodosHandler) SetCache(key string, todos *GetTodosResponse) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-	if data, err := json.Marshal(todos); err == nil {
-		if err := h.cache.Set(ctx, key, data, h.ttl); err != nil {
-			// TODO log cache set error
+// completeTodos fills in the fields that are computed per request.
+func completeTodos(todos *GetTodosResponse, renderer domain.MarkdownRenderer, includeHTML bool) error {
+	// Overdue depends on the current time, so it is computed here rather than being cached.
+	now := time.Now()
+	for i := range todos.Todos {
+		todo := &todos.Todos[i]
+		todo.Overdue = domain.IsOverdue(todo.DueAt, todo.Completed, now)
+	}
+
+	// HTML is rendered per request and never cached, the cache only holds the Markdown source.
+	if includeHTML {
+		for i := range todos.Todos {
+			todo := &todos.Todos[i]
+			var err error
+			if todo.DescriptionHTML, err = renderDescription(renderer, todo.Description); err != nil {
+				return err
+			}
 		}
 	}
+
+	return nil
 }
 
-func isCacheEmpty(cached []byte) bool {
-	return len(cached) == 0
+func newGetTodosQuery(req *GetTodosRequest) (GetTodosQuery, error) {
+	query := GetTodosQuery{
+		Overdue: req.Overdue,
+		Search:  strings.TrimSpace(req.Q),
+		Tags:    parseTagFilter(req.Tags),
+		TagMode: TagMode(req.TagMode),
+		Sort:    TodoSortField(req.Sort),
+		Order:   SortOrder(req.Order),
+		Limit:   req.Limit,
+	}
+
+	if utf8.RuneCountInString(query.Search) > MaxSearchLength {
+		return GetTodosQuery{}, domain.ErrSearchQueryTooLong
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultTodoPageSize
+	}
+	if query.Limit < 1 || query.Limit > MaxTodoPageSize {
+		return GetTodosQuery{}, domain.ErrInvalidPageLimit
+	}
+
+	if req.Completed != "" {
+		completed, err := strconv.ParseBool(req.Completed)
+		if err != nil {
+			return GetTodosQuery{}, domain.ErrInvalidCompletedFilter
+		}
+		query.Completed = &completed
+	}
+
+	switch query.TagMode {
+	case "":
+		query.TagMode = TagModeAnd
+	case TagModeAnd, TagModeOr:
+	default:
+		return GetTodosQuery{}, domain.ErrInvalidTagMode
+	}
+
+	var err error
+	if query.DueBefore, err = parseTimeFilter(req.DueBefore, domain.ErrInvalidDueFilter); err != nil {
+		return GetTodosQuery{}, err
+	}
+	if query.DueAfter, err = parseTimeFilter(req.DueAfter, domain.ErrInvalidDueFilter); err != nil {
+		return GetTodosQuery{}, err
+	}
+	if query.CreatedBefore, err = parseTimeFilter(req.CreatedBefore, domain.ErrInvalidCreatedFilter); err != nil {
+		return GetTodosQuery{}, err
+	}
+	if query.CreatedAfter, err = parseTimeFilter(req.CreatedAfter, domain.ErrInvalidCreatedFilter); err != nil {
+		return GetTodosQuery{}, err
+	}
+
+	if query.Sort == "" {
+		query.Sort = DefaultTodoSort
+	}
+	defaultOrder, ok := defaultSortOrders[query.Sort]
+	if !ok {
+		return GetTodosQuery{}, domain.ErrInvalidSort
+	}
+
+	switch query.Order {
+	case "":
+		query.Order = defaultOrder
+	case SortAsc, SortDesc:
+	default:
+		return GetTodosQuery{}, domain.ErrInvalidSortOrder
+	}
+
+	if req.Cursor != "" {
+		if query.After, err = decodeTodoCursor(req.Cursor, query.Sort, query.Order); err != nil {
+			return GetTodosQuery{}, err
+		}
+	}
+
+	return query, nil
+}
+
+func parseTagFilter(values []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// An unescaped "+" of a positive offset arrives as a space in the query string,
+// it is put back so that "2025-01-01T09:00:00+03:00" works without encoding.
+func parseTimeFilter(value string, errInvalid error) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.ReplaceAll(value, " ", "+"))
+	if err != nil {
+		return time.Time{}, errInvalid
+	}
+	return t.UTC(), nil
+}
+
+func renderDescription(renderer domain.MarkdownRenderer, description string) (string, error) {
+	if description == "" {
+		return "", nil
+	}
+	return renderer.Render(description)
 }
@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetTrashRequest struct {
+}
+
+type GetTrashResponse struct {
+	Todos []TrashedTodo `json:"todos"`
+}
+
+type TrashedTodo struct {
+	Id          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Completed   bool            `json:"completed"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DueAt       time.Time       `json:"due_at"`
+	Priority    domain.Priority `json:"priority"`
+	ProjectId   *uuid.UUID      `json:"project_id"`
+	ParentId    *uuid.UUID      `json:"parent_id"`
+	DeletedAt   time.Time       `json:"deleted_at"`
+	PurgeAt     time.Time       `json:"purge_at"`
+}
+
+type GetTrashHandler struct {
+	repo      TodoRepository
+	retention time.Duration
+}
+
+// retention must be the one the TrashPurger runs with, it only serves purge_at.
+func NewGetTrashHandler(repo TodoRepository, retention time.Duration) *GetTrashHandler {
+	return &GetTrashHandler{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+// GetTrashHandler lists the trashed todos of the authenticated user.
+//
+//	@Summary		Get the trash
+//	@Description	Retrieves the deleted todos of the authenticated user, most recently deleted first. Subtasks deleted together with their parent are not listed, they are restored and deleted with it. purge_at tells when a todo is deleted for good.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	GetTrashResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/trash [get]
+func (h *GetTrashHandler) Handle(ctx context.Context, req *GetTrashRequest) (*GetTrashResponse, int, error) {
+	todos, err := h.repo.GetTrash(ctx, domain.GetUserID(ctx))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	for i := range todos {
+		todos[i].PurgeAt = todos[i].DeletedAt.Add(h.retention)
+	}
+
+	return &GetTrashResponse{Todos: todos}, http.StatusOK, nil
+}
@@ -0,0 +1,131 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// icalProductId names this API as the producer of the calendars, RFC 5545 requires one.
+const icalProductId = "-//advanced-todo-api//Todos//EN"
+
+// icalLineLength is the longest a content line may be, in octets and without its CRLF.
+const icalLineLength = 75
+
+// icalPriorities maps the priorities to the 1 (highest) to 9 (lowest) scale of RFC 5545,
+// PriorityNone is left out, which stands for an undefined priority.
+var icalPriorities = map[domain.Priority]int{
+	domain.PriorityLow:    9,
+	domain.PriorityMedium: 5,
+	domain.PriorityHigh:   3,
+	domain.PriorityUrgent: 1,
+}
+
+// ICalendar returns a calendar holding the VTODO of the todo alone, stamped with stamp.
+func ICalendar(todo ExportedTodo, stamp time.Time) []byte {
+	var buf bytes.Buffer
+	enc := newICalTodoEncoder(&buf, stamp)
+	// writing to a buffer cannot fail
+	_ = enc.Encode(todo)
+	_ = enc.Close()
+	return buf.Bytes()
+}
+
+// icalTodoEncoder writes a VCALENDAR with a VTODO for each todo. stamp is the DTSTAMP of
+// every VTODO, the time the calendar was generated.
+type icalTodoEncoder struct {
+	buf   *bufio.Writer
+	stamp time.Time
+}
+
+func newICalTodoEncoder(w io.Writer, stamp time.Time) *icalTodoEncoder {
+	e := &icalTodoEncoder{buf: bufio.NewWriter(w), stamp: stamp}
+	// a failed write is kept by the writer and returned by Close
+	e.writeLine("BEGIN", "VCALENDAR")
+	e.writeLine("VERSION", "2.0")
+	e.writeLine("PRODID", icalProductId)
+	e.writeLine("CALSCALE", "GREGORIAN")
+	return e
+}
+
+func (e *icalTodoEncoder) Encode(todo ExportedTodo) error {
+	e.writeLine("BEGIN", "VTODO")
+	e.writeLine("UID", todo.Id.String())
+	e.writeLine("DTSTAMP", formatICalTime(e.stamp))
+	e.writeLine("CREATED", formatICalTime(todo.CreatedAt))
+	e.writeLine("SUMMARY", escapeICalText(todo.Title))
+	if todo.Description != "" {
+		e.writeLine("DESCRIPTION", escapeICalText(todo.Description))
+	}
+	if priority, ok := icalPriorities[todo.Priority]; ok {
+		e.writeLine("PRIORITY", strconv.Itoa(priority))
+	}
+	if todo.DueAt != nil {
+		e.writeLine("DUE", formatICalTime(*todo.DueAt))
+	}
+	if todo.Completed {
+		e.writeLine("STATUS", "COMPLETED")
+		e.writeLine("PERCENT-COMPLETE", "100")
+		if todo.CompletedAt != nil {
+			e.writeLine("COMPLETED", formatICalTime(*todo.CompletedAt))
+		}
+	} else {
+		e.writeLine("STATUS", "NEEDS-ACTION")
+	}
+	if len(todo.Tags) > 0 {
+		categories := make([]string, len(todo.Tags))
+		for i, tag := range todo.Tags {
+			categories[i] = escapeICalText(tag)
+		}
+		e.writeLine("CATEGORIES", strings.Join(categories, ","))
+	}
+	if todo.ParentId != nil {
+		e.writeLine("RELATED-TO", todo.ParentId.String())
+	}
+	return e.writeLine("END", "VTODO")
+}
+
+func (e *icalTodoEncoder) Close() error {
+	if err := e.writeLine("END", "VCALENDAR"); err != nil {
+		return err
+	}
+	return e.buf.Flush()
+}
+
+// writeLine writes a content line, folded into lines of at most icalLineLength octets.
+// A continuation line starts with a space, which is not part of the value, and lines
+// are never split inside a UTF-8 sequence.
+func (e *icalTodoEncoder) writeLine(name, value string) error {
+	line := name + ":" + value
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := e.buf.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// the leading space counts towards the length of a continuation line
+		limit = icalLineLength - 1
+	}
+	_, err := e.buf.WriteString(line + "\r\n")
+	return err
+}
+
+func formatICalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeICalText(text string) string {
+	return icalTextEscaper.Replace(text)
+}
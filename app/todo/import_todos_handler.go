@@ -0,0 +1,267 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+const MaxImportedTodos = 5000
+
+type ImportTodosRequest struct {
+	Format string                `query:"format" validate:"omitempty,oneof=json csv ndjson txt todotxt"`
+	DryRun bool                  `query:"dry_run"`
+	File   *multipart.FileHeader `form:"file" validate:"required" swaggerignore:"true"`
+}
+
+// ImportTodosResponse reports every invalid todo of the file. Todos are imported all
+// at once or not at all, so Imported is either Total or 0.
+type ImportTodosResponse struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is the error of the todo at Row, the first todo of the file is row 1
+// and the header of a CSV file is not counted. When the file itself is malformed the
+// error is reported at the row where reading stopped.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportTodosHandler struct {
+	repo TodoRepository
+}
+
+func NewImportTodosHandler(repo TodoRepository) *ImportTodosHandler {
+	return &ImportTodosHandler{repo: repo}
+}
+
+// Handle imports todos from a file.
+//
+//	@Summary		Import todos
+//	@Description	Creates up to 5000 todos from a JSON array, a CSV file with a header row, an NDJSON file or a todo.txt file, uploaded as `multipart/form-data`. The format is taken from the file extension unless it is given. Every todo has a title and optionally a description, completed, priority, due_at, which is an RFC 3339 timestamp or a YYYY-MM-DD date, project_id, parent_id and tags. Other fields and columns are ignored, so an export can be imported as is. Every todo is created anew, its id only names it for the parent_id of its subtasks, which is the id of another todo of the file or of an existing todo. A todo without a project lands in the inbox, a subtask in the project of its parent, the project and a parent outside the file must be editable by the user. Tags are matched by name, the missing ones are created. A todo.txt file also carries the creation and completion dates, its +project names a project of the user and its @contexts are the tags. Imported todos come after the other todos of the user. The todos are inserted in one transaction: when any of them is invalid nothing is imported and the status is 400, the response lists the error of every invalid row either way. With dry_run the file is only validated.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"File to import"
+//	@Param			format	query		string	false	"File format"	Enums(json, csv, ndjson, txt)
+//	@Param			dry_run	query		bool	false	"Only validate the file"
+//	@Success		200		{object}	ImportTodosResponse	"The file is valid, nothing was imported because of dry_run"
+//	@Success		201		{object}	ImportTodosResponse
+//	@Failure		400		{object}	ImportTodosResponse	"Invalid request or invalid todos"
+//	@Failure		401		"Unauthorized"
+//	@Failure		403		"A project or a parent todo can only be viewed"
+//	@Failure		404		"Project or parent todo not found"
+//	@Failure		500		"Internal server error"
+//	@Router			/todos/import [post]
+func (h *ImportTodosHandler) Handle(ctx context.Context, req *ImportTodosRequest) (*ImportTodosResponse, int, error) {
+	if req.File == nil {
+		return nil, http.StatusBadRequest, domain.ErrMissingImportFile
+	}
+
+	formatName := req.Format
+	if formatName == "" {
+		formatName = strings.TrimPrefix(filepath.Ext(req.File.Filename), ".")
+	}
+	format, err := ParseTodoFormat(formatName)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !format.Importable() {
+		return nil, http.StatusBadRequest, domain.ErrInvalidImportFormat
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	defer file.Close()
+
+	dec, err := newTodoDecoder(format, file)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	userId := domain.GetUserID(ctx)
+	resp := &ImportTodosResponse{DryRun: req.DryRun, Errors: []ImportRowError{}}
+	projects := &todoTxtProjects{repo: h.repo, userId: userId}
+	var rows []importedRow
+	for row := 1; ; row++ {
+		imported, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if row > MaxImportedTodos {
+			return nil, http.StatusBadRequest, domain.ErrTooManyImportedTodos
+		}
+		resp.Total = row
+
+		var rowErr *rowError
+		if err != nil && !errors.As(err, &rowErr) {
+			resp.Errors = append(resp.Errors, ImportRowError{Row: row, Error: err.Error()})
+			break
+		}
+		if err == nil && imported.project != "" {
+			var projectId uuid.UUID
+			if projectId, err = projects.find(ctx, imported.project); err == nil {
+				imported.ProjectId = projectId.String()
+			} else if !errors.Is(err, domain.ErrProjectNotFound) {
+				return nil, http.StatusInternalServerError, err
+			}
+		}
+		if err == nil {
+			var parsed importedRow
+			if parsed, err = newImportedRow(imported, userId, row); err == nil {
+				rows = append(rows, parsed)
+				continue
+			}
+		}
+		resp.Errors = append(resp.Errors, ImportRowError{Row: row, Error: err.Error()})
+	}
+
+	if resp.Total == 0 {
+		return nil, http.StatusBadRequest, domain.ErrEmptyImport
+	}
+	// the parents can only be told apart from missing ones once every row is valid
+	if len(resp.Errors) == 0 {
+		if resp.Errors, err = h.linkSubtasks(ctx, userId, rows); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp, http.StatusBadRequest, nil
+	}
+	if req.DryRun {
+		return resp, http.StatusOK, nil
+	}
+
+	todos := make([]*domain.Todo, len(rows))
+	tags := make(map[uuid.UUID][]string)
+	for i, row := range rows {
+		todos[i] = row.todo
+		if len(row.tags) > 0 {
+			tags[row.todo.Id] = row.tags
+		}
+	}
+	if err := h.repo.ImportTodos(ctx, userId, todos, tags); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProjectNotFound), errors.Is(err, domain.ErrParentTodoNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrProjectArchived):
+			return nil, http.StatusBadRequest, err
+		case errors.Is(err, domain.ErrProjectReadOnly), errors.Is(err, domain.ErrTodoReadOnly):
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	resp.Imported = len(todos)
+	return resp, http.StatusCreated, nil
+}
+
+// importedRow is a valid todo of the file. id and parentId are the ids in the file,
+// uuid.Nil when they are left out.
+type importedRow struct {
+	row      int
+	id       uuid.UUID
+	parentId uuid.UUID
+	todo     *domain.Todo
+	tags     []string
+}
+
+func newImportedRow(imported ImportedTodo, userId uuid.UUID, row int) (importedRow, error) {
+	parsed := importedRow{row: row}
+	var err error
+	if parsed.todo, err = imported.NewTodo(userId); err != nil {
+		return parsed, err
+	}
+	if parsed.id, err = parseImportedId(imported.Id); err != nil {
+		return parsed, err
+	}
+	if parsed.parentId, err = parseImportedId(imported.ParentId); err != nil {
+		return parsed, err
+	}
+	parsed.tags, err = imported.tagNames()
+	return parsed, err
+}
+
+// linkSubtasks points every subtask at the new id of its parent, or at the existing
+// todo when the parent is not part of the file, and checks how deep it ends up. A
+// parent that is its own ancestor nests the subtask endlessly deep.
+func (h *ImportTodosHandler) linkSubtasks(ctx context.Context, userId uuid.UUID, rows []importedRow) ([]ImportRowError, error) {
+	rowErrs := []ImportRowError{}
+	byId := make(map[uuid.UUID]*importedRow, len(rows))
+	for i := range rows {
+		if rows[i].id == uuid.Nil {
+			continue
+		}
+		if _, ok := byId[rows[i].id]; ok {
+			rowErrs = append(rowErrs, ImportRowError{Row: rows[i].row, Error: domain.ErrDuplicateImportedId.Error()})
+			continue
+		}
+		byId[rows[i].id] = &rows[i]
+	}
+
+	// the depth of each existing parent, looked up once
+	existing := make(map[uuid.UUID]int)
+	depthOf := func(row *importedRow) (int, error) {
+		depth := 0
+		for parentId := row.parentId; parentId != uuid.Nil; depth++ {
+			if depth > domain.MaxSubtaskDepth {
+				break
+			}
+			parent, ok := byId[parentId]
+			if ok {
+				parentId = parent.parentId
+				continue
+			}
+			parentDepth, ok := existing[parentId]
+			if !ok {
+				var err error
+				if parentDepth, err = h.repo.GetTodoDepth(ctx, parentId, userId); err != nil {
+					return 0, err
+				}
+				existing[parentId] = parentDepth
+			}
+			return depth + 1 + parentDepth, nil
+		}
+		return depth, nil
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.parentId == uuid.Nil {
+			continue
+		}
+		depth, err := depthOf(row)
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			err = domain.ErrParentTodoNotFound
+		} else if err != nil {
+			return nil, err
+		} else {
+			err = domain.ValidateSubtaskDepth(depth)
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, ImportRowError{Row: row.row, Error: err.Error()})
+			continue
+		}
+
+		row.todo.ParentId = row.parentId
+		if parent, ok := byId[row.parentId]; ok {
+			row.todo.ParentId = parent.todo.Id
+		}
+	}
+	slices.SortStableFunc(rowErrs, func(a, b ImportRowError) int { return a.Row - b.Row })
+	return rowErrs, nil
+}
@@ -0,0 +1,59 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type MoveTodoRequest struct {
+	Id        uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	ProjectId uuid.UUID `json:"project_id" validate:"omitempty,uuid"`
+}
+
+type MoveTodoResponse struct {
+}
+
+type MoveTodoHandler struct {
+	repo TodoRepository
+}
+
+func NewMoveTodoHandler(repo TodoRepository) *MoveTodoHandler {
+	return &MoveTodoHandler{repo: repo}
+}
+
+// MoveTodoHandler moves a todo between projects.
+//
+//	@Summary		Move a todo to another project
+//	@Description	Moves a todo of the authenticated user into one of their projects, together with its subtasks. A null or missing project_id moves it to the inbox. A subtask belongs to the project of its parent and cannot be moved on its own, move its top-level todo instead.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string			true	"Todo ID"
+//	@Param			MoveTodoRequest	body	MoveTodoRequest	true	"Target project"
+//	@Success		204				"Todo moved successfully"
+//	@Failure		400				"Invalid request, archived project or subtask"
+//	@Failure		401				"Unauthorized"
+//	@Failure		403				"The todo or the project can only be viewed"
+//	@Failure		404				"Todo or project not found"
+//	@Failure		500				"Internal server error"
+//	@Router			/todos/{id}/project [put]
+func (h *MoveTodoHandler) Handle(ctx context.Context, req *MoveTodoRequest) (*MoveTodoResponse, int, error) {
+	if err := h.repo.MoveTodo(ctx, req.Id, domain.GetUserID(ctx), req.ProjectId); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound), errors.Is(err, domain.ErrProjectNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrProjectArchived), errors.Is(err, domain.ErrSubtaskWithProject):
+			return nil, http.StatusBadRequest, err
+		case errors.Is(err, domain.ErrTodoReadOnly), errors.Is(err, domain.ErrProjectReadOnly):
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
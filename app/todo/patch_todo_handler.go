@@ -0,0 +1,169 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// MergePatchContentType is the media type of a JSON merge patch (RFC 7396).
+const MergePatchContentType = "application/merge-patch+json"
+
+// maxPatchAttempts is how often a patch without If-Match is applied before giving up
+// when the todo keeps changing between reading and writing it.
+const maxPatchAttempts = 3
+
+// PatchField is a member of a JSON merge patch. Set tells whether the patch has the
+// member at all, Null whether the member is null, which removes the field.
+type PatchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+type PatchTodoRequest struct {
+	Id          uuid.UUID                   `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Title       PatchField[string]          `json:"title" swaggertype:"string"`
+	Description PatchField[string]          `json:"description" swaggertype:"string"`
+	Completed   PatchField[bool]            `json:"completed" swaggertype:"boolean"`
+	DueAt       PatchField[time.Time]       `json:"due_at" swaggertype:"string" format:"date-time"`
+	Priority    PatchField[domain.Priority] `json:"priority" swaggertype:"string" enums:"none,low,medium,high,urgent"`
+	IfMatch     string                      `reqHeader:"If-Match" swaggerignore:"true"`
+}
+
+type PatchTodoResponse struct{}
+
+type PatchTodoHandler struct {
+	repo TodoRepository
+}
+
+func NewPatchTodoHandler(repo TodoRepository) *PatchTodoHandler {
+	return &PatchTodoHandler{repo: repo}
+}
+
+// PatchTodoHandler applies a JSON merge patch to a todo.
+//
+//	@Summary		Partially update a todo
+//	@Description	Sets the fields of a todo that are in the body, a JSON merge patch (RFC 7396) sent as `application/merge-patch+json`, and leaves the others as they are. A null due_at removes the due date, a null description empties it and a null priority resets it to none, title and completed cannot be null. Setting completed is idempotent: completing a completed todo keeps its completion time and the subtasks are left as they are. Changing the due date of a recurring todo restarts the series at the new due date. The fields are validated together, nothing is changed when one of them is invalid. With If-Match the todo is only patched while it is at one of the listed versions.
+//	@Description	A PATCH without a body and without a Content-Type toggles the completion of the todo like POST /todos/{id}/toggle. That behavior is deprecated and its responses carry a Deprecation header and a Link to the new endpoint. A body of any other content type is rejected with 415.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			id					path	string				true	"Todo ID"
+//	@Param			PatchTodoRequest	body	PatchTodoRequest	true	"The fields to change"
+//	@Param			If-Match			header	string				false	"ETags of the versions the todo may be at"
+//	@Success		204					"Todo updated successfully"
+//	@Failure		400					"Invalid request"
+//	@Failure		401					"Unauthorized"
+//	@Failure		403					"The todo can only be viewed"
+//	@Failure		404					"Todo not found"
+//	@Failure		412					"The todo has changed since it was read"
+//	@Failure		415					"The body is not a JSON merge patch"
+//	@Failure		500					"Internal server error"
+//	@Router			/todos/{id} [patch]
+func (h *PatchTodoHandler) Handle(ctx context.Context, req *PatchTodoRequest) (*PatchTodoResponse, int, error) {
+	versions, code, err := parseIfMatch(req.IfMatch)
+	if err != nil {
+		return nil, code, err
+	}
+
+	userId := domain.GetUserID(ctx)
+	for attempt := 1; ; attempt++ {
+		current, err := h.repo.GetById(ctx, req.Id, userId)
+		if err != nil {
+			if errors.Is(err, domain.ErrTodoNotFound) {
+				return nil, http.StatusNotFound, err
+			}
+			return nil, http.StatusInternalServerError, err
+		}
+		if versions != nil && !slices.Contains(versions, current.Version) {
+			return nil, http.StatusPreconditionFailed, domain.ErrTodoVersionMismatch
+		}
+
+		todo, err := req.apply(current, userId)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		err = h.repo.PatchTodo(ctx, todo)
+		// without If-Match the client does not mind a change made after the todo was read,
+		// the patch only has to be applied to the latest version
+		if errors.Is(err, domain.ErrTodoVersionMismatch) && versions == nil && attempt < maxPatchAttempts {
+			continue
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrTodoNotFound):
+				return nil, http.StatusNotFound, err
+			case errors.Is(err, domain.ErrTodoReadOnly):
+				return nil, http.StatusForbidden, err
+			case errors.Is(err, domain.ErrTodoVersionMismatch):
+				return nil, http.StatusPreconditionFailed, err
+			}
+			return nil, http.StatusInternalServerError, err
+		}
+
+		return nil, http.StatusNoContent, nil
+	}
+}
+
+// apply returns the current todo with the patch applied, at the version it was read at.
+// The fields are validated like the fields of a new todo.
+func (r *PatchTodoRequest) apply(current *GetTodoByIdResponse, userId uuid.UUID) (*domain.Todo, error) {
+	title, description, dueAt, priority, completed := current.Title, current.Description, current.DueAt, current.Priority, current.Completed
+	// a null member leaves the zero value, which removes the field
+	if r.Title.Set {
+		title = r.Title.Value
+	}
+	if r.Description.Set {
+		description = r.Description.Value
+	}
+	if r.DueAt.Set {
+		dueAt = r.DueAt.Value
+	}
+	if r.Priority.Set {
+		priority = r.Priority.Value
+	}
+	if r.Completed.Set {
+		if r.Completed.Null {
+			return nil, domain.ErrInvalidCompleted
+		}
+		completed = r.Completed.Value
+	}
+
+	todo, err := domain.NewTodo(userId, title, description, dueAt, priority)
+	if err != nil {
+		return nil, err
+	}
+	todo.Id = current.Id
+	todo.Completed = completed
+	todo.Version = current.Version
+
+	recurrence, err := current.recurrence()
+	if err != nil {
+		return nil, err
+	}
+	if recurrence != nil && !r.DueAt.Set {
+		todo.Recurrence = recurrence
+		todo.OccurrenceAt = current.OccurrenceAt
+	} else if err := todo.SetRecurrence(recurrence); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
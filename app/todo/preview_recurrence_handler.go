@@ -0,0 +1,77 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type PreviewRecurrenceRequest struct {
+	RRule    string `query:"rrule" validate:"required"`
+	Start    string `query:"start"`
+	Timezone string `query:"timezone"`
+	Count    int    `query:"count" validate:"omitempty,min=1,max=50"`
+}
+
+type PreviewRecurrenceResponse struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+type PreviewRecurrenceHandler struct {
+	clock domain.Clock
+}
+
+func NewPreviewRecurrenceHandler(clock domain.Clock) *PreviewRecurrenceHandler {
+	return &PreviewRecurrenceHandler{clock: clock}
+}
+
+// PreviewRecurrenceHandler lists the upcoming occurrences of a recurrence rule.
+//
+//	@Summary		Preview a recurrence rule
+//	@Description	Returns the next occurrences of an RFC 5545 RRULE, so that a rule can be checked before it is saved on a todo. The occurrences are given in the requested timezone.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			rrule		query		string	true	"Recurrence rule, e.g. FREQ=WEEKLY;BYDAY=MO,WE"
+//	@Param			start		query		string	false	"RFC 3339 start of the series, the current time by default"
+//	@Param			timezone	query		string	false	"IANA timezone the rule is expanded in, UTC by default"
+//	@Param			count		query		int		false	"Number of occurrences, 5 by default and at most 50"
+//	@Success		200			{object}	PreviewRecurrenceResponse
+//	@Failure		400			"Invalid request"
+//	@Failure		401			"Unauthorized"
+//	@Failure		500			"Internal server error"
+//	@Router			/todos/recurrence/preview [get]
+func (h *PreviewRecurrenceHandler) Handle(ctx context.Context, req *PreviewRecurrenceRequest) (*PreviewRecurrenceResponse, int, error) {
+	recurrence, err := domain.NewRecurrence(req.RRule, req.Timezone)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	count := req.Count
+	if count == 0 {
+		count = domain.DefaultPreviewCount
+	}
+	if count < 1 || count > domain.MaxPreviewCount {
+		return nil, http.StatusBadRequest, domain.ErrInvalidPreviewCount
+	}
+
+	start := h.clock.Now()
+	if req.Start != "" {
+		// an unescaped "+" of the offset arrives as a space
+		if start, err = time.Parse(time.RFC3339, strings.ReplaceAll(req.Start, " ", "+")); err != nil {
+			return nil, http.StatusBadRequest, domain.ErrInvalidRecurrenceStart
+		}
+	}
+	start = start.Truncate(time.Second)
+
+	occurrences := recurrence.Rule.Occurrences(start, recurrence.Timezone, start, count)
+	if occurrences == nil {
+		occurrences = []time.Time{}
+	}
+
+	return &PreviewRecurrenceResponse{Occurrences: occurrences}, http.StatusOK, nil
+}
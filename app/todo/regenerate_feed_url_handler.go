@@ -0,0 +1,46 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type RegenerateFeedURLRequest struct{}
+
+type RegenerateFeedURLHandler struct {
+	repo CalendarFeedRepository
+	ts   FeedTokenService
+}
+
+func NewRegenerateFeedURLHandler(repo CalendarFeedRepository, ts FeedTokenService) *RegenerateFeedURLHandler {
+	return &RegenerateFeedURLHandler{repo: repo, ts: ts}
+}
+
+// Handle creates a new calendar feed for the user.
+//
+//	@Summary		Regenerate the calendar feed link
+//	@Description	Turns on the iCalendar feed of the user's todos and returns its link. When the user already has a feed it is replaced, so the links handed out before stop working.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		201	{object}	FeedURLResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/feed [post]
+func (h *RegenerateFeedURLHandler) Handle(ctx context.Context, req *RegenerateFeedURLRequest) (*FeedURLResponse, int, error) {
+	userId := domain.GetUserID(ctx)
+
+	feedId := uuid.New()
+	if err := h.repo.SetCalendarFeedId(ctx, userId, feedId); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	resp, err := feedURL(h.ts, userId, feedId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return resp, http.StatusCreated, nil
+}
@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// ReorderTodoRequest holds the todos the moved todo ends up between, as the client saw
+// them: After is the todo right above it and Before the one right below it. One of them
+// is enough to move a todo to the top or the bottom of a list.
+type ReorderTodoRequest struct {
+	Id     uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	After  uuid.UUID `json:"after" validate:"omitempty,uuid"`
+	Before uuid.UUID `json:"before" validate:"omitempty,uuid"`
+}
+
+type ReorderTodoResponse struct {
+	Position string `json:"position"`
+}
+
+type ReorderTodoHandler struct {
+	repo TodoRepository
+}
+
+func NewReorderTodoHandler(repo TodoRepository) *ReorderTodoHandler {
+	return &ReorderTodoHandler{repo: repo}
+}
+
+// ReorderTodoHandler moves a todo within the manual order.
+//
+//	@Summary		Reorder a todo
+//	@Description	Moves a todo between two others of the manual order of its list, which is the default sort of the todo lists. A list is the inbox of the authenticated user or a project they can edit, the members of a project share its order. Only the moved todo gets a new position. When both after and before are set, they must still be next to each other, otherwise the list has changed in the meantime and the move is refused.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		string				true	"Todo ID"
+//	@Param			ReorderTodoRequest	body		ReorderTodoRequest	true	"The todos right above (after) and right below (before) the new place"
+//	@Success		200					{object}	ReorderTodoResponse
+//	@Failure		400					"Invalid request"
+//	@Failure		401					"Unauthorized"
+//	@Failure		403					"The todo can only be viewed"
+//	@Failure		404					"Todo or anchor todo not found, an anchor must be in the list of the todo"
+//	@Failure		409					"The anchors are no longer next to each other"
+//	@Failure		500					"Internal server error"
+//	@Router			/todos/{id}/move [post]
+func (h *ReorderTodoHandler) Handle(ctx context.Context, req *ReorderTodoRequest) (*ReorderTodoResponse, int, error) {
+	if req.After == uuid.Nil && req.Before == uuid.Nil {
+		return nil, http.StatusBadRequest, domain.ErrMissingMoveAnchor
+	}
+	if req.After == req.Id || req.Before == req.Id || req.After == req.Before {
+		return nil, http.StatusBadRequest, domain.ErrInvalidMoveAnchor
+	}
+
+	position, err := h.repo.ReorderTodo(ctx, req.Id, domain.GetUserID(ctx), req.After, req.Before)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound), errors.Is(err, domain.ErrAnchorTodoNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrTodoReadOnly):
+			return nil, http.StatusForbidden, err
+		case errors.Is(err, domain.ErrStaleMoveAnchors):
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &ReorderTodoResponse{Position: position}, http.StatusOK, nil
+}
@@ -2,16 +2,112 @@ package todo
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
+// Every by-id method is scoped to the user: a todo in the inbox is only accessible to
+// its creator, a todo in a project to the members of the project. Viewers can read the
+// todos of a project, editors and owners can change them as well. A todo the user
+// cannot access is reported as domain.ErrTodoNotFound so that its existence is not
+// leaked, the same goes for projects and domain.ErrProjectNotFound. A write to a todo
+// the user can only view is reported as domain.ErrTodoReadOnly, adding todos to such a
+// project as domain.ErrProjectReadOnly.
+//
+// The writes that take a version, and UpdateTodo with domain.Todo.Version, only change
+// the todo while it is at that version and report domain.ErrTodoVersionMismatch
+// otherwise. Version 0 changes the todo whatever its version. A todo gets a new version
+// whenever what GetById returns for it changes, which includes its tags and subtasks.
 type TodoRepository interface {
 	CreateTodo(ctx context.Context, todo *domain.Todo) error
-	UpdateTodo(ctx context.Context, id uuid.UUID, title string) error
-	GetById(ctx context.Context, id uuid.UUID) (*GetTodoByIdResponse, error)
-	Delete(ctx context.Context, id uuid.UUID) error
-	GetTodosByUserID(ctx context.Context, userID uuid.UUID) (*GetTodosResponse, error)
-	ToggleCompleted(ctx context.Context, id uuid.UUID) error
+	UpdateTodo(ctx context.Context, todo *domain.Todo) error
+	// PatchTodo updates the todo like UpdateTodo and completes or reopens it as well, in
+	// one transaction. Completing a completed todo again keeps its completion time and
+	// completing a recurring todo creates its next occurrence, once. Unlike
+	// ToggleCompleted it never touches the subtasks.
+	PatchTodo(ctx context.Context, todo *domain.Todo) error
+	GetById(ctx context.Context, id, userId uuid.UUID) (*GetTodoByIdResponse, error)
+	// GetTodoDepth returns how deep the todo is nested, 0 for a top-level todo.
+	GetTodoDepth(ctx context.Context, id, userId uuid.UUID) (int, error)
+	// Delete moves the todo and its subtasks to the trash. Trashed todos are left out of
+	// every other method until they are restored.
+	Delete(ctx context.Context, id, userId uuid.UUID, version int) error
+	// GetTrash returns the trashed todos of the user, most recently deleted first. Subtasks
+	// that were trashed together with their parent are left out, they come back with it.
+	GetTrash(ctx context.Context, userId uuid.UUID) ([]TrashedTodo, error)
+	// RestoreTodo brings back a trashed todo with the subtasks that were trashed together
+	// with it. A subtask cannot be restored while its parent is in the trash.
+	RestoreTodo(ctx context.Context, id, userId uuid.UUID) error
+	// DeleteTrashedTodo permanently deletes a trashed todo and its subtasks.
+	DeleteTrashedTodo(ctx context.Context, id, userId uuid.UUID) error
+	EmptyTrash(ctx context.Context, userId uuid.UUID) error
+	GetTodosByUserID(ctx context.Context, userID uuid.UUID, query GetTodosQuery) (*GetTodosResponse, error)
+	// SearchTodos returns the best matching todos first. The highlights are raw text with
+	// the matches between HighlightStart and HighlightStop.
+	SearchTodos(ctx context.Context, userId uuid.UUID, query SearchTodosQuery) (*SearchTodosResponse, error)
+	// ToggleCompleted completes the uncompleted subtasks as well when completeSubtasks
+	// is set and the todo gets completed. Reopening a todo never touches its subtasks.
+	ToggleCompleted(ctx context.Context, id, userId uuid.UUID, completeSubtasks bool, version int) error
+	GetByIdForAdmin(ctx context.Context, id uuid.UUID) (*GetTodoByIdForAdminResponse, error)
+	// AttachTag is idempotent. The tag must belong to the user.
+	AttachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error
+	DetachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error
+	// MoveTodo moves a todo and its subtasks into a project the user can edit, uuid.Nil
+	// moves them to the inbox of the creator of the todo.
+	// Todos cannot be moved into an archived project.
+	MoveTodo(ctx context.Context, id, userId, projectId uuid.UUID) error
+	// ReorderTodo moves a todo right after the after todo, or right before the before
+	// todo when after is uuid.Nil, in the manual order of its list and returns its new
+	// position. A list is a project, whose members share its order, or the inbox of a
+	// user, so the anchors must be in the list of the todo and the user must be able to
+	// edit it. When both are set they must still be next to each other among the todos
+	// that are not trashed, otherwise it returns domain.ErrStaleMoveAnchors.
+	ReorderTodo(ctx context.Context, id, userId, after, before uuid.UUID) (string, error)
+	// GetTodoHistory returns the revisions of the todo, newest first. Every write method
+	// records a revision for each todo whose title, description, completion, due date,
+	// priority or project it changes.
+	GetTodoHistory(ctx context.Context, id, userId uuid.UUID) (*GetTodoHistoryResponse, error)
+	// RevertTodo sets the fields changed by the revision back to their previous values,
+	// see domain.TodoRevision.Revert.
+	RevertTodo(ctx context.Context, id, revisionId, userId uuid.UUID) error
+	// ApplyBatch runs the operations in order in one transaction and returns the error of
+	// each operation, nil when it succeeded. When atomic is set it stops at the first
+	// failing operation and nothing is applied, the errors of the following operations
+	// stay nil. Otherwise the failing operations are skipped and the others are applied.
+	ApplyBatch(ctx context.Context, userId uuid.UUID, ops []TodoOperation, atomic bool) ([]error, error)
+	// ExportTodos calls fn with every todo the user created that is not in the trash, list
+	// by list in the manual order of each, the inbox first. The todos are read one at a
+	// time rather than all at once, it stops at the first error of fn and returns it.
+	ExportTodos(ctx context.Context, userId uuid.UUID, fn func(ExportedTodo) error) error
+	// ExportTodo returns a single todo the way ExportTodos does, domain.ErrTodoNotFound
+	// when the user did not create it or it is in the trash.
+	ExportTodo(ctx context.Context, id, userId uuid.UUID) (*ExportedTodo, error)
+	// ImportTodos inserts the todos in one transaction, after the other todos of the list
+	// each lands in, keeping their order. A todo goes to its project, which the user
+	// must be able to edit, and a subtask to the project of its parent. A parent that is
+	// not one of the todos must be a todo the user can edit. tags holds the names of the
+	// tags of each todo by its id, the tags the user does not have yet are created. A todo
+	// whose id is taken, even by a trashed todo, is reported as domain.ErrTodoAlreadyExists.
+	ImportTodos(ctx context.Context, userId uuid.UUID, todos []*domain.Todo, tags map[uuid.UUID][]string) error
+	// GetProjectNames returns every project the user is a member of, archived ones as
+	// well, in the order of the project list.
+	GetProjectNames(ctx context.Context, userId uuid.UUID) ([]ProjectName, error)
+	// GetCollaboratorIds returns the user and every user who shares a project with them.
+	GetCollaboratorIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error)
+}
+
+// ProjectName is a project as a todo.txt file names it.
+type ProjectName struct {
+	Id   uuid.UUID
+	Name string
+}
+
+// TrashPurgeRepository is used by the TrashPurger, unlike TodoRepository it is not
+// scoped to a user.
+type TrashPurgeRepository interface {
+	// PurgeTrash permanently deletes up to limit todos that were trashed before the given
+	// time, together with their subtasks, and returns how many it deleted.
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error)
 }
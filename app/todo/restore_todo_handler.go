@@ -0,0 +1,54 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type RestoreTodoRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type RestoreTodoResponse struct {
+}
+
+type RestoreTodoHandler struct {
+	repo TodoRepository
+}
+
+func NewRestoreTodoHandler(repo TodoRepository) *RestoreTodoHandler {
+	return &RestoreTodoHandler{repo: repo}
+}
+
+// RestoreTodoHandler brings a todo back from the trash.
+//
+//	@Summary		Restore a todo
+//	@Description	Restores a trashed todo of the authenticated user together with the subtasks that were deleted with it. A subtask cannot be restored while its parent is in the trash.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Todo ID"
+//	@Success		204	"Todo restored successfully"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"Todo not found in the trash"
+//	@Failure		409	"The parent todo is in the trash"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/{id}/restore [post]
+func (h *RestoreTodoHandler) Handle(ctx context.Context, req *RestoreTodoRequest) (*RestoreTodoResponse, int, error) {
+	if err := h.repo.RestoreTodo(ctx, req.Id, domain.GetUserID(ctx)); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrParentTodoInTrash):
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type RevertTodoRequest struct {
+	Id         uuid.UUID `params:"id" validate:"required,uuid"`
+	RevisionId uuid.UUID `params:"revision" validate:"required,uuid"`
+}
+
+type RevertTodoResponse struct {
+}
+
+type RevertTodoHandler struct {
+	repo TodoRepository
+}
+
+func NewRevertTodoHandler(repo TodoRepository) *RevertTodoHandler {
+	return &RevertTodoHandler{repo: repo}
+}
+
+// RevertTodoHandler undoes one change of a todo.
+//
+//	@Summary		Revert a change of a todo
+//	@Description	Sets the fields changed by a revision back to their previous values, the revert is recorded as a new revision. It is refused when one of the fields has changed again since, the later revisions have to be reverted first.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	string	true	"Todo ID"
+//	@Param			revision	path	string	true	"Revision ID"
+//	@Success		204			"Revision reverted successfully"
+//	@Failure		400			"The project of a subtask cannot be changed or the project is archived"
+//	@Failure		401			"Unauthorized"
+//	@Failure		403			"The todo or the project can only be viewed"
+//	@Failure		404			"Todo, revision or project not found"
+//	@Failure		409			"The todo has changed since the revision"
+//	@Failure		500			"Internal server error"
+//	@Router			/todos/{id}/revert/{revision} [post]
+func (h *RevertTodoHandler) Handle(ctx context.Context, req *RevertTodoRequest) (*RevertTodoResponse, int, error) {
+	if err := h.repo.RevertTodo(ctx, req.Id, req.RevisionId, domain.GetUserID(ctx)); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound), errors.Is(err, domain.ErrRevisionNotFound), errors.Is(err, domain.ErrProjectNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrRevisionConflict):
+			return nil, http.StatusConflict, err
+		case errors.Is(err, domain.ErrSubtaskWithProject), errors.Is(err, domain.ErrProjectArchived):
+			return nil, http.StatusBadRequest, err
+		case errors.Is(err, domain.ErrTodoReadOnly), errors.Is(err, domain.ErrProjectReadOnly):
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,38 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type RevokeFeedURLRequest struct{}
+
+type RevokeFeedURLResponse struct{}
+
+type RevokeFeedURLHandler struct {
+	repo CalendarFeedRepository
+}
+
+func NewRevokeFeedURLHandler(repo CalendarFeedRepository) *RevokeFeedURLHandler {
+	return &RevokeFeedURLHandler{repo: repo}
+}
+
+// Handle turns off the calendar feed of the user.
+//
+//	@Summary		Revoke the calendar feed
+//	@Description	Turns off the iCalendar feed of the user's todos, every link to it stops working. Revoking a feed that is already off succeeds.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Success		204	"No Content"
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/todos/feed [delete]
+func (h *RevokeFeedURLHandler) Handle(ctx context.Context, req *RevokeFeedURLRequest) (*RevokeFeedURLResponse, int, error) {
+	if err := h.repo.SetCalendarFeedId(ctx, domain.GetUserID(ctx), uuid.Nil); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,130 @@
+package todo
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type SearchTodosRequest struct {
+	Q     string `query:"q" validate:"required,max=100"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+
+	// HighlightStart and HighlightStop are private use characters, so they cannot be
+	// confused with the text of a todo before the highlights are turned into HTML.
+	HighlightStart = "\ue000"
+	HighlightStop  = "\ue001"
+)
+
+// SearchTodosQuery matches the todos that contain every term, each term also matches
+// the words it is a prefix of. Terms only contain letters and digits.
+type SearchTodosQuery struct {
+	Terms []string
+	Limit int
+}
+
+type SearchTodosResponse struct {
+	Results []SearchResult `json:"results"`
+}
+
+// SearchResult is a matching todo. TitleHighlight is the whole title and Snippet the
+// best matching fragments of the description, both are HTML escaped with the matches
+// wrapped in <mark> tags.
+type SearchResult struct {
+	Id             uuid.UUID       `json:"id"`
+	Title          string          `json:"title"`
+	Completed      bool            `json:"completed"`
+	DueAt          time.Time       `json:"due_at"`
+	Priority       domain.Priority `json:"priority"`
+	ProjectId      *uuid.UUID      `json:"project_id"`
+	Rank           float64         `json:"rank"`
+	TitleHighlight string          `json:"title_highlight"`
+	Snippet        string          `json:"snippet"`
+}
+
+type SearchTodosHandler struct {
+	repo TodoRepository
+}
+
+func NewSearchTodosHandler(repo TodoRepository) *SearchTodosHandler {
+	return &SearchTodosHandler{repo: repo}
+}
+
+// Handle searches the todos of the authenticated user.
+//
+//	@Summary		Search todos
+//	@Description	Full-text search over the title and the description of the todos of the authenticated user, shared ones included. Every word must match, as a whole word or as the beginning of one, and title matches rank higher. Words are stemmed according to the configured search language.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string	true	"Search text"
+//	@Param			limit	query		int		false	"Number of results, 20 by default and at most 50"
+//	@Success		200		{object}	SearchTodosResponse
+//	@Failure		400		"Invalid request"
+//	@Failure		401		"Unauthorized"
+//	@Failure		500		"Internal server error"
+//	@Router			/todos/search [get]
+func (h *SearchTodosHandler) Handle(ctx context.Context, req *SearchTodosRequest) (*SearchTodosResponse, int, error) {
+	if utf8.RuneCountInString(req.Q) > MaxSearchLength {
+		return nil, http.StatusBadRequest, domain.ErrSearchQueryTooLong
+	}
+
+	query := SearchTodosQuery{Terms: searchTerms(req.Q), Limit: req.Limit}
+	if len(query.Terms) == 0 {
+		return nil, http.StatusBadRequest, domain.ErrEmptySearchQuery
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	if query.Limit < 1 || query.Limit > MaxSearchLimit {
+		return nil, http.StatusBadRequest, domain.ErrInvalidSearchLimit
+	}
+
+	res, err := h.repo.SearchTodos(ctx, domain.GetUserID(ctx), query)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	for i := range res.Results {
+		result := &res.Results[i]
+		result.TitleHighlight = markHighlights(result.TitleHighlight)
+		result.Snippet = markHighlights(result.Snippet)
+	}
+
+	return res, http.StatusOK, nil
+}
+
+// searchTerms splits the search text into lowercase words, everything but letters and
+// digits separates them, so the terms never contain text search operators.
+func searchTerms(q string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func markHighlights(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, HighlightStart, "<mark>")
+	return strings.ReplaceAll(s, HighlightStop, "</mark>")
+}
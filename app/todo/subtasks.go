@@ -0,0 +1,44 @@
+package todo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type Subtask struct {
+	Id        uuid.UUID       `json:"id"`
+	ParentId  uuid.UUID       `json:"parent_id"`
+	Title     string          `json:"title"`
+	Completed bool            `json:"completed"`
+	CreatedAt time.Time       `json:"created_at"`
+	DueAt     time.Time       `json:"due_at"`
+	Priority  domain.Priority `json:"priority"`
+	Progress  domain.Progress `json:"progress"`
+	Subtasks  []Subtask       `json:"subtasks"`
+}
+
+// NewSubtaskTree nests the flat list of descendants of rootId, keeping their order.
+// The returned progress counts every descendant, the progress of each subtask
+// counts its own descendants.
+func NewSubtaskTree(rootId uuid.UUID, descendants []Subtask) ([]Subtask, domain.Progress) {
+	children := make(map[uuid.UUID][]Subtask)
+	for _, subtask := range descendants {
+		children[subtask.ParentId] = append(children[subtask.ParentId], subtask)
+	}
+
+	var build func(parentId uuid.UUID) ([]Subtask, domain.Progress)
+	build = func(parentId uuid.UUID) ([]Subtask, domain.Progress) {
+		subtasks := []Subtask{}
+		var progress domain.Progress
+		for _, subtask := range children[parentId] {
+			subtask.Subtasks, subtask.Progress = build(subtask.Id)
+			progress = progress.Add(subtask.Completed).Merge(subtask.Progress)
+			subtasks = append(subtasks, subtask)
+		}
+		return subtasks, progress
+	}
+
+	return build(rootId)
+}
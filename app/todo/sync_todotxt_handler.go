@@ -0,0 +1,339 @@
+package todo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type SyncTodoTxtRequest struct {
+	File    *multipart.FileHeader `form:"file" validate:"required" swaggerignore:"true"`
+	IfMatch string                `reqHeader:"If-Match" swaggerignore:"true"`
+}
+
+type SyncTodoTxtHandler struct {
+	repo   TodoRepository
+	clock  domain.Clock
+	logger domain.Logger
+}
+
+func NewSyncTodoTxtHandler(repo TodoRepository, clock domain.Clock, logger domain.Logger) *SyncTodoTxtHandler {
+	return &SyncTodoTxtHandler{repo: repo, clock: clock, logger: logger}
+}
+
+// Handle makes the todos of the user match a todo.txt file.
+//
+//	@Summary		Sync todos with a todo.txt file
+//	@Description	Takes the todo.txt file of a client, uploaded as `multipart/form-data`, as the state the todos of the user should be in. A line with an `id:` extension updates that todo: its title, priority, `due:` date, completion, project and tags. The `+project` of a line names a project of the user, a subtask stays in the project of its parent, and its `@contexts` are the tags, the missing ones are created. A line without an id creates a todo. A todo the user created that is left out of the file is only moved to the trash, together with its subtasks, when If-Match holds the ETag of the todo.txt file the client last got, from this endpoint or from `GET /todos/export?format=txt`. The ETag changes whenever a todo changes, so a file that is behind fails with 412 instead of deleting the todos added since. Without If-Match the todos left out of the file are kept and come back in the response. Descriptions and the creation and completion dates of existing todos are kept. The changes are applied in one transaction, nothing changes when a line is invalid. The response is the todo.txt file of the todos after the sync, with the id of every todo, which the client should keep in place of its own file together with its ETag.
+//	@Tags			Todo
+//	@Security		BearerAuth
+//	@Accept			multipart/form-data
+//	@Produce		text/plain
+//	@Param			file		formData	file	true	"todo.txt file"
+//	@Param			If-Match	header		string	false	"ETag of the todo.txt file the client last got, needed to delete todos"
+//	@Success		200			{string}	string	"The todo.txt file after the sync"
+//	@Header			200			{string}	ETag	"ETag of the todo.txt file after the sync"
+//	@Failure		400			"Invalid file, todo or If-Match"
+//	@Failure		401			"Unauthorized"
+//	@Failure		403			"A todo is read-only"
+//	@Failure		404			"A todo or a project of the file was not found"
+//	@Failure		412			"The todos have changed since the client got its file"
+//	@Failure		500			"Internal server error"
+//	@Router			/todos/todotxt [put]
+func (h *SyncTodoTxtHandler) Handle(ctx context.Context, req *SyncTodoTxtRequest) (*domain.File, int, error) {
+	if req.File == nil {
+		return nil, http.StatusBadRequest, domain.ErrMissingImportFile
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	defer file.Close()
+
+	tasks, lines, err := readTodoTxt(file)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	userId := domain.GetUserID(ctx)
+	projects := &todoTxtProjects{repo: h.repo, userId: userId}
+	for i, task := range tasks {
+		if task.Project == "" {
+			continue
+		}
+		tasks[i].ProjectId, err = projects.find(ctx, task.Project)
+		if errors.Is(err, domain.ErrProjectNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("line %d: %w", lines[i], err)
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	stored := make(map[uuid.UUID]ExportedTodo)
+	var order []uuid.UUID
+	err = h.repo.ExportTodos(ctx, userId, func(t ExportedTodo) error {
+		stored[t.Id] = t
+		order = append(order, t.Id)
+		return nil
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	deletes, err := todoTxtIfMatch(req.IfMatch, todoTxtETag(stored))
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoTxtChanged) {
+			return nil, http.StatusPreconditionFailed, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+
+	ops, opLines, code, err := diffTodoTxt(userId, tasks, lines, stored, order, deletes)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if len(ops) > 0 {
+		errs, err := h.repo.ApplyBatch(ctx, userId, ops, true)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+			code := batchErrorStatus(err)
+			if opLines[i] > 0 {
+				err = fmt.Errorf("line %d: %w", opLines[i], err)
+			}
+			return nil, code, err
+		}
+	}
+
+	etag, err := loadTodoTxtETag(ctx, h.repo, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	synced := streamTodos(ctx, h.repo, h.logger, userId, TodoFormatTodoTxt, h.clock.Now())
+	synced.ETag = etag
+	return synced, http.StatusOK, nil
+}
+
+// diffTodoTxt returns the operations that turn the stored todos into the tasks of the
+// file, in the manual order, with the line of each operation. Only with deletes are the
+// todos left out of the file deleted, at the version they are stored at unless the
+// batch changes one of their subtasks first. Such a
+// deletion has no line, and none is needed for a subtask whose parent is deleted as well.
+func diffTodoTxt(userId uuid.UUID, tasks []todoTxtTask, lines []int, stored map[uuid.UUID]ExportedTodo, order []uuid.UUID, deletes bool) ([]TodoOperation, []int, int, error) {
+	var ops []TodoOperation
+	var opLines []int
+	add := func(op TodoOperation, line int) {
+		ops = append(ops, op)
+		opLines = append(opLines, line)
+	}
+
+	// the tags in use, by the way the file writes them
+	tagNames := make(map[string]string)
+	for _, t := range stored {
+		for _, name := range t.Tags {
+			tagNames[strings.ToLower(todoTxtName(name))] = name
+		}
+	}
+
+	kept := make(map[uuid.UUID]bool, len(tasks))
+	for i, task := range tasks {
+		line := lines[i]
+		task.Tags = spellTags(task.Tags, tagNames)
+		if task.Id == uuid.Nil {
+			todo, err := task.importedTodo().NewTodo(userId)
+			if err != nil {
+				return nil, nil, http.StatusBadRequest, fmt.Errorf("line %d: %w", line, err)
+			}
+			todo.ProjectId = task.ProjectId
+			add(TodoOperation{Type: OpCreate, Todo: todo, Id: todo.Id, Tags: task.Tags}, line)
+			continue
+		}
+
+		if kept[task.Id] {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("line %d: %w", line, domain.ErrDuplicateTodoTxtId)
+		}
+		kept[task.Id] = true
+		current, ok := stored[task.Id]
+		if !ok {
+			return nil, nil, http.StatusNotFound, fmt.Errorf("line %d: %w", line, domain.ErrTodoNotFound)
+		}
+		if err := domain.ValidateTitle(task.Title); err != nil {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := domain.ValidateDueAt(task.DueAt); err != nil {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// the file holds the title on one line
+		title := strings.Join(strings.Fields(current.Title), " ")
+		if task.Title != title || task.Priority != current.Priority || !sameDueAt(current.DueAt, task.DueAt) ||
+			!sameTags(current.Tags, task.Tags) {
+			add(TodoOperation{Type: OpUpdate, Id: task.Id, Title: task.Title, DueAt: task.DueAt, Priority: task.Priority, Tags: task.Tags}, line)
+		}
+		// a subtask stays in the project of its parent
+		if current.ParentId == nil && task.ProjectId != derefId(current.ProjectId) {
+			add(TodoOperation{Type: OpMove, Id: task.Id, ProjectId: task.ProjectId}, line)
+		}
+		if task.Completed != current.Completed {
+			add(TodoOperation{Type: OpSetCompleted, Id: task.Id, Completed: task.Completed}, line)
+		}
+	}
+
+	if !deletes {
+		return ops, opLines, http.StatusOK, nil
+	}
+	// a change to a subtask changes the version of its ancestors as well
+	bumped := make(map[uuid.UUID]bool)
+	for _, op := range ops {
+		for parentId := stored[op.Id].ParentId; parentId != nil; parentId = stored[*parentId].ParentId {
+			if bumped[*parentId] {
+				break
+			}
+			bumped[*parentId] = true
+		}
+	}
+	for _, id := range order {
+		if kept[id] {
+			continue
+		}
+		// the subtasks go to the trash with their parent
+		if parentId := stored[id].ParentId; parentId != nil {
+			if _, ok := stored[*parentId]; ok && !kept[*parentId] {
+				continue
+			}
+		}
+		version := stored[id].Version
+		if bumped[id] {
+			version = 0
+		}
+		add(TodoOperation{Type: OpDelete, Id: id, Version: version}, 0)
+	}
+	return ops, opLines, http.StatusOK, nil
+}
+
+// sameDueAt tells whether the due date of a line is the stored one. A due:date is read
+// in UTC, it is the same as a stored all-day due date of that day in any zone.
+func sameDueAt(stored *time.Time, dueAt time.Time) bool {
+	if stored == nil {
+		return dueAt.IsZero()
+	}
+	if domain.IsAllDay(*stored) && domain.IsAllDay(dueAt) {
+		y1, m1, d1 := stored.Date()
+		y2, m2, d2 := dueAt.Date()
+		return y1 == y2 && m1 == m2 && d1 == d2
+	}
+	return stored.Equal(dueAt)
+}
+
+// todoTxtETag is the ETag of the todo.txt file of the todos, it changes whenever a todo
+// is added, changed or deleted.
+func todoTxtETag(todos map[uuid.UUID]ExportedTodo) string {
+	ids := make([]uuid.UUID, 0, len(todos))
+	for id := range todos {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	hash := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(hash, "%s:%d\n", id, todos[id].Version)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// loadTodoTxtETag reads the todos of the user for their ETag. The file it goes with is
+// read afterwards, when a todo changes in between the ETag is older than the file and
+// the next sync that deletes fails rather than deleting too much.
+func loadTodoTxtETag(ctx context.Context, repo TodoRepository, userId uuid.UUID) (string, error) {
+	todos := make(map[uuid.UUID]ExportedTodo)
+	err := repo.ExportTodos(ctx, userId, func(t ExportedTodo) error {
+		todos[t.Id] = t
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return todoTxtETag(todos), nil
+}
+
+// todoTxtIfMatch reports whether the If-Match header allows the sync to delete todos,
+// which it does when it lists etag or is "*". It returns domain.ErrTodoTxtChanged when
+// it lists other ETags, a weak ETag never matches.
+func todoTxtIfMatch(header, etag string) (bool, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false, nil
+	}
+	if header == "*" {
+		return true, nil
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		unquoted := strings.TrimPrefix(candidate, "W/")
+		if len(unquoted) < 2 || unquoted[0] != '"' || unquoted[len(unquoted)-1] != '"' {
+			return false, domain.ErrInvalidIfMatch
+		}
+		if candidate == etag {
+			return true, nil
+		}
+	}
+	return false, domain.ErrTodoTxtChanged
+}
+
+// spellTags spells the tags of a line the way the user named them, a tag with spaces
+// is written with underscores.
+func spellTags(tags []string, names map[string]string) []string {
+	var spelled []string
+	for _, tag := range tags {
+		if name, ok := names[strings.ToLower(tag)]; ok {
+			tag = name
+		}
+		spelled = append(spelled, tag)
+	}
+	return spelled
+}
+
+// sameTags compares the names regardless of case and order, both lists are free of
+// duplicates.
+func sameTags(stored, tags []string) bool {
+	if len(stored) != len(tags) {
+		return false
+	}
+	names := make(map[string]bool, len(stored))
+	for _, name := range stored {
+		names[strings.ToLower(name)] = true
+	}
+	for _, name := range tags {
+		if !names[strings.ToLower(name)] {
+			return false
+		}
+	}
+	return true
+}
+
+func derefId(id *uuid.UUID) uuid.UUID {
+	if id == nil {
+		return uuid.Nil
+	}
+	return *id
+}
@@ -0,0 +1,469 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// TodoFormat is a file format todos are exported to and imported from. A JSON file is
+// an array of todos, an NDJSON file has one todo per line and a CSV file has a header
+// naming the columns, with the tags of a todo separated by commas. A todo.txt file has
+// a todo on each line, see todotxt.go. An iCalendar file holds a VTODO for each todo, it
+// can only be exported.
+type TodoFormat string
+
+const (
+	TodoFormatJSON    TodoFormat = "json"
+	TodoFormatCSV     TodoFormat = "csv"
+	TodoFormatNDJSON  TodoFormat = "ndjson"
+	TodoFormatICS     TodoFormat = "ics"
+	TodoFormatTodoTxt TodoFormat = "txt"
+)
+
+// ParseTodoFormat accepts "jsonl" as another name of NDJSON and "todotxt" as another
+// name of todo.txt.
+func ParseTodoFormat(format string) (TodoFormat, error) {
+	switch f := TodoFormat(strings.ToLower(format)); f {
+	case TodoFormatJSON, TodoFormatCSV, TodoFormatNDJSON, TodoFormatICS, TodoFormatTodoTxt:
+		return f, nil
+	case "jsonl":
+		return TodoFormatNDJSON, nil
+	case "todotxt":
+		return TodoFormatTodoTxt, nil
+	}
+	return "", domain.ErrInvalidTodoFormat
+}
+
+func (f TodoFormat) ContentType() string {
+	switch f {
+	case TodoFormatCSV:
+		return "text/csv; charset=utf-8"
+	case TodoFormatNDJSON:
+		return "application/x-ndjson"
+	case TodoFormatICS:
+		return "text/calendar; charset=utf-8"
+	case TodoFormatTodoTxt:
+		return "text/plain; charset=utf-8"
+	}
+	return "application/json"
+}
+
+func (f TodoFormat) Importable() bool {
+	return f != TodoFormatICS
+}
+
+// ExportedTodo is a todo as it is written to an export. Times are in UTC.
+type ExportedTodo struct {
+	Id          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Completed   bool            `json:"completed"`
+	Priority    domain.Priority `json:"priority"`
+	DueAt       *time.Time      `json:"due_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	ProjectId   *uuid.UUID      `json:"project_id"`
+	ParentId    *uuid.UUID      `json:"parent_id"`
+	Tags        []string        `json:"tags"`
+	// Project is the name of the project, only a todo.txt file names it rather than
+	// giving its id.
+	Project string `json:"-"`
+	// Version is not exported, the CalDAV calendar sends it as the ETag of the todo.
+	Version int `json:"-"`
+}
+
+var exportCSVHeader = []string{"id", "title", "description", "completed", "priority", "due_at", "created_at", "completed_at", "project_id", "parent_id", "tags"}
+
+func (t ExportedTodo) csvRecord() []string {
+	return []string{
+		t.Id.String(), t.Title, t.Description, strconv.FormatBool(t.Completed), string(t.Priority),
+		formatExportedDueAt(t.DueAt), t.CreatedAt.UTC().Format(time.RFC3339), formatExportedTime(t.CompletedAt),
+		formatExportedId(t.ProjectId), formatExportedId(t.ParentId), strings.Join(t.Tags, ","),
+	}
+}
+
+func formatExportedTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatExportedDueAt keeps the offset of the zone of the due date, an import then
+// ends its day where the client set it.
+func formatExportedDueAt(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatExportedId(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// todoEncoder writes an export one todo at a time, Close completes the file. The
+// output is buffered, nothing may be written after Close.
+type todoEncoder interface {
+	Encode(todo ExportedTodo) error
+	Close() error
+}
+
+// now is when the export started, only iCalendar files record it.
+func newTodoEncoder(format TodoFormat, w io.Writer, now time.Time) todoEncoder {
+	switch format {
+	case TodoFormatICS:
+		return newICalTodoEncoder(w, now)
+	case TodoFormatTodoTxt:
+		return &todoTxtEncoder{buf: bufio.NewWriter(w)}
+	case TodoFormatCSV:
+		enc := &csvTodoEncoder{w: csv.NewWriter(w)}
+		// a failed write is kept by the writer and returned by Close
+		_ = enc.w.Write(exportCSVHeader)
+		return enc
+	case TodoFormatNDJSON:
+		buf := bufio.NewWriter(w)
+		return &ndjsonTodoEncoder{buf: buf, enc: json.NewEncoder(buf)}
+	}
+	return &jsonTodoEncoder{buf: bufio.NewWriter(w)}
+}
+
+type jsonTodoEncoder struct {
+	buf     *bufio.Writer
+	started bool
+}
+
+func (e *jsonTodoEncoder) Encode(todo ExportedTodo) error {
+	data, err := json.Marshal(todo)
+	if err != nil {
+		return err
+	}
+	separator := byte(',')
+	if !e.started {
+		separator, e.started = '[', true
+	}
+	if err := e.buf.WriteByte(separator); err != nil {
+		return err
+	}
+	_, err = e.buf.Write(data)
+	return err
+}
+
+func (e *jsonTodoEncoder) Close() error {
+	closing := "]\n"
+	if !e.started {
+		closing = "[]\n"
+	}
+	if _, err := e.buf.WriteString(closing); err != nil {
+		return err
+	}
+	return e.buf.Flush()
+}
+
+type ndjsonTodoEncoder struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonTodoEncoder) Encode(todo ExportedTodo) error {
+	return e.enc.Encode(todo)
+}
+
+func (e *ndjsonTodoEncoder) Close() error {
+	return e.buf.Flush()
+}
+
+type csvTodoEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTodoEncoder) Encode(todo ExportedTodo) error {
+	return e.w.Write(todo.csvRecord())
+}
+
+func (e *csvTodoEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ImportedTodo is a todo as it is read from an import. The other fields of an export
+// are ignored, so that an export can be imported as is. DueAt is an RFC 3339 timestamp
+// or a date, which stands for midnight UTC. In a CSV file completed is anything
+// strconv.ParseBool accepts, an empty cell is false, and the tags are separated by
+// commas. Only a todo.txt file carries the creation and completion dates, and it names
+// the project instead of giving its id.
+//
+// Id only names the todo within the file, for the parent_id of its subtasks, every
+// imported todo gets a new id. ParentId is either the id of another todo of the file or
+// of an existing todo of the user.
+type ImportedTodo struct {
+	Id          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Completed   bool     `json:"completed"`
+	Priority    string   `json:"priority"`
+	DueAt       string   `json:"due_at"`
+	ProjectId   string   `json:"project_id"`
+	ParentId    string   `json:"parent_id"`
+	Tags        []string `json:"tags"`
+	// project is the name of the +project of a todo.txt line, which the importer looks up
+	project     string
+	createdAt   time.Time
+	completedAt time.Time
+}
+
+// NewTodo validates the imported todo the same way a created one is, the title and
+// priority are trimmed since spreadsheet cells often carry extra spaces. The parent is
+// left to the caller, who knows the todos of the file.
+func (t ImportedTodo) NewTodo(userId uuid.UUID) (*domain.Todo, error) {
+	dueAt, err := parseImportedDueAt(strings.TrimSpace(t.DueAt))
+	if err != nil {
+		return nil, err
+	}
+	projectId, err := parseImportedId(t.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := domain.Priority(strings.ToLower(strings.TrimSpace(t.Priority)))
+	todo, err := domain.NewTodo(userId, strings.TrimSpace(t.Title), t.Description, dueAt, priority)
+	if err != nil {
+		return nil, err
+	}
+	todo.ProjectId = projectId
+	if !t.createdAt.IsZero() {
+		todo.CreatedAt = t.createdAt
+	}
+	if t.Completed {
+		todo.Completed, todo.CompletedAt = true, todo.CreatedAt
+		if !t.completedAt.IsZero() {
+			todo.CompletedAt = t.completedAt
+		}
+	}
+	return todo, nil
+}
+
+// tagNames returns the trimmed names of the tags, a name that differs from an earlier
+// one only in case is left out.
+func (t ImportedTodo) tagNames() ([]string, error) {
+	var names []string
+	seen := make(map[string]bool, len(t.Tags))
+	for _, name := range t.Tags {
+		name = strings.TrimSpace(name)
+		if err := domain.ValidateTagName(name); err != nil {
+			return nil, err
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// parseImportedId reads an id of the file, an empty one is uuid.Nil.
+func parseImportedId(value string) (uuid.UUID, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidImportedId
+	}
+	return id, nil
+}
+
+func parseImportedDueAt(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if dueAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return dueAt, nil
+	}
+	if dueAt, err := time.Parse(time.DateOnly, value); err == nil {
+		return dueAt, nil
+	}
+	return time.Time{}, domain.ErrInvalidDueAtFormat
+}
+
+// todoDecoder reads an import one todo at a time, Next returns io.EOF after the last
+// one. A *rowError only concerns the todo it was returned for and decoding can go on,
+// any other error means the rest of the file cannot be read.
+type todoDecoder interface {
+	Next() (ImportedTodo, error)
+}
+
+type rowError struct {
+	err error
+}
+
+func (e *rowError) Error() string { return e.err.Error() }
+
+func (e *rowError) Unwrap() error { return e.err }
+
+func newTodoDecoder(format TodoFormat, r io.Reader) (todoDecoder, error) {
+	switch format {
+	case TodoFormatCSV:
+		return newCSVTodoDecoder(r)
+	case TodoFormatTodoTxt:
+		return newTodoTxtDecoder(r), nil
+	case TodoFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxImportedLineLength)
+		return &ndjsonTodoDecoder{scanner: scanner}, nil
+	}
+	return newJSONTodoDecoder(r)
+}
+
+// maxImportedLineLength leaves room for a description of MaxDescriptionLength bytes,
+// even when every character of it is escaped.
+const maxImportedLineLength = 1 << 20
+
+type jsonTodoDecoder struct {
+	dec *json.Decoder
+}
+
+func newJSONTodoDecoder(r io.Reader) (*jsonTodoDecoder, error) {
+	dec := json.NewDecoder(r)
+	if token, err := dec.Token(); err != nil || token != json.Delim('[') {
+		return nil, domain.ErrInvalidJSONImport
+	}
+	return &jsonTodoDecoder{dec: dec}, nil
+}
+
+func (d *jsonTodoDecoder) Next() (ImportedTodo, error) {
+	var todo ImportedTodo
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return todo, err
+		}
+		return todo, io.EOF
+	}
+	if err := d.dec.Decode(&todo); err != nil {
+		// the decoder has read the whole value before failing to store it
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return todo, &rowError{err: fieldTypeError(typeErr)}
+		}
+		return todo, err
+	}
+	return todo, nil
+}
+
+type ndjsonTodoDecoder struct {
+	scanner *bufio.Scanner
+}
+
+// Blank lines are skipped, they are not counted as todos.
+func (d *ndjsonTodoDecoder) Next() (ImportedTodo, error) {
+	var todo ImportedTodo
+	for d.scanner.Scan() {
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &todo); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				err = fieldTypeError(typeErr)
+			}
+			return todo, &rowError{err: err}
+		}
+		return todo, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return todo, err
+	}
+	return todo, io.EOF
+}
+
+func fieldTypeError(err *json.UnmarshalTypeError) error {
+	switch err.Field {
+	case "":
+		return errors.New("a todo must be an object")
+	case "completed":
+		return domain.ErrInvalidCompleted
+	}
+	return fmt.Errorf("%s must be a %s", err.Field, err.Type)
+}
+
+type csvTodoDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+// The columns are found by their name in the header, regardless of case and order.
+func newCSVTodoDecoder(r io.Reader) (*csvTodoDecoder, error) {
+	reader := csv.NewReader(r)
+	// rows may leave out the trailing empty cells
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, domain.ErrEmptyImport
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// spreadsheet applications start UTF-8 files with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, domain.ErrMissingTitleColumn
+	}
+	return &csvTodoDecoder{r: reader, columns: columns}, nil
+}
+
+func (d *csvTodoDecoder) Next() (ImportedTodo, error) {
+	var todo ImportedTodo
+	record, err := d.r.Read()
+	if err != nil {
+		return todo, err
+	}
+
+	cell := func(column string) string {
+		if i, ok := d.columns[column]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	todo = ImportedTodo{
+		Id:          cell("id"),
+		Title:       cell("title"),
+		Description: cell("description"),
+		Priority:    cell("priority"),
+		DueAt:       cell("due_at"),
+		ProjectId:   cell("project_id"),
+		ParentId:    cell("parent_id"),
+	}
+	if tags := strings.TrimSpace(cell("tags")); tags != "" {
+		todo.Tags = strings.Split(tags, ",")
+	}
+	if completed := strings.TrimSpace(cell("completed")); completed != "" {
+		if todo.Completed, err = strconv.ParseBool(completed); err != nil {
+			return todo, &rowError{err: domain.ErrInvalidCompleted}
+		}
+	}
+	return todo, nil
+}
@@ -0,0 +1,341 @@
+package todo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// A todo.txt file (https://github.com/todotxt/todo.txt) has a todo on each line:
+//
+//	x 2030-05-02 2030-04-01 Call Mom +Family @phone due:2030-05-01 id:b1c8f0d2-…
+//	(A) 2030-04-01 Renew passport @errands due:2030-06-15 id:0f6b2c1e-…
+//
+// A completed todo starts with "x" and its completion date, an open one with its
+// priority. Some clients keep the priority of a completed todo after the "x", others
+// move it to a pri extension, both are read. The creation date comes next. The +project
+// of a line is the project of the todo and its @contexts are its tags, a name that has
+// spaces is written with underscores. Of the key:value extensions due, id and pri are
+// read, the others are dropped along with the +project and @context tokens, so that
+// the title is the same every time the file goes back and forth.
+
+// todoTxtPriorities maps the priorities to the letters of todo.txt, PriorityNone has no
+// letter. When a file is read, the letters after D are low priorities too.
+var todoTxtPriorities = map[domain.Priority]byte{
+	domain.PriorityUrgent: 'A',
+	domain.PriorityHigh:   'B',
+	domain.PriorityMedium: 'C',
+	domain.PriorityLow:    'D',
+}
+
+// todoTxtTask is a line of a todo.txt file. Id is uuid.Nil for a todo that was added
+// to the file by hand, the dates are zero when they are left out. Project is the name
+// of the +project token, without the plus, ProjectId is left to the caller to find.
+type todoTxtTask struct {
+	Id          uuid.UUID
+	Title       string
+	Completed   bool
+	Priority    domain.Priority
+	DueAt       time.Time
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	Project     string
+	ProjectId   uuid.UUID
+	Tags        []string
+}
+
+// parseTodoTxtLine reads a line that is not blank.
+func parseTodoTxtLine(line string) (todoTxtTask, error) {
+	task := todoTxtTask{Priority: domain.PriorityNone}
+	fields := strings.Fields(line)
+
+	if len(fields) > 0 && fields[0] == "x" {
+		task.Completed = true
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		if priority, ok := parseTodoTxtPriority(fields[0]); ok {
+			task.Priority, fields = priority, fields[1:]
+		}
+	}
+	if date, ok := parseTodoTxtDate(fields); ok {
+		fields = fields[1:]
+		task.CreatedAt = date
+		// a completed todo has its completion date first
+		if task.Completed {
+			task.CompletedAt, task.CreatedAt = date, time.Time{}
+			if date, ok := parseTodoTxtDate(fields); ok {
+				task.CreatedAt, fields = date, fields[1:]
+			}
+		}
+	}
+
+	title := make([]string, 0, len(fields))
+	seenTags := make(map[string]bool)
+	for _, field := range fields {
+		if name, ok := strings.CutPrefix(field, "+"); ok && name != "" {
+			if task.Project != "" && !strings.EqualFold(task.Project, name) {
+				return task, domain.ErrTodoTxtProjects
+			}
+			task.Project = name
+			continue
+		}
+		if name, ok := strings.CutPrefix(field, "@"); ok && name != "" {
+			if err := domain.ValidateTagName(name); err != nil {
+				return task, err
+			}
+			if key := strings.ToLower(name); !seenTags[key] {
+				seenTags[key] = true
+				task.Tags = append(task.Tags, name)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || !isTodoTxtExtension(key, value) {
+			title = append(title, field)
+			continue
+		}
+		switch key {
+		case "due":
+			dueAt, err := parseImportedDueAt(value)
+			if err != nil {
+				return task, err
+			}
+			task.DueAt = dueAt
+		case "pri":
+			if priority, ok := parseTodoTxtPriority("(" + value + ")"); ok {
+				task.Priority = priority
+			}
+		case "id":
+			// an id that is not a UUID is one of the user's own, some todo.txt add-ons
+			// number their todos
+			if id, err := uuid.Parse(value); err == nil {
+				task.Id = id
+			}
+		default:
+			// the value of an extension cannot have a colon, such a word is part of the title
+			if strings.Contains(value, ":") {
+				title = append(title, field)
+			}
+		}
+	}
+	task.Title = strings.Join(title, " ")
+	return task, nil
+}
+
+// isTodoTxtExtension tells a key:value extension from words that merely have a colon,
+// such as a time of the day or a URL.
+func isTodoTxtExtension(key, value string) bool {
+	if key == "" || value == "" || strings.HasPrefix(value, "/") {
+		return false
+	}
+	for i, r := range key {
+		letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !letter && (i == 0 || (r < '0' || r > '9') && r != '-' && r != '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// todoTxtName writes a project or tag name as a single token of the file.
+func todoTxtName(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
+
+func parseTodoTxtDate(fields []string) (time.Time, bool) {
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(time.DateOnly, fields[0])
+	return date, err == nil
+}
+
+func parseTodoTxtPriority(field string) (domain.Priority, bool) {
+	if len(field) != 3 || field[0] != '(' || field[2] != ')' || field[1] < 'A' || field[1] > 'Z' {
+		return "", false
+	}
+	for priority, letter := range todoTxtPriorities {
+		if letter == field[1] {
+			return priority, true
+		}
+	}
+	return domain.PriorityLow, true
+}
+
+// todoTxtProjects finds the project a +project token names, regardless of case. The
+// projects of the user are loaded on first use, a name several projects share stands
+// for the first of them in the project list.
+type todoTxtProjects struct {
+	repo   TodoRepository
+	userId uuid.UUID
+	ids    map[string]uuid.UUID
+}
+
+// find returns domain.ErrProjectNotFound when the user has no project of that name.
+func (p *todoTxtProjects) find(ctx context.Context, name string) (uuid.UUID, error) {
+	if p.ids == nil {
+		projects, err := p.repo.GetProjectNames(ctx, p.userId)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		p.ids = make(map[string]uuid.UUID, len(projects))
+		for _, project := range projects {
+			key := strings.ToLower(todoTxtName(project.Name))
+			if _, ok := p.ids[key]; !ok {
+				p.ids[key] = project.Id
+			}
+		}
+	}
+	id, ok := p.ids[strings.ToLower(name)]
+	if !ok {
+		return uuid.Nil, domain.ErrProjectNotFound
+	}
+	return id, nil
+}
+
+// todoTxtLine writes the todo as a line of a todo.txt file, without its line break.
+func (t ExportedTodo) todoTxtLine() string {
+	var sb strings.Builder
+	letter, hasPriority := todoTxtPriorities[t.Priority]
+	if t.Completed {
+		completedAt := t.CreatedAt
+		if t.CompletedAt != nil {
+			completedAt = *t.CompletedAt
+		}
+		sb.WriteString("x " + completedAt.UTC().Format(time.DateOnly) + " ")
+	} else if hasPriority {
+		sb.WriteString("(" + string(letter) + ") ")
+	}
+	sb.WriteString(t.CreatedAt.UTC().Format(time.DateOnly) + " ")
+
+	// a line break would start another todo
+	sb.WriteString(strings.Join(strings.Fields(t.Title), " "))
+
+	if t.Project != "" {
+		sb.WriteString(" +" + todoTxtName(t.Project))
+	}
+	for _, tag := range t.Tags {
+		sb.WriteString(" @" + todoTxtName(tag))
+	}
+	if t.DueAt != nil {
+		sb.WriteString(" due:" + formatTodoTxtDueAt(*t.DueAt))
+	}
+	if t.Completed && hasPriority {
+		sb.WriteString(" pri:" + string(letter))
+	}
+	sb.WriteString(" id:" + t.Id.String())
+	return sb.String()
+}
+
+// formatTodoTxtDueAt writes a date, as todo.txt clients expect, unless the todo is due
+// at a time of the day, which keeps the offset of its zone.
+func formatTodoTxtDueAt(dueAt time.Time) string {
+	if domain.IsAllDay(dueAt) {
+		return dueAt.Format(time.DateOnly)
+	}
+	return dueAt.Format(time.RFC3339)
+}
+
+type todoTxtEncoder struct {
+	buf *bufio.Writer
+}
+
+func (e *todoTxtEncoder) Encode(todo ExportedTodo) error {
+	_, err := e.buf.WriteString(todo.todoTxtLine() + "\n")
+	return err
+}
+
+func (e *todoTxtEncoder) Close() error {
+	return e.buf.Flush()
+}
+
+// todoTxtDecoder reads the tasks of a file, line is the line of the last one.
+type todoTxtDecoder struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newTodoTxtDecoder(r io.Reader) *todoTxtDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportedLineLength)
+	return &todoTxtDecoder{scanner: scanner}
+}
+
+// next skips the blank lines, a task that cannot be read is returned with a *rowError.
+func (d *todoTxtDecoder) next() (todoTxtTask, error) {
+	for d.scanner.Scan() {
+		d.line++
+		text := d.scanner.Text()
+		if d.line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		task, err := parseTodoTxtLine(text)
+		if err != nil {
+			return task, &rowError{err: err}
+		}
+		return task, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return todoTxtTask{}, err
+	}
+	return todoTxtTask{}, io.EOF
+}
+
+// Next reads the task as an imported todo, an import creates new todos so the id of
+// the task is ignored.
+func (d *todoTxtDecoder) Next() (ImportedTodo, error) {
+	task, err := d.next()
+	if err != nil {
+		return ImportedTodo{}, err
+	}
+	return task.importedTodo(), nil
+}
+
+func (t todoTxtTask) importedTodo() ImportedTodo {
+	imported := ImportedTodo{
+		Title:       t.Title,
+		Completed:   t.Completed,
+		Priority:    string(t.Priority),
+		Tags:        t.Tags,
+		project:     t.Project,
+		createdAt:   t.CreatedAt,
+		completedAt: t.CompletedAt,
+	}
+	if !t.DueAt.IsZero() {
+		imported.DueAt = t.DueAt.Format(time.RFC3339)
+	}
+	return imported
+}
+
+// readTodoTxt reads every task of the file together with its line, the error of a task
+// that cannot be read names its line.
+func readTodoTxt(r io.Reader) ([]todoTxtTask, []int, error) {
+	dec := newTodoTxtDecoder(r)
+	var tasks []todoTxtTask
+	var lines []int
+	for {
+		task, err := dec.next()
+		if err == io.EOF {
+			return tasks, lines, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", dec.line, err)
+		}
+		if len(tasks) == MaxImportedTodos {
+			return nil, nil, domain.ErrTooManyImportedTodos
+		}
+		tasks = append(tasks, task)
+		lines = append(lines, dec.line)
+	}
+}
@@ -2,6 +2,7 @@ package todo
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -9,7 +10,9 @@ import (
 )
 
 type ToggleCompletedTodoRequest struct {
-	Id uuid.UUID `params:"id"`
+	Id               uuid.UUID `params:"id"`
+	CompleteSubtasks bool      `query:"complete_subtasks"`
+	IfMatch          string    `reqHeader:"If-Match" swaggerignore:"true"`
 }
 
 type ToggleCompletedTodoResponse struct{}
@@ -25,24 +28,39 @@ func NewToggleCompletedTodoHandler(repo TodoRepository) *ToggleCompletedTodoHand
 // ToggleCompletedTodoHandler handles the toggling of a todo item's completion status.
 //
 //	@Summary		Toggle todo completion status
-//	@Description	Toggles the completion status of a todo item for the authenticated user.
+//	@Description	Toggles the completion status of a todo item for the authenticated user. With complete_subtasks, completing a todo completes all of its subtasks too. Completing a recurring todo creates its next occurrence, once. With If-Match the todo is only toggled while it is at one of the listed versions. A retried toggle flips the todo back, PATCH /todos/{id} sets the completion idempotently.
 //	@Tags			Todo
 //	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path	string	true	"Todo ID"
+//	@Param			id					path	string	true	"Todo ID"
+//	@Param			complete_subtasks	query	bool	false	"Complete the subtasks as well when the todo gets completed"
+//	@Param			If-Match			header	string	false	"ETags of the versions the todo may be at"
 //
 //	@Success		204	"Todo completion status toggled"
 //
 //	@Failure		400	"Invalid request"
 //	@Failure		401	"Unauthorized"
+//	@Failure		403	"The todo can only be viewed"
 //	@Failure		404	"Todo not found"
+//	@Failure		412	"The todo has changed since it was read"
 //	@Failure		500	"Internal server error"
-//	@Router			/todos/{id} [patch]
+//	@Router			/todos/{id}/toggle [post]
 func (h *ToggleCompletedTodoHandler) Handle(ctx context.Context, req *ToggleCompletedTodoRequest) (*ToggleCompletedTodoResponse, int, error) {
-	if err := h.repo.ToggleCompleted(ctx, req.Id); err != nil {
-		if err == domain.ErrTodoNotFound {
+	userId := domain.GetUserID(ctx)
+	version, code, err := ifMatchVersion(ctx, h.repo, req.Id, userId, req.IfMatch)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if err := h.repo.ToggleCompleted(ctx, req.Id, userId, req.CompleteSubtasks, version); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound):
 			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrTodoReadOnly):
+			return nil, http.StatusForbidden, err
+		case errors.Is(err, domain.ErrTodoVersionMismatch):
+			return nil, http.StatusPreconditionFailed, err
 		}
 		return nil, http.StatusInternalServerError, err
 	}
@@ -0,0 +1,76 @@
+package todo
+
+import (
+	"context"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Todos are purged in batches and a single run is bounded like the one of the reminder
+// scheduler, whatever is left over is purged on the next tick.
+const (
+	purgeBatchSize  = 500
+	maxPurgedPerRun = 10 * purgeBatchSize
+)
+
+// TrashPurger permanently deletes the todos that have been in the trash for longer than
+// the retention period. Every API instance runs one, purging the same todos twice is
+// harmless so they don't need to coordinate.
+type TrashPurger struct {
+	repo      TrashPurgeRepository
+	clock     domain.Clock
+	logger    domain.Logger
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(repo TrashPurgeRepository, clock domain.Clock, logger domain.Logger, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		repo:      repo,
+		clock:     clock,
+		logger:    logger,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Start runs the purger until ctx is cancelled.
+func (p *TrashPurger) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges the todos trashed before the current time of the clock minus the
+// retention and returns how many were deleted.
+func (p *TrashPurger) RunOnce(ctx context.Context) int {
+	before := p.clock.Now().Add(-p.retention)
+
+	purged := 0
+	for purged < maxPurgedPerRun {
+		n, err := p.repo.PurgeTrash(ctx, before, purgeBatchSize)
+		if err != nil {
+			p.logger.Error("failed to purge the trash", "error", err)
+			break
+		}
+		purged += n
+		if n < purgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		p.logger.Info("purged trashed todos", "count", purged)
+	}
+	return purged
+}
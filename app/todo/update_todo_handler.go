@@ -4,14 +4,25 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
+// UpdateTodoRequest changes the fields of a todo that are in the body, a field that is
+// left out or null keeps its stored value.
 type UpdateTodoRequest struct {
-	Id    uuid.UUID `params:"id" validate:"required,uuid" swaggerignore:"true"`
-	Title string    `json:"title" validate:"required"`
+	Id          uuid.UUID  `params:"id" validate:"required,uuid" swaggerignore:"true"`
+	Title       string     `json:"title" validate:"required"`
+	Description *string    `json:"description,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    *string    `json:"priority,omitempty" validate:"omitempty,oneof=none low medium high urgent"`
+	// Recurrence keeps the current rule when it is missing, an empty rule stops the series.
+	Recurrence *string `json:"recurrence,omitempty"`
+	Timezone   string  `json:"timezone"`
+	Scope      string  `json:"scope" validate:"omitempty,oneof=this all_future"`
+	IfMatch    string  `reqHeader:"If-Match" swaggerignore:"true"`
 }
 
 type UpdateTodoResponse struct {
@@ -28,29 +39,103 @@ func NewUpdateTodoHandler(repo TodoRepository) *UpdateTodoHandler {
 // UpdateTodoHandler handles the update of an existing todo item.
 //
 //	@Summary		Update an existing todo
-//	@Description	Updates an existing todo item for the authenticated user.
+//	@Description	Updates the title, the Markdown description, the due date, the priority and the recurrence of an existing todo item for the authenticated user. The title is required, the other fields keep their stored value when they are left out or null, so a body with only a title renames the todo. An empty description empties it and the priority none resets it. PATCH /todos/{id} with a null due_at removes the due date.
+//	@Description	For a recurring todo, scope=this only changes the current occurrence and the series keeps its schedule. scope=all_future (the default) restarts the series at the new due date, with the new rule if one is given. A body that changes neither the due date nor the recurrence leaves the series as it is.
+//	@Description	With If-Match the todo is only updated while it is still at the version of that ETag, as returned by GET /todos/{id}, so that an update never overwrites a change made on another device.
 //	@Tags			Todo
 //	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
 //	@Param			id					path	string				true	"Todo ID"
 //	@Param			UpdateTodoRequest	body	UpdateTodoRequest	true	"Todo details"
+//	@Param			If-Match			header	string				false	"ETags of the versions the todo may be at"
 //	@Success		204					"Todo updated successfully"
 //	@Failure		400					"Invalid request"
 //	@Failure		401					"Unauthorized"
+//	@Failure		403					"The todo can only be viewed"
 //	@Failure		404					"Todo not found"
+//	@Failure		412					"The todo has changed since it was read"
 //	@Failure		500					"Internal server error"
 //	@Router			/todos/{id} [put]
 func (h *UpdateTodoHandler) Handle(ctx context.Context, req *UpdateTodoRequest) (*UpdateTodoResponse, int, error) {
+	scope := domain.RecurrenceScope(req.Scope)
+	if scope == "" {
+		scope = domain.RecurrenceScopeAllFuture
+	}
+	if err := domain.ValidateRecurrenceScope(scope); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if scope == domain.RecurrenceScopeThis && req.Recurrence != nil {
+		return nil, http.StatusBadRequest, domain.ErrRecurrenceScope
+	}
+
 	userId := domain.GetUserID(ctx)
-	_, err := domain.NewTodo(userId, req.Title)
+	current, err := h.repo.GetById(ctx, req.Id, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	description, dueAt, priority := current.Description, current.DueAt, current.Priority
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.DueAt != nil {
+		dueAt = *req.DueAt
+	}
+	if req.Priority != nil {
+		priority = domain.Priority(*req.Priority)
+	}
+	todo, err := domain.NewTodo(userId, req.Title, description, dueAt, priority)
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
+	todo.Id = req.Id
 
-	if err = h.repo.UpdateTodo(ctx, req.Id, req.Title); err != nil {
-		if errors.Is(err, domain.ErrTodoNotFound) {
+	var code int
+	if todo.Version, code, err = ifMatchVersion(ctx, h.repo, req.Id, userId, req.IfMatch); err != nil {
+		return nil, code, err
+	}
+
+	var recurrence *domain.Recurrence
+	if req.Recurrence != nil && *req.Recurrence != "" {
+		if recurrence, err = domain.NewRecurrence(*req.Recurrence, req.Timezone); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	if req.Recurrence == nil {
+		if recurrence, err = current.recurrence(); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		// the occurrence keeps its place in the series unless it is moved for all future
+		// occurrences
+		if recurrence != nil && (scope == domain.RecurrenceScopeThis || req.DueAt == nil) {
+			if todo.DueAt.IsZero() {
+				return nil, http.StatusBadRequest, domain.ErrRecurrenceWithoutDueAt
+			}
+			todo.Recurrence = recurrence
+			todo.OccurrenceAt = current.OccurrenceAt
+			recurrence = nil
+		}
+	}
+
+	if recurrence != nil {
+		if err := todo.SetRecurrence(recurrence); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	if err = h.repo.UpdateTodo(ctx, todo); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTodoNotFound):
 			return nil, http.StatusNotFound, err
+		case errors.Is(err, domain.ErrTodoReadOnly):
+			return nil, http.StatusForbidden, err
+		case errors.Is(err, domain.ErrTodoVersionMismatch):
+			return nil, http.StatusPreconditionFailed, err
 		}
 		return nil, http.StatusInternalServerError, err
 	}
@@ -0,0 +1,53 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// parseIfMatch returns the versions the If-Match header of a write lists, together
+// with the status its error is reported with.
+func parseIfMatch(header string) ([]int, int, error) {
+	versions, err := domain.ParseIfMatch(header)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoVersionMismatch) {
+			return nil, http.StatusPreconditionFailed, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+	return versions, http.StatusOK, nil
+}
+
+// ifMatchVersion returns the version a write to the todo expects for its If-Match
+// header, 0 for any version. The repository checks a single version, so when the header
+// lists several the todo is read and the write expects the listed version it is at. A
+// change made in between fails the write like any other change.
+func ifMatchVersion(ctx context.Context, repo TodoRepository, id, userId uuid.UUID, header string) (int, int, error) {
+	versions, code, err := parseIfMatch(header)
+	if err != nil {
+		return 0, code, err
+	}
+	switch len(versions) {
+	case 0:
+		return 0, http.StatusOK, nil
+	case 1:
+		return versions[0], http.StatusOK, nil
+	}
+
+	current, err := repo.GetById(ctx, id, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return 0, http.StatusNotFound, err
+		}
+		return 0, http.StatusInternalServerError, err
+	}
+	if !slices.Contains(versions, current.Version) {
+		return 0, http.StatusPreconditionFailed, domain.ErrTodoVersionMismatch
+	}
+	return current.Version, http.StatusOK, nil
+}
@@ -0,0 +1,27 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// AppPasswordRepository keeps the app passwords of the users, CalDAV clients sign in
+// with them.
+type AppPasswordRepository interface {
+	CreateAppPassword(ctx context.Context, appPassword *domain.AppPassword) error
+	// GetAppPasswords returns the app passwords of the user, newest first.
+	GetAppPasswords(ctx context.Context, userId uuid.UUID) (GetAppPasswordsResponse, error)
+	DeleteAppPassword(ctx context.Context, id, userId uuid.UUID) error
+}
+
+// AppPassword never carries the password, it is only shown when it is created.
+// LastUsedAt is zero until a client signs in with it.
+type AppPassword struct {
+	Id         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
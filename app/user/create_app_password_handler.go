@@ -0,0 +1,64 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type CreateAppPasswordRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type CreateAppPasswordResponse struct {
+	AppPassword
+	Password string `json:"password"`
+}
+
+type CreateAppPasswordHandler struct {
+	repo AppPasswordRepository
+}
+
+func NewCreateAppPasswordHandler(repo AppPasswordRepository) *CreateAppPasswordHandler {
+	return &CreateAppPasswordHandler{repo: repo}
+}
+
+// CreateAppPasswordHandler generates an app password for a client that cannot sign in
+// with a bearer token.
+//
+//	@Summary		Create an app password
+//	@Description	Generates a password for a CalDAV client such as Apple Reminders or Thunderbird, which sign in with HTTP Basic authentication: the email of the account and the app password. The name tells the clients apart. The password is only returned now, it cannot be shown again.
+//	@Tags			User
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			CreateAppPasswordRequest	body		CreateAppPasswordRequest	true	"App password details"
+//	@Success		201							{object}	CreateAppPasswordResponse
+//	@Failure		400							"Invalid request"
+//	@Failure		401							"Unauthorized"
+//	@Failure		500							"Internal server error"
+//	@Router			/users/app-passwords [post]
+func (h *CreateAppPasswordHandler) Handle(ctx context.Context, req *CreateAppPasswordRequest) (*CreateAppPasswordResponse, int, error) {
+	appPassword, password, err := domain.NewAppPassword(domain.GetUserID(ctx), req.Name)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := h.repo.CreateAppPassword(ctx, appPassword); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &CreateAppPasswordResponse{
+		AppPassword: AppPassword{
+			Id:        appPassword.Id,
+			Name:      appPassword.Name,
+			CreatedAt: appPassword.CreatedAt,
+		},
+		Password: password,
+	}, http.StatusCreated, nil
+}
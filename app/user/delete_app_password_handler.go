@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type DeleteAppPasswordRequest struct {
+	Id uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type DeleteAppPasswordResponse struct{}
+
+type DeleteAppPasswordHandler struct {
+	repo AppPasswordRepository
+}
+
+func NewDeleteAppPasswordHandler(repo AppPasswordRepository) *DeleteAppPasswordHandler {
+	return &DeleteAppPasswordHandler{repo: repo}
+}
+
+// DeleteAppPasswordHandler revokes an app password.
+//
+//	@Summary		Delete an app password
+//	@Description	Revokes an app password of the authenticated user, the clients that use it are signed out with their next request.
+//	@Tags			User
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"App password ID"
+//	@Success		204	"App password deleted successfully"
+//	@Failure		400	"Invalid request"
+//	@Failure		401	"Unauthorized"
+//	@Failure		404	"App password not found"
+//	@Failure		500	"Internal server error"
+//	@Router			/users/app-passwords/{id} [delete]
+func (h *DeleteAppPasswordHandler) Handle(ctx context.Context, req *DeleteAppPasswordRequest) (*DeleteAppPasswordResponse, int, error) {
+	if err := h.repo.DeleteAppPassword(ctx, req.Id, domain.GetUserID(ctx)); err != nil {
+		if errors.Is(err, domain.ErrAppPasswordNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,40 @@
+package user
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+type GetAppPasswordsRequest struct{}
+
+type GetAppPasswordsResponse []AppPassword
+
+type GetAppPasswordsHandler struct {
+	repo AppPasswordRepository
+}
+
+func NewGetAppPasswordsHandler(repo AppPasswordRepository) *GetAppPasswordsHandler {
+	return &GetAppPasswordsHandler{repo: repo}
+}
+
+// GetAppPasswordsHandler lists the app passwords of the user.
+//
+//	@Summary		Get app passwords
+//	@Description	Lists the app passwords of the authenticated user, newest first, with when each was last used. The passwords themselves are not returned.
+//	@Tags			User
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	GetAppPasswordsResponse
+//	@Failure		401	"Unauthorized"
+//	@Failure		500	"Internal server error"
+//	@Router			/users/app-passwords [get]
+func (h *GetAppPasswordsHandler) Handle(ctx context.Context, req *GetAppPasswordsRequest) (*GetAppPasswordsResponse, int, error) {
+	appPasswords, err := h.repo.GetAppPasswords(ctx, domain.GetUserID(ctx))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &appPasswords, http.StatusOK, nil
+}
@@ -5,18 +5,172 @@ CREATE TABLE users (
   password VARCHAR(200) NOT NULL,
   email VARCHAR(200) NOT NULL UNIQUE,
   is_email_verified BOOLEAN DEFAULT FALSE,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  calendar_feed_id UUID DEFAULT NULL
+);
+
+CREATE TABLE projects (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  color VARCHAR(7) NOT NULL,
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  position INTEGER NOT NULL DEFAULT 0 CHECK (position >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_projects_user_id_position ON projects (user_id, position);
+
+CREATE TABLE project_members (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members (user_id);
+
+CREATE TABLE project_invitations (
+  id UUID PRIMARY KEY,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  email VARCHAR(200) NOT NULL,
+  role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+  invited_by UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
+CREATE UNIQUE INDEX idx_project_invitations_project_id_email ON project_invitations (project_id, email);
+CREATE INDEX idx_project_invitations_email ON project_invitations (email);
+
 CREATE TABLE todos (
   id UUID PRIMARY KEY,
   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
   title VARCHAR(255) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
   completed BOOLEAN DEFAULT FALSE,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  completed_at TIMESTAMP DEFAULT NULL
+  completed_at TIMESTAMP DEFAULT NULL,
+  due_at TIMESTAMPTZ DEFAULT NULL,
+  priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4),
+  project_id UUID DEFAULT NULL REFERENCES projects(id) ON DELETE SET NULL,
+  parent_id UUID DEFAULT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  recurrence TEXT DEFAULT NULL,
+  recurrence_timezone TEXT DEFAULT NULL,
+  occurrence_at TIMESTAMPTZ DEFAULT NULL,
+  next_occurrence_id UUID DEFAULT NULL REFERENCES todos(id) ON DELETE SET NULL,
+  deleted_at TIMESTAMPTZ DEFAULT NULL,
+  position TEXT COLLATE "C" DEFAULT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple'::regconfig, title), 'A') ||
+    setweight(to_tsvector('simple'::regconfig, description), 'B')
+  ) STORED
+);
+
+CREATE INDEX idx_todos_user_id_due_at ON todos (user_id, due_at);
+CREATE INDEX idx_todos_user_id_priority ON todos (user_id, priority);
+CREATE INDEX idx_todos_user_id_created_at ON todos (user_id, created_at, id);
+CREATE INDEX idx_todos_search_vector ON todos USING GIN (search_vector);
+CREATE INDEX idx_todos_project_id ON todos (project_id);
+CREATE INDEX idx_todos_parent_id ON todos (parent_id);
+CREATE INDEX idx_todos_deleted_at ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_todos_user_id_position ON todos (user_id, position);
+
+CREATE TABLE reminders (
+  id UUID PRIMARY KEY,
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  remind_at TIMESTAMPTZ DEFAULT NULL,
+  offset_seconds INTEGER DEFAULT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_attempt_at TIMESTAMPTZ DEFAULT NULL,
+  sent_at TIMESTAMPTZ DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((remind_at IS NULL) <> (offset_seconds IS NULL))
+);
+
+CREATE INDEX idx_reminders_todo_id ON reminders (todo_id);
+CREATE INDEX idx_reminders_pending ON reminders (remind_at) WHERE sent_at IS NULL;
+
+CREATE TABLE tags (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(50) NOT NULL,
+  color VARCHAR(7) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_tags_user_id_lower_name ON tags (user_id, LOWER(name));
+
+CREATE TABLE todo_tags (
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
+
+CREATE TABLE todo_revisions (
+  id UUID PRIMARY KEY,
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  actor_id UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  changes JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX idx_todo_revisions_todo_id_created_at ON todo_revisions (todo_id, created_at);
+
+CREATE TABLE todo_comments (
+  id UUID PRIMARY KEY,
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  author_id UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE INDEX idx_todo_comments_todo_id_created_at ON todo_comments (todo_id, created_at);
+
+CREATE TABLE todo_attachments (
+  id UUID PRIMARY KEY,
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  uploader_id UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  name VARCHAR(255) NOT NULL,
+  content_type VARCHAR(255) NOT NULL,
+  size BIGINT NOT NULL,
+  blob_key TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_todo_attachments_todo_id_created_at ON todo_attachments (todo_id, created_at);
+
+CREATE TABLE orphaned_blobs (
+  blob_key TEXT PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE FUNCTION queue_orphaned_blob() RETURNS TRIGGER AS $$
+BEGIN
+  INSERT INTO orphaned_blobs (blob_key) VALUES (OLD.blob_key) ON CONFLICT DO NOTHING;
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_attachments_queue_orphaned_blob
+  AFTER DELETE ON todo_attachments
+  FOR EACH ROW EXECUTE FUNCTION queue_orphaned_blob();
+
+CREATE TABLE app_passwords (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  password_hash TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_used_at TIMESTAMPTZ DEFAULT NULL
 );
 
+CREATE INDEX idx_app_passwords_user_id_created_at ON app_passwords (user_id, created_at);
+
 CREATE TABLE refresh_tokens (
     id              UUID PRIMARY KEY,
     user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
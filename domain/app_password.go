@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const MaxAppPasswordNameLength = 100
+
+// appPasswordAlphabet leaves out letters that are easily mistaken for one another when
+// the password is typed into a device by hand.
+const appPasswordAlphabet = "abcdefghjkmnpqrstuvwxyz"
+
+// An AppPassword lets a client that only supports HTTP Basic authentication, like a
+// CalDAV client, sign in without the password of the account. It is generated by the
+// server and shown once, only its hash is stored. Deleting it signs the client out.
+type AppPassword struct {
+	Id         uuid.UUID
+	UserId     uuid.UUID
+	Name       string
+	Hash       string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// NewAppPassword returns the app password together with the password itself, which
+// looks like "abcd-efgh-jkmn-pqrs".
+func NewAppPassword(userId uuid.UUID, name string) (*AppPassword, string, error) {
+	if IsUserIdEmpty(userId) {
+		return nil, "", ErrUserIdCannotBeEmpty
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrEmptyAppPasswordName
+	}
+	if utf8.RuneCountInString(name) > MaxAppPasswordNameLength {
+		return nil, "", ErrAppPasswordNameTooLong
+	}
+
+	password, err := generateAppPassword()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &AppPassword{
+		Id:        uuid.New(),
+		UserId:    userId,
+		Name:      name,
+		Hash:      HashAppPassword(password),
+		CreatedAt: time.Now(),
+	}, password, nil
+}
+
+// HashAppPassword ignores case, spaces and dashes, so the password can be typed
+// without its grouping. App passwords are random rather than chosen by the user, a
+// single SHA-256 is enough to protect them and keeps every request of a client from
+// paying for bcrypt.
+func HashAppPassword(password string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(password))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateAppPassword returns 16 random letters in groups of four, about 72 bits.
+func generateAppPassword() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(appPasswordAlphabet)))
+	for i := 0; i < 16; i++ {
+		if i > 0 && i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(appPasswordAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxAttachmentSize       = 10 << 20
+	MaxAttachmentNameLength = 255
+)
+
+// The content type of an attachment is sniffed from its first bytes rather than taken
+// from the client.
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"application/zip": true,
+	"image/gif":       true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+// An Attachment is a file uploaded to a todo, its content is kept in a BlobStore.
+// UploaderId is empty once the uploader deletes their account.
+type Attachment struct {
+	Id          uuid.UUID
+	TodoId      uuid.UUID
+	UploaderId  uuid.UUID
+	Name        string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+}
+
+func NewAttachment(todoId, uploaderId uuid.UUID, name, contentType string, size int64) (*Attachment, error) {
+	if IsUserIdEmpty(uploaderId) {
+		return nil, ErrUserIdCannotBeEmpty
+	}
+
+	name, err := NormalizeAttachmentName(name)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, ErrEmptyAttachment
+	}
+	if size > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	if !allowedAttachmentTypes[contentType] {
+		return nil, ErrUnsupportedAttachmentType
+	}
+
+	return &Attachment{
+		Id:          uuid.New(),
+		TodoId:      todoId,
+		UploaderId:  uploaderId,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (a *Attachment) BlobKey() string {
+	return "attachments/" + a.Id.String()
+}
+
+// NormalizeAttachmentName keeps the base name of the uploaded file, some clients send
+// the whole path. The name ends up in a Content-Disposition header, so control
+// characters are rejected.
+func NormalizeAttachmentName(name string) (string, error) {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" || !utf8.ValidString(name) || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", ErrInvalidAttachmentName
+	}
+	if len(name) > MaxAttachmentNameLength {
+		return "", ErrInvalidAttachmentName
+	}
+	return name, nil
+}
+
+// DetectAttachmentType returns the media type of the content starting with head,
+// without parameters such as the charset.
+func DetectAttachmentType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
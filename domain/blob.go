@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// BlobStore keeps the content of files, e.g. attachments, outside of the database.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns ErrBlobNotFound when there is no blob under the key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when there is no blob under the key, so that it can be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+var blobKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// ValidateBlobKey accepts slash separated paths of letters, digits, dots, dashes and
+// underscores. The stores map keys to file paths and URLs, so ".." is rejected too.
+func ValidateBlobKey(key string) error {
+	if !blobKeyPattern.MatchString(key) {
+		return ErrInvalidBlobKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return ErrInvalidBlobKey
+		}
+	}
+	return nil
+}
+
+// A File is sent as the response body as is, instead of being encoded as JSON. Body is
+// closed once it has been sent. Size is -1 when it is not known upfront, e.g. for a
+// file that is written while it is sent. ETag is sent along when it is set.
+type File struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+	ETag        string
+}
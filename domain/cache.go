@@ -10,9 +10,15 @@ import (
 type Cache interface {
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
-	Delete(ctx context.Context, key string) error
+	Delete(ctx context.Context, keys ...string) error
 }
 
 func NewTodoCacheKey(userId uuid.UUID) string {
 	return "todos:" + userId.String()
 }
+
+// NewTodoVariantCacheKey keeps other views of the todo list, e.g. other sort orders,
+// next to the NewTodoCacheKey entry.
+func NewTodoVariantCacheKey(userId uuid.UUID, variant string) string {
+	return NewTodoCacheKey(userId) + ":" + variant
+}
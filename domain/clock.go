@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Clock is injected wherever the current time drives behavior, so that tests can move time forward.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func NewSystemClock() *SystemClock {
+	return &SystemClock{}
+}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// NormalizeColor lowercases a "#rrggbb" color, an empty color becomes defaultColor.
+func NormalizeColor(color, defaultColor string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return defaultColor, nil
+	}
+	if !colorPattern.MatchString(color) {
+		return "", ErrInvalidColor
+	}
+	return color, nil
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const MaxCommentLength = 2000
+
+// A Comment is a message on a todo, every user who can see the todo can comment on it.
+// Only the author, or an admin, can edit or delete it.
+type Comment struct {
+	Id        uuid.UUID
+	TodoId    uuid.UUID
+	AuthorId  uuid.UUID
+	Body      string
+	CreatedAt time.Time
+}
+
+func NewComment(todoId, authorId uuid.UUID, body string) (*Comment, error) {
+	if IsUserIdEmpty(authorId) {
+		return nil, ErrUserIdCannotBeEmpty
+	}
+
+	body, err := NormalizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comment{
+		Id:        uuid.New(),
+		TodoId:    todoId,
+		AuthorId:  authorId,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NormalizeCommentBody trims the body and checks its length, which is counted in
+// characters rather than bytes.
+func NormalizeCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", ErrEmptyComment
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return "", ErrCommentTooLong
+	}
+	return body, nil
+}
@@ -2,7 +2,9 @@ package domain
 
 import (
 	"fmt"
+	"html"
 	"os"
+	"time"
 )
 
 type EmailClaims struct {
@@ -65,11 +67,25 @@ func NewVerificationEmailBody(url string) string {
 	return fmt.Sprintf("<p>Hello,</p><p>Please click the link below to verify your email address:</p><a href='%s'>Verify Email</a>", url)
 }
 
+// The title is user input, so it is escaped before it is put into the HTML.
+func NewReminderEmailBody(title string, dueAt time.Time) string {
+	due := "No due date"
+	if !dueAt.IsZero() {
+		due = "Due " + dueAt.UTC().Format("Mon, 02 Jan 2006 15:04 MST")
+	}
+	return fmt.Sprintf("<p>Hello,</p><p>This is a reminder for your todo <strong>%s</strong>.</p><p>%s</p>", html.EscapeString(title), due)
+}
+
+func NewReminderEmailSubject(title string) string {
+	return fmt.Sprintf(ReminderEmailSubject, title)
+}
+
 var (
 	WelcomeEmailSubject             = "Welcome to Advanced Todo API"
 	SuccessfullyDeletedEmailSubject = "Your Account Has Been Successfully Deleted"
 	VerificationEmailSubject        = "Please Verify Your Email Address"
 	ForgotPasswordEmailSubject      = "Password Reset"
+	ReminderEmailSubject            = "Reminder: %s"
 	EnglishSuccessfullyDeletedEmail = `
 <!DOCTYPE html>
 <html lang="en">
@@ -50,6 +50,123 @@ var (
 	ErrUserIdCannotBeEmpty = errors.New("user ID cannot be empty")
 	ErrTitleTooLong        = errors.New("title cannot exceed 100 characters")
 	ErrTitleTooShort       = errors.New("title must be at least 3 characters long")
+	ErrDescriptionTooLong  = errors.New("description cannot exceed 10000 characters")
+	ErrInvalidDueAt        = errors.New("due date must be between the years 2000 and 2100")
+	ErrInvalidDueFilter    = errors.New("due_before and due_after must be RFC 3339 timestamps")
+	ErrInvalidPriority     = errors.New("priority must be one of none, low, medium, high, urgent")
+	ErrInvalidSort         = errors.New("sort must be one of position, priority, created_at, due_at, title")
+	ErrInvalidSortOrder    = errors.New("order must be asc or desc")
+
+	ErrInvalidCreatedFilter   = errors.New("created_before and created_after must be RFC 3339 timestamps")
+	ErrInvalidCompletedFilter = errors.New("completed must be true or false")
+	ErrSearchQueryTooLong     = errors.New("q cannot exceed 100 characters")
+	ErrInvalidPageLimit       = errors.New("limit must be between 1 and 100")
+	ErrInvalidCursor          = errors.New("cursor is invalid or does not match the requested sort")
+	ErrEmptySearchQuery       = errors.New("q must contain at least one letter or digit")
+	ErrInvalidSearchLimit     = errors.New("limit must be between 1 and 50")
+
+	ErrEmptyBatch            = errors.New("operations cannot be empty")
+	ErrTooManyOperations     = errors.New("a batch cannot have more than 100 operations")
+	ErrInvalidBatchMode      = errors.New("mode must be atomic or per_item")
+	ErrInvalidBatchOperation = errors.New("op must be one of create, update_title, set_completed, delete, move")
+	ErrMissingTodoId         = errors.New("id is required")
+	ErrMissingCompleted      = errors.New("completed is required")
+	ErrBatchNotApplied       = errors.New("not applied because another operation of the batch failed")
+
+	ErrInvalidReminder       = errors.New("either remind_at or minutes_before_due must be set")
+	ErrReminderInPast        = errors.New("reminder time must be in the future")
+	ErrInvalidReminderOffset = errors.New("minutes_before_due must be between 0 and 43200")
+	ErrReminderWithoutDueAt  = errors.New("a relative reminder requires the todo to have a due date")
+	ErrReminderNotFound      = errors.New("reminder not found")
+
+	ErrEmptyTagName     = errors.New("tag name cannot be empty")
+	ErrTagNameTooLong   = errors.New("tag name cannot exceed 50 characters")
+	ErrInvalidTagName   = errors.New("tag name cannot contain commas")
+	ErrInvalidColor     = errors.New("color must be a hex color like #1e90ff")
+	ErrInvalidTagMode   = errors.New("tag_mode must be and or or")
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagAlreadyExists = errors.New("a tag with this name already exists")
+
+	ErrEmptyProjectName       = errors.New("project name cannot be empty")
+	ErrProjectNameTooLong     = errors.New("project name cannot exceed 100 characters")
+	ErrInvalidProjectPosition = errors.New("project position cannot be negative")
+	ErrInvalidDeleteMode      = errors.New("todos must be inbox or cascade")
+	ErrProjectNotFound        = errors.New("project not found")
+	ErrProjectArchived        = errors.New("todos cannot be added to an archived project")
+
+	ErrInvalidProjectRole     = errors.New("role must be one of viewer, editor, owner")
+	ErrInvalidInvitationEmail = errors.New("email must be a valid email address")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	ErrAlreadyProjectMember   = errors.New("the user is already a member of the project")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrLastProjectOwner       = errors.New("a project must keep at least one owner")
+	ErrNotProjectOwner        = errors.New("only the owners of the project can do this")
+	ErrProjectReadOnly        = errors.New("you can only view the todos of this project")
+	ErrTodoReadOnly           = errors.New("you can only view this todo")
+
+	ErrSubtaskTooDeep     = errors.New("subtasks cannot be nested more than 3 levels deep")
+	ErrParentTodoNotFound = errors.New("parent todo not found")
+	ErrSubtaskWithProject = errors.New("subtasks belong to the project of their parent")
+	ErrParentTodoInTrash  = errors.New("the parent todo is in the trash, restore it first")
+
+	ErrInvalidRRule           = errors.New("invalid recurrence rule")
+	ErrInvalidTimezone        = errors.New("invalid timezone")
+	ErrRecurrenceWithoutDueAt = errors.New("a recurring todo must have a due date")
+	ErrInvalidRecurrenceScope = errors.New("scope must be this or all_future")
+	ErrRecurrenceScope        = errors.New("the recurrence can only be changed for all future occurrences")
+	ErrInvalidPreviewCount    = errors.New("count must be between 1 and 50")
+	ErrInvalidRecurrenceStart = errors.New("start must be an RFC 3339 timestamp")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrRevisionConflict = errors.New("the todo has changed since this revision, revert the later revisions first")
+
+	ErrInvalidPosition    = errors.New("invalid position")
+	ErrMissingMoveAnchor  = errors.New("either before or after must be set")
+	ErrInvalidMoveAnchor  = errors.New("before and after must be two different todos other than the moved one")
+	ErrAnchorTodoNotFound = errors.New("anchor todo not found")
+	ErrStaleMoveAnchors   = errors.New("before and after are no longer next to each other, reload the list")
+
+	ErrEmptyComment     = errors.New("comment cannot be empty")
+	ErrCommentTooLong   = errors.New("comment cannot exceed 2000 characters")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrNotCommentAuthor = errors.New("only the author of the comment can do this")
+
+	ErrMissingAttachment         = errors.New("file is required")
+	ErrEmptyAttachment           = errors.New("file cannot be empty")
+	ErrAttachmentTooLarge        = errors.New("file cannot exceed 10 MiB")
+	ErrUnsupportedAttachmentType = errors.New("file type is not supported, allowed types are images, PDF, ZIP and plain text")
+	ErrInvalidAttachmentName     = errors.New("file name must be at most 255 bytes and cannot contain control characters")
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrBlobNotFound              = errors.New("blob not found")
+	ErrInvalidBlobKey            = errors.New("invalid blob key")
+
+	ErrInvalidTodoFormat    = errors.New("format must be one of json, csv, ndjson, txt, ics")
+	ErrInvalidImportFormat  = errors.New("only json, csv, ndjson and txt files can be imported")
+	ErrMissingImportFile    = errors.New("file is required")
+	ErrEmptyImport          = errors.New("the file contains no todos")
+	ErrTooManyImportedTodos = errors.New("an import cannot exceed 5000 todos")
+	ErrInvalidJSONImport    = errors.New("a JSON import must be an array of todos")
+	ErrMissingTitleColumn   = errors.New("the CSV header must have a title column")
+	ErrInvalidCompleted     = errors.New("completed must be true or false")
+	ErrInvalidDueAtFormat   = errors.New("due_at must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	ErrInvalidImportedId    = errors.New("id, project_id and parent_id must be UUIDs")
+	ErrDuplicateImportedId  = errors.New("a todo id appears more than once in the file")
+
+	ErrFeedNotFound = errors.New("calendar feed not found")
+
+	ErrEmptyAppPasswordName   = errors.New("app password name cannot be empty")
+	ErrAppPasswordNameTooLong = errors.New("app password name cannot exceed 100 characters")
+	ErrAppPasswordNotFound    = errors.New("app password not found")
+
+	ErrTodoAlreadyExists = errors.New("a todo with this id already exists")
+
+	ErrDuplicateTodoTxtId = errors.New("a todo appears more than once in the file")
+	ErrTodoTxtProjects    = errors.New("a todo can only have one +project")
+	ErrTodoTxtChanged     = errors.New("the todos have changed since the file was downloaded, download it again")
+
+	ErrTodoVersionMismatch = errors.New("the todo has changed since it was read, fetch it again")
+	ErrInvalidIfMatch      = errors.New("If-Match must be a list of ETags or *")
+	ErrUnsupportedPatch    = errors.New("a PATCH body must be a JSON merge patch sent as application/merge-patch+json, POST /todos/{id}/toggle toggles the completion")
 
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrNoRows            = errors.New("no rows in result set")
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Every write to a todo increments its version. The version is sent as a strong ETag,
+// a client that sends it back in If-Match only changes the todo when nobody else has
+// changed it in the meantime. A version sent in more than one representation has an
+// ETag for each, the version followed by the variants of the representation, as in
+// "3-html". If-Match takes any of them for the version.
+
+// VersionETag returns the ETag of a version in the representation with the variants.
+func VersionETag(version int, variants ...string) string {
+	return `"` + strings.Join(append([]string{strconv.Itoa(version)}, variants...), "-") + `"`
+}
+
+// ParseIfMatch returns the versions an If-Match header lists, nil when the header is
+// empty or "*", which match any version. A weak ETag never matches, If-Match uses the
+// strong comparison, and neither does the ETag of some other resource. When no entry
+// of the list can match it returns ErrTodoVersionMismatch.
+func ParseIfMatch(header string) ([]int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var versions []int
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimSpace(etag)
+		if etag == "" {
+			// a list may have empty elements
+			continue
+		}
+		weak := strings.HasPrefix(etag, "W/")
+		etag = strings.TrimPrefix(etag, "W/")
+		if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+			return nil, ErrInvalidIfMatch
+		}
+		number, _, _ := strings.Cut(etag[1:len(etag)-1], "-")
+		version, err := strconv.Atoi(number)
+		if weak || err != nil || version < 1 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrTodoVersionMismatch
+	}
+	return versions, nil
+}
+
+// ETagMatches reports whether the list of ETags of an If-None-Match header holds etag,
+// "*" matches any. The comparison is weak, as If-None-Match requires.
+func ETagMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
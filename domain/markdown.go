@@ -0,0 +1,6 @@
+package domain
+
+type MarkdownRenderer interface {
+	// Render converts Markdown to HTML that is safe to embed in a page.
+	Render(markdown string) (string, error)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectRole is what a member can do in a shared project. Every role includes the
+// ones below it: viewers see the todos, editors change them as well, owners also manage
+// the project and its members. Whoever creates a project is its first owner.
+type ProjectRole string
+
+const (
+	ProjectViewer ProjectRole = "viewer"
+	ProjectEditor ProjectRole = "editor"
+	ProjectOwner  ProjectRole = "owner"
+)
+
+var projectRoleRanks = map[ProjectRole]int{
+	ProjectViewer: 1,
+	ProjectEditor: 2,
+	ProjectOwner:  3,
+}
+
+func ParseProjectRole(role string) (ProjectRole, error) {
+	r := ProjectRole(strings.ToLower(strings.TrimSpace(role)))
+	if _, ok := projectRoleRanks[r]; !ok {
+		return "", ErrInvalidProjectRole
+	}
+	return r, nil
+}
+
+// Includes reports whether the role allows everything the other role allows.
+func (r ProjectRole) Includes(other ProjectRole) bool {
+	return projectRoleRanks[r] >= projectRoleRanks[other]
+}
+
+// RolesIncluding returns every role that includes the given one, lowest first.
+func RolesIncluding(role ProjectRole) []ProjectRole {
+	var roles []ProjectRole
+	for _, r := range []ProjectRole{ProjectViewer, ProjectEditor, ProjectOwner} {
+		if r.Includes(role) {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// ProjectInvitation waits for the user with the email to accept it, that user may not
+// have signed up yet. Emails are compared case-insensitively.
+type ProjectInvitation struct {
+	Id        uuid.UUID
+	ProjectId uuid.UUID
+	Email     string
+	Role      ProjectRole
+	InvitedBy uuid.UUID
+	CreatedAt time.Time
+}
+
+func NewProjectInvitation(projectId, invitedBy uuid.UUID, email string, role ProjectRole) (*ProjectInvitation, error) {
+	if IsUserIdEmpty(invitedBy) {
+		return nil, ErrUserIdCannotBeEmpty
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	// a display name is not an email, "Jane <jane@example.com>" is refused as well
+	if err != nil || address.Address != strings.TrimSpace(email) {
+		return nil, ErrInvalidInvitationEmail
+	}
+
+	if _, ok := projectRoleRanks[role]; !ok {
+		return nil, ErrInvalidProjectRole
+	}
+
+	return &ProjectInvitation{
+		Id:        uuid.New(),
+		ProjectId: projectId,
+		Email:     strings.ToLower(address.Address),
+		Role:      role,
+		InvitedBy: invitedBy,
+		CreatedAt: time.Now(),
+	}, nil
+}
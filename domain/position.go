@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Positions are fractional indexes: base 62 strings that sort in byte order, with a key
+// between any two others, so that moving a todo only changes the todo itself.
+//
+// A key is an integer part followed by a fraction. The first character of the integer
+// part tells its length: "a" to "z" are followed by 1 to 26 digits, "Z" to "A" by 1 to
+// 26 digits as well and sort before them. Appending to the end increments the integer
+// part, so keys stay short when todos are added one after another. The fraction never
+// ends with "0", there would be no key right before it otherwise.
+const positionDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// the smallest integer part, "A" followed by 26 zeros, only ever gets a fraction
+var smallestPositionInteger = "A" + strings.Repeat("0", 26)
+
+var errPositionExhausted = errors.New("no position left at this end")
+
+// PositionBetween returns a position that sorts strictly between a and b. An empty a
+// stands for the beginning of the list, an empty b for its end.
+func PositionBetween(a, b string) (string, error) {
+	if a != "" {
+		if err := validatePosition(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := validatePosition(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrInvalidPosition
+	}
+
+	if a == "" {
+		if b == "" {
+			return "a0", nil
+		}
+		intB, _ := positionInteger(b)
+		fracB := b[len(intB):]
+		if intB == smallestPositionInteger {
+			return intB + positionMidpoint("", fracB), nil
+		}
+		if intB < b {
+			return intB, nil
+		}
+		return decrementPositionInteger(intB)
+	}
+
+	intA, _ := positionInteger(a)
+	fracA := a[len(intA):]
+	if b == "" {
+		next, err := incrementPositionInteger(intA)
+		if err != nil {
+			return intA + positionMidpoint(fracA, ""), nil
+		}
+		return next, nil
+	}
+
+	intB, _ := positionInteger(b)
+	fracB := b[len(intB):]
+	if intA == intB {
+		return intA + positionMidpoint(fracA, fracB), nil
+	}
+	next, err := incrementPositionInteger(intA)
+	if err != nil {
+		return "", err
+	}
+	if next < b {
+		return next, nil
+	}
+	return intA + positionMidpoint(fracA, ""), nil
+}
+
+func validatePosition(key string) error {
+	if key == smallestPositionInteger {
+		return ErrInvalidPosition
+	}
+	integer, err := positionInteger(key)
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(positionDigits, key[i]) < 0 {
+			return ErrInvalidPosition
+		}
+	}
+	if fraction := key[len(integer):]; strings.HasSuffix(fraction, "0") {
+		return ErrInvalidPosition
+	}
+	return nil
+}
+
+func positionIntegerLength(head byte) (int, error) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, nil
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, nil
+	}
+	return 0, ErrInvalidPosition
+}
+
+func positionInteger(key string) (string, error) {
+	length, err := positionIntegerLength(key[0])
+	if err != nil {
+		return "", err
+	}
+	if length > len(key) {
+		return "", ErrInvalidPosition
+	}
+	return key[:length], nil
+}
+
+// positionMidpoint returns a fraction between a and b, an empty b stands for 1. Neither
+// ends with "0".
+func positionMidpoint(a, b string) string {
+	if b != "" {
+		// the common prefix is kept as is, a is padded with zeros
+		n := 0
+		for n < len(b) && positionDigitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + positionMidpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(positionDigits, a[0])
+	}
+	digitB := len(positionDigits)
+	if b != "" {
+		digitB = strings.IndexByte(positionDigits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(positionDigits[(digitA+digitB+1)/2])
+	}
+
+	// the first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(positionDigits[digitA]) + positionMidpoint(rest, "")
+}
+
+func positionDigitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return positionDigits[0]
+}
+
+func incrementPositionInteger(integer string) (string, error) {
+	head, digits := integer[0], []byte(integer[1:])
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(positionDigits, digits[i]) + 1
+		if d < len(positionDigits) {
+			digits[i] = positionDigits[d]
+			return string(head) + string(digits), nil
+		}
+		digits[i] = positionDigits[0]
+	}
+
+	// every digit overflowed, the integer part gets one digit longer
+	switch head {
+	case 'Z':
+		return "a" + string(positionDigits[0]), nil
+	case 'z':
+		return "", errPositionExhausted
+	}
+	head++
+	if head > 'a' {
+		digits = append(digits, positionDigits[0])
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), nil
+}
+
+func decrementPositionInteger(integer string) (string, error) {
+	last := positionDigits[len(positionDigits)-1]
+	head, digits := integer[0], []byte(integer[1:])
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(positionDigits, digits[i]) - 1
+		if d >= 0 {
+			digits[i] = positionDigits[d]
+			return string(head) + string(digits), nil
+		}
+		digits[i] = last
+	}
+
+	// every digit underflowed, the integer part gets one digit shorter or longer
+	switch head {
+	case 'a':
+		return "Z" + string(last), nil
+	case 'A':
+		return "", errPositionExhausted
+	}
+	head--
+	if head < 'Z' {
+		digits = append(digits, last)
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), nil
+}
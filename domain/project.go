@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxProjectNameLength = 100
+	DefaultProjectColor  = "#3b82f6"
+)
+
+// Project groups todos. A todo without a project is in the inbox.
+// Projects are listed by Position in ascending order.
+type Project struct {
+	Id        uuid.UUID
+	UserId    uuid.UUID
+	Name      string
+	Color     string
+	Archived  bool
+	Position  int
+	CreatedAt time.Time
+}
+
+func NewProject(userId uuid.UUID, name, color string, archived bool, position int) (*Project, error) {
+	if IsUserIdEmpty(userId) {
+		return nil, ErrUserIdCannotBeEmpty
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyProjectName
+	}
+	if len(name) > MaxProjectNameLength {
+		return nil, ErrProjectNameTooLong
+	}
+
+	color, err := NormalizeColor(color, DefaultProjectColor)
+	if err != nil {
+		return nil, err
+	}
+
+	if position < 0 {
+		return nil, ErrInvalidProjectPosition
+	}
+
+	return &Project{
+		Id:        uuid.New(),
+		UserId:    userId,
+		Name:      name,
+		Color:     color,
+		Archived:  archived,
+		Position:  position,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// ProjectDeleteMode decides what happens to the todos of a deleted project.
+type ProjectDeleteMode string
+
+const (
+	// ProjectDeleteMoveToInbox keeps the todos and moves them to the inbox.
+	ProjectDeleteMoveToInbox ProjectDeleteMode = "inbox"
+	// ProjectDeleteCascade deletes the todos together with the project.
+	ProjectDeleteCascade ProjectDeleteMode = "cascade"
+)
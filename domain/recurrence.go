@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultRecurrenceTimezone = "UTC"
+	DefaultPreviewCount       = 5
+	MaxPreviewCount           = 50
+)
+
+// Recurrence makes a todo repeat. Only one occurrence of a series exists at a time:
+// completing it creates the next one.
+//
+// Rule.Count is the number of occurrences left including the current one, so that a
+// series can be continued from any of its occurrences.
+type Recurrence struct {
+	Rule     *RRule
+	Timezone *time.Location
+}
+
+func NewRecurrence(rule, timezone string) (*Recurrence, error) {
+	parsed, err := ParseRRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recurrence{Rule: parsed, Timezone: loc}, nil
+}
+
+// LoadTimezone loads an IANA timezone such as "Europe/Istanbul", an empty name is UTC.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultRecurrenceTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// RecurrenceScope tells whether an edit of a recurring todo applies to the current
+// occurrence only or to the rest of the series.
+type RecurrenceScope string
+
+const (
+	// RecurrenceScopeThis changes the current occurrence, the series keeps its schedule.
+	RecurrenceScopeThis RecurrenceScope = "this"
+	// RecurrenceScopeAllFuture restarts the series at the current occurrence, with its
+	// due date and rule.
+	RecurrenceScopeAllFuture RecurrenceScope = "all_future"
+)
+
+func ValidateRecurrenceScope(scope RecurrenceScope) error {
+	if scope != RecurrenceScopeThis && scope != RecurrenceScopeAllFuture {
+		return ErrInvalidRecurrenceScope
+	}
+	return nil
+}
+
+// SetRecurrence makes the todo repeat, starting with the current due date. A nil
+// recurrence makes it a one-off todo again.
+func (t *Todo) SetRecurrence(recurrence *Recurrence) error {
+	if recurrence == nil {
+		t.Recurrence = nil
+		t.OccurrenceAt = time.Time{}
+		return nil
+	}
+	if t.DueAt.IsZero() {
+		return ErrRecurrenceWithoutDueAt
+	}
+
+	t.Recurrence = recurrence
+	t.OccurrenceAt = t.DueAt
+	return nil
+}
+
+// NextOccurrence returns the todo that follows t in its series, or nil when t does not
+// repeat or its series has ended. The next todo is due at the next slot of the series,
+// even when t itself was rescheduled with RecurrenceScopeThis.
+func (t *Todo) NextOccurrence() *Todo {
+	if t.Recurrence == nil || t.OccurrenceAt.IsZero() {
+		return nil
+	}
+
+	rule := *t.Recurrence.Rule
+	if rule.Count == 1 {
+		return nil
+	}
+
+	unlimited := rule
+	unlimited.Count = 0
+	next, ok := unlimited.Next(t.OccurrenceAt, t.Recurrence.Timezone, t.OccurrenceAt)
+	if !ok {
+		return nil
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	return &Todo{
+		UserId:       t.UserId,
+		Id:           uuid.New(),
+		Title:        t.Title,
+		Description:  t.Description,
+		CreatedAt:    time.Now(),
+		DueAt:        next.In(t.DueAt.Location()),
+		Priority:     t.Priority,
+		ProjectId:    t.ProjectId,
+		ParentId:     t.ParentId,
+		Recurrence:   &Recurrence{Rule: &rule, Timezone: t.Recurrence.Timezone},
+		OccurrenceAt: next.UTC(),
+	}
+}
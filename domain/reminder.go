@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxReminderOffset   = 30 * 24 * time.Hour
+	MaxReminderAttempts = 5
+	ReminderRetryDelay  = time.Minute
+)
+
+// A Reminder is either absolute, fired at RemindAt, or relative, fired Offset before
+// the due date of its todo. A relative reminder follows the due date when it changes.
+type Reminder struct {
+	Id        uuid.UUID
+	TodoId    uuid.UUID
+	RemindAt  time.Time
+	Offset    time.Duration
+	Relative  bool
+	CreatedAt time.Time
+	SentAt    time.Time
+}
+
+func NewAbsoluteReminder(todoId uuid.UUID, remindAt, now time.Time) (*Reminder, error) {
+	if remindAt.IsZero() {
+		return nil, ErrInvalidReminder
+	}
+	if !remindAt.After(now) {
+		return nil, ErrReminderInPast
+	}
+
+	return &Reminder{
+		Id:        uuid.New(),
+		TodoId:    todoId,
+		RemindAt:  remindAt.UTC(),
+		CreatedAt: now,
+	}, nil
+}
+
+// The todo must have a due date, otherwise the reminder could never fire.
+func NewRelativeReminder(todoId uuid.UUID, offset time.Duration, dueAt, now time.Time) (*Reminder, error) {
+	if offset < 0 || offset > MaxReminderOffset {
+		return nil, ErrInvalidReminderOffset
+	}
+	if dueAt.IsZero() {
+		return nil, ErrReminderWithoutDueAt
+	}
+
+	return &Reminder{
+		Id:        uuid.New(),
+		TodoId:    todoId,
+		Offset:    offset,
+		Relative:  true,
+		CreatedAt: now,
+	}, nil
+}
+
+// FireAt returns when the reminder fires, or the zero time if it never will.
+func (r *Reminder) FireAt(dueAt time.Time) time.Time {
+	if !r.Relative {
+		return r.RemindAt
+	}
+	if dueAt.IsZero() {
+		return time.Time{}
+	}
+	return dueAt.Add(-r.Offset)
+}
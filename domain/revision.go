@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TodoState holds the fields of a todo whose changes are recorded as revisions. Due
+// dates are kept in UTC so that equal states marshal to the same JSON.
+type TodoState struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	DueAt       *time.Time `json:"due_at"`
+	Priority    Priority   `json:"priority"`
+	ProjectId   *uuid.UUID `json:"project_id"`
+}
+
+// FieldChange holds the JSON values of a field before and after a change.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+type TodoRevision struct {
+	Id      uuid.UUID
+	TodoId  uuid.UUID
+	ActorId uuid.UUID
+	// Changes is keyed by the JSON name of the field in TodoState, unchanged fields are
+	// left out.
+	Changes map[string]FieldChange
+}
+
+// NewTodoRevision returns nil when before and after are the same, there is nothing to
+// record then.
+func NewTodoRevision(todoId, actorId uuid.UUID, before, after TodoState) (*TodoRevision, error) {
+	beforeFields, err := before.fields()
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := after.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]FieldChange{}
+	for name, value := range afterFields {
+		if !sameJSON(beforeFields[name], value) {
+			changes[name] = FieldChange{Before: beforeFields[name], After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	return &TodoRevision{
+		Id:      uuid.New(),
+		TodoId:  todoId,
+		ActorId: actorId,
+		Changes: changes,
+	}, nil
+}
+
+// Revert returns the current state with the fields of the revision set back to their
+// previous values. A revision can only be reverted while the todo still has the values
+// it set, otherwise a later change would be undone silently.
+func (r *TodoRevision) Revert(current TodoState) (TodoState, error) {
+	fields, err := current.fields()
+	if err != nil {
+		return TodoState{}, err
+	}
+
+	for name, change := range r.Changes {
+		if !sameJSON(fields[name], change.After) {
+			return TodoState{}, ErrRevisionConflict
+		}
+		fields[name] = change.Before
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return TodoState{}, err
+	}
+	var reverted TodoState
+	if err := json.Unmarshal(data, &reverted); err != nil {
+		return TodoState{}, err
+	}
+	return reverted, nil
+}
+
+func (s TodoState) fields() (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// sameJSON compares the values rather than the bytes, the database may store a value
+// with a different formatting or escaping than encoding/json.
+func sameJSON(a, b json.RawMessage) bool {
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,491 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule is the subset of an RFC 5545 recurrence rule that makes sense for todos:
+// FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL, COUNT, UNTIL, BYMONTH,
+// BYMONTHDAY, BYDAY and WKST. Rules with other parts are rejected rather than
+// silently expanded differently than the client expects.
+type RRule struct {
+	Freq     Frequency
+	Interval int
+	// Count is 0 when the number of occurrences is not limited.
+	Count int
+	// Until is zero when there is no end date. A date-only UNTIL is kept as midnight
+	// UTC of that date and compared with the local date of the occurrences.
+	Until      time.Time
+	UntilDate  bool
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []RRuleWeekday
+	WeekStart  time.Weekday
+}
+
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+const (
+	MaxRRuleLength   = 500
+	MaxRRuleInterval = 1000
+	MaxRRuleCount    = 1000
+)
+
+// RRuleWeekday is a BYDAY entry. N is 0 for every such weekday of the period,
+// otherwise it is the nth one, counted from the end when negative (-1FR is the last Friday).
+type RRuleWeekday struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+const (
+	untilDateLayout     = "20060102"
+	untilDateTimeLayout = "20060102T150405Z"
+)
+
+// ParseRRule parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE", an "RRULE:" prefix is
+// accepted. Names and values are case-insensitive.
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > MaxRRuleLength {
+		return nil, invalidRRule("the rule is too long")
+	}
+	value = strings.ToUpper(value)
+	value = strings.TrimPrefix(value, "RRULE:")
+	if value == "" {
+		return nil, invalidRRule("the rule is empty")
+	}
+
+	rule := &RRule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, invalidRRule("%q is not a NAME=VALUE pair", part)
+		}
+		if seen[name] {
+			return nil, invalidRRule("%s is given more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			err = rule.parseFreq(val)
+		case "INTERVAL":
+			rule.Interval, err = parseRRuleInt(name, val, 1, MaxRRuleInterval)
+		case "COUNT":
+			rule.Count, err = parseRRuleInt(name, val, 1, MaxRRuleCount)
+		case "UNTIL":
+			err = rule.parseUntil(val)
+		case "BYMONTH":
+			err = parseRRuleList(val, func(v string) error {
+				month, err := parseRRuleInt(name, v, 1, 12)
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+				return err
+			})
+		case "BYMONTHDAY":
+			err = parseRRuleList(val, func(v string) error {
+				day, err := parseRRuleInt(name, v, -31, 31)
+				if err == nil && day == 0 {
+					err = invalidRRule("BYMONTHDAY cannot be 0")
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+				return err
+			})
+		case "BYDAY":
+			err = parseRRuleList(val, func(v string) error {
+				weekday, err := parseRRuleWeekday(v)
+				rule.ByDay = append(rule.ByDay, weekday)
+				return err
+			})
+		case "WKST":
+			weekday, ok := weekdayCodes[val]
+			if !ok {
+				return nil, invalidRRule("unknown WKST %q", val)
+			}
+			rule.WeekStart = weekday
+		case "BYSECOND", "BYMINUTE", "BYHOUR", "BYYEARDAY", "BYWEEKNO", "BYSETPOS":
+			return nil, invalidRRule("%s is not supported", name)
+		default:
+			return nil, invalidRRule("unknown part %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, invalidRRule("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, invalidRRule("COUNT and UNTIL cannot be combined")
+	}
+	if err := rule.validateByDay(); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *RRule) parseFreq(value string) error {
+	switch freq := Frequency(value); freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+		r.Freq = freq
+		return nil
+	case "SECONDLY", "MINUTELY", "HOURLY":
+		return invalidRRule("FREQ=%s is not supported", value)
+	}
+	return invalidRRule("unknown FREQ %q", value)
+}
+
+func (r *RRule) parseUntil(value string) error {
+	if t, err := time.Parse(untilDateTimeLayout, value); err == nil {
+		r.Until = t
+		return nil
+	}
+	if t, err := time.Parse(untilDateLayout, value); err == nil {
+		r.Until = t
+		r.UntilDate = true
+		return nil
+	}
+	return invalidRRule("UNTIL must be a date like 20250131 or a UTC time like 20250131T090000Z")
+}
+
+// Ordinals are only meaningful when the period has more than one week of a weekday.
+func (r *RRule) validateByDay() error {
+	for _, weekday := range r.ByDay {
+		if weekday.N == 0 {
+			continue
+		}
+		switch {
+		case r.Freq == FreqMonthly || (r.Freq == FreqYearly && len(r.ByMonth) > 0):
+			if weekday.N < -5 || weekday.N > 5 {
+				return invalidRRule("a monthly BYDAY ordinal must be between -5 and 5")
+			}
+		case r.Freq == FreqYearly:
+			if weekday.N < -53 || weekday.N > 53 {
+				return invalidRRule("a yearly BYDAY ordinal must be between -53 and 53")
+			}
+		default:
+			return invalidRRule("BYDAY ordinals require FREQ=MONTHLY or FREQ=YEARLY")
+		}
+	}
+	return nil
+}
+
+func parseRRuleInt(name, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, invalidRRule("%s must be a number between %d and %d", name, min, max)
+	}
+	return n, nil
+}
+
+func parseRRuleList(value string, parse func(string) error) error {
+	for _, v := range strings.Split(value, ",") {
+		if err := parse(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseRRuleWeekday(value string) (RRuleWeekday, error) {
+	if len(value) < 2 {
+		return RRuleWeekday{}, invalidRRule("unknown BYDAY %q", value)
+	}
+	code, ordinal := value[len(value)-2:], value[:len(value)-2]
+	weekday, ok := weekdayCodes[code]
+	if !ok {
+		return RRuleWeekday{}, invalidRRule("unknown BYDAY %q", value)
+	}
+	if ordinal == "" {
+		return RRuleWeekday{Weekday: weekday}, nil
+	}
+	n, err := strconv.Atoi(ordinal)
+	if err != nil || n == 0 {
+		return RRuleWeekday{}, invalidRRule("unknown BYDAY %q", value)
+	}
+	return RRuleWeekday{N: n, Weekday: weekday}, nil
+}
+
+func invalidRRule(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRRule, fmt.Sprintf(format, args...))
+}
+
+// String returns the rule in a canonical form, parsing it again gives the same rule.
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeLayout))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = weekdayCode(day.Weekday)
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func weekdayCode(weekday time.Weekday) string {
+	for code, w := range weekdayCodes {
+		if w == weekday {
+			return code
+		}
+	}
+	return ""
+}
+
+// Next returns the first occurrence strictly after the given time. ok is false when
+// the series has no more occurrences.
+func (r *RRule) Next(dtstart time.Time, loc *time.Location, after time.Time) (time.Time, bool) {
+	occurrences := r.Occurrences(dtstart, loc, after.Add(time.Second), 1)
+	if len(occurrences) == 0 {
+		return time.Time{}, false
+	}
+	return occurrences[0], true
+}
+
+// Occurrences returns at most n occurrences at or after from, of the series that
+// starts at dtstart.
+//
+// The series is expanded in loc: every occurrence has the wall clock time of dtstart
+// in loc, so a 09:00 todo stays at 09:00 across DST changes. An occurrence that falls
+// into a DST gap is moved forward by the length of the gap, as RFC 5545 requires.
+// dtstart itself is only an occurrence when it matches the rule. Expansion stops
+// at MaxDueAtYear, which also bounds rules that never match.
+func (r *RRule) Occurrences(dtstart time.Time, loc *time.Location, from time.Time, n int) []time.Time {
+	var occurrences []time.Time
+	if n <= 0 {
+		return occurrences
+	}
+
+	start := dtstart.In(loc)
+	expansion := r.withDefaults(start)
+	count := 0
+
+	for period := firstPeriod(expansion, start); period.Year() <= MaxDueAtYear; period = nextPeriod(expansion, period) {
+		for _, day := range periodDays(expansion, period) {
+			if !expansion.matches(day) {
+				continue
+			}
+
+			occurrence := wallClock(day, start, loc)
+			if occurrence.Before(start) {
+				continue
+			}
+			if r.isAfterUntil(day, occurrence) {
+				return occurrences
+			}
+
+			count++
+			if !occurrence.Before(from) {
+				occurrences = append(occurrences, occurrence)
+				if len(occurrences) == n {
+					return occurrences
+				}
+			}
+			if r.Count > 0 && count == r.Count {
+				return occurrences
+			}
+		}
+	}
+
+	return occurrences
+}
+
+func (r *RRule) isAfterUntil(day, occurrence time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.UntilDate {
+		return day.After(civilDate(r.Until.Date()))
+	}
+	return occurrence.After(r.Until)
+}
+
+// wallClock returns the time of day of start, down to the nanosecond, on the given
+// day in loc; dropping the fraction would put dtstart itself before dtstart. time.Date
+// does not define which offset is used for a wall clock time that a DST change skips,
+// so the offset before the change is applied explicitly.
+func wallClock(day, start time.Time, loc *time.Location) time.Time {
+	t := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), loc)
+	if t.Hour() == start.Hour() && t.Minute() == start.Minute() {
+		return t
+	}
+
+	// DST changes are months apart, so the offset half a day earlier is the one before the gap
+	_, offset := t.Add(-12 * time.Hour).Zone()
+	wall := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), time.UTC)
+	return wall.Add(-time.Duration(offset) * time.Second).In(loc)
+}
+
+// withDefaults fills in what RFC 5545 takes from DTSTART when the rule leaves it
+// open, e.g. FREQ=MONTHLY repeats on the day of the month of the first occurrence.
+func (r *RRule) withDefaults(start time.Time) *RRule {
+	rule := *r
+	switch rule.Freq {
+	case FreqWeekly:
+		if len(rule.ByDay) == 0 {
+			rule.ByDay = []RRuleWeekday{{Weekday: start.Weekday()}}
+		}
+	case FreqMonthly:
+		if len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 {
+			rule.ByMonthDay = []int{start.Day()}
+		}
+	case FreqYearly:
+		if len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 {
+			rule.ByMonthDay = []int{start.Day()}
+			if len(rule.ByMonth) == 0 {
+				rule.ByMonth = []time.Month{start.Month()}
+			}
+		}
+	}
+	return &rule
+}
+
+// Days are handled as noon UTC so that adding days never runs into a DST change.
+func civilDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func firstPeriod(r *RRule, start time.Time) time.Time {
+	day := civilDate(start.Year(), start.Month(), start.Day())
+	switch r.Freq {
+	case FreqWeekly:
+		offset := (int(day.Weekday()) - int(r.WeekStart) + 7) % 7
+		return day.AddDate(0, 0, -offset)
+	case FreqMonthly:
+		return civilDate(day.Year(), day.Month(), 1)
+	case FreqYearly:
+		return civilDate(day.Year(), time.January, 1)
+	}
+	return day
+}
+
+func nextPeriod(r *RRule, period time.Time) time.Time {
+	switch r.Freq {
+	case FreqWeekly:
+		return period.AddDate(0, 0, 7*r.Interval)
+	case FreqMonthly:
+		return period.AddDate(0, r.Interval, 0)
+	case FreqYearly:
+		return period.AddDate(r.Interval, 0, 0)
+	}
+	return period.AddDate(0, 0, r.Interval)
+}
+
+func periodDays(r *RRule, period time.Time) []time.Time {
+	var end time.Time
+	switch r.Freq {
+	case FreqWeekly:
+		end = period.AddDate(0, 0, 7)
+	case FreqMonthly:
+		end = period.AddDate(0, 1, 0)
+	case FreqYearly:
+		end = period.AddDate(1, 0, 0)
+	default:
+		end = period.AddDate(0, 0, 1)
+	}
+
+	var days []time.Time
+	for day := period; day.Before(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+func (r *RRule) matches(day time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, day.Month()) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		daysInMonth := daysIn(day.Year(), day.Month())
+		if !slices.ContainsFunc(r.ByMonthDay, func(n int) bool {
+			return n == day.Day() || (n < 0 && daysInMonth+n+1 == day.Day())
+		}) {
+			return false
+		}
+	}
+
+	if len(r.ByDay) > 0 && !slices.ContainsFunc(r.ByDay, func(w RRuleWeekday) bool {
+		return w.Weekday == day.Weekday() && (w.N == 0 || r.matchesOrdinal(day, w.N))
+	}) {
+		return false
+	}
+
+	return true
+}
+
+// Ordinals count within the month for FREQ=MONTHLY and for FREQ=YEARLY with BYMONTH,
+// within the year otherwise.
+func (r *RRule) matchesOrdinal(day time.Time, n int) bool {
+	position, length := day.Day(), daysIn(day.Year(), day.Month())
+	if r.Freq == FreqYearly && len(r.ByMonth) == 0 {
+		position, length = day.YearDay(), civilDate(day.Year(), time.December, 31).YearDay()
+	}
+
+	if n > 0 {
+		return (position-1)/7+1 == n
+	}
+	return -((length-position)/7 + 1) == n
+}
+
+func daysIn(year int, month time.Month) int {
+	return civilDate(year, month+1, 0).Day()
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxTagNameLength = 50
+	DefaultTagColor  = "#6b7280"
+)
+
+type Tag struct {
+	Id        uuid.UUID
+	UserId    uuid.UUID
+	Name      string
+	Color     string
+	CreatedAt time.Time
+}
+
+// Names are unique per user regardless of case, that is enforced by the database.
+// An empty color falls back to DefaultTagColor.
+func NewTag(userId uuid.UUID, name, color string) (*Tag, error) {
+	if IsUserIdEmpty(userId) {
+		return nil, ErrUserIdCannotBeEmpty
+	}
+
+	name = strings.TrimSpace(name)
+	if err := ValidateTagName(name); err != nil {
+		return nil, err
+	}
+
+	color, err := NormalizeColor(color, DefaultTagColor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tag{
+		Id:        uuid.New(),
+		UserId:    userId,
+		Name:      name,
+		Color:     color,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Commas separate the names in the tag filter of the todo list, so they can't be part of a name.
+func ValidateTagName(name string) error {
+	if name == "" {
+		return ErrEmptyTagName
+	}
+	if len(name) > MaxTagNameLength {
+		return ErrTagNameTooLong
+	}
+	if strings.Contains(name, ",") {
+		return ErrInvalidTagName
+	}
+	return nil
+}
@@ -5,13 +5,16 @@ import (
 )
 
 var (
-	RealUserId   = "8e94e3f7-8944-454b-ab6a-5ef208337e2c"
-	FakeUserId   = "121df86a-d02d-4b69-b6aa-6463df162831"
-	RealTodoId   = "b1c8f0d2-3c4e-4f5a-9b6d-7e8f9a0b1c2d"
-	FakeTodoId   = "e687f0ab-6965-4631-9e89-1ce86986fdec"
-	FakeTodoUuid = uuid.MustParse(FakeTodoId)
-	MockToken    = "mockedToken"
-	TestUser     = &User{
+	RealUserId    = "8e94e3f7-8944-454b-ab6a-5ef208337e2c"
+	FakeUserId    = "121df86a-d02d-4b69-b6aa-6463df162831"
+	SecondUserId  = "3f1a9c52-6b7d-4e2a-8c3f-9d0e1b2a4c5d"
+	RealTodoId    = "b1c8f0d2-3c4e-4f5a-9b6d-7e8f9a0b1c2d"
+	FakeTodoId    = "e687f0ab-6965-4631-9e89-1ce86986fdec"
+	FakeTodoUuid  = uuid.MustParse(FakeTodoId)
+	RealTagId     = "5d2c7a41-8e3b-4f6d-9a1c-2b4e6f8a0c3d"
+	RealProjectId = "a3f1c9e2-7b4d-4e8a-b6c5-1d2e3f4a5b6c"
+	MockToken     = "mockedToken"
+	TestUser      = &User{
 		Id:              uuid.MustParse(RealUserId),
 		FullName:        "Test User",
 		Email:           "user@user.com",
@@ -19,12 +22,32 @@ var (
 		Password:        "user1234",
 		IsEmailVerified: false,
 	}
+	SecondTestUser = &User{
+		Id:              uuid.MustParse(SecondUserId),
+		FullName:        "Second Test User",
+		Email:           "second@user.com",
+		Role:            "USER",
+		Password:        "second1234",
+		IsEmailVerified: false,
+	}
 	TestTodo = &Todo{
 		Id:        uuid.MustParse(RealTodoId),
 		UserId:    TestUser.Id,
 		Title:     "Test Todo",
 		Completed: false,
 	}
+	TestTag = &Tag{
+		Id:     uuid.MustParse(RealTagId),
+		UserId: TestUser.Id,
+		Name:   "Work",
+		Color:  "#1e90ff",
+	}
+	TestProject = &Project{
+		Id:     uuid.MustParse(RealProjectId),
+		UserId: TestUser.Id,
+		Name:   "Home",
+		Color:  DefaultProjectColor,
+	}
 
 	MockJWTTestKey = "d16fb74a2c2d3ab64d6247fdcb703d08f9f4dd86625420a3e23ea08c1deaad19"
 )
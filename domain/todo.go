@@ -1,26 +1,62 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const (
-	MaxTitleLength = 100
-	MinTitleLength = 3
+	MaxTitleLength       = 100
+	MinTitleLength       = 3
+	MaxDescriptionLength = 10000
+	MinDueAtYear         = 2000
+	MaxDueAtYear         = 2100
+	// MaxSubtaskDepth is how deep subtasks can be nested, a top-level todo has depth 0.
+	MaxSubtaskDepth = 3
 )
 
 type Todo struct {
 	UserId      uuid.UUID
 	Id          uuid.UUID
 	Title       string
+	Description string
 	Completed   bool
 	CreatedAt   time.Time
 	CompletedAt time.Time
+	DueAt       time.Time
+	Priority    Priority
+	// ProjectId is uuid.Nil for todos in the inbox.
+	ProjectId uuid.UUID
+	// ParentId is uuid.Nil for top-level todos. Subtasks belong to the project of
+	// their parent.
+	ParentId uuid.UUID
+	// Recurrence is nil for one-off todos. OccurrenceAt is the slot of the series this
+	// todo stands for, it only differs from DueAt when this occurrence was rescheduled.
+	Recurrence   *Recurrence
+	OccurrenceAt time.Time
+	// Version is the version an update expects the todo to be at, 0 for any version.
+	Version int
 }
 
-func NewTodo(userId uuid.UUID, title string) (*Todo, error) {
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities are ordered from the lowest to the highest, the index is the rank that gets stored.
+var Priorities = []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// A zero dueAt means the todo has no due date. The due date keeps the zone it was sent
+// in, see DueTimezone, so that its day ends at midnight there rather than in UTC.
+// An empty priority defaults to PriorityNone.
+func NewTodo(userId uuid.UUID, title, description string, dueAt time.Time, priority Priority) (*Todo, error) {
 
 	if IsUserIdEmpty(userId) {
 		return nil, ErrUserIdCannotBeEmpty
@@ -30,13 +66,31 @@ func NewTodo(userId uuid.UUID, title string) (*Todo, error) {
 		return nil, err
 	}
 
+	if err := ValidateDescription(description); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateDueAt(dueAt); err != nil {
+		return nil, err
+	}
+
+	if priority == "" {
+		priority = PriorityNone
+	}
+	if err := ValidatePriority(priority); err != nil {
+		return nil, err
+	}
+
 	return &Todo{
 		UserId:      userId,
 		Id:          uuid.New(),
 		Title:       title,
+		Description: description,
 		Completed:   false,
 		CreatedAt:   time.Now(),
 		CompletedAt: time.Time{},
+		DueAt:       dueAt,
+		Priority:    priority,
 	}, nil
 }
 
@@ -56,3 +110,126 @@ func ValidateTitle(title string) error {
 	}
 	return nil
 }
+
+// Description is optional and holds Markdown, so only its length is validated here.
+// It is sanitized when it gets rendered to HTML.
+func ValidateDescription(description string) error {
+	if len(description) > MaxDescriptionLength {
+		return ErrDescriptionTooLong
+	}
+	return nil
+}
+
+// Past due dates are allowed since a todo can be updated after its deadline, only
+// values that can't be a real deadline are rejected.
+func ValidateDueAt(dueAt time.Time) error {
+	if dueAt.IsZero() {
+		return nil
+	}
+	if year := dueAt.UTC().Year(); year < MinDueAtYear || year > MaxDueAtYear {
+		return ErrInvalidDueAt
+	}
+	return nil
+}
+
+// DueTimezone is the zone stored with a due date: the IANA name of its location, such
+// as "Europe/Istanbul", or the UTC offset the client sent it with, such as "+02:00".
+func DueTimezone(dueAt time.Time) string {
+	if name := dueAt.Location().String(); name != "" && name != "Local" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	if _, offset := dueAt.Zone(); offset == 0 {
+		return DefaultRecurrenceTimezone
+	}
+	return dueAt.Format("-07:00")
+}
+
+// LoadDueTimezone loads a zone written by DueTimezone, an empty name is UTC.
+func LoadDueTimezone(name string) (*time.Location, error) {
+	if !strings.HasPrefix(name, "+") && !strings.HasPrefix(name, "-") {
+		return LoadTimezone(name)
+	}
+	offset, err := time.Parse("-07:00", name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	_, seconds := offset.Zone()
+	return time.FixedZone("", seconds), nil
+}
+
+// IsAllDay tells whether the due date is at midnight in its zone, it then stands for
+// the whole day, e.g. the due:2030-06-01 of a todo.txt file.
+func IsAllDay(dueAt time.Time) bool {
+	hour, minute, second := dueAt.Clock()
+	return hour == 0 && minute == 0 && second == 0 && dueAt.Nanosecond() == 0
+}
+
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return IsOverdue(t.DueAt, t.Completed, now)
+}
+
+// IsOverdue compares the instants, except for a due date that is all day: it is only
+// overdue once its day has ended in the zone of the due date.
+func IsOverdue(dueAt time.Time, completed bool, now time.Time) bool {
+	if completed || dueAt.IsZero() {
+		return false
+	}
+	if IsAllDay(dueAt) {
+		year, month, day := dueAt.Date()
+		return !now.Before(time.Date(year, month, day+1, 0, 0, 0, 0, dueAt.Location()))
+	}
+	return dueAt.Before(now)
+}
+
+// ValidateSubtaskDepth validates the depth of a new subtask, which is one more than
+// the depth of its parent.
+func ValidateSubtaskDepth(depth int) error {
+	if depth > MaxSubtaskDepth {
+		return ErrSubtaskTooDeep
+	}
+	return nil
+}
+
+// Progress counts the completed todos of a subtree, e.g. 3 of 5 subtasks are done.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func (p Progress) Add(completed bool) Progress {
+	p.Total++
+	if completed {
+		p.Done++
+	}
+	return p
+}
+
+func (p Progress) Merge(other Progress) Progress {
+	return Progress{Done: p.Done + other.Done, Total: p.Total + other.Total}
+}
+
+func ValidatePriority(priority Priority) error {
+	if priority.Rank() < 0 {
+		return ErrInvalidPriority
+	}
+	return nil
+}
+
+// Rank returns -1 for an unknown priority.
+func (p Priority) Rank() int {
+	for i, priority := range Priorities {
+		if p == priority {
+			return i
+		}
+	}
+	return -1
+}
+
+func PriorityFromRank(rank int) (Priority, error) {
+	if rank < 0 || rank >= len(Priorities) {
+		return "", ErrInvalidPriority
+	}
+	return Priorities[rank], nil
+}
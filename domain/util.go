@@ -3,6 +3,8 @@ package domain
 import (
 	"context"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -27,3 +29,26 @@ func GetRole(ctx context.Context) string {
 func IsProdEnv() bool {
 	return os.Getenv("ENV") == "production"
 }
+
+const DefaultSearchLanguage = "simple"
+
+// SearchLanguage is the Postgres text search configuration todos are indexed with,
+// e.g. "english" for stemming. "simple" only lowercases, it suits any language.
+func SearchLanguage() string {
+	if language := os.Getenv("SEARCH_LANGUAGE"); language != "" {
+		return language
+	}
+	return DefaultSearchLanguage
+}
+
+const DefaultTrashRetentionDays = 30
+
+// TrashRetention is how long deleted todos stay in the trash before they are purged,
+// read from TRASH_RETENTION_DAYS.
+func TrashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = DefaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
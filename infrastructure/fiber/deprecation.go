@@ -0,0 +1,45 @@
+package fiber
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// toggleDeprecatedAt is when PATCH /todos/{id} stopped toggling the completion of a
+// todo, as a Structured Field Date.
+const toggleDeprecatedAt = "@1792281600"
+
+// Deprecated serves a route that is kept for existing clients. Its responses carry a
+// Deprecation header (RFC 9745) with the date of since and a Link to the route that
+// replaces it, successor returns the path of that route for the request.
+func Deprecated(since string, successor func(c *fiber.Ctx) string, handler fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", since)
+		c.Set(fiber.HeaderLink, "<"+successor(c)+`>; rel="successor-version"`)
+		return handler(c)
+	}
+}
+
+// ByContentType sends the requests whose body has the media type to handler and the
+// requests without a body or a Content-Type to fallback. Any other body is answered with
+// 415 and unsupported, so that a body of the wrong type is never taken for a request
+// without one.
+func ByContentType(mediaType string, handler, fallback fiber.Handler, unsupported error) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderContentType)
+		contentType, _, _ := strings.Cut(header, ";")
+		if strings.EqualFold(strings.TrimSpace(contentType), mediaType) {
+			return handler(c)
+		}
+		if header == "" && len(c.Body()) == 0 {
+			return fallback(c)
+		}
+		c.Set("Accept-Patch", mediaType)
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(domain.Error{
+			Message: unsupported.Error(),
+			Code:    fiber.StatusUnsupportedMediaType,
+		})
+	}
+}
@@ -3,6 +3,9 @@ package fiber
 import (
 	"context"
 	"errors"
+	"mime/multipart"
+	"reflect"
+	"strings"
 
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 
@@ -17,6 +20,13 @@ type HandlerInterface[R Request, Res Response] interface {
 	Handle(ctx context.Context, req *R) (*Res, int, error)
 }
 
+// ETagger is a response that carries the ETag of the resource it represents. Handle
+// sends it, and answers a GET whose If-None-Match holds it with 304 Not Modified.
+// Handlers read If-Match and If-None-Match like any other header, with a reqHeader tag.
+type ETagger interface {
+	ETag() string
+}
+
 func Handle[R Request, Res Response](handler HandlerInterface[R, Res], logger domain.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var req R
@@ -25,6 +35,10 @@ func Handle[R Request, Res Response](handler HandlerInterface[R, Res], logger do
 			return handleError(c, fiber.StatusBadRequest, err, logger)
 		}
 
+		if err := parseFiles(c, &req); err != nil {
+			return handleError(c, fiber.StatusBadRequest, err, logger)
+		}
+
 		if err := c.CookieParser(&req); err != nil {
 			return handleError(c, fiber.StatusBadRequest, err, logger)
 		}
@@ -67,11 +81,63 @@ func Handle[R Request, Res Response](handler HandlerInterface[R, Res], logger do
 			return c.SendStatus(code)
 		}
 
+		if tagged, ok := any(res).(ETagger); ok {
+			etag := tagged.ETag()
+			c.Set(fiber.HeaderETag, etag)
+			if (c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead) && domain.ETagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+		}
+
+		if file, ok := any(res).(*domain.File); ok {
+			return sendFile(c, code, file)
+		}
+
 		return c.Status(code).JSON(res)
 
 	}
 }
 
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// parseFiles fills the *multipart.FileHeader fields of the request with the uploaded
+// files named by their form tag, BodyParser only fills the other form values.
+func parseFiles(c *fiber.Ctx, req any) error {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return nil
+	}
+
+	v := reflect.ValueOf(req).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("form")
+		if field.Type != fileHeaderType || name == "" {
+			continue
+		}
+		form, err := c.MultipartForm()
+		if err != nil {
+			return err
+		}
+		if files := form.File[name]; len(files) > 0 {
+			v.Field(i).Set(reflect.ValueOf(files[0]))
+		}
+	}
+	return nil
+}
+
+// sendFile streams the file as a download, fasthttp closes the body once it is sent.
+func sendFile(c *fiber.Ctx, code int, file *domain.File) error {
+	c.Attachment(file.Name)
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	if file.ETag != "" {
+		c.Set(fiber.HeaderETag, file.ETag)
+	}
+	return c.Status(code).SendStream(file.Body, int(file.Size))
+}
+
 func handleError(c *fiber.Ctx, code int, err error, logger domain.Logger) error {
 	var errorType string
 	if code >= 500 {
@@ -64,25 +64,36 @@
 package fiber
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/attachment"
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/auth"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/caldav"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/comment"
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/healthcheck"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/reminder"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/tag"
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/user"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 	jwe "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/jwe"
+	localfsInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/localfs"
 	mailersendInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/mailersend"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
 	postgresInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
 	redisInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/redis"
+	s3Infra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/s3"
 	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
 	validatorInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/validator"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
 func SetupRoutes(app *fiber.App) {
@@ -93,9 +104,12 @@ func SetupRoutes(app *fiber.App) {
 		AccessTokenEncryptionKey:  "12345678901234567890123456789012",
 		RefreshTokenEncryptionKey: "12345678901234567890123456789012",
 		SecureEmailEncryptionKey:  "12345678901234567890123456789012",
+		SignedURLEncryptionKey:    "12345678901234567890123456789012",
+		FeedEncryptionKey:         "12345678901234567890123456789012",
 		AuthAccessTokenDuration:   time.Minute * 15,
 		AuthRefreshTokenDuration:  time.Hour * 24 * 30,
 		SecureEmailTokenDuration:  time.Minute * 11,
+		SignedURLTokenDuration:    time.Minute * 5,
 	})
 
 	mailersendService := mailersendInfra.NewMailerSendService(os.Getenv("MAILERSEND_API_KEY"), os.Getenv("MAILERSEND_SENDER_EMAIL"), os.Getenv("MAILERSEND_SENDER_NAME"))
@@ -105,6 +119,7 @@ func SetupRoutes(app *fiber.App) {
 	validator := validatorInfra.NewValidator(slogLogger)
 	redisClient := redisInfra.NewRedisClient(os.Getenv("REDIS_URL"))
 	fiberCookieService := NewCookieService()
+	markdownRenderer := markdownInfra.NewRenderer()
 
 	middlewareManager := NewMiddlewareManager(jweTokenService, slogLogger)
 
@@ -143,13 +158,92 @@ func SetupRoutes(app *fiber.App) {
 	resetPasswordHandler := user.NewResetPasswordHandler(postgresRepo, jweTokenService, sl, validator)
 	verifyEmailHandler := user.NewVerifyEmailHandler(postgresRepo, validator, jweTokenService)
 	sendVerificationEmailHandler := user.NewSendVerificationEmailHandler(postgresRepo, validator, jweTokenService, mailersendService)
+	createAppPasswordHandler := user.NewCreateAppPasswordHandler(postgresRepo)
+	getAppPasswordsHandler := user.NewGetAppPasswordsHandler(postgresRepo)
+	deleteAppPasswordHandler := user.NewDeleteAppPasswordHandler(postgresRepo)
 
-	createTodoHandler := todo.NewCreateTodoHandler(postgresRepo, redisClient, sl)
-	getTodoByIdHandler := todo.NewGetTodoByIdHandler(postgresRepo)
-	getTodosHandler := todo.NewGetTodosHandler(postgresRepo, redisClient, time.Minute*5)
-	updateTodoHandler := todo.NewUpdateTodoHandler(postgresRepo)
-	deleteTodoHandler := todo.NewDeleteTodoHandler(postgresRepo)
-	toggleCompletedTodoHandler := todo.NewToggleCompletedTodoHandler(postgresRepo)
+	todoRepo := todo.NewCachedTodoRepository(postgresRepo, redisClient, sl, time.Minute*5)
+
+	systemClock := domain.NewSystemClock()
+	trashRetention := domain.TrashRetention()
+	createTodoHandler := todo.NewCreateTodoHandler(todoRepo)
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(todoRepo, markdownRenderer)
+	getTodosHandler := todo.NewGetTodosHandler(todoRepo, markdownRenderer)
+	updateTodoHandler := todo.NewUpdateTodoHandler(todoRepo)
+	patchTodoHandler := todo.NewPatchTodoHandler(todoRepo)
+	deleteTodoHandler := todo.NewDeleteTodoHandler(todoRepo)
+	toggleCompletedTodoHandler := todo.NewToggleCompletedTodoHandler(todoRepo)
+	getTodoByIdForAdminHandler := todo.NewGetTodoByIdForAdminHandler(todoRepo)
+	attachTagHandler := todo.NewAttachTagHandler(todoRepo)
+	detachTagHandler := todo.NewDetachTagHandler(todoRepo)
+	moveTodoHandler := todo.NewMoveTodoHandler(todoRepo)
+	reorderTodoHandler := todo.NewReorderTodoHandler(todoRepo)
+	getProjectTodosHandler := todo.NewGetProjectTodosHandler(todoRepo, markdownRenderer)
+	previewRecurrenceHandler := todo.NewPreviewRecurrenceHandler(systemClock)
+	searchTodosHandler := todo.NewSearchTodosHandler(todoRepo)
+	batchTodosHandler := todo.NewBatchTodosHandler(todoRepo)
+	getTrashHandler := todo.NewGetTrashHandler(todoRepo, trashRetention)
+	restoreTodoHandler := todo.NewRestoreTodoHandler(todoRepo)
+	deleteTrashedTodoHandler := todo.NewDeleteTrashedTodoHandler(todoRepo)
+	emptyTrashHandler := todo.NewEmptyTrashHandler(todoRepo)
+	getTodoHistoryHandler := todo.NewGetTodoHistoryHandler(todoRepo)
+	revertTodoHandler := todo.NewRevertTodoHandler(todoRepo)
+	exportTodosHandler := todo.NewExportTodosHandler(todoRepo, systemClock, sl)
+	getFeedURLHandler := todo.NewGetFeedURLHandler(postgresRepo, jweTokenService)
+	regenerateFeedURLHandler := todo.NewRegenerateFeedURLHandler(postgresRepo, jweTokenService)
+	revokeFeedURLHandler := todo.NewRevokeFeedURLHandler(postgresRepo)
+	getCalendarFeedHandler := todo.NewGetCalendarFeedHandler(todoRepo, postgresRepo, jweTokenService, systemClock, sl)
+	importTodosHandler := todo.NewImportTodosHandler(todoRepo)
+	syncTodoTxtHandler := todo.NewSyncTodoTxtHandler(todoRepo, systemClock, sl)
+	caldavHandler := caldav.NewHandler(todoRepo, postgresRepo, sl)
+
+	tagRepo := tag.NewCachedTagRepository(postgresRepo, todoRepo)
+
+	createTagHandler := tag.NewCreateTagHandler(tagRepo)
+	getTagsHandler := tag.NewGetTagsHandler(tagRepo)
+	updateTagHandler := tag.NewUpdateTagHandler(tagRepo)
+	deleteTagHandler := tag.NewDeleteTagHandler(tagRepo)
+
+	projectRepo := project.NewCachedProjectRepository(postgresRepo, todoRepo)
+
+	createProjectHandler := project.NewCreateProjectHandler(projectRepo)
+	getProjectsHandler := project.NewGetProjectsHandler(projectRepo)
+	updateProjectHandler := project.NewUpdateProjectHandler(projectRepo)
+	deleteProjectHandler := project.NewDeleteProjectHandler(projectRepo)
+	inviteMemberHandler := project.NewInviteMemberHandler(projectRepo)
+	getMembersHandler := project.NewGetMembersHandler(projectRepo)
+	updateMemberHandler := project.NewUpdateMemberHandler(projectRepo)
+	removeMemberHandler := project.NewRemoveMemberHandler(projectRepo)
+	getInvitationsHandler := project.NewGetInvitationsHandler(projectRepo)
+	acceptInvitationHandler := project.NewAcceptInvitationHandler(projectRepo)
+	deleteInvitationHandler := project.NewDeleteInvitationHandler(projectRepo)
+
+	createCommentHandler := comment.NewCreateCommentHandler(postgresRepo)
+	getCommentsHandler := comment.NewGetCommentsHandler(postgresRepo)
+	updateCommentHandler := comment.NewUpdateCommentHandler(postgresRepo)
+	deleteCommentHandler := comment.NewDeleteCommentHandler(postgresRepo)
+
+	blobStore := newBlobStore()
+	uploadAttachmentHandler := attachment.NewUploadAttachmentHandler(postgresRepo, blobStore, jweTokenService, sl)
+	getAttachmentsHandler := attachment.NewGetAttachmentsHandler(postgresRepo, jweTokenService)
+	downloadAttachmentHandler := attachment.NewDownloadAttachmentHandler(postgresRepo, blobStore, jweTokenService)
+	deleteAttachmentHandler := attachment.NewDeleteAttachmentHandler(postgresRepo)
+
+	createReminderHandler := reminder.NewCreateReminderHandler(postgresRepo, systemClock)
+	getRemindersHandler := reminder.NewGetRemindersHandler(postgresRepo)
+	deleteReminderHandler := reminder.NewDeleteReminderHandler(postgresRepo)
+
+	reminderScheduler := reminder.NewScheduler(postgresRepo, mailersendService, systemClock, sl, time.Second*30)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	go reminderScheduler.Start(schedulerCtx)
+	trashPurger := todo.NewTrashPurger(postgresRepo, systemClock, sl, trashRetention, time.Hour)
+	go trashPurger.Start(schedulerCtx)
+	blobJanitor := attachment.NewBlobJanitor(postgresRepo, blobStore, sl, time.Minute)
+	go blobJanitor.Start(schedulerCtx)
+	app.Hooks().OnShutdown(func() error {
+		stopScheduler()
+		return nil
+	})
 
 	app.Get("/healthcheck", Handle(healthcheckHandler, sl))
 	app.Use(contextMiddleware)
@@ -173,18 +267,97 @@ func SetupRoutes(app *fiber.App) {
 	usersApp.Patch("/account", Handle(updateFullNameHandler, sl))
 	usersApp.Patch("/password", Handle(updatePasswordHandler, sl))
 	usersApp.Post("/send-verification-email", Handle(sendVerificationEmailHandler, sl))
+	usersApp.Post("/app-passwords", Handle(createAppPasswordHandler, sl))
+	usersApp.Get("/app-passwords", Handle(getAppPasswordsHandler, sl))
+	usersApp.Delete("/app-passwords/:id", Handle(deleteAppPasswordHandler, sl))
 
 	usersAdminApp := adminApp.Group("/users")
 	usersAdminApp.Get("/", Handle(getUsersHandler, sl))
 	usersAdminApp.Get("/:id", Handle(getUserHandler, sl))
 
+	todosAdminApp := adminApp.Group("/todos")
+	todosAdminApp.Get("/:id", Handle(getTodoByIdForAdminHandler, sl))
+
 	todosApp := app.Group("/todos", middlewareManager.AuthMiddleware)
 	todosApp.Post("/", Handle(createTodoHandler, sl))
+	todosApp.Post("/batch", Handle(batchTodosHandler, sl))
+	todosApp.Get("/export", Handle(exportTodosHandler, sl))
+	todosApp.Post("/import", Handle(importTodosHandler, sl))
+	todosApp.Put("/todotxt", Handle(syncTodoTxtHandler, sl))
+	todosApp.Get("/feed", Handle(getFeedURLHandler, sl))
+	todosApp.Post("/feed", Handle(regenerateFeedURLHandler, sl))
+	todosApp.Delete("/feed", Handle(revokeFeedURLHandler, sl))
+	todosApp.Get("/recurrence/preview", Handle(previewRecurrenceHandler, sl))
+	todosApp.Get("/search", Handle(searchTodosHandler, sl))
+	todosApp.Get("/trash", Handle(getTrashHandler, sl))
+	todosApp.Delete("/trash", Handle(emptyTrashHandler, sl))
+	todosApp.Delete("/trash/:id", Handle(deleteTrashedTodoHandler, sl))
 	todosApp.Get("/:id", Handle(getTodoByIdHandler, sl))
 	todosApp.Get("/", Handle(getTodosHandler, sl))
 	todosApp.Put("/:id", Handle(updateTodoHandler, sl))
 	todosApp.Delete("/:id", Handle(deleteTodoHandler, sl))
-	todosApp.Patch("/:id", Handle(toggleCompletedTodoHandler, sl))
+	// a PATCH without a body comes from a client that still expects a toggle
+	todosApp.Patch("/:id", ByContentType(todo.MergePatchContentType,
+		Handle(patchTodoHandler, sl),
+		Deprecated(toggleDeprecatedAt, func(c *fiber.Ctx) string { return "/todos/" + c.Params("id") + "/toggle" },
+			Handle(toggleCompletedTodoHandler, sl)),
+		domain.ErrUnsupportedPatch,
+	))
+	todosApp.Post("/:id/toggle", Handle(toggleCompletedTodoHandler, sl))
+	todosApp.Post("/:id/restore", Handle(restoreTodoHandler, sl))
+	todosApp.Get("/:id/history", Handle(getTodoHistoryHandler, sl))
+	todosApp.Post("/:id/revert/:revision", Handle(revertTodoHandler, sl))
+	todosApp.Post("/:id/reminders", Handle(createReminderHandler, sl))
+	todosApp.Get("/:id/reminders", Handle(getRemindersHandler, sl))
+	todosApp.Delete("/:id/reminders/:reminderId", Handle(deleteReminderHandler, sl))
+	todosApp.Post("/:id/comments", Handle(createCommentHandler, sl))
+	todosApp.Get("/:id/comments", Handle(getCommentsHandler, sl))
+	todosApp.Put("/:id/comments/:commentId", Handle(updateCommentHandler, sl))
+	todosApp.Delete("/:id/comments/:commentId", Handle(deleteCommentHandler, sl))
+	todosApp.Post("/:id/attachments", Handle(uploadAttachmentHandler, sl))
+	todosApp.Get("/:id/attachments", Handle(getAttachmentsHandler, sl))
+	todosApp.Delete("/:id/attachments/:attachmentId", Handle(deleteAttachmentHandler, sl))
+	todosApp.Post("/:id/tags", Handle(attachTagHandler, sl))
+	todosApp.Delete("/:id/tags/:tagId", Handle(detachTagHandler, sl))
+	todosApp.Put("/:id/project", Handle(moveTodoHandler, sl))
+	todosApp.Post("/:id/move", Handle(reorderTodoHandler, sl))
+
+	// the token of a signed link replaces the authentication
+	attachmentsApp := app.Group("/attachments")
+	attachmentsApp.Get("/:id", Handle(downloadAttachmentHandler, sl))
+
+	// calendar apps cannot send a bearer token, the link of the feed is its credential
+	feedsApp := app.Group("/feeds")
+	feedsApp.Get("/:token.ics", Handle(getCalendarFeedHandler, sl))
+
+	// CalDAV clients sign in with HTTP Basic authentication and an app password
+	app.All("/.well-known/caldav", func(c *fiber.Ctx) error {
+		return c.Redirect(caldav.Prefix, fiber.StatusMovedPermanently)
+	})
+	app.All("/caldav", adaptor.HTTPHandler(caldavHandler))
+	app.All("/caldav/*", adaptor.HTTPHandler(caldavHandler))
+
+	tagsApp := app.Group("/tags", middlewareManager.AuthMiddleware)
+	tagsApp.Post("/", Handle(createTagHandler, sl))
+	tagsApp.Get("/", Handle(getTagsHandler, sl))
+	tagsApp.Put("/:id", Handle(updateTagHandler, sl))
+	tagsApp.Delete("/:id", Handle(deleteTagHandler, sl))
+
+	projectsApp := app.Group("/projects", middlewareManager.AuthMiddleware)
+	projectsApp.Post("/", Handle(createProjectHandler, sl))
+	projectsApp.Get("/", Handle(getProjectsHandler, sl))
+	projectsApp.Put("/:id", Handle(updateProjectHandler, sl))
+	projectsApp.Delete("/:id", Handle(deleteProjectHandler, sl))
+	projectsApp.Get("/:id/todos", Handle(getProjectTodosHandler, sl))
+	projectsApp.Post("/:id/invitations", Handle(inviteMemberHandler, sl))
+	projectsApp.Get("/:id/members", Handle(getMembersHandler, sl))
+	projectsApp.Put("/:id/members/:userId", Handle(updateMemberHandler, sl))
+	projectsApp.Delete("/:id/members/:userId", Handle(removeMemberHandler, sl))
+
+	invitationsApp := app.Group("/invitations", middlewareManager.AuthMiddleware)
+	invitationsApp.Get("/", Handle(getInvitationsHandler, sl))
+	invitationsApp.Post("/:id/accept", Handle(acceptInvitationHandler, sl))
+	invitationsApp.Delete("/:id", Handle(deleteInvitationHandler, sl))
 
 	if !domain.IsProdEnv() {
 		app.Get("/swagger/*", fiberSwagger.WrapHandler)
@@ -204,3 +377,23 @@ func SetupRoutes(app *fiber.App) {
 		})
 	})
 }
+
+// newBlobStore keeps the attachments on the local disk, under BLOB_DIR, unless
+// BLOB_STORE is "s3".
+func newBlobStore() domain.BlobStore {
+	if os.Getenv("BLOB_STORE") == "s3" {
+		return s3Infra.NewBlobStore(&s3Infra.Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+	}
+
+	dir := os.Getenv("BLOB_DIR")
+	if dir == "" {
+		dir = "data/blobs"
+	}
+	return localfsInfra.NewBlobStore(dir)
+}
@@ -12,15 +12,21 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/caldav"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
 func SetupServer() *fiber.App {
+	// the timeouts and the body limit leave room for uploading and downloading attachments
 	app := fiber.New(fiber.Config{
 		IdleTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		BodyLimit:    domain.MaxAttachmentSize + 1<<20,
 		Concurrency:  256 * 1024,
 		AppName:      "Advanced Todo API",
+		// CalDAV clients discover and list the todos with WebDAV methods
+		RequestMethods: append(append([]string{}, fiber.DefaultMethods...), caldav.Methods...),
 	})
 
 	registerMiddlewares(app)
@@ -39,7 +45,8 @@ func registerMiddlewares(app *fiber.App) {
 		AllowOrigins:     allowOrigins,
 		AllowCredentials: true,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Content-Type,Authorization,X-Requested-With",
+		AllowHeaders:     "Content-Type,Authorization,X-Requested-With,If-Match,If-None-Match",
+		ExposeHeaders:    "ETag,Deprecation,Link",
 	}))
 
 	app.Use(recover.New())
@@ -10,24 +10,32 @@ type Config struct {
 	AccessTokenEncryptionKey  string
 	RefreshTokenEncryptionKey string
 	SecureEmailEncryptionKey  string
+	SignedURLEncryptionKey    string
+	FeedEncryptionKey         string
 
 	AuthAccessTokenDuration  time.Duration
 	AuthRefreshTokenDuration time.Duration
 	SecureEmailTokenDuration time.Duration
+	SignedURLTokenDuration   time.Duration
 }
 
 type Service struct {
 	accessTokenEncryptionKey  []byte
 	refreshTokenEncryptionKey []byte
 	secureEmailEncryptionKey  []byte
+	signedURLEncryptionKey    []byte
+	feedEncryptionKey         []byte
 
 	accessTokenEncrypter  jose.Encrypter
 	refreshTokenEncrypter jose.Encrypter
 	secureEmailEncrypter  jose.Encrypter
+	signedURLEncrypter    jose.Encrypter
+	feedEncrypter         jose.Encrypter
 
 	authAccessTokenDuration  time.Duration
 	authRefreshTokenDuration time.Duration
 	secureEmailTokenDuration time.Duration
+	signedURLTokenDuration   time.Duration
 }
 
 type AuthClaims struct {
@@ -43,6 +51,19 @@ type EmailClaims struct {
 	Exp   int64  `json:"exp"`
 }
 
+type SignedURLClaims struct {
+	Resource string `json:"resource"`
+	Iat      int64  `json:"iat"`
+	Exp      int64  `json:"exp"`
+}
+
+// FeedClaims have no expiration, a feed link stays valid until the feed is replaced.
+type FeedClaims struct {
+	UserID string `json:"userID"`
+	FeedID string `json:"feedID"`
+	Iat    int64  `json:"iat"`
+}
+
 func NewJWETokenService(config *Config) *Service {
 
 	if !config.hasProperEncryptionKeys() {
@@ -83,21 +104,48 @@ func NewJWETokenService(config *Config) *Service {
 		panic(err)
 	}
 
+	signedURLEncrypter, err := jose.NewEncrypter(
+		jose.A256GCM,
+		jose.Recipient{Algorithm: jose.DIRECT, Key: []byte(config.SignedURLEncryptionKey)},
+		(&jose.EncrypterOptions{}).
+			WithType("JWE").
+			WithContentType("JWT"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	feedEncrypter, err := jose.NewEncrypter(
+		jose.A256GCM,
+		jose.Recipient{Algorithm: jose.DIRECT, Key: []byte(config.FeedEncryptionKey)},
+		(&jose.EncrypterOptions{}).
+			WithType("JWE").
+			WithContentType("JWT"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
 	return &Service{
 		accessTokenEncryptionKey:  []byte(config.AccessTokenEncryptionKey),
 		refreshTokenEncryptionKey: []byte(config.RefreshTokenEncryptionKey),
 		secureEmailEncryptionKey:  []byte(config.SecureEmailEncryptionKey),
+		signedURLEncryptionKey:    []byte(config.SignedURLEncryptionKey),
+		feedEncryptionKey:         []byte(config.FeedEncryptionKey),
 
 		secureEmailEncrypter:  secureEmailEncrypter,
 		accessTokenEncrypter:  accessTokenEncrypter,
 		refreshTokenEncrypter: refreshTokenEncrypter,
+		signedURLEncrypter:    signedURLEncrypter,
+		feedEncrypter:         feedEncrypter,
 
 		authAccessTokenDuration:  config.AuthAccessTokenDuration,
 		authRefreshTokenDuration: config.AuthRefreshTokenDuration,
 		secureEmailTokenDuration: config.SecureEmailTokenDuration,
+		signedURLTokenDuration:   config.SignedURLTokenDuration,
 	}
 }
 
 func (c Config) hasProperEncryptionKeys() bool {
-	return is32ByteKey(c.AccessTokenEncryptionKey) && is32ByteKey(c.RefreshTokenEncryptionKey) && is32ByteKey(c.SecureEmailEncryptionKey)
+	return is32ByteKey(c.AccessTokenEncryptionKey) && is32ByteKey(c.RefreshTokenEncryptionKey) && is32ByteKey(c.SecureEmailEncryptionKey) && is32ByteKey(c.SignedURLEncryptionKey) && is32ByteKey(c.FeedEncryptionKey)
 }
@@ -0,0 +1,36 @@
+package jwe
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// These functions are used for the calendar feed links, the token names the user and
+// the feed it was minted for.
+
+func (s *Service) GenerateFeedToken(userId, feedId uuid.UUID) (string, error) {
+	claims := FeedClaims{
+		UserID: userId.String(),
+		FeedID: feedId.String(),
+		Iat:    time.Now().Unix(),
+	}
+	return encryptClaims(s.feedEncrypter, claims)
+}
+
+func (s *Service) ValidateFeedToken(tokenString string) (uuid.UUID, uuid.UUID, error) {
+	var claims FeedClaims
+	if err := decryptClaims(tokenString, s.feedEncryptionKey, &claims); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	userId, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidToken
+	}
+	feedId, err := uuid.Parse(claims.FeedID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidToken
+	}
+	return userId, feedId, nil
+}
@@ -0,0 +1,30 @@
+package jwe
+
+import (
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// These functions are used for the links that download attachments, the token names
+// the resource it grants access to.
+
+func (s *Service) GenerateSignedURLToken(resource string) (string, error) {
+	claims := SignedURLClaims{
+		Resource: resource,
+		Iat:      time.Now().Unix(),
+		Exp:      time.Now().Add(s.signedURLTokenDuration).Unix(),
+	}
+	return encryptClaims(s.signedURLEncrypter, claims)
+}
+
+func (s *Service) ValidateSignedURLToken(tokenString string) (string, error) {
+	var claims SignedURLClaims
+	if err := decryptClaims(tokenString, s.signedURLEncryptionKey, &claims); err != nil {
+		return "", err
+	}
+	if isExpired(claims.Exp) {
+		return "", domain.ErrExpiredToken
+	}
+	return claims.Resource, nil
+}
@@ -111,3 +111,27 @@ func (m *MailerSendService) SendVerificationEmail(ctx context.Context, claims *d
 	_, err := m.client.Email.Send(ctx, message)
 	return err
 }
+
+func (m *MailerSendService) SendReminderEmail(ctx context.Context, claims *domain.EmailClaims) error {
+
+	from := mailersend.From{
+		Name:  m.SenderName,
+		Email: m.SenderEmail,
+	}
+
+	recipients := []mailersend.Recipient{
+		{
+			Name:  claims.Name,
+			Email: claims.To,
+		},
+	}
+
+	message := m.client.Email.NewMessage()
+	message.SetFrom(from)
+	message.SetRecipients(recipients)
+	message.SetSubject(claims.Subject)
+	message.SetHTML(claims.HTML)
+
+	_, err := m.client.Email.Send(ctx, message)
+	return err
+}
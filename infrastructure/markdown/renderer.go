@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+type Renderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+// NewRenderer renders GitHub flavored Markdown. Raw HTML in the input is not trusted:
+// the output always goes through bluemonday's UGC policy before it leaves the server.
+func NewRenderer() *Renderer {
+	policy := bluemonday.UGCPolicy()
+	// keep the read-only checkboxes of GFM task lists, which are common in todo notes
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return &Renderer{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+		),
+		policy: policy,
+	}
+}
+
+func (r *Renderer) Render(markdown string) (string, error) {
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
+	}
+	return r.policy.Sanitize(buf.String()), nil
+}
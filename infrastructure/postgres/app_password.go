@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/user"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+func (r *Repository) CreateAppPassword(ctx context.Context, p *domain.AppPassword) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO app_passwords (id, user_id, name, password_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, p.Id, p.UserId, p.Name, p.Hash, p.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetAppPasswords(ctx context.Context, userId uuid.UUID) (user.GetAppPasswordsResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, created_at, last_used_at
+		FROM app_passwords
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appPasswords := user.GetAppPasswordsResponse{}
+	for rows.Next() {
+		var p user.AppPassword
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(&p.Id, &p.Name, &p.CreatedAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+		p.LastUsedAt = lastUsedAt.Time
+		appPasswords = append(appPasswords, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return appPasswords, nil
+}
+
+func (r *Repository) DeleteAppPassword(ctx context.Context, id, userId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM app_passwords WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrAppPasswordNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) AuthenticateAppPassword(ctx context.Context, email, hash string) (uuid.UUID, string, error) {
+	var userId uuid.UUID
+	var role string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE app_passwords p SET last_used_at = NOW()
+		FROM users u
+		WHERE u.id = p.user_id AND u.email = $1 AND p.password_hash = $2
+		RETURNING u.id, u.role
+	`, email, hash).Scan(&userId, &role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, "", domain.ErrInvalidCredentials
+		}
+		return uuid.Nil, "", err
+	}
+	return userId, role, nil
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/attachment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+func (r *Repository) CreateAttachment(ctx context.Context, a *domain.Attachment) error {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO todo_attachments (id, todo_id, uploader_id, name, content_type, size, blob_key, created_at)
+		SELECT $1, id, $3, $4, $5, $6, $7, $8 FROM todos
+		WHERE id = $2 AND deleted_at IS NULL AND `+todoAccess("", 3, domain.ProjectEditor)+`
+	`, a.Id, a.TodoId, a.UploaderId, a.Name, a.ContentType, a.Size, a.BlobKey(), a.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return todoWriteError(ctx, r.db, a.TodoId, a.UploaderId)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetAttachments(ctx context.Context, todoId, userId uuid.UUID) (*attachment.GetAttachmentsResponse, error) {
+	if err := checkTodoViewable(ctx, r.db, todoId, userId); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, content_type, size, uploader_id, created_at
+		FROM todo_attachments
+		WHERE todo_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, todoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := attachment.GetAttachmentsResponse{}
+	for rows.Next() {
+		var resp attachment.Attachment
+		var uploaderId uuid.NullUUID
+		if err := rows.Scan(&resp.Id, &resp.Name, &resp.ContentType, &resp.Size, &uploaderId, &resp.CreatedAt); err != nil {
+			return nil, err
+		}
+		resp.UploaderId = uuidPtr(uploaderId)
+		attachments = append(attachments, resp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &attachments, nil
+}
+
+func (r *Repository) GetAttachment(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	var a domain.Attachment
+	var uploaderId uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, `
+		SELECT a.id, a.todo_id, a.uploader_id, a.name, a.content_type, a.size, a.created_at
+		FROM todo_attachments a
+		JOIN todos t ON t.id = a.todo_id
+		WHERE a.id = $1 AND t.deleted_at IS NULL
+	`, id).Scan(&a.Id, &a.TodoId, &uploaderId, &a.Name, &a.ContentType, &a.Size, &a.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	a.UploaderId = uploaderId.UUID
+	return &a, nil
+}
+
+// The blob is queued for deletion by the trigger on todo_attachments.
+func (r *Repository) DeleteAttachment(ctx context.Context, id, todoId, userId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM todo_attachments a
+		USING todos t
+		WHERE a.id = $1 AND a.todo_id = $2 AND t.id = a.todo_id AND t.deleted_at IS NULL AND `+todoAccess("t.", 3, domain.ProjectEditor)+`
+	`, id, todoId, userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows > 0 {
+		return nil
+	}
+
+	var editable bool
+	err = r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`)
+	`, todoId, userId).Scan(&editable)
+	if err != nil {
+		return err
+	}
+	if !editable {
+		return todoWriteError(ctx, r.db, todoId, userId)
+	}
+	return domain.ErrAttachmentNotFound
+}
+
+func (r *Repository) GetOrphanedBlobKeys(ctx context.Context, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT blob_key FROM orphaned_blobs ORDER BY created_at ASC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *Repository) ForgetOrphanedBlobKeys(ctx context.Context, keys []string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM orphaned_blobs WHERE blob_key = ANY($1)`, pq.Array(keys))
+	return err
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// ApplyBatch runs every operation in one transaction. Outside of atomic mode each
+// operation runs in its own savepoint, so that a failing one is rolled back on its own
+// and the transaction can go on with the next.
+func (r *Repository) ApplyBatch(ctx context.Context, userId uuid.UUID, ops []todo.TodoOperation, atomic bool) ([]error, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollbackTx(tx)
+
+	// creating todos, completing recurring ones and moving them adds todos at the end of
+	// a list: the inbox of the user, a project or the list of a todo
+	orderIds := []uuid.UUID{userId}
+	var todoIds []uuid.UUID
+	for _, op := range ops {
+		switch op.Type {
+		case todo.OpCreate:
+			if op.Todo.ProjectId != uuid.Nil {
+				orderIds = append(orderIds, op.Todo.ProjectId)
+			}
+			if op.Todo.ParentId != uuid.Nil {
+				todoIds = append(todoIds, op.Todo.ParentId)
+			}
+		case todo.OpMove:
+			if op.ProjectId != uuid.Nil {
+				orderIds = append(orderIds, op.ProjectId)
+			}
+			todoIds = append(todoIds, op.Id)
+		default:
+			todoIds = append(todoIds, op.Id)
+		}
+	}
+	if err := lockTodoOrders(ctx, tx, orderIds, todoIds); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		if !atomic {
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_operation`); err != nil {
+				return nil, err
+			}
+		}
+
+		errs[i] = applyOperation(ctx, tx, userId, op)
+		if errs[i] == nil {
+			if !atomic {
+				if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_operation`); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if atomic {
+			return errs, nil
+		}
+		if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_operation`); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+func applyOperation(ctx context.Context, q querier, userId uuid.UUID, op todo.TodoOperation) error {
+	switch op.Type {
+	case todo.OpCreate:
+		if err := createTodo(ctx, q, op.Todo); err != nil {
+			return err
+		}
+		return attachTags(ctx, q, userId, []uuid.UUID{op.Todo.Id}, map[uuid.UUID][]string{op.Todo.Id: op.Tags})
+	case todo.OpUpdateTitle:
+		return updateTodoTitle(ctx, q, op.Id, userId, op.Title)
+	case todo.OpUpdate:
+		if err := updateTodoFields(ctx, q, op.Id, userId, op.Title, op.DueAt, op.Priority); err != nil {
+			return err
+		}
+		return setTodoTags(ctx, q, userId, op.Id, op.Tags)
+	case todo.OpSetCompleted:
+		return setTodoCompleted(ctx, q, op.Id, userId, op.Completed)
+	case todo.OpDelete:
+		return deleteTodo(ctx, q, op.Id, userId, op.Version)
+	case todo.OpMove:
+		return moveTodo(ctx, q, op.Id, userId, op.ProjectId)
+	}
+	return domain.ErrInvalidBatchOperation
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/comment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+func (r *Repository) CreateComment(ctx context.Context, c *domain.Comment) error {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO todo_comments (id, todo_id, author_id, body, created_at)
+		SELECT $1, id, $3, $4, $5 FROM todos
+		WHERE id = $2 AND deleted_at IS NULL AND `+todoAccess("", 3, domain.ProjectViewer)+`
+	`, c.Id, c.TodoId, c.AuthorId, c.Body, c.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrTodoNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) GetComments(ctx context.Context, todoId, userId uuid.UUID) (*comment.GetCommentsResponse, error) {
+	if err := checkTodoViewable(ctx, r.db, todoId, userId); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.body, c.author_id, COALESCE(u.fullname, ''), c.created_at, c.updated_at
+		FROM todo_comments c
+		LEFT JOIN users u ON u.id = c.author_id
+		WHERE c.todo_id = $1
+		ORDER BY c.created_at ASC, c.id ASC
+	`, todoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := comment.GetCommentsResponse{}
+	for rows.Next() {
+		var resp comment.Comment
+		var authorId uuid.NullUUID
+		var authorName string
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&resp.Id, &resp.Body, &authorId, &authorName, &resp.CreatedAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if authorId.Valid {
+			resp.Author = &comment.CommentAuthor{Id: authorId.UUID, FullName: authorName}
+		}
+		if updatedAt.Valid {
+			resp.UpdatedAt = &updatedAt.Time
+		}
+		comments = append(comments, resp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &comments, nil
+}
+
+func (r *Repository) UpdateComment(ctx context.Context, id, todoId, userId uuid.UUID, asAdmin bool, body string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := lockCommentForWrite(ctx, tx, id, todoId, userId, asAdmin); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE todo_comments SET body = $1, updated_at = NOW() WHERE id = $2`, body, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) DeleteComment(ctx context.Context, id, todoId, userId uuid.UUID, asAdmin bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := lockCommentForWrite(ctx, tx, id, todoId, userId, asAdmin); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todo_comments WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkTodoViewable reports whether the user can view the todo.
+func checkTodoViewable(ctx context.Context, q querier, todoId, userId uuid.UUID) error {
+	var viewable bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`)
+	`, todoId, userId).Scan(&viewable)
+	if err != nil {
+		return err
+	}
+	if !viewable {
+		return domain.ErrTodoNotFound
+	}
+	return nil
+}
+
+// lockCommentForWrite locks the comment and checks that the user may change it. The
+// todo is checked first so that a user who cannot view it learns nothing about its
+// comments.
+func lockCommentForWrite(ctx context.Context, tx *sql.Tx, id, todoId, userId uuid.UUID, asAdmin bool) error {
+	if !asAdmin {
+		if err := checkTodoViewable(ctx, tx, todoId, userId); err != nil {
+			return err
+		}
+	}
+
+	var authorId uuid.NullUUID
+	err := tx.QueryRowContext(ctx, `
+		SELECT c.author_id
+		FROM todo_comments c
+		JOIN todos t ON t.id = c.todo_id
+		WHERE c.id = $1 AND c.todo_id = $2 AND t.deleted_at IS NULL
+		FOR UPDATE OF c
+	`, id, todoId).Scan(&authorId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrCommentNotFound
+		}
+		return err
+	}
+
+	if !asAdmin && (!authorId.Valid || authorId.UUID != userId) {
+		return domain.ErrNotCommentAuthor
+	}
+	return nil
+}
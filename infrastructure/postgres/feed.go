@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+func (r *Repository) GetCalendarFeedId(ctx context.Context, userId uuid.UUID) (uuid.UUID, error) {
+	var feedId uuid.NullUUID
+	if err := r.db.QueryRowContext(ctx, `SELECT calendar_feed_id FROM users WHERE id = $1`, userId).Scan(&feedId); err != nil {
+		if err == sql.ErrNoRows {
+			// the feed of a deleted account is gone with it
+			return uuid.Nil, nil
+		}
+		return uuid.Nil, err
+	}
+	return feedId.UUID, nil
+}
+
+func (r *Repository) SetCalendarFeedId(ctx context.Context, userId, feedId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET calendar_feed_id = $1 WHERE id = $2`, nullUUID(feedId), userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// exportedTodoColumns are the columns scanExportedTodo reads, of the todos t. An export
+// only holds the todos of the user, so the tags are the ones t.user_id put on them.
+const exportedTodoColumns = `
+	t.id, t.title, t.description, t.completed, t.priority, t.due_at, t.due_timezone, t.created_at, t.completed_at,
+	t.project_id, t.parent_id,
+	ARRAY(SELECT tg.name FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.todo_id = t.id AND tg.user_id = t.user_id ORDER BY tg.name),
+	COALESCE((SELECT p.name FROM projects p WHERE p.id = t.project_id), ''),
+	t.version`
+
+func scanExportedTodo(row interface{ Scan(...any) error }) (*todo.ExportedTodo, error) {
+	var exported todo.ExportedTodo
+	var priority int
+	var dueAt, completedAt sql.NullTime
+	var dueZone sql.NullString
+	var projectId, parentId uuid.NullUUID
+	if err := row.Scan(&exported.Id, &exported.Title, &exported.Description, &exported.Completed, &priority, &dueAt, &dueZone,
+		&exported.CreatedAt, &completedAt, &projectId, &parentId, pq.Array(&exported.Tags), &exported.Project, &exported.Version); err != nil {
+		return nil, err
+	}
+	var err error
+	if exported.Priority, err = domain.PriorityFromRank(priority); err != nil {
+		return nil, err
+	}
+	if dueAt.Valid {
+		due := scanDueAt(dueAt, dueZone)
+		exported.DueAt = &due
+	}
+	exported.CompletedAt = timePtr(completedAt)
+	exported.ProjectId = uuidPtr(projectId)
+	exported.ParentId = uuidPtr(parentId)
+	return &exported, nil
+}
+
+// ExportTodos iterates the rows as they arrive from the database, so an export of any
+// size only keeps one todo in memory.
+func (r *Repository) ExportTodos(ctx context.Context, userId uuid.UUID, fn func(todo.ExportedTodo) error) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+exportedTodoColumns+`
+		FROM todos t
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.project_id ASC NULLS FIRST, t.position ASC NULLS LAST, t.created_at ASC, t.id ASC
+	`, userId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		exported, err := scanExportedTodo(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*exported); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *Repository) ExportTodo(ctx context.Context, id, userId uuid.UUID) (*todo.ExportedTodo, error) {
+	exported, err := scanExportedTodo(r.db.QueryRowContext(ctx, `
+		SELECT `+exportedTodoColumns+`
+		FROM todos t
+		WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
+	`, id, userId))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTodoNotFound
+	}
+	return exported, err
+}
+
+// ImportTodos copies the todos into the table with COPY rather than inserting them one
+// by one, the positions are handed out upfront while the lists they land in are locked.
+// The project of a subtask is worked out here, as COPY cannot look up its parent.
+func (r *Repository) ImportTodos(ctx context.Context, userId uuid.UUID, todos []*domain.Todo, tags map[uuid.UUID][]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	imported := make(map[uuid.UUID]*domain.Todo, len(todos))
+	for _, t := range todos {
+		imported[t.Id] = t
+	}
+
+	// the subtasks of existing todos, whose ancestors change version
+	var attached []uuid.UUID
+	projects := make(map[uuid.UUID]bool)
+	parentProjects := make(map[uuid.UUID]uuid.UUID)
+	for _, t := range todos {
+		switch {
+		case t.ParentId != uuid.Nil:
+			if _, ok := imported[t.ParentId]; ok {
+				continue
+			}
+			attached = append(attached, t.Id)
+			if _, ok := parentProjects[t.ParentId]; ok {
+				continue
+			}
+			if err := checkParentWritable(ctx, tx, t.ParentId, userId); err != nil {
+				return err
+			}
+			var projectId uuid.NullUUID
+			if err := tx.QueryRowContext(ctx, `SELECT project_id FROM todos WHERE id = $1`, t.ParentId).Scan(&projectId); err != nil {
+				return err
+			}
+			parentProjects[t.ParentId] = projectId.UUID
+		case t.ProjectId != uuid.Nil && !projects[t.ProjectId]:
+			if err := checkProjectWritable(ctx, tx, t.ProjectId, userId); err != nil {
+				return err
+			}
+			projects[t.ProjectId] = true
+		}
+	}
+	var projectOf func(t *domain.Todo) uuid.UUID
+	projectOf = func(t *domain.Todo) uuid.UUID {
+		if t.ParentId == uuid.Nil {
+			return t.ProjectId
+		}
+		if parent, ok := imported[t.ParentId]; ok {
+			return projectOf(parent)
+		}
+		return parentProjects[t.ParentId]
+	}
+
+	// a todo is appended to its project, or to the inbox of the user
+	orderOf := func(t *domain.Todo) uuid.UUID {
+		if projectId := projectOf(t); projectId != uuid.Nil {
+			return projectId
+		}
+		return userId
+	}
+	orderIds := []uuid.UUID{userId}
+	for projectId := range projects {
+		orderIds = append(orderIds, projectId)
+	}
+	for _, projectId := range parentProjects {
+		if projectId != uuid.Nil {
+			orderIds = append(orderIds, projectId)
+		}
+	}
+	if err := lockTodoOrders(ctx, tx, orderIds, nil); err != nil {
+		return err
+	}
+	positions := make(map[uuid.UUID]string)
+	for _, t := range todos {
+		orderId := orderOf(t)
+		if _, ok := positions[orderId]; ok {
+			continue
+		}
+		if positions[orderId], err = lastTodoPosition(ctx, tx, orderId); err != nil {
+			return err
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("todos",
+		"user_id", "id", "title", "description", "completed", "created_at", "completed_at", "due_at", "due_timezone", "priority",
+		"project_id", "parent_id", "position"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, t := range todos {
+		orderId := orderOf(t)
+		position, err := domain.PositionBetween(positions[orderId], "")
+		if err != nil {
+			return err
+		}
+		positions[orderId] = position
+		// created_at and completed_at have no time zone, they are stored in UTC
+		if _, err := stmt.ExecContext(ctx, userId, t.Id, t.Title, t.Description, t.Completed, t.CreatedAt.UTC(),
+			nullTime(t.CompletedAt.UTC()), nullTime(t.DueAt), dueTimezone(t.DueAt), t.Priority.Rank(),
+			nullUUID(projectOf(t)), nullUUID(t.ParentId), position); err != nil {
+			return err
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23503":
+				// the project or a parent has been deleted in the meantime
+				switch pqErr.Constraint {
+				case "todos_project_id_fkey":
+					return domain.ErrProjectNotFound
+				case "todos_parent_id_fkey":
+					return domain.ErrParentTodoNotFound
+				}
+				return domain.ErrUserNotFound
+			case "23505":
+				return domain.ErrTodoAlreadyExists
+			}
+		}
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, len(todos))
+	for i, t := range todos {
+		ids[i] = t.Id
+	}
+	if err := attachTags(ctx, tx, userId, ids, tags); err != nil {
+		return err
+	}
+	if err := bumpAncestorVersions(ctx, tx, attached); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// attachTags attaches the tags to the todos by name, regardless of case. The tags the
+// user does not have yet are created with the default color, named as they are first
+// written.
+func attachTags(ctx context.Context, q querier, userId uuid.UUID, ids []uuid.UUID, tags map[uuid.UUID][]string) error {
+	var todoIds []uuid.UUID
+	var names []string
+	var newIds []uuid.UUID
+	var newNames []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		for _, name := range tags[id] {
+			todoIds = append(todoIds, id)
+			names = append(names, name)
+			if key := strings.ToLower(name); !seen[key] {
+				seen[key] = true
+				newIds = append(newIds, uuid.New())
+				newNames = append(newNames, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO tags (id, user_id, name, color)
+		SELECT n.id, $1, n.name, $2 FROM UNNEST($3::uuid[], $4::text[]) AS n(id, name)
+		ON CONFLICT (user_id, LOWER(name)) DO NOTHING
+	`, userId, domain.DefaultTagColor, pq.Array(newIds), pq.Array(newNames))
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT i.todo_id, tg.id
+		FROM UNNEST($2::uuid[], $3::text[]) AS i(todo_id, name)
+		JOIN tags tg ON tg.user_id = $1 AND LOWER(tg.name) = LOWER(i.name)
+		ON CONFLICT DO NOTHING
+	`, userId, pq.Array(todoIds), pq.Array(names))
+	return err
+}
+
+// setTodoTags makes the named tags the tags of the user on the todo, the tags other
+// members of its project put on it are kept.
+func setTodoTags(ctx context.Context, q querier, userId, id uuid.UUID, names []string) error {
+	_, err := q.ExecContext(ctx, `
+		DELETE FROM todo_tags tt USING tags tg
+		WHERE tt.todo_id = $1 AND tg.id = tt.tag_id AND tg.user_id = $2
+			AND LOWER(tg.name) <> ALL (SELECT LOWER(n) FROM UNNEST($3::text[]) AS n)
+	`, id, userId, pq.Array(names))
+	if err != nil {
+		return err
+	}
+	return attachTags(ctx, q, userId, []uuid.UUID{id}, map[uuid.UUID][]string{id: names})
+}
+
+func (r *Repository) GetProjectNames(ctx context.Context, userId uuid.UUID) ([]todo.ProjectName, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.name
+		FROM projects p
+		JOIN project_members m ON m.project_id = p.id
+		WHERE m.user_id = $1
+		ORDER BY p.position ASC, p.created_at ASC, p.id ASC
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []todo.ProjectName
+	for rows.Next() {
+		var project todo.ProjectName
+		if err := rows.Scan(&project.Id, &project.Name); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
@@ -0,0 +1,402 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// todoAccess returns the condition under which the user passed as $userArg can access a
+// todo with the given role: a todo in the inbox belongs to its creator alone, a todo in
+// a project to the members of the project. prefix is the alias of todos, e.g. "t.", or
+// empty. The roles are constants, so they are written into the query.
+func todoAccess(prefix string, userArg int, role domain.ProjectRole) string {
+	roles := domain.RolesIncluding(role)
+	quoted := make([]string, len(roles))
+	for i, r := range roles {
+		quoted[i] = "'" + string(r) + "'"
+	}
+	return fmt.Sprintf(`((%[1]sproject_id IS NULL AND %[1]suser_id = $%[2]d) OR %[1]sproject_id IN (
+		SELECT project_id FROM project_members WHERE user_id = $%[2]d AND role IN (%[3]s)
+	))`, prefix, userArg, strings.Join(quoted, ", "))
+}
+
+// todoWriteError tells why a write matched no todo: the user may only be allowed to
+// view it.
+func todoWriteError(ctx context.Context, q querier, id, userId uuid.UUID) error {
+	var readable bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`)
+	`, id, userId).Scan(&readable)
+	if err != nil {
+		return err
+	}
+	if readable {
+		return domain.ErrTodoReadOnly
+	}
+	return domain.ErrTodoNotFound
+}
+
+// getProjectMembership returns the role of the user in the project and whether the
+// project is archived.
+func getProjectMembership(ctx context.Context, q querier, id, userId uuid.UUID) (domain.ProjectRole, bool, error) {
+	var role domain.ProjectRole
+	var archived bool
+	err := q.QueryRowContext(ctx, `
+		SELECT m.role, p.archived
+		FROM projects p
+		JOIN project_members m ON m.project_id = p.id
+		WHERE p.id = $1 AND m.user_id = $2
+	`, id, userId).Scan(&role, &archived)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, domain.ErrProjectNotFound
+		}
+		return "", false, err
+	}
+	return role, archived, nil
+}
+
+// checkProjectWritable reports whether the user can add todos to the project.
+func checkProjectWritable(ctx context.Context, q querier, id, userId uuid.UUID) error {
+	role, archived, err := getProjectMembership(ctx, q, id, userId)
+	if err != nil {
+		return err
+	}
+	if !role.Includes(domain.ProjectEditor) {
+		return domain.ErrProjectReadOnly
+	}
+	if archived {
+		return domain.ErrProjectArchived
+	}
+	return nil
+}
+
+// checkProjectOwner reports whether the user can change the project and its members.
+func checkProjectOwner(ctx context.Context, q querier, id, userId uuid.UUID) error {
+	role, _, err := getProjectMembership(ctx, q, id, userId)
+	if err != nil {
+		return err
+	}
+	if role != domain.ProjectOwner {
+		return domain.ErrNotProjectOwner
+	}
+	return nil
+}
+
+func (r *Repository) InviteMember(ctx context.Context, invitation *domain.ProjectInvitation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := checkProjectOwner(ctx, tx, invitation.ProjectId, invitation.InvitedBy); err != nil {
+		return err
+	}
+
+	var member bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM project_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.project_id = $1 AND LOWER(u.email) = $2
+		)
+	`, invitation.ProjectId, invitation.Email).Scan(&member)
+	if err != nil {
+		return err
+	}
+	if member {
+		return domain.ErrAlreadyProjectMember
+	}
+
+	// inviting the email again keeps the invitation and its id, only the role changes
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO project_invitations (id, project_id, email, role, invited_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (project_id, email) DO UPDATE SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by
+		RETURNING id
+	`, invitation.Id, invitation.ProjectId, invitation.Email, invitation.Role, invitation.InvitedBy, invitation.CreatedAt).Scan(&invitation.Id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetMembers(ctx context.Context, projectId, userId uuid.UUID) (*project.GetMembersResponse, error) {
+	if _, _, err := getProjectMembership(ctx, r.db, projectId, userId); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, COALESCE(u.fullname, ''), u.email, m.role, m.created_at
+		FROM project_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.project_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, m.created_at ASC, u.id ASC
+	`, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp := project.GetMembersResponse{Members: []project.Member{}}
+	for rows.Next() {
+		var member project.Member
+		if err := rows.Scan(&member.UserId, &member.FullName, &member.Email, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		member.JoinedAt = member.JoinedAt.UTC()
+		resp.Members = append(resp.Members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if resp.Invitations, err = r.getInvitations(ctx, `i.project_id = $1`, projectId); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Repository) UpdateMemberRole(ctx context.Context, projectId, memberId uuid.UUID, role domain.ProjectRole, userId uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := checkProjectOwner(ctx, tx, projectId, userId); err != nil {
+		return err
+	}
+
+	current, err := lockProjectMembers(ctx, tx, projectId, memberId)
+	if err != nil {
+		return err
+	}
+	if current == domain.ProjectOwner && role != domain.ProjectOwner {
+		if err := checkOtherOwner(ctx, tx, projectId, memberId); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE project_members SET role = $1 WHERE project_id = $2 AND user_id = $3
+	`, role, projectId, memberId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveMember keeps the todos the member created in the project, they are no longer
+// visible to the member.
+func (r *Repository) RemoveMember(ctx context.Context, projectId, memberId, userId uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if memberId == userId {
+		if _, _, err := getProjectMembership(ctx, tx, projectId, userId); err != nil {
+			return err
+		}
+	} else if err := checkProjectOwner(ctx, tx, projectId, userId); err != nil {
+		return err
+	}
+
+	current, err := lockProjectMembers(ctx, tx, projectId, memberId)
+	if err != nil {
+		return err
+	}
+	if current == domain.ProjectOwner {
+		if err := checkOtherOwner(ctx, tx, projectId, memberId); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM project_members WHERE project_id = $1 AND user_id = $2`, projectId, memberId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockProjectMembers locks the members of the project until the end of the transaction,
+// so that two owners demoting each other cannot leave the project without one, and
+// returns the role of the given member.
+func lockProjectMembers(ctx context.Context, tx querier, projectId, memberId uuid.UUID) (domain.ProjectRole, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, role FROM project_members WHERE project_id = $1 FOR UPDATE
+	`, projectId)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var role domain.ProjectRole
+	for rows.Next() {
+		var id uuid.UUID
+		var r domain.ProjectRole
+		if err := rows.Scan(&id, &r); err != nil {
+			return "", err
+		}
+		if id == memberId {
+			role = r
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", domain.ErrMemberNotFound
+	}
+	return role, nil
+}
+
+func checkOtherOwner(ctx context.Context, tx querier, projectId, memberId uuid.UUID) error {
+	var others bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM project_members WHERE project_id = $1 AND user_id <> $2 AND role = 'owner')
+	`, projectId, memberId).Scan(&others)
+	if err != nil {
+		return err
+	}
+	if !others {
+		return domain.ErrLastProjectOwner
+	}
+	return nil
+}
+
+func (r *Repository) GetInvitations(ctx context.Context, userId uuid.UUID) (*project.GetInvitationsResponse, error) {
+	invitations, err := r.getInvitations(ctx, `i.email = (SELECT LOWER(email) FROM users WHERE id = $1)`, userId)
+	if err != nil {
+		return nil, err
+	}
+	resp := project.GetInvitationsResponse(invitations)
+	return &resp, nil
+}
+
+// getInvitations returns the invitations matched by where, newest first.
+func (r *Repository) getInvitations(ctx context.Context, where string, args ...any) ([]project.Invitation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT i.id, i.project_id, p.name, i.email, i.role, i.invited_by, i.created_at
+		FROM project_invitations i
+		JOIN projects p ON p.id = i.project_id
+		WHERE `+where+`
+		ORDER BY i.created_at DESC, i.id ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []project.Invitation{}
+	for rows.Next() {
+		var invitation project.Invitation
+		var invitedBy uuid.NullUUID
+		if err := rows.Scan(&invitation.Id, &invitation.ProjectId, &invitation.ProjectName, &invitation.Email, &invitation.Role,
+			&invitedBy, &invitation.CreatedAt); err != nil {
+			return nil, err
+		}
+		invitation.InvitedBy = uuidPtr(invitedBy)
+		invitation.CreatedAt = invitation.CreatedAt.UTC()
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (r *Repository) AcceptInvitation(ctx context.Context, id, userId uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer rollbackTx(tx)
+
+	var projectId uuid.UUID
+	var role domain.ProjectRole
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM project_invitations
+		WHERE id = $1 AND email = (SELECT LOWER(email) FROM users WHERE id = $2)
+		RETURNING project_id, role
+	`, id, userId).Scan(&projectId, &role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, domain.ErrInvitationNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3)
+	`, projectId, userId, role)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return uuid.Nil, domain.ErrAlreadyProjectMember
+		}
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+	return projectId, nil
+}
+
+func (r *Repository) DeleteInvitation(ctx context.Context, id, userId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM project_invitations i
+		WHERE i.id = $1 AND (
+			i.email = (SELECT LOWER(email) FROM users WHERE id = $2)
+			OR i.project_id IN (SELECT project_id FROM project_members WHERE user_id = $2 AND role = 'owner')
+		)
+	`, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// GetCollaboratorIds returns the user and everyone who shares a project with them.
+func (r *Repository) GetCollaboratorIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT $1::uuid
+		UNION
+		SELECT o.user_id
+		FROM project_members m
+		JOIN project_members o ON o.project_id = m.project_id
+		WHERE m.user_id = $1
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
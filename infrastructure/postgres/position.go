@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// todoOrderId is the list a todo is ordered in: its project, or the inbox of its creator
+// when it has none. Every member of a project sees the same order, positions are unique
+// per list.
+const todoOrderId = `COALESCE(project_id, user_id)`
+
+// lockTodoOrder serializes the writes that give todos of the list a position until the
+// end of the transaction, so that two of them never pick the same one. orderId is a
+// project id, or the id of a user for their inbox. Transactions that may insert a todo
+// after locking rows take it first, otherwise they could deadlock with ReorderTodo.
+func lockTodoOrder(ctx context.Context, tx querier, orderId uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('todo_order:' || $1::text, 0))`, orderId)
+	return err
+}
+
+// lockTodoOrders locks the given lists and the lists of the given todos, whose next
+// occurrence, or whose subtask, is added at the end of them. The lists are locked in a
+// fixed sequence, so two transactions never wait for each other.
+func lockTodoOrders(ctx context.Context, tx querier, orderIds, todoIds []uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT UNNEST($1::uuid[]) AS order_id
+		UNION
+		SELECT `+todoOrderId+` FROM todos WHERE id = ANY($2::uuid[])
+		ORDER BY order_id
+	`, pq.Array(orderIds), pq.Array(todoIds))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var lockIds []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		lockIds = append(lockIds, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, id := range lockIds {
+		if err := lockTodoOrder(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastTodoPosition returns the position of the last todo of the list, trashed ones
+// included, so that a restored todo never collides with a newer one. The list must be
+// locked.
+func lastTodoPosition(ctx context.Context, tx querier, orderId uuid.UUID) (string, error) {
+	var last sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT MAX(position) FROM todos WHERE `+todoOrderId+` = $1`, orderId).Scan(&last)
+	return last.String, err
+}
+
+// nextTodoPosition returns a position after every todo of the list. It must run in a
+// transaction.
+func nextTodoPosition(ctx context.Context, tx querier, orderId uuid.UUID) (string, error) {
+	if err := lockTodoOrder(ctx, tx, orderId); err != nil {
+		return "", err
+	}
+
+	last, err := lastTodoPosition(ctx, tx, orderId)
+	if err != nil {
+		return "", err
+	}
+	return domain.PositionBetween(last, "")
+}
+
+// ensureTodoPositions appends the todos of the list that have no position yet, in
+// creation order. The list must be locked.
+func ensureTodoPositions(ctx context.Context, tx querier, orderId uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM todos WHERE `+todoOrderId+` = $1 AND position IS NULL ORDER BY created_at ASC, id ASC
+	`, orderId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	position, err := lastTodoPosition(ctx, tx, orderId)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if position, err = domain.PositionBetween(position, ""); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE todos SET position = $1 WHERE id = $2`, position, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearTodoPositions takes the positions of the todos matched by where, trashed ones
+// included, before they change list, and returns their ids for appendTodoPositions.
+func clearTodoPositions(ctx context.Context, tx querier, where string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, `UPDATE todos SET position = NULL WHERE `+where+` RETURNING id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// appendTodoPositions locks the lists the given todos are in and appends the ones that
+// have no position, e.g. because they have just been moved to another list.
+func appendTodoPositions(ctx context.Context, tx querier, todoIds []uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT `+todoOrderId+` AS order_id FROM todos WHERE id = ANY($1::uuid[]) AND position IS NULL
+		ORDER BY order_id
+	`, pq.Array(todoIds))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var orderIds []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		orderIds = append(orderIds, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, orderId := range orderIds {
+		if err := lockTodoOrder(ctx, tx, orderId); err != nil {
+			return err
+		}
+		if err := ensureTodoPositions(ctx, tx, orderId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReorderTodo only writes the position of the moved todo. The neighbours are looked up
+// among every todo of its list, trashed ones included, since they keep their positions.
+func (r *Repository) ReorderTodo(ctx context.Context, id, userId, after, before uuid.UUID) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer rollbackTx(tx)
+
+	var orderId uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		SELECT `+todoOrderId+` FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`
+	`, id, userId).Scan(&orderId)
+	if err == sql.ErrNoRows {
+		return "", todoWriteError(ctx, tx, id, userId)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := lockTodoOrder(ctx, tx, orderId); err != nil {
+		return "", err
+	}
+	if err := ensureTodoPositions(ctx, tx, orderId); err != nil {
+		return "", err
+	}
+
+	// the todo may have been moved to another list before the lock was taken
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND `+todoOrderId+` = $2 AND deleted_at IS NULL)
+	`, id, orderId).Scan(&exists)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", domain.ErrTodoNotFound
+	}
+
+	var lower, upper string
+	if after != uuid.Nil {
+		if lower, err = anchorPosition(ctx, tx, after, orderId); err != nil {
+			return "", err
+		}
+	}
+	if before != uuid.Nil {
+		if upper, err = anchorPosition(ctx, tx, before, orderId); err != nil {
+			return "", err
+		}
+	}
+
+	if after != uuid.Nil && before != uuid.Nil {
+		// the anchors are what the client saw next to each other, another move may have
+		// changed that in the meantime
+		if upper <= lower {
+			return "", domain.ErrStaleMoveAnchors
+		}
+		var between bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM todos
+				WHERE `+todoOrderId+` = $1 AND deleted_at IS NULL AND position > $2 AND position < $3 AND id <> $4
+			)
+		`, orderId, lower, upper, id).Scan(&between)
+		if err != nil {
+			return "", err
+		}
+		if between {
+			return "", domain.ErrStaleMoveAnchors
+		}
+	}
+
+	var neighbour sql.NullString
+	if after != uuid.Nil {
+		err = tx.QueryRowContext(ctx, `
+			SELECT MIN(position) FROM todos WHERE `+todoOrderId+` = $1 AND position > $2 AND id <> $3
+		`, orderId, lower, id).Scan(&neighbour)
+		upper = neighbour.String
+	} else {
+		err = tx.QueryRowContext(ctx, `
+			SELECT MAX(position) FROM todos WHERE `+todoOrderId+` = $1 AND position < $2 AND id <> $3
+		`, orderId, upper, id).Scan(&neighbour)
+		lower = neighbour.String
+	}
+	if err != nil {
+		return "", err
+	}
+
+	position, err := domain.PositionBetween(lower, upper)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE todos SET position = $1 WHERE id = $2`, position, id); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return position, nil
+}
+
+// anchorPosition returns the position of a todo of the list, an anchor in another list
+// is not found.
+func anchorPosition(ctx context.Context, tx querier, id, orderId uuid.UUID) (string, error) {
+	var position string
+	err := tx.QueryRowContext(ctx, `
+		SELECT position FROM todos WHERE id = $1 AND `+todoOrderId+` = $2 AND deleted_at IS NULL
+	`, id, orderId).Scan(&position)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", domain.ErrAnchorTodoNotFound
+		}
+		return "", err
+	}
+	return position, nil
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// Concurrently created projects may end up with the same position, they are then
+// ordered by creation time. The creator becomes the first owner of the project.
+func (r *Repository) CreateProject(ctx context.Context, project *domain.Project) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO projects (id, user_id, name, color, archived, position, created_at)
+		VALUES ($1, $2, $3, $4, $5, (SELECT COALESCE(MAX(position) + 1, 0) FROM projects WHERE user_id = $2), $6)
+		RETURNING position
+	`, project.Id, project.UserId, project.Name, project.Color, project.Archived, project.CreatedAt).Scan(&project.Position)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO project_members (project_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+	`, project.Id, project.UserId, domain.ProjectOwner, project.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetProjectsByUserID(ctx context.Context, userId uuid.UUID, includeArchived bool) (*project.GetProjectsResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.name, p.color, p.archived, p.position, p.created_at, m.role
+		FROM projects p
+		JOIN project_members m ON m.project_id = p.id
+		WHERE m.user_id = $1 AND ($2 OR NOT p.archived)
+		ORDER BY p.position ASC, p.created_at ASC, p.id ASC
+	`, userId, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := project.GetProjectsResponse{}
+	for rows.Next() {
+		var resp project.Project
+		if err := rows.Scan(&resp.Id, &resp.Name, &resp.Color, &resp.Archived, &resp.Position, &resp.CreatedAt, &resp.Role); err != nil {
+			return nil, err
+		}
+		projects = append(projects, resp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &projects, nil
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, project *domain.Project) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := checkProjectOwner(ctx, tx, project.Id, project.UserId); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE projects SET name = $1, color = $2, archived = $3, position = $4
+		WHERE id = $5
+	`, project.Name, project.Color, project.Archived, project.Position, project.Id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// With domain.ProjectDeleteMoveToInbox the foreign key sets project_id of the todos to
+// NULL, each todo ends up at the end of the inbox of its creator.
+func (r *Repository) DeleteProject(ctx context.Context, id, userId uuid.UUID, mode domain.ProjectDeleteMode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := checkProjectOwner(ctx, tx, id, userId); err != nil {
+		return err
+	}
+
+	if err := lockTodoOrder(ctx, tx, id); err != nil {
+		return err
+	}
+
+	// the positions of the project would collide with the ones of the inboxes
+	var moved []uuid.UUID
+	if mode == domain.ProjectDeleteCascade {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM todos WHERE project_id = $1`, id); err != nil {
+			return err
+		}
+	} else if moved, err = clearTodoPositions(ctx, tx, `project_id = $1`, id); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrProjectNotFound
+	}
+
+	if err := appendTodoPositions(ctx, tx, moved); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
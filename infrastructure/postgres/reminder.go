@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/reminder"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+func (r *Repository) GetTodoDueAt(ctx context.Context, todoId, userId uuid.UUID) (time.Time, error) {
+	var dueAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT due_at FROM todos WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, todoId, userId).Scan(&dueAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, domain.ErrTodoNotFound
+		}
+		return time.Time{}, err
+	}
+	if !dueAt.Valid {
+		return time.Time{}, nil
+	}
+	return dueAt.Time.UTC(), nil
+}
+
+func (r *Repository) CreateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	offsetSeconds := sql.NullInt64{Int64: int64(reminder.Offset / time.Second), Valid: reminder.Relative}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO reminders (id, todo_id, remind_at, offset_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, reminder.Id, reminder.TodoId, nullTime(reminder.RemindAt), offsetSeconds, reminder.CreatedAt)
+	return err
+}
+
+func (r *Repository) GetRemindersByTodoID(ctx context.Context, todoId, userId uuid.UUID) (*reminder.GetRemindersResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.remind_at, r.offset_seconds, r.sent_at, r.created_at
+		FROM reminders r
+		JOIN todos t ON t.id = r.todo_id
+		WHERE r.todo_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
+		ORDER BY r.created_at ASC
+	`, todoId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := reminder.GetRemindersResponse{}
+	for rows.Next() {
+		var resp reminder.Reminder
+		var remindAt, sentAt sql.NullTime
+		var offsetSeconds sql.NullInt64
+		if err := rows.Scan(&resp.Id, &remindAt, &offsetSeconds, &sentAt, &resp.CreatedAt); err != nil {
+			return nil, err
+		}
+		if remindAt.Valid {
+			resp.RemindAt = remindAt.Time.UTC()
+		}
+		if offsetSeconds.Valid {
+			minutes := int(offsetSeconds.Int64 / 60)
+			resp.MinutesBeforeDue = &minutes
+		}
+		if sentAt.Valid {
+			resp.SentAt = sentAt.Time.UTC()
+		}
+		reminders = append(reminders, resp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &reminders, nil
+}
+
+func (r *Repository) DeleteReminder(ctx context.Context, id, todoId, userId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM reminders r
+		USING todos t
+		WHERE r.id = $1 AND r.todo_id = $2 AND t.id = r.todo_id AND t.user_id = $3 AND t.deleted_at IS NULL
+	`, id, todoId, userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// The reminder row stays locked until the outcome is committed, SKIP LOCKED lets other
+// instances move on to the next reminder instead of waiting for it. If the process dies
+// before the commit the lock is released and the reminder is picked up again.
+// Reminders of trashed todos wait until the todo is restored or purged.
+func (r *Repository) ProcessNextDueReminder(ctx context.Context, now time.Time, deliver func(ctx context.Context, reminder *reminder.DueReminder) error) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer rollbackTx(tx)
+
+	row := tx.QueryRowContext(ctx, `
+		SELECT r.id, t.id, t.title, t.completed, t.due_at, u.fullname, u.email
+		FROM reminders r
+		JOIN todos t ON t.id = r.todo_id
+		JOIN users u ON u.id = t.user_id
+		WHERE r.sent_at IS NULL
+			AND t.deleted_at IS NULL
+			AND r.attempts < $2
+			AND (r.last_attempt_at IS NULL OR r.last_attempt_at <= $3)
+			AND COALESCE(r.remind_at, t.due_at - r.offset_seconds * INTERVAL '1 second') <= $1
+		ORDER BY COALESCE(r.remind_at, t.due_at - r.offset_seconds * INTERVAL '1 second') ASC
+		LIMIT 1
+		FOR UPDATE OF r SKIP LOCKED
+	`, now, domain.MaxReminderAttempts, now.Add(-domain.ReminderRetryDelay))
+
+	var due reminder.DueReminder
+	var dueAt sql.NullTime
+	var fullName sql.NullString
+	if err := row.Scan(&due.Id, &due.TodoId, &due.TodoTitle, &due.TodoCompleted, &dueAt, &fullName, &due.UserEmail); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	if dueAt.Valid {
+		due.DueAt = dueAt.Time.UTC()
+	}
+	due.UserFullName = fullName.String
+
+	if deliverErr := deliver(ctx, &due); deliverErr != nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE reminders SET attempts = attempts + 1, last_attempt_at = $1 WHERE id = $2
+		`, now, due.Id)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE reminders SET sent_at = $1 WHERE id = $2`, now, due.Id)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
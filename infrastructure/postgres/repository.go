@@ -1,8 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"regexp"
+	"strings"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
@@ -10,7 +14,8 @@ import (
 )
 
 type Repository struct {
-	db *sql.DB
+	db             *sql.DB
+	searchLanguage string
 }
 
 func NewRepository(databaseUrl string) *Repository {
@@ -28,12 +33,16 @@ func NewRepository(databaseUrl string) *Repository {
 	}
 
 	runTableMigrations(db)
+	runPositionMigrations(db)
+
+	searchLanguage := domain.SearchLanguage()
+	runSearchMigrations(db, searchLanguage)
 
 	if !domain.IsProdEnv() {
 		runTestUserMigrations(db)
 	}
 
-	return &Repository{db: db}
+	return &Repository{db: db, searchLanguage: searchLanguage}
 }
 
 func (r *Repository) Close() error {
@@ -58,6 +67,8 @@ func runTableMigrations(db *sql.DB) {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_feed_id UUID DEFAULT NULL;
+
 
 		CREATE TABLE IF NOT EXISTS todos (
 			id UUID PRIMARY KEY,
@@ -68,6 +79,150 @@ func runTableMigrations(db *sql.DB) {
 			completed_at TIMESTAMP DEFAULT NULL
 		);
 
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ DEFAULT NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_timezone TEXT DEFAULT NULL;
+		CREATE INDEX IF NOT EXISTS idx_todos_user_id_due_at ON todos (user_id, due_at);
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4);
+		CREATE INDEX IF NOT EXISTS idx_todos_user_id_priority ON todos (user_id, priority);
+		CREATE INDEX IF NOT EXISTS idx_todos_user_id_created_at ON todos (user_id, created_at, id);
+
+		CREATE TABLE IF NOT EXISTS projects (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			color VARCHAR(7) NOT NULL,
+			archived BOOLEAN NOT NULL DEFAULT FALSE,
+			position INTEGER NOT NULL DEFAULT 0 CHECK (position >= 0),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_projects_user_id_position ON projects (user_id, position);
+
+		CREATE TABLE IF NOT EXISTS project_members (
+			project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (project_id, user_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members (user_id);
+		-- every project has at least one owner, projects created before sharing are owned by their creator
+		INSERT INTO project_members (project_id, user_id, role, created_at)
+		SELECT p.id, p.user_id, 'owner', p.created_at FROM projects p
+		WHERE NOT EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = p.id);
+
+		CREATE TABLE IF NOT EXISTS project_invitations (
+			id UUID PRIMARY KEY,
+			project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			email VARCHAR(200) NOT NULL,
+			role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+			invited_by UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_project_invitations_project_id_email ON project_invitations (project_id, email);
+		CREATE INDEX IF NOT EXISTS idx_project_invitations_email ON project_invitations (email);
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS project_id UUID DEFAULT NULL REFERENCES projects(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos (project_id);
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS parent_id UUID DEFAULT NULL REFERENCES todos(id) ON DELETE CASCADE;
+		CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos (parent_id);
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence TEXT DEFAULT NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence_timezone TEXT DEFAULT NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMPTZ DEFAULT NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS next_occurrence_id UUID DEFAULT NULL REFERENCES todos(id) ON DELETE SET NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ DEFAULT NULL;
+		CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C" DEFAULT NULL;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+		CREATE TABLE IF NOT EXISTS reminders (
+			id UUID PRIMARY KEY,
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			remind_at TIMESTAMPTZ DEFAULT NULL,
+			offset_seconds INTEGER DEFAULT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_attempt_at TIMESTAMPTZ DEFAULT NULL,
+			sent_at TIMESTAMPTZ DEFAULT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			CHECK ((remind_at IS NULL) <> (offset_seconds IS NULL))
+		);
+		CREATE INDEX IF NOT EXISTS idx_reminders_todo_id ON reminders (todo_id);
+		CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders (remind_at) WHERE sent_at IS NULL;
+
+		CREATE TABLE IF NOT EXISTS tags (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(50) NOT NULL,
+			color VARCHAR(7) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_id_lower_name ON tags (user_id, LOWER(name));
+
+		CREATE TABLE IF NOT EXISTS todo_tags (
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			PRIMARY KEY (todo_id, tag_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags (tag_id);
+
+		CREATE TABLE IF NOT EXISTS todo_revisions (
+			id UUID PRIMARY KEY,
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			actor_id UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+			changes JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+		);
+		CREATE INDEX IF NOT EXISTS idx_todo_revisions_todo_id_created_at ON todo_revisions (todo_id, created_at);
+
+		CREATE TABLE IF NOT EXISTS todo_comments (
+			id UUID PRIMARY KEY,
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			author_id UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+			body TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_todo_comments_todo_id_created_at ON todo_comments (todo_id, created_at);
+
+		CREATE TABLE IF NOT EXISTS todo_attachments (
+			id UUID PRIMARY KEY,
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			uploader_id UUID DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+			name VARCHAR(255) NOT NULL,
+			content_type VARCHAR(255) NOT NULL,
+			size BIGINT NOT NULL,
+			blob_key TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_todo_attachments_todo_id_created_at ON todo_attachments (todo_id, created_at);
+
+		-- the blobs live outside of the database, a trigger queues the key of every deleted
+		-- attachment, including the ones deleted by a cascade, until the janitor deletes it
+		CREATE TABLE IF NOT EXISTS orphaned_blobs (
+			blob_key TEXT PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE OR REPLACE FUNCTION queue_orphaned_blob() RETURNS TRIGGER AS $$
+		BEGIN
+			INSERT INTO orphaned_blobs (blob_key) VALUES (OLD.blob_key) ON CONFLICT DO NOTHING;
+			RETURN OLD;
+		END;
+		$$ LANGUAGE plpgsql;
+		CREATE OR REPLACE TRIGGER todo_attachments_queue_orphaned_blob
+			AFTER DELETE ON todo_attachments
+			FOR EACH ROW EXECUTE FUNCTION queue_orphaned_blob();
+
+		-- only a hash of each app password is stored, it is unique so that a password
+		-- finds its user on its own
+		CREATE TABLE IF NOT EXISTS app_passwords (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			password_hash TEXT NOT NULL UNIQUE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			last_used_at TIMESTAMPTZ DEFAULT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_app_passwords_user_id_created_at ON app_passwords (user_id, created_at);
+
 		CREATE TABLE IF NOT EXISTS refresh_tokens (
 			id              UUID PRIMARY KEY,
 			user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -85,6 +240,67 @@ func runTableMigrations(db *sql.DB) {
 
 }
 
+// runPositionMigrations gives the todos created before manual ordering a position,
+// they are appended to their list in creation order. Positions used to be unique per
+// creator, so the todos of a project with several creators may share one, all but the
+// first of them lose it and are appended again.
+func runPositionMigrations(db *sql.DB) {
+	_, err := db.Exec(`
+		DO $$
+		BEGIN
+			IF to_regclass('idx_todos_order_position') IS NULL THEN
+				DROP INDEX IF EXISTS idx_todos_user_id_position;
+				UPDATE todos SET position = NULL WHERE id IN (
+					SELECT id FROM (
+						SELECT id, ROW_NUMBER() OVER (
+							PARTITION BY ` + todoOrderId + `, position ORDER BY created_at ASC, id ASC
+						) AS n
+						FROM todos WHERE position IS NOT NULL
+					) ranked WHERE n > 1
+				);
+				CREATE UNIQUE INDEX idx_todos_order_position ON todos ((` + todoOrderId + `), position);
+			END IF;
+		END $$
+	`)
+	if err != nil {
+		panic("Failed to create the todo position index: " + err.Error())
+	}
+
+	rows, err := db.Query(`SELECT DISTINCT ` + todoOrderId + ` FROM todos WHERE position IS NULL`)
+	if err != nil {
+		panic("Failed to read todos without a position: " + err.Error())
+	}
+	var orderIds []uuid.UUID
+	for rows.Next() {
+		var orderId uuid.UUID
+		if err := rows.Scan(&orderId); err != nil {
+			panic("Failed to read todos without a position: " + err.Error())
+		}
+		orderIds = append(orderIds, orderId)
+	}
+	if err := rows.Err(); err != nil {
+		panic("Failed to read todos without a position: " + err.Error())
+	}
+	rows.Close()
+
+	ctx := context.Background()
+	for _, orderId := range orderIds {
+		tx, err := db.Begin()
+		if err != nil {
+			panic("Failed to begin transaction: " + err.Error())
+		}
+		if err := lockTodoOrder(ctx, tx, orderId); err != nil {
+			panic("Failed to lock the todo order: " + err.Error())
+		}
+		if err := ensureTodoPositions(ctx, tx, orderId); err != nil {
+			panic("Failed to set the todo positions: " + err.Error())
+		}
+		if err := tx.Commit(); err != nil {
+			panic("Failed to commit transaction: " + err.Error())
+		}
+	}
+}
+
 func runTestUserMigrations(db *sql.DB) {
 	tx, err := db.Begin()
 
@@ -132,3 +348,45 @@ func runTestUserMigrations(db *sql.DB) {
 		panic("Failed to commit transaction: " + tx.Commit().Error())
 	}
 }
+
+var searchLanguagePattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// runSearchMigrations creates the full-text search column of todos, which is generated
+// with the given text search configuration. The configuration is part of the column,
+// so changing it rebuilds the column and its index.
+func runSearchMigrations(db *sql.DB, language string) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = $1)`, language).Scan(&exists)
+	if err != nil {
+		panic("Failed to check the search language: " + err.Error())
+	}
+	// the name is checked against the pattern as well, because it ends up in the DDL below
+	if !exists || !searchLanguagePattern.MatchString(language) {
+		panic("Unknown search language: " + language)
+	}
+
+	var expression sql.NullString
+	err = db.QueryRow(`
+		SELECT generation_expression FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'todos' AND column_name = 'search_vector'
+	`).Scan(&expression)
+	if err != nil && err != sql.ErrNoRows {
+		panic("Failed to read the search column: " + err.Error())
+	}
+	if err == nil && !strings.Contains(expression.String, "'"+language+"'::regconfig") {
+		if _, err := db.Exec(`ALTER TABLE todos DROP COLUMN search_vector`); err != nil {
+			panic("Failed to drop the search column: " + err.Error())
+		}
+	}
+
+	_, err = db.Exec(fmt.Sprintf(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('%[1]s'::regconfig, title), 'A') ||
+			setweight(to_tsvector('%[1]s'::regconfig, description), 'B')
+		) STORED;
+		CREATE INDEX IF NOT EXISTS idx_todos_search_vector ON todos USING GIN (search_vector);
+	`, language))
+	if err != nil {
+		panic("Failed to create the search column: " + err.Error())
+	}
+}
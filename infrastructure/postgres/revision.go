@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// updateTodos runs an UPDATE on the todos matched by where and records a revision for
+// every todo whose domain.TodoState it changes, it returns how many todos were matched.
+// The version of every matched todo is incremented, and so is the version of the
+// ancestors of each todo that changes the way it is shown as a subtask.
+// The old values are read in the same statement, the set expressions can use the
+// columns of todos without a prefix.
+//
+// It must run in a transaction, so that a change is never left without its revision.
+func updateTodos(ctx context.Context, tx querier, actorId uuid.UUID, set, where string, args ...any) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		WITH old AS (
+			SELECT id AS old_id, title AS old_title, description AS old_description, completed AS old_completed,
+				due_at AS old_due_at, priority AS old_priority, project_id AS old_project_id
+			FROM todos
+			WHERE `+where+`
+			FOR UPDATE
+		)
+		UPDATE todos SET version = version + 1, `+set+`
+		FROM old
+		WHERE id = old_id
+		RETURNING id, old_title, old_description, old_completed, old_due_at, old_priority, old_project_id,
+			title, description, completed, due_at, priority, project_id
+	`, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	matched := 0
+	var revisions []*domain.TodoRevision
+	var changedSubtasks []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var before, after todoStateRow
+		if err := rows.Scan(append([]any{&id}, append(before.dest(), after.dest()...)...)...); err != nil {
+			return 0, err
+		}
+		matched++
+		if before.subtaskChanged(after) {
+			changedSubtasks = append(changedSubtasks, id)
+		}
+
+		beforeState, err := before.state()
+		if err != nil {
+			return 0, err
+		}
+		afterState, err := after.state()
+		if err != nil {
+			return 0, err
+		}
+		revision, err := domain.NewTodoRevision(id, actorId, beforeState, afterState)
+		if err != nil {
+			return 0, err
+		}
+		if revision != nil {
+			revisions = append(revisions, revision)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	// the connection of the transaction is busy until the rows are closed
+	rows.Close()
+
+	for _, revision := range revisions {
+		if err := insertRevision(ctx, tx, revision); err != nil {
+			return 0, err
+		}
+	}
+	if err := bumpAncestorVersions(ctx, tx, changedSubtasks); err != nil {
+		return 0, err
+	}
+	return matched, nil
+}
+
+func insertRevision(ctx context.Context, q querier, revision *domain.TodoRevision) error {
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO todo_revisions (id, todo_id, actor_id, changes)
+		VALUES ($1, $2, $3, $4)
+	`, revision.Id, revision.TodoId, nullUUID(revision.ActorId), changes)
+	return err
+}
+
+// todoStateRow scans the columns of domain.TodoState.
+type todoStateRow struct {
+	title       string
+	description string
+	completed   bool
+	dueAt       sql.NullTime
+	priority    int
+	projectId   uuid.NullUUID
+}
+
+func (r *todoStateRow) dest() []any {
+	return []any{&r.title, &r.description, &r.completed, &r.dueAt, &r.priority, &r.projectId}
+}
+
+// subtaskChanged reports whether a todo at r and at after is shown differently as the
+// subtask of another todo, see todo.Subtask.
+func (r *todoStateRow) subtaskChanged(after todoStateRow) bool {
+	return r.title != after.title || r.completed != after.completed || r.priority != after.priority ||
+		r.dueAt.Valid != after.dueAt.Valid || !r.dueAt.Time.Equal(after.dueAt.Time)
+}
+
+func (r *todoStateRow) state() (domain.TodoState, error) {
+	priority, err := domain.PriorityFromRank(r.priority)
+	if err != nil {
+		return domain.TodoState{}, err
+	}
+	state := domain.TodoState{
+		Title:       r.title,
+		Description: r.description,
+		Completed:   r.completed,
+		Priority:    priority,
+		ProjectId:   uuidPtr(r.projectId),
+	}
+	if r.dueAt.Valid {
+		dueAt := r.dueAt.Time.UTC()
+		state.DueAt = &dueAt
+	}
+	return state, nil
+}
+
+func (r *Repository) GetTodoHistory(ctx context.Context, id, userId uuid.UUID) (*todo.GetTodoHistoryResponse, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`)
+	`, id, userId).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrTodoNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, actor_id, changes, created_at
+		FROM todo_revisions
+		WHERE todo_id = $1
+		ORDER BY created_at DESC, id ASC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp := todo.GetTodoHistoryResponse{Revisions: []todo.TodoRevision{}}
+	for rows.Next() {
+		var revision todo.TodoRevision
+		var actorId uuid.NullUUID
+		var changes []byte
+		if err := rows.Scan(&revision.Id, &actorId, &changes, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+			return nil, err
+		}
+		revision.ActorId = uuidPtr(actorId)
+		revision.CreatedAt = revision.CreatedAt.UTC()
+		resp.Revisions = append(resp.Revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RevertTodo records the revert as a revision of its own, so it can be reverted as well.
+// A project change is reverted like a move, together with the subtasks.
+func (r *Repository) RevertTodo(ctx context.Context, id, revisionId, userId uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	var current todoStateRow
+	var parentId uuid.NullUUID
+	err = tx.QueryRowContext(ctx, `
+		SELECT title, description, completed, due_at, priority, project_id, parent_id
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`
+		FOR UPDATE
+	`, id, userId).Scan(append(current.dest(), &parentId)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return todoWriteError(ctx, tx, id, userId)
+		}
+		return err
+	}
+
+	var changes []byte
+	err = tx.QueryRowContext(ctx, `SELECT changes FROM todo_revisions WHERE id = $1 AND todo_id = $2`, revisionId, id).Scan(&changes)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrRevisionNotFound
+		}
+		return err
+	}
+	revision := domain.TodoRevision{Id: revisionId, TodoId: id}
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return err
+	}
+
+	currentState, err := current.state()
+	if err != nil {
+		return err
+	}
+	reverted, err := revision.Revert(currentState)
+	if err != nil {
+		return err
+	}
+
+	var dueAt time.Time
+	if reverted.DueAt != nil {
+		dueAt = *reverted.DueAt
+	}
+	_, err = updateTodos(ctx, tx, userId, `
+		title = $3, description = $4, due_at = $5, priority = $6, completed = $7,
+		completed_at = CASE WHEN $7 THEN COALESCE(completed_at, NOW()) ELSE NULL END
+	`, `id = $1 AND `+todoAccess("", 2, domain.ProjectEditor),
+		id, userId, reverted.Title, reverted.Description, nullTime(dueAt), reverted.Priority.Rank(), reverted.Completed)
+	if err != nil {
+		return err
+	}
+
+	if uuidValue(reverted.ProjectId) != uuidValue(currentState.ProjectId) {
+		if parentId.Valid {
+			return domain.ErrSubtaskWithProject
+		}
+		if err := moveTodo(ctx, tx, id, userId, uuidValue(reverted.ProjectId)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func uuidValue(id *uuid.UUID) uuid.UUID {
+	if id == nil {
+		return uuid.Nil
+	}
+	return *id
+}
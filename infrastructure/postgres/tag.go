@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/tag"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+func (r *Repository) CreateTag(ctx context.Context, tag *domain.Tag) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO tags (id, user_id, name, color, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, tag.Id, tag.UserId, tag.Name, tag.Color, tag.CreatedAt)
+	if err != nil {
+		return tagWriteError(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetTagsByUserID(ctx context.Context, userId uuid.UUID) (*tag.GetTagsResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, color, created_at
+		FROM tags
+		WHERE user_id = $1
+		ORDER BY LOWER(name) ASC
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := tag.GetTagsResponse{}
+	for rows.Next() {
+		var resp tag.Tag
+		if err := rows.Scan(&resp.Id, &resp.Name, &resp.Color, &resp.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, resp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &tags, nil
+}
+
+func (r *Repository) UpdateTag(ctx context.Context, tag *domain.Tag) error {
+	// the todos show the tag, so their versions change with it
+	var updated int
+	err := r.db.QueryRowContext(ctx, `
+		WITH updated AS (
+			UPDATE tags SET name = $1, color = $2
+			WHERE id = $3 AND user_id = $4
+			RETURNING id
+		), bumped AS (
+			UPDATE todos SET version = version + 1
+			WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id IN (SELECT id FROM updated))
+		)
+		SELECT COUNT(*) FROM updated
+	`, tag.Name, tag.Color, tag.Id, tag.UserId).Scan(&updated)
+	if err != nil {
+		return tagWriteError(err)
+	}
+
+	if updated == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteTag(ctx context.Context, id, userId uuid.UUID) error {
+	// todo_tags is read before the cascade removes the tag from the todos
+	var deleted int
+	err := r.db.QueryRowContext(ctx, `
+		WITH deleted AS (
+			DELETE FROM tags WHERE id = $1 AND user_id = $2
+			RETURNING id
+		), bumped AS (
+			UPDATE todos SET version = version + 1
+			WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id IN (SELECT id FROM deleted))
+		)
+		SELECT COUNT(*) FROM deleted
+	`, id, userId).Scan(&deleted)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) AttachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error {
+	var todoExists, tagExists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 3, domain.ProjectEditor)+`),
+			EXISTS (SELECT 1 FROM tags WHERE id = $2 AND user_id = $3)
+	`, todoId, tagId, userId).Scan(&todoExists, &tagExists)
+	if err != nil {
+		return err
+	}
+	if !todoExists {
+		return todoWriteError(ctx, r.db, todoId, userId)
+	}
+	if !tagExists {
+		return domain.ErrTagNotFound
+	}
+
+	// attaching an attached tag again leaves the version as it is
+	_, err = r.db.ExecContext(ctx, `
+		WITH attached AS (
+			INSERT INTO todo_tags (todo_id, tag_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING todo_id
+		)
+		UPDATE todos SET version = version + 1
+		WHERE id IN (SELECT todo_id FROM attached)
+	`, todoId, tagId)
+	if err != nil {
+		// the todo or the tag has been deleted in the meantime
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return domain.ErrTodoNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) DetachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		WITH detached AS (
+			DELETE FROM todo_tags tt
+			USING todos t, tags g
+			WHERE tt.todo_id = $1 AND tt.tag_id = $2 AND g.id = tt.tag_id AND g.user_id = $3 AND t.id = tt.todo_id AND t.deleted_at IS NULL AND `+todoAccess("t.", 3, domain.ProjectEditor)+`
+			RETURNING tt.todo_id
+		)
+		UPDATE todos SET version = version + 1
+		WHERE id IN (SELECT todo_id FROM detached)
+	`, todoId, tagId, userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// getTodoTags returns the tags the user put on the given todos keyed by todo id, every
+// todo gets at least an empty slice. Tags are personal, the members of a shared project
+// do not see each other's tags on its todos.
+func (r *Repository) getTodoTags(ctx context.Context, todoIds []uuid.UUID, userId uuid.UUID) (map[uuid.UUID][]todo.TodoTag, error) {
+	tags := make(map[uuid.UUID][]todo.TodoTag, len(todoIds))
+	for _, id := range todoIds {
+		tags[id] = []todo.TodoTag{}
+	}
+	if len(todoIds) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tt.todo_id, g.id, g.name, g.color
+		FROM todo_tags tt
+		JOIN tags g ON g.id = tt.tag_id
+		WHERE tt.todo_id = ANY($1::uuid[]) AND g.user_id = $2
+		ORDER BY LOWER(g.name) ASC
+	`, pq.Array(todoIds), userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoId uuid.UUID
+		var t todo.TodoTag
+		if err := rows.Scan(&todoId, &t.Id, &t.Name, &t.Color); err != nil {
+			return nil, err
+		}
+		tags[todoId] = append(tags[todoId], t)
+	}
+
+	return tags, rows.Err()
+}
+
+func tagWriteError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return domain.ErrTagAlreadyExists
+		case "23503":
+			return domain.ErrUserNotFound
+		}
+	}
+	return err
+}
@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"database/sql"
@@ -13,12 +15,47 @@ import (
 )
 
 func (r *Repository) CreateTodo(ctx context.Context, todo *domain.Todo) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO todos (user_id, id, title, completed)
-		VALUES ($1, $2, $3, $4)
-	`, todo.UserId, todo.Id, todo.Title, todo.Completed)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := createTodo(ctx, tx, todo); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx, so that a write can run on its own
+// or as part of a batch.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func createTodo(ctx context.Context, q querier, todo *domain.Todo) error {
+	if todo.ProjectId != uuid.Nil {
+		if err := checkProjectWritable(ctx, q, todo.ProjectId, todo.UserId); err != nil {
+			return err
+		}
+	}
+	if todo.ParentId != uuid.Nil {
+		if err := checkParentWritable(ctx, q, todo.ParentId, todo.UserId); err != nil {
+			return err
+		}
+	}
+
+	if err := insertTodo(ctx, q, todo); err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			// the project or the parent has been deleted in the meantime
+			switch pqErr.Constraint {
+			case "todos_project_id_fkey":
+				return domain.ErrProjectNotFound
+			case "todos_parent_id_fkey":
+				return domain.ErrParentTodoNotFound
+			}
 			return domain.ErrUserNotFound
 		}
 		return err
@@ -26,63 +63,305 @@ func (r *Repository) CreateTodo(ctx context.Context, todo *domain.Todo) error {
 	return nil
 }
 
-func (r *Repository) UpdateTodo(ctx context.Context, id uuid.UUID, title string) error {
-	res, err := r.db.ExecContext(ctx, `UPDATE todos SET title = $1 WHERE id = $2`, title, id)
+// checkParentWritable reports whether the user can add a subtask to the todo, which may
+// be in a project shared with the user.
+func checkParentWritable(ctx context.Context, q querier, parentId, userId uuid.UUID) error {
+	var writable bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`)
+	`, parentId, userId).Scan(&writable)
+	if err != nil || writable {
+		return err
+	}
+
+	if err := todoWriteError(ctx, q, parentId, userId); err != domain.ErrTodoNotFound {
+		return err
+	}
+	return domain.ErrParentTodoNotFound
+}
+
+// insertTodo appends the todo to the manual order of its list, a subtask changes the
+// version of its ancestors. It must run in a transaction.
+func insertTodo(ctx context.Context, q querier, todo *domain.Todo) error {
+	// the list the todo lands in, with the project worked out like the INSERT below does
+	var orderId uuid.UUID
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT project_id FROM todos WHERE id = $1 AND deleted_at IS NULL), $2, $3)
+	`, nullUUID(todo.ParentId), nullUUID(todo.ProjectId), todo.UserId).Scan(&orderId)
+	if err != nil {
+		return err
+	}
+	position, err := nextTodoPosition(ctx, q, orderId)
 	if err != nil {
 		return err
 	}
 
-	if rows, _ := res.RowsAffected(); rows == 0 {
-		return domain.ErrTodoNotFound
+	rule, timezone := recurrenceColumns(todo.Recurrence)
+	// a subtask takes the project of its parent, created_at and completed_at have no time
+	// zone, they are stored in UTC
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO todos (user_id, id, title, description, completed, due_at, priority, project_id, parent_id,
+			recurrence, recurrence_timezone, occurrence_at, position, created_at, completed_at, due_timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE((SELECT project_id FROM todos WHERE id = $9 AND deleted_at IS NULL), $8), $9, $10, $11, $12, $13,
+			COALESCE($14, CURRENT_TIMESTAMP), $15, $16)
+	`, todo.UserId, todo.Id, todo.Title, todo.Description, todo.Completed, nullTime(todo.DueAt), todo.Priority.Rank(),
+		nullUUID(todo.ProjectId), nullUUID(todo.ParentId), rule, timezone, nullTime(todo.OccurrenceAt), position,
+		nullTime(todo.CreatedAt.UTC()), nullTime(todo.CompletedAt.UTC()), dueTimezone(todo.DueAt))
+	if err != nil {
+		return err
+	}
+	return bumpAncestorVersions(ctx, q, []uuid.UUID{todo.Id})
+}
+
+func (r *Repository) UpdateTodo(ctx context.Context, todo *domain.Todo) error {
+	return r.updateTodo(ctx, todo, false)
+}
+
+func (r *Repository) PatchTodo(ctx context.Context, todo *domain.Todo) error {
+	return r.updateTodo(ctx, todo, true)
+}
+
+// updateTodo sets the fields of the todo, its completion as well when setCompleted is
+// set.
+func (r *Repository) updateTodo(ctx context.Context, todo *domain.Todo, setCompleted bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	rule, timezone := recurrenceColumns(todo.Recurrence)
+	set := `
+		title = $1, description = $2, due_at = $3, priority = $4,
+		recurrence = $5, recurrence_timezone = $6, occurrence_at = $7, due_timezone = $11
+	`
+	args := []any{todo.Title, todo.Description, nullTime(todo.DueAt), todo.Priority.Rank(), rule, timezone, nullTime(todo.OccurrenceAt), todo.Id, todo.UserId,
+		todo.Version, dueTimezone(todo.DueAt)}
+	if setCompleted {
+		// completing a recurring todo inserts its next occurrence
+		if err := lockTodoOrders(ctx, tx, nil, []uuid.UUID{todo.Id}); err != nil {
+			return err
+		}
+		set += `, completed = $12, completed_at = CASE WHEN $12 THEN COALESCE(completed_at, NOW()) ELSE NULL END`
+		args = append(args, todo.Completed)
+	}
+
+	matched, err := updateTodos(ctx, tx, todo.UserId, set,
+		`id = $8 AND deleted_at IS NULL AND `+todoAccess("", 9, domain.ProjectEditor)+` AND `+todoVersion(10), args...)
+	if err != nil {
+		return err
+	}
+
+	if matched == 0 {
+		return todoVersionWriteError(ctx, tx, todo.Id, todo.UserId, todo.Version)
+	}
+
+	if setCompleted {
+		if _, err := completeRecurringTodo(ctx, tx, todo.Id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func updateTodoTitle(ctx context.Context, tx querier, id, userId uuid.UUID, title string) error {
+	matched, err := updateTodos(ctx, tx, userId, `title = $1`, `id = $2 AND deleted_at IS NULL AND `+todoAccess("", 3, domain.ProjectEditor), title, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if matched == 0 {
+		return todoWriteError(ctx, tx, id, userId)
+	}
+
+	return nil
+}
+
+// updateTodoFields sets the fields a todo.txt file carries, the description and the
+// recurrence of the todo are kept.
+func updateTodoFields(ctx context.Context, tx querier, id, userId uuid.UUID, title string, dueAt time.Time, priority domain.Priority) error {
+	matched, err := updateTodos(ctx, tx, userId, `title = $1, due_at = $2, priority = $3, due_timezone = $6`,
+		`id = $4 AND deleted_at IS NULL AND `+todoAccess("", 5, domain.ProjectEditor), title, nullTime(dueAt), priority.Rank(), id, userId,
+		dueTimezone(dueAt))
+	if err != nil {
+		return err
+	}
+
+	if matched == 0 {
+		return todoWriteError(ctx, tx, id, userId)
 	}
 
 	return nil
 }
 
-func (r *Repository) GetById(ctx context.Context, id uuid.UUID) (*todo.GetTodoByIdResponse, error) {
+func (r *Repository) GetById(ctx context.Context, id, userId uuid.UUID) (*todo.GetTodoByIdResponse, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, title, completed, created_at, completed_at
+		SELECT id, title, description, completed, created_at, completed_at, due_at, due_timezone, priority, project_id, parent_id,
+			COALESCE(recurrence, ''), COALESCE(recurrence_timezone, ''), occurrence_at, version
 		FROM todos
-		WHERE id = $1
-	`, id)
+		WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`
+	`, id, userId)
 
 	var resp todo.GetTodoByIdResponse
-	var completedAt sql.NullTime
-	if err := row.Scan(&resp.Id, &resp.Title, &resp.Completed, &resp.CreatedAt, &completedAt); err != nil {
+	var completedAt, dueAt, occurrenceAt sql.NullTime
+	var dueZone sql.NullString
+	var priority int
+	var projectId, parentId uuid.NullUUID
+	if err := row.Scan(&resp.Id, &resp.Title, &resp.Description, &resp.Completed, &resp.CreatedAt, &completedAt, &dueAt, &dueZone, &priority, &projectId, &parentId,
+		&resp.Recurrence, &resp.Timezone, &occurrenceAt, &resp.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrTodoNotFound
 		}
 		return nil, err
 	}
+	var err error
+	if resp.Priority, err = domain.PriorityFromRank(priority); err != nil {
+		return nil, err
+	}
 	if completedAt.Valid {
 		resp.CompletedAt = completedAt.Time
 	} else {
 		resp.CompletedAt = time.Time{}
 	}
+	resp.DueAt = scanDueAt(dueAt, dueZone)
+	if occurrenceAt.Valid {
+		resp.OccurrenceAt = occurrenceAt.Time.UTC()
+	}
+	resp.ProjectId = uuidPtr(projectId)
+	resp.ParentId = uuidPtr(parentId)
+
+	tags, err := r.getTodoTags(ctx, []uuid.UUID{resp.Id}, userId)
+	if err != nil {
+		return nil, err
+	}
+	resp.Tags = tags[resp.Id]
+
+	descendants, err := r.getDescendants(ctx, resp.Id, userId)
+	if err != nil {
+		return nil, err
+	}
+	resp.Subtasks, resp.Progress = todo.NewSubtaskTree(resp.Id, descendants)
+
 	return &resp, nil
 }
 
-func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM todos WHERE id = $1`, id)
-	return err
+func (r *Repository) GetByIdForAdmin(ctx context.Context, id uuid.UUID) (*todo.GetTodoByIdForAdminResponse, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, title, description, completed, created_at, completed_at, due_at, priority, project_id, parent_id
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+
+	var resp todo.GetTodoByIdForAdminResponse
+	var completedAt, dueAt sql.NullTime
+	var priority int
+	var projectId, parentId uuid.NullUUID
+	if err := row.Scan(&resp.Id, &resp.UserId, &resp.Title, &resp.Description, &resp.Completed, &resp.CreatedAt, &completedAt, &dueAt, &priority, &projectId, &parentId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrTodoNotFound
+		}
+		return nil, err
+	}
+	var err error
+	if resp.Priority, err = domain.PriorityFromRank(priority); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		resp.CompletedAt = completedAt.Time
+	}
+	if dueAt.Valid {
+		resp.DueAt = dueAt.Time.UTC()
+	}
+	resp.ProjectId = uuidPtr(projectId)
+	resp.ParentId = uuidPtr(parentId)
+	return &resp, nil
 }
 
-func (r *Repository) GetTodosByUserID(ctx context.Context, userID uuid.UUID) (*todo.GetTodosResponse, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, title, completed, created_at, completed_at
-		FROM todos
-		WHERE user_id = $1
-	`, userID)
+func (r *Repository) Delete(ctx context.Context, id, userId uuid.UUID, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	if err := deleteTodo(ctx, tx, id, userId, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteTodo moves the todo and its subtasks to the trash. They all get the same
+// deleted_at, which is how RestoreTodo tells them apart from subtasks that were trashed
+// on their own before. The version only applies to the todo itself. It must run in a
+// transaction.
+func deleteTodo(ctx context.Context, q querier, id, userId uuid.UUID, version int) error {
+	res, err := q.ExecContext(ctx, `
+		UPDATE todos SET deleted_at = NOW(), version = version + 1
+		WHERE (id = $1 OR id IN (`+descendantIdsQuery+`)) AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`
+			AND EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoVersion(3)+`)
+	`, id, userId, version)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return todoVersionWriteError(ctx, q, id, userId, version)
+	}
+
+	return bumpAncestorVersions(ctx, q, []uuid.UUID{id})
+}
+
+func (r *Repository) GetTodosByUserID(ctx context.Context, userID uuid.UUID, query todo.GetTodosQuery) (*todo.GetTodosResponse, error) {
+	orderBy, err := todoOrderBy(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.ProjectId != uuid.Nil {
+		if _, _, err := getProjectMembership(ctx, r.db, query.ProjectId, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	where, args := todoFilterConditions(userID, query)
+
+	resp := todo.GetTodosResponse{Todos: []todo.Todo{}}
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM todos"+where, args...).Scan(&resp.Total); err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		SELECT id, title, description, completed, created_at, completed_at, due_at, due_timezone, priority, project_id, parent_id,
+			COALESCE(recurrence, ''), COALESCE(recurrence_timezone, ''), COALESCE(position, '')
+		FROM todos` + where
+	if query.After != nil {
+		var condition string
+		condition, args = todoCursorCondition(query, args)
+		sqlQuery += condition
+	}
+	// one more todo than requested tells whether there is a next page
+	args = append(args, query.Limit+1)
+	sqlQuery += " ORDER BY " + orderBy + fmt.Sprintf(" LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var todos todo.GetTodosResponse
+	todos := resp.Todos
 	for rows.Next() {
 		var resp todo.Todo
-		var completedAt sql.NullTime
-		if err := rows.Scan(&resp.Id, &resp.Title, &resp.Completed, &resp.CreatedAt, &completedAt); err != nil {
+		var completedAt, dueAt sql.NullTime
+		var dueZone sql.NullString
+		var priority int
+		var projectId, parentId uuid.NullUUID
+		if err := rows.Scan(&resp.Id, &resp.Title, &resp.Description, &resp.Completed, &resp.CreatedAt, &completedAt, &dueAt, &dueZone, &priority, &projectId, &parentId,
+			&resp.Recurrence, &resp.Timezone, &resp.Position); err != nil {
+			return nil, err
+		}
+		if resp.Priority, err = domain.PriorityFromRank(priority); err != nil {
 			return nil, err
 		}
 		if completedAt.Valid {
@@ -90,6 +369,9 @@ func (r *Repository) GetTodosByUserID(ctx context.Context, userID uuid.UUID) (*t
 		} else {
 			resp.CompletedAt = time.Time{}
 		}
+		resp.DueAt = scanDueAt(dueAt, dueZone)
+		resp.ProjectId = uuidPtr(projectId)
+		resp.ParentId = uuidPtr(parentId)
 		todos = append(todos, resp)
 	}
 
@@ -97,29 +379,577 @@ func (r *Repository) GetTodosByUserID(ctx context.Context, userID uuid.UUID) (*t
 		return nil, err
 	}
 
-	return &todos, nil
+	if len(todos) > query.Limit {
+		todos = todos[:query.Limit]
+		resp.NextCursor = query.NextCursor(todos[len(todos)-1])
+	}
+
+	ids := make([]uuid.UUID, len(todos))
+	for i, t := range todos {
+		ids[i] = t.Id
+	}
+	tags, err := r.getTodoTags(ctx, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range todos {
+		todos[i].Tags = tags[todos[i].Id]
+	}
+	resp.Todos = todos
+
+	return &resp, nil
 }
 
-func (r *Repository) ToggleCompleted(ctx context.Context, id uuid.UUID) error {
-	res, err := r.db.ExecContext(ctx, `
-	UPDATE todos
-	SET completed = NOT completed,
-	    completed_at = CASE
-			WHEN NOT completed THEN NOW() 
-			ELSE NULL
-		END
-	WHERE id = $1
-	`, id)
+// The title is highlighted as a whole, the description is cut down to its best matching
+// fragments.
+var (
+	titleHeadlineOptions   = fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, todo.HighlightStart, todo.HighlightStop)
+	snippetHeadlineOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "`,
+		todo.HighlightStart, todo.HighlightStop)
+)
+
+func (r *Repository) SearchTodos(ctx context.Context, userId uuid.UUID, query todo.SearchTodosQuery) (*todo.SearchTodosResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH search AS (SELECT to_tsquery($1::regconfig, $2) AS query)
+		SELECT id, title, completed, due_at, priority, project_id,
+			ts_rank_cd(search_vector, search.query) AS rank,
+			ts_headline($1::regconfig, title, search.query, $3),
+			ts_headline($1::regconfig, description, search.query, $4)
+		FROM todos, search
+		WHERE deleted_at IS NULL AND `+todoAccess("", 5, domain.ProjectViewer)+` AND search_vector @@ search.query
+		ORDER BY rank DESC, created_at DESC, id ASC
+		LIMIT $6
+	`, r.searchLanguage, prefixTsQuery(query.Terms), titleHeadlineOptions, snippetHeadlineOptions, userId, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp := todo.SearchTodosResponse{Results: []todo.SearchResult{}}
+	for rows.Next() {
+		var result todo.SearchResult
+		var dueAt sql.NullTime
+		var priority int
+		var projectId uuid.NullUUID
+		if err := rows.Scan(&result.Id, &result.Title, &result.Completed, &dueAt, &priority, &projectId,
+			&result.Rank, &result.TitleHighlight, &result.Snippet); err != nil {
+			return nil, err
+		}
+		if result.Priority, err = domain.PriorityFromRank(priority); err != nil {
+			return nil, err
+		}
+		if dueAt.Valid {
+			result.DueAt = dueAt.Time.UTC()
+		}
+		result.ProjectId = uuidPtr(projectId)
+		resp.Results = append(resp.Results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// prefixTsQuery requires every term, as a word or as the beginning of one. The terms
+// only contain letters and digits, so they cannot inject tsquery operators.
+func prefixTsQuery(terms []string) string {
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	return strings.Join(prefixes, " & ")
+}
+
+// todoFilterConditions returns the WHERE clause of a todo list with its arguments, the
+// user id is always the first one. The list holds the todos of the user and the ones of
+// the projects shared with the user.
+func todoFilterConditions(userID uuid.UUID, query todo.GetTodosQuery) (string, []any) {
+	where := " WHERE deleted_at IS NULL AND " + todoAccess("", 1, domain.ProjectViewer)
+	args := []any{userID}
+
+	if query.ProjectId != uuid.Nil {
+		args = append(args, query.ProjectId)
+		where += fmt.Sprintf(" AND project_id = $%d", len(args))
+	}
+
+	if !query.DueAfter.IsZero() {
+		args = append(args, query.DueAfter)
+		where += fmt.Sprintf(" AND due_at >= $%d", len(args))
+	}
+	if !query.DueBefore.IsZero() {
+		args = append(args, query.DueBefore)
+		where += fmt.Sprintf(" AND due_at < $%d", len(args))
+	}
+	if !query.CreatedAfter.IsZero() {
+		args = append(args, query.CreatedAfter)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !query.CreatedBefore.IsZero() {
+		args = append(args, query.CreatedBefore)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if query.Completed != nil {
+		args = append(args, *query.Completed)
+		where += fmt.Sprintf(" AND completed = $%d", len(args))
+	}
+	if query.Overdue {
+		// see domain.IsOverdue, an all-day due date is overdue once its day has ended
+		where += " AND due_at < NOW() AND NOT completed AND (" + localDueAt("due_at") + "::time <> '00:00' OR " +
+			localDueAt("due_at") + "::date < " + localDueAt("NOW()") + "::date)"
+	}
+	if query.Search != "" {
+		args = append(args, "%"+escapeLike(query.Search)+"%")
+		where += fmt.Sprintf(" AND (title ILIKE $%d OR description ILIKE $%d)", len(args), len(args))
+	}
+	if len(query.Tags) > 0 {
+		args = append(args, pq.Array(query.Tags))
+		where += todoTagCondition(query.TagMode, 1, len(args))
+		if query.TagMode == todo.TagModeAnd {
+			args = append(args, len(query.Tags))
+		}
+	}
+
+	return where, args
+}
+
+// todoCursorCondition skips the todos up to the cursor, following the order built by
+// todoOrderBy: the sort column first, then created_at and id, which are always ascending.
+func todoCursorCondition(query todo.GetTodosQuery, args []any) (string, []any) {
+	cursor := query.After
+	args = append(args, cursor.CreatedAt, cursor.Id)
+	tie := fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)-1, len(args))
+
+	op := ">"
+	if query.Order == todo.SortDesc {
+		op = "<"
+	}
+
+	value := "$%d"
+	switch query.Sort {
+	case todo.SortByPriority:
+		args = append(args, cursor.Priority)
+	case todo.SortByCreatedAt:
+		args = append(args, cursor.CreatedAt)
+	case todo.SortByTitle:
+		args = append(args, cursor.Title)
+		value = "LOWER($%d)"
+	case todo.SortByDueAt:
+		// todos without a due date come last in both directions
+		if cursor.DueAt == nil {
+			return " AND due_at IS NULL AND " + tie, args
+		}
+		args = append(args, *cursor.DueAt)
+		return fmt.Sprintf(" AND (due_at %s $%d OR due_at IS NULL OR (due_at = $%d AND %s))", op, len(args), len(args), tie), args
+	case todo.SortByPosition:
+		// so do todos that have not been given a position yet
+		if cursor.Position == nil {
+			return " AND position IS NULL AND " + tie, args
+		}
+		args = append(args, *cursor.Position)
+		return fmt.Sprintf(" AND (position %s $%d OR position IS NULL OR (position = $%d AND %s))", op, len(args), len(args), tie), args
+	}
+
+	column := todoSortColumns[query.Sort]
+	value = fmt.Sprintf(value, len(args))
+	return fmt.Sprintf(" AND (%s %s %s OR (%s = %s AND %s))", column, op, value, column, value, tie), args
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, backslash is the default escape
+// character of Postgres.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *Repository) ToggleCompleted(ctx context.Context, id, userId uuid.UUID, completeSubtasks bool, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	// completing a recurring todo inserts its next occurrence
+	if err := lockTodoOrders(ctx, tx, nil, []uuid.UUID{id}); err != nil {
+		return err
+	}
+
+	matched, err := updateTodos(ctx, tx, userId, `
+		completed = NOT completed,
+		completed_at = CASE WHEN NOT completed THEN NOW() ELSE NULL END
+	`, `id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+` AND `+todoVersion(3), id, userId, version)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return todoVersionWriteError(ctx, tx, id, userId, version)
+	}
+
+	completed, err := completeRecurringTodo(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if completed && completeSubtasks {
+		_, err = updateTodos(ctx, tx, userId, `completed = TRUE, completed_at = NOW()`,
+			`id IN (`+descendantIdsQuery+`) AND NOT completed AND deleted_at IS NULL`, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// setTodoCompleted completes or reopens a todo, completing a completed todo again keeps
+// its completion time. Unlike ToggleCompleted it never touches the subtasks.
+func setTodoCompleted(ctx context.Context, tx querier, id, userId uuid.UUID, completed bool) error {
+	matched, err := updateTodos(ctx, tx, userId, `
+		completed = $3,
+		completed_at = CASE WHEN $3 THEN COALESCE(completed_at, NOW()) ELSE NULL END
+	`, `id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor), id, userId, completed)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return todoWriteError(ctx, tx, id, userId)
+	}
+
+	_, err = completeRecurringTodo(ctx, tx, id)
+	return err
+}
+
+// completeRecurringTodo creates the next occurrence of the todo when it is a completed
+// occurrence of a series, and tells whether the todo is completed. Completing the same
+// occurrence again, after reopening it, must not repeat the series twice.
+func completeRecurringTodo(ctx context.Context, tx querier, id uuid.UUID) (bool, error) {
+	var completed, recurring bool
+	var nextOccurrenceId uuid.NullUUID
+	err := tx.QueryRowContext(ctx, `
+		SELECT completed, recurrence IS NOT NULL, next_occurrence_id FROM todos WHERE id = $1
+	`, id).Scan(&completed, &recurring, &nextOccurrenceId)
+	if err != nil {
+		return false, err
+	}
+
+	if completed && recurring && !nextOccurrenceId.Valid {
+		return completed, createNextOccurrence(ctx, tx, id)
+	}
+	return completed, nil
+}
+
+// createNextOccurrence creates the todo that follows the given one in its series,
+// with the same tags, and links the two. It must run in the transaction that completes
+// the todo.
+func createNextOccurrence(ctx context.Context, tx querier, id uuid.UUID) error {
+	var current domain.Todo
+	var dueAt, occurrenceAt sql.NullTime
+	var priority int
+	var projectId, parentId uuid.NullUUID
+	var dueZone, rule, timezone sql.NullString
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, user_id, title, description, due_at, due_timezone, priority, project_id, parent_id,
+			recurrence, recurrence_timezone, occurrence_at
+		FROM todos
+		WHERE id = $1
+	`, id).Scan(&current.Id, &current.UserId, &current.Title, &current.Description, &dueAt, &dueZone, &priority, &projectId, &parentId,
+		&rule, &timezone, &occurrenceAt)
+	if err != nil {
+		return err
+	}
+	if current.Priority, err = domain.PriorityFromRank(priority); err != nil {
+		return err
+	}
+	if current.Recurrence, err = scanRecurrence(rule, timezone); err != nil {
+		return err
+	}
+	current.DueAt = scanDueAt(dueAt, dueZone)
+	current.OccurrenceAt = occurrenceAt.Time
+	current.ProjectId = projectId.UUID
+	current.ParentId = parentId.UUID
 
+	next := current.NextOccurrence()
+	if next == nil {
+		return nil
+	}
+
+	if err := insertTodo(ctx, tx, next); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, tag_id FROM todo_tags WHERE todo_id = $2
+	`, next.Id, current.Id)
 	if err != nil {
 		return err
 	}
-	rowsAffected, err := res.RowsAffected()
+	_, err = tx.ExecContext(ctx, `UPDATE todos SET next_occurrence_id = $1 WHERE id = $2`, next.Id, current.Id)
+	return err
+}
+
+func (r *Repository) MoveTodo(ctx context.Context, id, userId, projectId uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
+	defer rollbackTx(tx)
+
+	if projectId != uuid.Nil {
+		if err := lockTodoOrder(ctx, tx, projectId); err != nil {
+			return err
+		}
+	}
+	if err := moveTodo(ctx, tx, id, userId, projectId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// A todo moved out of a shared project lands in the inbox of its creator. Only a
+// top-level todo is moved, its subtasks follow it into the project. The todos that
+// change list are appended to the end of the one they land in.
+func moveTodo(ctx context.Context, tx querier, id, userId, projectId uuid.UUID) error {
+	var parentId uuid.NullUUID
+	err := tx.QueryRowContext(ctx, `
+		SELECT parent_id FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`
+	`, id, userId).Scan(&parentId)
+	if err == sql.ErrNoRows {
 		return domain.ErrTodoNotFound
 	}
-	return nil
+	if err != nil {
+		return err
+	}
+	if parentId.Valid {
+		return domain.ErrSubtaskWithProject
+	}
+
+	if projectId != uuid.Nil {
+		if err := checkProjectWritable(ctx, tx, projectId, userId); err != nil {
+			return err
+		}
+	}
+
+	// trashed subtasks move as well, so that they are restored into the project of their parent
+	matched, err := updateTodos(ctx, tx, userId, `
+		project_id = $3, position = CASE WHEN project_id IS DISTINCT FROM $3 THEN NULL ELSE position END
+	`, `
+		(id = $1 OR id IN (`+descendantIdsQuery+`)) AND `+todoAccess("", 2, domain.ProjectEditor)+`
+		AND EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL)
+	`, id, userId, nullUUID(projectId))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+
+	if matched == 0 {
+		return todoWriteError(ctx, tx, id, userId)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM todos WHERE id = $1 OR id IN (`+descendantIdsQuery+`)`, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var moved []uuid.UUID
+	for rows.Next() {
+		var movedId uuid.UUID
+		if err := rows.Scan(&movedId); err != nil {
+			return err
+		}
+		moved = append(moved, movedId)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	return appendTodoPositions(ctx, tx, moved)
+}
+
+func (r *Repository) GetTodoDepth(ctx context.Context, id, userId uuid.UUID) (int, error) {
+	var depth sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id, 0 AS depth FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`
+			UNION ALL
+			SELECT t.parent_id, a.depth + 1 FROM todos t JOIN ancestors a ON t.id = a.parent_id
+		)
+		SELECT MAX(depth) FROM ancestors
+	`, id, userId).Scan(&depth)
+	if err != nil {
+		return 0, err
+	}
+	if !depth.Valid {
+		return 0, domain.ErrTodoNotFound
+	}
+	return int(depth.Int64), nil
+}
+
+// descendantIdsQuery selects the ids of every subtask below the todo passed as $1,
+// trashed ones included.
+const descendantIdsQuery = `
+	WITH RECURSIVE descendants AS (
+		SELECT id FROM todos WHERE parent_id = $1
+		UNION ALL
+		SELECT t.id FROM todos t JOIN descendants d ON t.parent_id = d.id
+	)
+	SELECT id FROM descendants`
+
+// getDescendants returns every subtask below the todo the user can view as a flat list,
+// in creation order. A subtask the user cannot view is left out with its own subtasks.
+func (r *Repository) getDescendants(ctx context.Context, id, userId uuid.UUID) ([]todo.Subtask, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE descendants AS (
+			SELECT id, parent_id, title, completed, created_at, due_at, priority FROM todos
+			WHERE parent_id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectViewer)+`
+			UNION ALL
+			SELECT t.id, t.parent_id, t.title, t.completed, t.created_at, t.due_at, t.priority
+			FROM todos t JOIN descendants d ON t.parent_id = d.id
+			WHERE t.deleted_at IS NULL AND `+todoAccess("t.", 2, domain.ProjectViewer)+`
+		)
+		SELECT id, parent_id, title, completed, created_at, due_at, priority
+		FROM descendants
+		ORDER BY created_at ASC, id ASC
+	`, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subtasks []todo.Subtask
+	for rows.Next() {
+		var subtask todo.Subtask
+		var dueAt sql.NullTime
+		var priority int
+		if err := rows.Scan(&subtask.Id, &subtask.ParentId, &subtask.Title, &subtask.Completed, &subtask.CreatedAt, &dueAt, &priority); err != nil {
+			return nil, err
+		}
+		if subtask.Priority, err = domain.PriorityFromRank(priority); err != nil {
+			return nil, err
+		}
+		if dueAt.Valid {
+			subtask.DueAt = dueAt.Time.UTC()
+		}
+		subtasks = append(subtasks, subtask)
+	}
+
+	return subtasks, rows.Err()
+}
+
+var todoSortColumns = map[todo.TodoSortField]string{
+	todo.SortByPriority:  "priority",
+	todo.SortByCreatedAt: "created_at",
+	todo.SortByDueAt:     "due_at",
+	todo.SortByTitle:     "LOWER(title)",
+	todo.SortByPosition:  "position",
+}
+
+// The ORDER BY clause is only built from the whitelisted columns above. Todos without
+// a due date or a position come last in both directions, the id makes the order
+// deterministic.
+func todoOrderBy(query todo.GetTodosQuery) (string, error) {
+	column, ok := todoSortColumns[query.Sort]
+	if !ok {
+		return "", domain.ErrInvalidSort
+	}
+
+	var direction string
+	switch query.Order {
+	case todo.SortAsc:
+		direction = "ASC"
+	case todo.SortDesc:
+		direction = "DESC"
+	default:
+		return "", domain.ErrInvalidSortOrder
+	}
+
+	orderBy := column + " " + direction
+	if query.Sort == todo.SortByDueAt || query.Sort == todo.SortByPosition {
+		orderBy += " NULLS LAST"
+	}
+	return orderBy + ", created_at ASC, id ASC", nil
+}
+
+// Tag names are matched case-insensitively, namesArg is the position of the lowercase
+// names. With TagModeAnd the number of names follows at namesArg+1. Only the tags of
+// the user at userArg are matched, a shared todo is not found by another member's tag.
+func todoTagCondition(mode todo.TagMode, userArg, namesArg int) string {
+	if mode == todo.TagModeOr {
+		return fmt.Sprintf(`
+		AND EXISTS (
+			SELECT 1 FROM todo_tags tt
+			JOIN tags g ON g.id = tt.tag_id
+			WHERE tt.todo_id = todos.id AND g.user_id = $%d AND LOWER(g.name) = ANY($%d)
+		)`, userArg, namesArg)
+	}
+	return fmt.Sprintf(`
+		AND id IN (
+			SELECT tt.todo_id FROM todo_tags tt
+			JOIN tags g ON g.id = tt.tag_id
+			WHERE g.user_id = $%d AND LOWER(g.name) = ANY($%d)
+			GROUP BY tt.todo_id
+			HAVING COUNT(DISTINCT LOWER(g.name)) = $%d
+		)`, userArg, namesArg, namesArg+1)
+}
+
+// uuid.Nil is stored as NULL, e.g. a todo in the inbox has no project_id.
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
+
+func uuidPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func recurrenceColumns(recurrence *domain.Recurrence) (sql.NullString, sql.NullString) {
+	if recurrence == nil {
+		return sql.NullString{}, sql.NullString{}
+	}
+	return sql.NullString{String: recurrence.Rule.String(), Valid: true},
+		sql.NullString{String: recurrence.Timezone.String(), Valid: true}
+}
+
+func scanRecurrence(rule, timezone sql.NullString) (*domain.Recurrence, error) {
+	if !rule.Valid {
+		return nil, nil
+	}
+	return domain.NewRecurrence(rule.String, timezone.String)
+}
+
+// A zero time is stored as NULL, that's how "not set" is represented in the domain.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// dueTimezone is stored next to due_at, NULL when the todo has no due date.
+func dueTimezone(dueAt time.Time) sql.NullString {
+	if dueAt.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: domain.DueTimezone(dueAt), Valid: true}
+}
+
+// scanDueAt returns the due date in the zone it was set in, the due dates set before
+// the zone was stored are in UTC.
+func scanDueAt(dueAt sql.NullTime, timezone sql.NullString) time.Time {
+	if !dueAt.Valid {
+		return time.Time{}
+	}
+	loc, err := domain.LoadDueTimezone(timezone.String)
+	if err != nil {
+		loc = time.UTC
+	}
+	return dueAt.Time.In(loc)
+}
+
+// localDueAt is the wall clock time of the timestamp at in the zone of the due date.
+// Postgres reads a bare offset such as "+02:00" the POSIX way, west of UTC being
+// positive, so an offset is read as an interval instead.
+func localDueAt(at string) string {
+	return fmt.Sprintf(`(CASE WHEN due_timezone ~ '^[+-]' THEN timezone(due_timezone::interval, %[1]s)
+		ELSE timezone(COALESCE(due_timezone, 'UTC'), %[1]s) END)`, at)
 }
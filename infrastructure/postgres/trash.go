@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// The trash holds the trashed todos the user can edit, the ones of shared projects
+// included. A subtask that shares deleted_at with its parent was trashed together with
+// it, only the parent is listed.
+func (r *Repository) GetTrash(ctx context.Context, userId uuid.UUID) ([]todo.TrashedTodo, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, t.title, t.description, t.completed, t.created_at, t.due_at, t.priority, t.project_id, t.parent_id, t.deleted_at
+		FROM todos t
+		LEFT JOIN todos p ON p.id = t.parent_id
+		WHERE t.deleted_at IS NOT NULL AND `+todoAccess("t.", 1, domain.ProjectEditor)+`
+			AND (p.deleted_at IS NULL OR p.deleted_at <> t.deleted_at)
+		ORDER BY t.deleted_at DESC, t.id ASC
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []todo.TrashedTodo{}
+	for rows.Next() {
+		var trashed todo.TrashedTodo
+		var dueAt sql.NullTime
+		var priority int
+		var projectId, parentId uuid.NullUUID
+		if err := rows.Scan(&trashed.Id, &trashed.Title, &trashed.Description, &trashed.Completed, &trashed.CreatedAt, &dueAt, &priority,
+			&projectId, &parentId, &trashed.DeletedAt); err != nil {
+			return nil, err
+		}
+		if trashed.Priority, err = domain.PriorityFromRank(priority); err != nil {
+			return nil, err
+		}
+		if dueAt.Valid {
+			trashed.DueAt = dueAt.Time.UTC()
+		}
+		trashed.DeletedAt = trashed.DeletedAt.UTC()
+		trashed.ProjectId = uuidPtr(projectId)
+		trashed.ParentId = uuidPtr(parentId)
+		todos = append(todos, trashed)
+	}
+
+	return todos, rows.Err()
+}
+
+func (r *Repository) RestoreTodo(ctx context.Context, id, userId uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollbackTx(tx)
+
+	var parentTrashed bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.deleted_at IS NOT NULL
+		FROM todos t
+		LEFT JOIN todos p ON p.id = t.parent_id
+		WHERE t.id = $1 AND t.deleted_at IS NOT NULL AND `+todoAccess("t.", 2, domain.ProjectEditor)+`
+		FOR UPDATE OF t
+	`, id, userId).Scan(&parentTrashed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrTodoNotFound
+		}
+		return err
+	}
+	if parentTrashed {
+		return domain.ErrParentTodoInTrash
+	}
+
+	// subtasks trashed on their own before stay in the trash
+	_, err = tx.ExecContext(ctx, `
+		WITH RECURSIVE restored AS (
+			SELECT id, deleted_at FROM todos WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.deleted_at FROM todos t JOIN restored r ON t.parent_id = r.id
+			WHERE t.deleted_at = r.deleted_at
+		)
+		UPDATE todos SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM restored)
+	`, id)
+	if err != nil {
+		return err
+	}
+	if err := bumpAncestorVersions(ctx, tx, []uuid.UUID{id}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// The subtasks are deleted by the ON DELETE CASCADE of parent_id.
+func (r *Repository) DeleteTrashedTodo(ctx context.Context, id, userId uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM todos WHERE id = $1 AND deleted_at IS NOT NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`
+	`, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domain.ErrTodoNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) EmptyTrash(ctx context.Context, userId uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM todos WHERE deleted_at IS NOT NULL AND `+todoAccess("", 1, domain.ProjectEditor)+`
+	`, userId)
+	return err
+}
+
+func (r *Repository) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM todos
+		WHERE id IN (
+			SELECT id FROM todos
+			WHERE deleted_at < $1
+			ORDER BY deleted_at ASC
+			LIMIT $2
+		)
+	`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := res.RowsAffected()
+	return int(rows), err
+}
@@ -42,11 +42,19 @@ func (r *Repository) DeleteAccount(ctx context.Context, id uuid.UUID) (string, s
 	if err != nil {
 		return "", "", err
 	}
+	// the todos other members created in the projects of the user move to their inboxes
+	moved, err := clearTodoPositions(ctx, tx, `user_id <> $1 AND project_id IN (SELECT id FROM projects WHERE user_id = $1)`, id)
+	if err != nil {
+		return "", "", err
+	}
 	var fullName, email string
 	err = tx.QueryRowContext(ctx, "DELETE FROM users WHERE id = $1 RETURNING fullname, email", id).Scan(&fullName, &email)
 	if err != nil {
 		return "", "", err
 	}
+	if err := appendTodoPositions(ctx, tx, moved); err != nil {
+		return "", "", err
+	}
 	if err := tx.Commit(); err != nil {
 		return "", "", err
 	}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// todoVersion returns the condition under which a todo is at the version passed as
+// $versionArg, version 0 matches any version.
+func todoVersion(versionArg int) string {
+	return fmt.Sprintf(`($%[1]d = 0 OR version = $%[1]d)`, versionArg)
+}
+
+// todoVersionWriteError tells why a write to the todo at version matched no todo: the
+// todo may have changed since the user read it, or todoWriteError applies.
+func todoVersionWriteError(ctx context.Context, q querier, id, userId uuid.UUID, version int) error {
+	if version == 0 {
+		return todoWriteError(ctx, q, id, userId)
+	}
+
+	var current int
+	err := q.QueryRowContext(ctx, `
+		SELECT version FROM todos WHERE id = $1 AND deleted_at IS NULL AND `+todoAccess("", 2, domain.ProjectEditor)+`
+	`, id, userId).Scan(&current)
+	if err == nil && current != version {
+		return domain.ErrTodoVersionMismatch
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return todoWriteError(ctx, q, id, userId)
+}
+
+// bumpAncestorVersions increments the version of every todo above the given todos. A
+// todo is read together with its subtasks, so a change to a subtask changes each of its
+// ancestors as well. It must run in the transaction of the change.
+func bumpAncestorVersions(ctx context.Context, q querier, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := q.ExecContext(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id AS id FROM todos WHERE id = ANY($1) AND parent_id IS NOT NULL
+			UNION
+			SELECT t.parent_id FROM todos t JOIN ancestors a ON t.id = a.id WHERE t.parent_id IS NOT NULL
+		)
+		UPDATE todos SET version = version + 1
+		WHERE id IN (SELECT id FROM ancestors)
+	`, pq.Array(ids))
+	return err
+}
@@ -48,6 +48,6 @@ func (r *RedisClient) Set(ctx context.Context, key string, value []byte, ttl tim
 	return r.client.Set(ctx, key, value, ttl).Err()
 }
 
-func (r *RedisClient) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
 }
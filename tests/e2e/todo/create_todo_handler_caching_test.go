@@ -14,6 +14,7 @@ import (
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
 	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
 	redisInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/redis"
 	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
@@ -65,8 +66,10 @@ func TestCreateTodoHandlerCaching(t *testing.T) {
 
 	redisClient := redisInfra.NewRedisClient(redisAddr)
 
-	createTodoHandler := todo.NewCreateTodoHandler(repo, redisClient, logger)
-	getTodosHandler := todo.NewGetTodosHandler(repo, redisClient, time.Minute*5)
+	cachedRepo := todo.NewCachedTodoRepository(repo, redisClient, logger, time.Minute*5)
+
+	createTodoHandler := todo.NewCreateTodoHandler(cachedRepo)
+	getTodosHandler := todo.NewGetTodosHandler(cachedRepo, markdownInfra.NewRenderer())
 	app.Post("/todos", fiberInfra.Handle(createTodoHandler, logger))
 	app.Get("/todos", fiberInfra.Handle(getTodosHandler, logger))
 
@@ -143,19 +146,19 @@ func sendTestGetRequestForCreatedTodos(t *testing.T, app *fiber.App, cgt *CacheG
 	var todosResponse todo.GetTodosResponse
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&todosResponse), "failed to decode response body")
 
-	require.Equal(t, cgt.wantTodosCount, len(todosResponse), "GET todos count mismatch")
+	require.Equal(t, cgt.wantTodosCount, len(todosResponse.Todos), "GET todos count mismatch")
 }
 
 func createNTodosAndCache(t *testing.T, repo todo.TodoRepository, cacheClient domain.Cache, n int) {
 	ctx := context.Background()
-	var todos []*domain.Todo
+	todos := todo.GetTodosResponse{Todos: []todo.Todo{}}
 	for range n {
-		newTodo, _ := domain.NewTodo(domain.TestUser.Id, domain.TestTodo.Title)
+		newTodo, _ := domain.NewTodo(domain.TestUser.Id, domain.TestTodo.Title, "", time.Time{}, domain.PriorityNone)
 		err := repo.CreateTodo(ctx, newTodo)
 		require.NoError(t, err, "failed to create todo")
 
-		todos = append(todos, newTodo)
-
+		todos.Todos = append(todos.Todos, todo.Todo{Id: newTodo.Id, Title: newTodo.Title, CreatedAt: newTodo.CreatedAt})
+		todos.Total++
 	}
 	data, err := json.Marshal(todos)
 	require.NoError(t, err, "failed to marshal todos to JSON")
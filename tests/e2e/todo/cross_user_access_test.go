@@ -0,0 +1,107 @@
+package e2etest_todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossUserTodoAccess(t *testing.T) {
+	app := fiber.New()
+
+	tokenService := testUtils.NewTestJWETokenService()
+	logger := slogInfra.NewLogger()
+	middlewareManager := fiberInfra.NewMiddlewareManager(tokenService, logger)
+	app.Use(middlewareManager.AuthMiddleware)
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupSecondTestUser(t, connStr)
+
+	app.Get("/todos/:id", fiberInfra.Handle(todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer()), logger))
+	app.Put("/todos/:id", fiberInfra.Handle(todo.NewUpdateTodoHandler(repo), logger))
+	app.Patch("/todos/:id", fiberInfra.Handle(todo.NewToggleCompletedTodoHandler(repo), logger))
+	app.Delete("/todos/:id", fiberInfra.Handle(todo.NewDeleteTodoHandler(repo), logger))
+
+	ownerToken, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate owner token")
+
+	otherUserToken, err := tokenService.GenerateAuthAccessToken(domain.SecondUserId, domain.SecondTestUser.Role)
+	require.NoError(t, err, "failed to generate other user token")
+
+	ownerTokenHeader := "Bearer " + ownerToken
+	otherUserTokenHeader := "Bearer " + otherUserToken
+
+	todoId := uuid.New()
+	setupTestTodoWithTitle(t, todoId, connStr)
+
+	updateBody, err := json.Marshal(&todo.UpdateTodoRequest{Title: "Hijacked Title"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+	}{
+		{"other user GET", http.MethodGet, nil},
+		{"other user PUT", http.MethodPut, updateBody},
+		{"other user PATCH", http.MethodPatch, nil},
+		{"other user DELETE", http.MethodDelete, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/todos/"+todoId.String(), bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", otherUserTokenHeader)
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err, "failed to send request")
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusNotFound, resp.StatusCode, "status code mismatch")
+			testUtils.VerifyErrorResponse(t, resp.Body, domain.ErrTodoNotFound)
+		})
+	}
+
+	// the owner must still see the todo exactly as it was created
+	t.Run("owner GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/todos/"+todoId.String(), nil)
+		req.Header.Set("Authorization", ownerTokenHeader)
+
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err, "failed to send GET request")
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode, "GET status code mismatch")
+		verifyGetRequestSuccessResponse(t, resp.Body, &todo.GetTodoByIdResponse{
+			Id:        todoId,
+			Title:     domain.TestTodo.Title,
+			Completed: domain.TestTodo.Completed,
+		})
+	})
+}
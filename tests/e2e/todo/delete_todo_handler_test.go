@@ -13,6 +13,7 @@ import (
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
 	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
 	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
 	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
@@ -56,7 +57,7 @@ func TestDeleteTodoHandler(t *testing.T) {
 	setupTestUser(t, connStr)
 
 	deleteTodoHandler := todo.NewDeleteTodoHandler(repo)
-	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo)
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
 	app.Delete("/todos/:id", fiberInfra.Handle(deleteTodoHandler, logger))
 	app.Get("/todos/:id", fiberInfra.Handle(getTodoByIdHandler, logger))
 
@@ -83,8 +84,8 @@ func TestDeleteTodoHandler(t *testing.T) {
 			args: &deleteTodoHandlerArgs{
 				authHeader: validTokenHeader,
 			},
-			deleteCode:        http.StatusNoContent,
-			wantDeleteErr:     nil,
+			deleteCode:        http.StatusNotFound,
+			wantDeleteErr:     domain.ErrTodoNotFound,
 			getCode:           http.StatusNotFound,
 			wantGetErr:        domain.ErrTodoNotFound,
 			wantGetResp:       nil,
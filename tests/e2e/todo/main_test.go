@@ -39,6 +39,25 @@ func setupTestUser(t *testing.T, connStr string) {
 
 }
 
+func setupSecondTestUser(t *testing.T, connStr string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	hashedPassword, err := domain.HashPassword(domain.SecondTestUser.Password)
+	require.NoError(t, err)
+
+	query := "INSERT INTO users (id, fullname, email, password, role) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		domain.SecondTestUser.Id,
+		domain.SecondTestUser.FullName,
+		domain.SecondTestUser.Email,
+		hashedPassword,
+		domain.SecondTestUser.Role,
+	)
+	require.NoError(t, err)
+}
+
 func setupTestTodoWithTitle(t *testing.T, id uuid.UUID, connStr string) {
 	t.Helper()
 
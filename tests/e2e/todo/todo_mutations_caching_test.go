@@ -0,0 +1,121 @@
+package e2etest_todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	redisInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/redis"
+	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoMutationsInvalidateCache(t *testing.T) {
+	app := fiber.New()
+
+	tokenService := testUtils.NewTestJWETokenService()
+	logger := slogInfra.NewLogger()
+	middlewareManager := fiberInfra.NewMiddlewareManager(tokenService, logger)
+	app.Use(middlewareManager.AuthMiddleware)
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	redisContainer, redisAddr := testUtils.CreateRedisTestContainer(t, ctx)
+	defer func() {
+		err := redisContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate redis container")
+
+	}()
+
+	cachedRepo := todo.NewCachedTodoRepository(repo, redisInfra.NewRedisClient(redisAddr), logger, time.Minute*5)
+
+	app.Get("/todos", fiberInfra.Handle(todo.NewGetTodosHandler(cachedRepo, markdownInfra.NewRenderer()), logger))
+	app.Put("/todos/:id", fiberInfra.Handle(todo.NewUpdateTodoHandler(cachedRepo), logger))
+	app.Patch("/todos/:id", fiberInfra.Handle(todo.NewToggleCompletedTodoHandler(cachedRepo), logger))
+	app.Delete("/todos/:id", fiberInfra.Handle(todo.NewDeleteTodoHandler(cachedRepo), logger))
+
+	validToken, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate valid token")
+
+	validTokenHeader := "Bearer " + validToken
+
+	updatedId, toggledId, deletedId := uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{updatedId, toggledId, deletedId} {
+		setupTestTodoWithTitle(t, id, connStr)
+	}
+
+	// warm the cache before every mutation
+	require.Len(t, getTodosFromAPI(t, app, validTokenHeader), 3)
+
+	updateBody, err := json.Marshal(&todo.UpdateTodoRequest{Title: "Updated Test Todo"})
+	require.NoError(t, err)
+	sendMutation(t, app, http.MethodPut, updatedId, updateBody, validTokenHeader)
+	assert.Equal(t, "Updated Test Todo", findTodo(t, getTodosFromAPI(t, app, validTokenHeader), updatedId).Title)
+
+	sendMutation(t, app, http.MethodPatch, toggledId, nil, validTokenHeader)
+	assert.True(t, findTodo(t, getTodosFromAPI(t, app, validTokenHeader), toggledId).Completed)
+
+	sendMutation(t, app, http.MethodDelete, deletedId, nil, validTokenHeader)
+	assert.Len(t, getTodosFromAPI(t, app, validTokenHeader), 2)
+}
+
+func sendMutation(t *testing.T, app *fiber.App, method string, id uuid.UUID, body []byte, authHeader string) {
+	req := httptest.NewRequest(method, "/todos/"+id.String(), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authHeader)
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err, "failed to send %s request", method)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusNoContent, resp.StatusCode, "%s status code mismatch", method)
+}
+
+func getTodosFromAPI(t *testing.T, app *fiber.App, authHeader string) todo.GetTodosResponse {
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set("Authorization", authHeader)
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err, "failed to send GET request")
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode, "GET status code mismatch")
+
+	var todos todo.GetTodosResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&todos), "failed to decode response body")
+	return todos
+}
+
+func findTodo(t *testing.T, todos todo.GetTodosResponse, id uuid.UUID) todo.Todo {
+	for _, td := range todos.Todos {
+		if td.Id == id {
+			return td
+		}
+	}
+	require.FailNow(t, "todo not found in list", id.String())
+	return todo.Todo{}
+}
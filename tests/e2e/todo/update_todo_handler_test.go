@@ -14,6 +14,7 @@ import (
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
 	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
 	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
 	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
@@ -58,7 +59,7 @@ func TestUpdateTodoHandler(t *testing.T) {
 	setupTestUser(t, connStr)
 
 	updateTodoHandler := todo.NewUpdateTodoHandler(repo)
-	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo)
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
 	app.Put("/todos/:id", fiberInfra.Handle(updateTodoHandler, logger))
 	app.Get("/todos/:id", fiberInfra.Handle(getTodoByIdHandler, logger))
 
@@ -0,0 +1,122 @@
+package httptest_attachment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/attachment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	s3Infra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/s3"
+	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentHandlers(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: domain.MaxAttachmentSize + 1<<20})
+	tokenService := testUtils.NewTestJWETokenService()
+	logger := slogInfra.NewLogger()
+	middlewareManager := fiberInfra.NewMiddlewareManager(tokenService, logger)
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	todoId := setupTestTodo(t, connStr)
+
+	s3 := testUtils.NewS3StandIn(t)
+	blobs := s3Infra.NewBlobStore(s3.Config())
+
+	app.Post("/todos/:id/attachments", middlewareManager.AuthMiddleware, fiberInfra.Handle(attachment.NewUploadAttachmentHandler(repo, blobs, tokenService, logger), logger))
+	app.Get("/todos/:id/attachments", middlewareManager.AuthMiddleware, fiberInfra.Handle(attachment.NewGetAttachmentsHandler(repo, tokenService), logger))
+	app.Get("/attachments/:id", fiberInfra.Handle(attachment.NewDownloadAttachmentHandler(repo, blobs, tokenService), logger))
+
+	token, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
+	require.NoError(t, err)
+
+	upload := func(name string, content []byte) *http.Response {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		part, err := w.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/todos/"+todoId.String()+"/attachments", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return res
+	}
+
+	content := []byte("%PDF-1.7\n% the minutes of the meeting\n")
+	res := upload("minutes.pdf", content)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var uploaded attachment.UploadAttachmentResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&uploaded))
+	assert.Equal(t, []string{"attachments/" + uploaded.Id.String()}, s3.Keys())
+
+	t.Run("download through the signed link", func(t *testing.T) {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, uploaded.URL, nil), -1)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/pdf", res.Header.Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="minutes.pdf"`, res.Header.Get("Content-Disposition"))
+		got, _ := io.ReadAll(res.Body)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("links need their token", func(t *testing.T) {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/attachments/"+uploaded.Id.String(), nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, err = app.Test(httptest.NewRequest(http.MethodGet, "/attachments/"+uuid.NewString()+"?token=x", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/todos/"+todoId.String()+"/attachments", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var list attachment.GetAttachmentsResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+		require.Len(t, list, 1)
+		assert.Equal(t, "minutes.pdf", list[0].Name)
+		assert.Equal(t, int64(len(content)), list[0].Size)
+		assert.Equal(t, domain.TestUser.Id, *list[0].UploaderId)
+	})
+
+	t.Run("rejected uploads", func(t *testing.T) {
+		res := upload("script.pdf", []byte("<html><script>alert(1)</script></html>"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+		testUtils.VerifyErrorResponse(t, res.Body, domain.ErrUnsupportedAttachmentType)
+
+		res = upload("huge.txt", bytes.Repeat([]byte("a"), domain.MaxAttachmentSize+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+
+		assert.Len(t, s3.Keys(), 1)
+	})
+}
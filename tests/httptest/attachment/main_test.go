@@ -0,0 +1,31 @@
+package httptest_attachment
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("Running tests in httptest_attachment package...")
+
+	code := m.Run()
+
+	os.Exit(code)
+}
+
+func setupTestTodo(t *testing.T, connStr string) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	_, err = db.Exec("INSERT INTO todos (id, user_id, title, completed) VALUES ($1, $2, $3, $4)", id, domain.TestUser.Id, domain.TestTodo.Title, false)
+	require.NoError(t, err)
+	return id
+}
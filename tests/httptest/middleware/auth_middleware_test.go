@@ -136,18 +136,24 @@ var (
 		AccessTokenEncryptionKey:  "12345678901234567890123456789012",
 		RefreshTokenEncryptionKey: "12345678901234567890123456789012",
 		SecureEmailEncryptionKey:  "12345678901234567890123456789012",
+		SignedURLEncryptionKey:    "12345678901234567890123456789012",
+		FeedEncryptionKey:         "12345678901234567890123456789012",
 		AuthAccessTokenDuration:   time.Hour * 24,
 	}
 	fakeTokenServiceConfig = jweInfra.Config{
 		AccessTokenEncryptionKey:  "1234567890123456789012345678901a",
 		RefreshTokenEncryptionKey: "12345678901234567890123456789012",
 		SecureEmailEncryptionKey:  "12345678901234567890123456789012",
+		SignedURLEncryptionKey:    "12345678901234567890123456789012",
+		FeedEncryptionKey:         "12345678901234567890123456789012",
 		AuthAccessTokenDuration:   time.Hour * 24,
 	}
 	expiredTokenServiceConfig = jweInfra.Config{
 		AccessTokenEncryptionKey:  "12345678901234567890123456789012",
 		RefreshTokenEncryptionKey: "12345678901234567890123456789012",
 		SecureEmailEncryptionKey:  "12345678901234567890123456789012",
+		SignedURLEncryptionKey:    "12345678901234567890123456789012",
+		FeedEncryptionKey:         "12345678901234567890123456789012",
 		AuthAccessTokenDuration:   -time.Hour * 24,
 	}
 )
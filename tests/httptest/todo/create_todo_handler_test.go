@@ -40,8 +40,7 @@ func TestCreateTodoHandler(t *testing.T) {
 	runMigrations(t, connStr)
 	setupTestUser(t, connStr)
 
-	// I am not trying to test caching. So, i can use mock.
-	createTodoHandler := todo.NewCreateTodoHandler(repo, testUtils.NewMockCache(), testUtils.NewMockLogger())
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
 	app.Post("/todos", fiberInfra.Handle(createTodoHandler, logger))
 
 	validToken, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
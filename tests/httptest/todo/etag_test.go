@@ -0,0 +1,93 @@
+package httptest_todo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoETags(t *testing.T) {
+
+	app := fiber.New()
+	tokenService := testUtils.NewTestJWETokenService()
+	logger := slogInfra.NewLogger()
+	middlewareManager := fiberInfra.NewMiddlewareManager(tokenService, logger)
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	app.Get("/todos/:id", middlewareManager.AuthMiddleware, fiberInfra.Handle(todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer()), logger))
+	app.Put("/todos/:id", middlewareManager.AuthMiddleware, fiberInfra.Handle(todo.NewUpdateTodoHandler(repo), logger))
+	app.Patch("/todos/:id", middlewareManager.AuthMiddleware, fiberInfra.Handle(todo.NewToggleCompletedTodoHandler(repo), logger))
+	app.Delete("/todos/:id", middlewareManager.AuthMiddleware, fiberInfra.Handle(todo.NewDeleteTodoHandler(repo), logger))
+
+	token, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate token")
+
+	send := func(method, header, etag, body string) *http.Response {
+		req, _ := http.NewRequest(method, "/todos/"+domain.RealTodoId, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		if etag != "" {
+			req.Header.Set(header, etag)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err, "failed to send request")
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	steps := []struct {
+		name     string
+		method   string
+		header   string
+		etag     string
+		body     string
+		code     int
+		wantETag string
+		wantErr  error
+	}{
+		{"get", http.MethodGet, "", "", "", http.StatusOK, `"1"`, nil},
+		{"get the same version", http.MethodGet, "If-None-Match", `"1"`, "", http.StatusNotModified, `"1"`, nil},
+		{"get a weak ETag of the same version", http.MethodGet, "If-None-Match", `W/"1"`, "", http.StatusNotModified, `"1"`, nil},
+		{"update", http.MethodPut, "If-Match", `"1"`, `{"title":"Updated on the phone"}`, http.StatusNoContent, "", nil},
+		{"stale update", http.MethodPut, "If-Match", `"1"`, `{"title":"Updated on the laptop"}`, http.StatusPreconditionFailed, "", domain.ErrTodoVersionMismatch},
+		{"get an old version", http.MethodGet, "If-None-Match", `"1"`, "", http.StatusOK, `"2"`, nil},
+		{"toggle", http.MethodPatch, "If-Match", `"2"`, "", http.StatusNoContent, "", nil},
+		{"invalid If-Match", http.MethodDelete, "If-Match", `"2", 3`, "", http.StatusBadRequest, "", domain.ErrInvalidIfMatch},
+		{"stale delete", http.MethodDelete, "If-Match", `"1", "2"`, "", http.StatusPreconditionFailed, "", domain.ErrTodoVersionMismatch},
+		{"delete", http.MethodDelete, "If-Match", `"2", "3"`, "", http.StatusNoContent, "", nil},
+		{"delete a deleted todo", http.MethodDelete, "If-Match", `"4"`, "", http.StatusNotFound, "", domain.ErrTodoNotFound},
+	}
+
+	for _, step := range steps {
+		resp := send(step.method, step.header, step.etag, step.body)
+		require.Equal(t, step.code, resp.StatusCode, step.name)
+		assert.Equal(t, step.wantETag, resp.Header.Get("ETag"), step.name)
+		if testUtils.IsErrorStatusCode(step.code) {
+			testUtils.VerifyErrorResponse(t, resp.Body, step.wantErr)
+		}
+	}
+}
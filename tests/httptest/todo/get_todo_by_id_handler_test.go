@@ -0,0 +1,105 @@
+package httptest_todo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTodoByIdHandler(t *testing.T) {
+
+	app := fiber.New()
+	tokenService := testUtils.NewTestJWETokenService()
+	logger := slogInfra.NewLogger()
+	middlewareManager := fiberInfra.NewMiddlewareManager(tokenService, logger)
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+	getTodoByIdForAdminHandler := todo.NewGetTodoByIdForAdminHandler(repo)
+	app.Get("/todos/:id", middlewareManager.AuthMiddleware, fiberInfra.Handle(getTodoByIdHandler, logger))
+	app.Get("/admin/todos/:id",
+		middlewareManager.AuthMiddleware,
+		middlewareManager.AdminMiddleware,
+		fiberInfra.Handle(getTodoByIdForAdminHandler, logger),
+	)
+
+	ownerToken, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate owner token")
+
+	otherUserToken, err := tokenService.GenerateAuthAccessToken(domain.SecondUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate other user token")
+
+	adminToken, err := tokenService.GenerateAuthAccessToken(domain.SecondUserId, domain.AdminRole)
+	require.NoError(t, err, "failed to generate admin token")
+
+	tests := []struct {
+		name       string
+		path       string
+		authHeader string
+		code       int
+		wantErr    error
+	}{
+		{
+			"owner", "/todos/" + domain.RealTodoId, "Bearer " + ownerToken, http.StatusOK, nil,
+		},
+		{
+			"other user", "/todos/" + domain.RealTodoId, "Bearer " + otherUserToken, http.StatusNotFound, domain.ErrTodoNotFound,
+		},
+		{
+			"admin on user route", "/todos/" + domain.RealTodoId, "Bearer " + adminToken, http.StatusNotFound, domain.ErrTodoNotFound,
+		},
+		{
+			"admin on admin route", "/admin/todos/" + domain.RealTodoId, "Bearer " + adminToken, http.StatusOK, nil,
+		},
+		{
+			"user on admin route", "/admin/todos/" + domain.RealTodoId, "Bearer " + otherUserToken, http.StatusForbidden, domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+
+			req.Header.Set("Authorization", tt.authHeader)
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err, "failed to create request")
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.code, resp.StatusCode)
+			if testUtils.IsErrorStatusCode(tt.code) {
+				testUtils.VerifyErrorResponse(t, resp.Body, tt.wantErr)
+			} else {
+				var res todo.GetTodoByIdResponse
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&res), "failed to decode response")
+				assert.Equal(t, domain.TestTodo.Id, res.Id)
+				assert.Equal(t, domain.TestTodo.Title, res.Title)
+			}
+		})
+	}
+}
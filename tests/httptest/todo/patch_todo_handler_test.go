@@ -0,0 +1,129 @@
+package httptest_todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	fiberInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/fiber"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	slogInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/slog"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchTodoHandler(t *testing.T) {
+
+	app := fiber.New()
+	tokenService := testUtils.NewTestJWETokenService()
+	logger := slogInfra.NewLogger()
+	middlewareManager := fiberInfra.NewMiddlewareManager(tokenService, logger)
+	app.Use(middlewareManager.AuthMiddleware)
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	app.Get("/todos/:id", fiberInfra.Handle(todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer()), logger))
+	app.Patch("/todos/:id", fiberInfra.ByContentType(todo.MergePatchContentType,
+		fiberInfra.Handle(todo.NewPatchTodoHandler(repo), logger),
+		fiberInfra.Deprecated("@1792281600", func(c *fiber.Ctx) string { return "/todos/" + c.Params("id") + "/toggle" },
+			fiberInfra.Handle(todo.NewToggleCompletedTodoHandler(repo), logger)),
+		domain.ErrUnsupportedPatch,
+	))
+
+	token, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate token")
+
+	send := func(method, contentType, body string) *http.Response {
+		req, _ := http.NewRequest(method, "/todos/"+domain.RealTodoId, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err, "failed to send request")
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	get := func() todo.GetTodoByIdResponse {
+		resp := send(http.MethodGet, "", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var res todo.GetTodoByIdResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res), "failed to decode response")
+		return res
+	}
+
+	t.Run("merge patch", func(t *testing.T) {
+		resp := send(http.MethodPatch, todo.MergePatchContentType, `{"completed":true,"due_at":"2030-06-01T10:00:00Z","priority":"high"}`)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Deprecation"))
+
+		res := get()
+		assert.Equal(t, domain.TestTodo.Title, res.Title)
+		assert.True(t, res.Completed)
+		assert.Equal(t, time.Date(2030, 6, 1, 10, 0, 0, 0, time.UTC), res.DueAt)
+		assert.Equal(t, domain.PriorityHigh, res.Priority)
+	})
+
+	t.Run("a retried patch changes nothing", func(t *testing.T) {
+		completedAt := get().CompletedAt
+
+		resp := send(http.MethodPatch, todo.MergePatchContentType+"; charset=utf-8", `{"completed":true}`)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		res := get()
+		assert.True(t, res.Completed)
+		assert.Equal(t, completedAt, res.CompletedAt)
+	})
+
+	t.Run("null removes a field", func(t *testing.T) {
+		resp := send(http.MethodPatch, todo.MergePatchContentType, `{"due_at":null,"priority":null}`)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		res := get()
+		assert.True(t, res.DueAt.IsZero())
+		assert.Equal(t, domain.PriorityNone, res.Priority)
+	})
+
+	t.Run("invalid patch", func(t *testing.T) {
+		resp := send(http.MethodPatch, todo.MergePatchContentType, `{"title":null,"completed":false}`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		testUtils.VerifyErrorResponse(t, resp.Body, domain.ErrEmptyTitle)
+		assert.True(t, get().Completed)
+	})
+
+	t.Run("deprecated toggle", func(t *testing.T) {
+		resp := send(http.MethodPatch, "", "")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "@1792281600", resp.Header.Get("Deprecation"))
+		assert.Equal(t, `</todos/`+domain.RealTodoId+`/toggle>; rel="successor-version"`, resp.Header.Get("Link"))
+		assert.False(t, get().Completed)
+	})
+
+	t.Run("a body of another type toggles nothing", func(t *testing.T) {
+		for _, contentType := range []string{"application/json", ""} {
+			resp := send(http.MethodPatch, contentType, `{"completed":true}`)
+			require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode, contentType)
+			assert.Equal(t, todo.MergePatchContentType, resp.Header.Get("Accept-Patch"))
+			testUtils.VerifyErrorResponse(t, resp.Body, domain.ErrUnsupportedPatch)
+			assert.False(t, get().Completed)
+		}
+	})
+}
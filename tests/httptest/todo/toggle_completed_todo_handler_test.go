@@ -37,33 +37,41 @@ func TestToggleCompletedTodoHandler(t *testing.T) {
 	setupTestTodo(t, connStr)
 
 	toogleCompletedTodoHandler := todo.NewToggleCompletedTodoHandler(repo)
-	app.Patch("/todos/:id", fiberInfra.Handle(toogleCompletedTodoHandler, logger))
+	app.Post("/todos/:id/toggle", fiberInfra.Handle(toogleCompletedTodoHandler, logger))
 
 	validToken, err := tokenService.GenerateAuthAccessToken(domain.RealUserId, domain.TestUser.Role)
 	require.NoError(t, err, "failed to generate valid token")
 
+	otherUserToken, err := tokenService.GenerateAuthAccessToken(domain.SecondUserId, domain.TestUser.Role)
+	require.NoError(t, err, "failed to generate other user token")
+
 	validTokenHeader := "Bearer " + validToken
+	otherUserTokenHeader := "Bearer " + otherUserToken
 
 	tests := []struct {
-		name    string
-		id      string
-		code    int
-		wantErr error
+		name       string
+		id         string
+		authHeader string
+		code       int
+		wantErr    error
 	}{
 		{
-			"valid", domain.TestTodo.Id.String(), http.StatusNoContent, nil,
+			"valid", domain.TestTodo.Id.String(), validTokenHeader, http.StatusNoContent, nil,
+		},
+		{
+			"todo not found", domain.FakeTodoId, validTokenHeader, http.StatusNotFound, domain.ErrTodoNotFound,
 		},
 		{
-			"todo not found", domain.FakeTodoId, http.StatusNotFound, domain.ErrTodoNotFound,
+			"other user's todo", domain.TestTodo.Id.String(), otherUserTokenHeader, http.StatusNotFound, domain.ErrTodoNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 
 		t.Run(tt.name, func(t *testing.T) {
-			req, _ := http.NewRequest(http.MethodPatch, "/todos/"+tt.id, nil)
+			req, _ := http.NewRequest(http.MethodPost, "/todos/"+tt.id+"/toggle", nil)
 
-			req.Header.Set("Authorization", validTokenHeader)
+			req.Header.Set("Authorization", tt.authHeader)
 
 			resp, err := app.Test(req, -1)
 			require.NoError(t, err, "failed to create request")
@@ -0,0 +1,114 @@
+package integrationtest_attachment
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/attachment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/user"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	localfsInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/localfs"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobCleanup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	secondCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	setupSecondTestUser(t, connStr)
+
+	blobs := localfsInfra.NewBlobStore(t.TempDir())
+	logger := testUtils.NewMockLogger()
+	uploadHandler := attachment.NewUploadAttachmentHandler(repo, blobs, testUtils.NewTestJWETokenService(), logger)
+	janitor := attachment.NewBlobJanitor(repo, blobs, logger, time.Minute)
+
+	upload := func(ctx context.Context, todoId uuid.UUID) string {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		part, err := w.CreateFormFile("file", "notes.txt")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("some notes"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+		require.NoError(t, err)
+		defer form.RemoveAll()
+
+		res, _, err := uploadHandler.Handle(ctx, &attachment.UploadAttachmentRequest{TodoId: todoId, File: form.File["file"][0]})
+		require.NoError(t, err)
+		return "attachments/" + res.Id.String()
+	}
+	exists := func(key string) bool {
+		body, err := blobs.Get(ctx, key)
+		if err != nil {
+			require.ErrorIs(t, err, domain.ErrBlobNotFound)
+			return false
+		}
+		body.Close()
+		return true
+	}
+
+	trashed := setupTestTodo(t, connStr, domain.TestUser.Id)
+	purged := setupTestTodo(t, connStr, domain.TestUser.Id)
+	kept := setupTestTodo(t, connStr, domain.TestUser.Id)
+	trashedKey := upload(ctx, trashed)
+	purgedKey := upload(ctx, purged)
+	keptKey := upload(ctx, kept)
+	deletedKey := upload(ctx, kept)
+	accountKey := upload(secondCtx, setupTestTodo(t, connStr, domain.SecondTestUser.Id))
+
+	t.Run("deleting an attachment", func(t *testing.T) {
+		id := uuid.MustParse(deletedKey[len("attachments/"):])
+		_, _, err := attachment.NewDeleteAttachmentHandler(repo).Handle(ctx, &attachment.DeleteAttachmentRequest{TodoId: kept, Id: id})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, janitor.RunOnce(ctx))
+		assert.False(t, exists(deletedKey))
+	})
+
+	t.Run("trashed todos keep their blobs until they are deleted for good", func(t *testing.T) {
+		deleteTodoHandler := todo.NewDeleteTodoHandler(repo)
+		for _, id := range []uuid.UUID{trashed, purged} {
+			_, _, err := deleteTodoHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: id})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 0, janitor.RunOnce(ctx))
+		assert.True(t, exists(trashedKey))
+
+		_, _, err := todo.NewDeleteTrashedTodoHandler(repo).Handle(ctx, &todo.DeleteTrashedTodoRequest{Id: trashed})
+		require.NoError(t, err)
+		_, err = repo.PurgeTrash(ctx, time.Now().Add(time.Hour), 100)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, janitor.RunOnce(ctx))
+		assert.False(t, exists(trashedKey))
+		assert.False(t, exists(purgedKey))
+	})
+
+	t.Run("deleting the account", func(t *testing.T) {
+		_, _, err := user.NewDeleteAccountHandler(repo, logger, testUtils.NewMockEmailService()).Handle(secondCtx, &user.DeleteAccountRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, janitor.RunOnce(ctx))
+		assert.False(t, exists(accountKey))
+	})
+
+	assert.True(t, exists(keptKey))
+}
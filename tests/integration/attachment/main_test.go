@@ -0,0 +1,50 @@
+package integrationtest_attachment
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("Running attachment integration tests...")
+
+	code := m.Run()
+
+	os.Exit(code)
+}
+
+func setupSecondTestUser(t *testing.T, connStr string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	hashedPassword, err := domain.HashPassword(domain.SecondTestUser.Password)
+	require.NoError(t, err)
+
+	query := "INSERT INTO users (id, fullname, email, password, role) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		domain.SecondTestUser.Id,
+		domain.SecondTestUser.FullName,
+		domain.SecondTestUser.Email,
+		hashedPassword,
+		domain.SecondTestUser.Role,
+	)
+	require.NoError(t, err)
+}
+
+func setupTestTodo(t *testing.T, connStr string, userId uuid.UUID) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	_, err = db.Exec("INSERT INTO todos (id, user_id, title, completed) VALUES ($1, $2, $3, $4)", id, userId, domain.TestTodo.Title, false)
+	require.NoError(t, err)
+	return id
+}
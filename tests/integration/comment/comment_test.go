@@ -0,0 +1,146 @@
+package integrationtest_comment
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/comment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	ctx = context.WithValue(ctx, domain.RoleKey, domain.TestUser.Role)
+	viewerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+	viewerCtx = context.WithValue(viewerCtx, domain.RoleKey, domain.SecondTestUser.Role)
+	strangerCtx := context.WithValue(context.Background(), domain.UserIDKey, uuid.NewString())
+	strangerCtx = context.WithValue(strangerCtx, domain.RoleKey, "USER")
+	adminCtx := context.WithValue(strangerCtx, domain.RoleKey, domain.AdminRole)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	setupSecondTestUser(t, connStr)
+
+	// the second user views the todo through a shared project
+	res, _, err := project.NewCreateProjectHandler(repo).Handle(ctx, &project.CreateProjectRequest{Name: "Team"})
+	require.NoError(t, err)
+	team := res.Id
+	invitation, _, err := project.NewInviteMemberHandler(repo).Handle(ctx, &project.InviteMemberRequest{Id: team, Email: domain.SecondTestUser.Email, Role: "viewer"})
+	require.NoError(t, err)
+	_, _, err = project.NewAcceptInvitationHandler(repo).Handle(viewerCtx, &project.AcceptInvitationRequest{Id: invitation.Id})
+	require.NoError(t, err)
+
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+	todoId := uuid.New()
+	_, err = db.Exec("INSERT INTO todos (id, user_id, title, completed, project_id) VALUES ($1, $2, $3, $4, $5)", todoId, domain.TestUser.Id, "plan the sprint", false, team)
+	require.NoError(t, err)
+
+	createHandler := comment.NewCreateCommentHandler(repo)
+	getHandler := comment.NewGetCommentsHandler(repo)
+	updateHandler := comment.NewUpdateCommentHandler(repo)
+	deleteHandler := comment.NewDeleteCommentHandler(repo)
+
+	create := func(ctx context.Context, body string) (uuid.UUID, int, error) {
+		res, code, err := createHandler.Handle(ctx, &comment.CreateCommentRequest{TodoId: todoId, Body: body})
+		if err != nil {
+			return uuid.Nil, code, err
+		}
+		return res.Id, code, nil
+	}
+	list := func(ctx context.Context) comment.GetCommentsResponse {
+		res, _, err := getHandler.Handle(ctx, &comment.GetCommentsRequest{TodoId: todoId})
+		require.NoError(t, err)
+		return *res
+	}
+
+	first, code, err := create(ctx, "Who takes this?")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, code)
+	second, _, err := create(viewerCtx, "  I can, read-only members may comment too  ")
+	require.NoError(t, err)
+
+	t.Run("list with authors, oldest first", func(t *testing.T) {
+		comments := list(viewerCtx)
+		require.Len(t, comments, 2)
+		assert.Equal(t, first, comments[0].Id)
+		assert.Equal(t, domain.TestUser.Id, comments[0].Author.Id)
+		assert.Equal(t, domain.TestUser.FullName, comments[0].Author.FullName)
+		assert.Equal(t, "I can, read-only members may comment too", comments[1].Body)
+		assert.Equal(t, domain.SecondTestUser.FullName, comments[1].Author.FullName)
+		assert.Nil(t, comments[1].UpdatedAt)
+	})
+
+	t.Run("outsiders cannot see the thread", func(t *testing.T) {
+		_, code, err := getHandler.Handle(strangerCtx, &comment.GetCommentsRequest{TodoId: todoId})
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		_, code, err = create(strangerCtx, "hello")
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("only the author edits", func(t *testing.T) {
+		_, code, err := updateHandler.Handle(ctx, &comment.UpdateCommentRequest{TodoId: todoId, Id: second, Body: "rewritten"})
+		assert.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		_, code, err = updateHandler.Handle(viewerCtx, &comment.UpdateCommentRequest{TodoId: todoId, Id: second, Body: "I can take it"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		comments := list(ctx)
+		assert.Equal(t, "I can take it", comments[1].Body)
+		assert.NotNil(t, comments[1].UpdatedAt)
+	})
+
+	t.Run("a comment is addressed through its todo", func(t *testing.T) {
+		_, code, err := deleteHandler.Handle(ctx, &comment.DeleteCommentRequest{TodoId: domain.TestTodo.Id, Id: first})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Error(t, err)
+	})
+
+	t.Run("admins moderate any comment", func(t *testing.T) {
+		_, code, err := updateHandler.Handle(adminCtx, &comment.UpdateCommentRequest{TodoId: todoId, Id: first, Body: "[removed]"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		_, code, err = deleteHandler.Handle(adminCtx, &comment.DeleteCommentRequest{TodoId: todoId, Id: first})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		_, code, err = deleteHandler.Handle(ctx, &comment.DeleteCommentRequest{TodoId: todoId, Id: first})
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("comments outlive their author", func(t *testing.T) {
+		deleteUser(t, connStr, domain.SecondUserId)
+
+		comments := list(ctx)
+		require.Len(t, comments, 1)
+		assert.Equal(t, "I can take it", comments[0].Body)
+		assert.Nil(t, comments[0].Author)
+
+		_, code, err := deleteHandler.Handle(ctx, &comment.DeleteCommentRequest{TodoId: todoId, Id: second})
+		assert.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+}
@@ -0,0 +1,47 @@
+package integrationtest_comment
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("Running comment integration tests...")
+
+	code := m.Run()
+
+	os.Exit(code)
+}
+
+func setupSecondTestUser(t *testing.T, connStr string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	hashedPassword, err := domain.HashPassword(domain.SecondTestUser.Password)
+	require.NoError(t, err)
+
+	query := "INSERT INTO users (id, fullname, email, password, role) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		domain.SecondTestUser.Id,
+		domain.SecondTestUser.FullName,
+		domain.SecondTestUser.Email,
+		hashedPassword,
+		domain.SecondTestUser.Role,
+	)
+	require.NoError(t, err)
+}
+
+func deleteUser(t *testing.T, connStr string, id string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM users WHERE id = $1", id)
+	require.NoError(t, err)
+}
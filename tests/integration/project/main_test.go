@@ -0,0 +1,51 @@
+package integrationtest_project
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("Running project integration tests...")
+
+	code := m.Run()
+
+	os.Exit(code)
+}
+
+func setupSecondTestUser(t *testing.T, connStr string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	hashedPassword, err := domain.HashPassword(domain.SecondTestUser.Password)
+	require.NoError(t, err)
+
+	query := "INSERT INTO users (id, fullname, email, password, role) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		domain.SecondTestUser.Id,
+		domain.SecondTestUser.FullName,
+		domain.SecondTestUser.Email,
+		hashedPassword,
+		domain.SecondTestUser.Role,
+	)
+	require.NoError(t, err)
+}
+
+func countTodos(t *testing.T, connStr string, ids ...uuid.UUID) int {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM todos WHERE id = ANY($1::uuid[])", pq.Array(ids)).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
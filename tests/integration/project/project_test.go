@@ -0,0 +1,179 @@
+package integrationtest_project
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjects(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	// NewRepository seeds domain.TestUser outside of production
+	repo := postgresRepo.NewRepository(connStr)
+	setupSecondTestUser(t, connStr)
+
+	createProjectHandler := project.NewCreateProjectHandler(repo)
+	getProjectsHandler := project.NewGetProjectsHandler(repo)
+	updateProjectHandler := project.NewUpdateProjectHandler(repo)
+	deleteProjectHandler := project.NewDeleteProjectHandler(repo)
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
+	moveTodoHandler := todo.NewMoveTodoHandler(repo)
+	getProjectTodosHandler := todo.NewGetProjectTodosHandler(repo, markdownInfra.NewRenderer())
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+
+	createProject := func(name string) uuid.UUID {
+		res, code, err := createProjectHandler.Handle(ctx, &project.CreateProjectRequest{Name: name})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code)
+		return res.Id
+	}
+	createTodo := func(title string, projectId uuid.UUID) uuid.UUID {
+		_, code, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: title, ProjectId: projectId})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code)
+		return findTodoId(t, ctx, getTodosHandler, title)
+	}
+	projectTodoIds := func(ctx context.Context, projectId uuid.UUID) ([]uuid.UUID, int, error) {
+		res, code, err := getProjectTodosHandler.Handle(ctx, &todo.GetProjectTodosRequest{Id: projectId})
+		if err != nil {
+			return nil, code, err
+		}
+		var ids []uuid.UUID
+		for _, td := range res.Todos {
+			ids = append(ids, td.Id)
+		}
+		return ids, code, nil
+	}
+
+	home := createProject("Home")
+	work := createProject("Work")
+
+	t.Run("projects are appended in creation order", func(t *testing.T) {
+		res, _, err := getProjectsHandler.Handle(ctx, &project.GetProjectsRequest{})
+		require.NoError(t, err)
+		require.Len(t, *res, 2)
+		assert.Equal(t, home, (*res)[0].Id)
+		assert.Equal(t, 0, (*res)[0].Position)
+		assert.Equal(t, work, (*res)[1].Id)
+		assert.Equal(t, 1, (*res)[1].Position)
+	})
+
+	dishes := createTodo("wash the dishes", home)
+	report := createTodo("write the report", work)
+	inbox := createTodo("call mom", uuid.Nil)
+
+	t.Run("project todos", func(t *testing.T) {
+		ids, code, err := projectTodoIds(ctx, home)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []uuid.UUID{dishes}, ids)
+
+		_, code, err = projectTodoIds(otherCtx, home)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+	})
+
+	t.Run("move between projects", func(t *testing.T) {
+		_, code, err := moveTodoHandler.Handle(ctx, &todo.MoveTodoRequest{Id: inbox, ProjectId: home})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		ids, _, err := projectTodoIds(ctx, home)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{dishes, inbox}, ids)
+
+		_, _, err = moveTodoHandler.Handle(ctx, &todo.MoveTodoRequest{Id: inbox})
+		require.NoError(t, err)
+
+		ids, _, err = projectTodoIds(ctx, home)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{dishes}, ids)
+	})
+
+	t.Run("another user cannot move into the project", func(t *testing.T) {
+		_, code, err := moveTodoHandler.Handle(otherCtx, &todo.MoveTodoRequest{Id: inbox, ProjectId: home})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Error(t, err)
+	})
+
+	t.Run("archived projects take no new todos", func(t *testing.T) {
+		_, _, err := updateProjectHandler.Handle(ctx, &project.UpdateProjectRequest{Id: work, Name: "Work", Archived: true, Position: 1})
+		require.NoError(t, err)
+
+		_, code, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: "another report", ProjectId: work})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrProjectArchived)
+
+		_, code, err = moveTodoHandler.Handle(ctx, &todo.MoveTodoRequest{Id: inbox, ProjectId: work})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrProjectArchived)
+
+		res, _, err := getProjectsHandler.Handle(ctx, &project.GetProjectsRequest{})
+		require.NoError(t, err)
+		assert.Len(t, *res, 1)
+
+		res, _, err = getProjectsHandler.Handle(ctx, &project.GetProjectsRequest{IncludeArchived: true})
+		require.NoError(t, err)
+		assert.Len(t, *res, 2)
+	})
+
+	t.Run("delete moves the todos to the inbox", func(t *testing.T) {
+		_, code, err := deleteProjectHandler.Handle(ctx, &project.DeleteProjectRequest{Id: home})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.Equal(t, 1, countTodos(t, connStr, dishes))
+		res, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		for _, td := range res.Todos {
+			if td.Id == dishes {
+				assert.Nil(t, td.ProjectId)
+			}
+		}
+	})
+
+	t.Run("cascade delete removes the todos", func(t *testing.T) {
+		_, code, err := deleteProjectHandler.Handle(otherCtx, &project.DeleteProjectRequest{Id: work, Todos: "cascade"})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+		assert.Equal(t, 1, countTodos(t, connStr, report))
+
+		_, code, err = deleteProjectHandler.Handle(ctx, &project.DeleteProjectRequest{Id: work, Todos: "cascade"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Equal(t, 0, countTodos(t, connStr, report))
+		assert.Equal(t, 2, countTodos(t, connStr, dishes, inbox))
+	})
+}
+
+func findTodoId(t *testing.T, ctx context.Context, handler *todo.GetTodosHandler, title string) uuid.UUID {
+	res, _, err := handler.Handle(ctx, &todo.GetTodosRequest{})
+	require.NoError(t, err)
+	for _, td := range res.Todos {
+		if td.Title == title {
+			return td.Id
+		}
+	}
+	require.FailNow(t, "todo not found", title)
+	return uuid.Nil
+}
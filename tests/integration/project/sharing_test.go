@@ -0,0 +1,298 @@
+package integrationtest_project
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/tag"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectSharing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	memberCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+	memberId := domain.SecondTestUser.Id
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	setupSecondTestUser(t, connStr)
+
+	// the lists are read through the cache, revoking access must not leave them stale
+	todoRepo := todo.NewCachedTodoRepository(repo, testUtils.NewMockMemoryCache(), testUtils.NewMockLogger(), time.Minute)
+	projectRepo := project.NewCachedProjectRepository(repo, todoRepo)
+
+	createProjectHandler := project.NewCreateProjectHandler(projectRepo)
+	getProjectsHandler := project.NewGetProjectsHandler(projectRepo)
+	updateProjectHandler := project.NewUpdateProjectHandler(projectRepo)
+	inviteMemberHandler := project.NewInviteMemberHandler(projectRepo)
+	getMembersHandler := project.NewGetMembersHandler(projectRepo)
+	updateMemberHandler := project.NewUpdateMemberHandler(projectRepo)
+	removeMemberHandler := project.NewRemoveMemberHandler(projectRepo)
+	getInvitationsHandler := project.NewGetInvitationsHandler(projectRepo)
+	acceptInvitationHandler := project.NewAcceptInvitationHandler(projectRepo)
+	deleteInvitationHandler := project.NewDeleteInvitationHandler(projectRepo)
+	createTodoHandler := todo.NewCreateTodoHandler(todoRepo)
+	getTodosHandler := todo.NewGetTodosHandler(todoRepo, markdownInfra.NewRenderer())
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(todoRepo, markdownInfra.NewRenderer())
+	toggleHandler := todo.NewToggleCompletedTodoHandler(todoRepo)
+	createTagHandler := tag.NewCreateTagHandler(repo)
+	attachTagHandler := todo.NewAttachTagHandler(todoRepo)
+	detachTagHandler := todo.NewDetachTagHandler(todoRepo)
+	reorderHandler := todo.NewReorderTodoHandler(todoRepo)
+	getProjectTodosHandler := todo.NewGetProjectTodosHandler(todoRepo, markdownInfra.NewRenderer())
+
+	res, _, err := createProjectHandler.Handle(ctx, &project.CreateProjectRequest{Name: "Team"})
+	require.NoError(t, err)
+	team := res.Id
+
+	_, _, err = createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: "plan the sprint", ProjectId: team})
+	require.NoError(t, err)
+	plan := findTodoId(t, ctx, getTodosHandler, "plan the sprint")
+	_, _, err = createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: "private note"})
+	require.NoError(t, err)
+
+	titles := func(ctx context.Context) []string {
+		res, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		titles := []string{}
+		for _, td := range res.Todos {
+			titles = append(titles, td.Title)
+		}
+		return titles
+	}
+	toggle := func(ctx context.Context) (int, error) {
+		_, code, err := toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: plan})
+		return code, err
+	}
+	invite := func(ctx context.Context, role string) (uuid.UUID, int, error) {
+		res, code, err := inviteMemberHandler.Handle(ctx, &project.InviteMemberRequest{Id: team, Email: domain.SecondTestUser.Email, Role: role})
+		if err != nil {
+			return uuid.Nil, code, err
+		}
+		return res.Id, code, nil
+	}
+
+	// cached before the todos are shared
+	assert.Empty(t, titles(memberCtx))
+
+	t.Run("only owners invite", func(t *testing.T) {
+		_, code, err := invite(memberCtx, "viewer")
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+	})
+
+	t.Run("invitation can be declined", func(t *testing.T) {
+		id, code, err := invite(ctx, "editor")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		_, code, err = deleteInvitationHandler.Handle(memberCtx, &project.DeleteInvitationRequest{Id: id})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		invitations, _, err := getInvitationsHandler.Handle(memberCtx, &project.GetInvitationsRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, *invitations)
+	})
+
+	t.Run("viewer", func(t *testing.T) {
+		first, _, err := invite(ctx, "editor")
+		require.NoError(t, err)
+		// inviting again only changes the role
+		second, _, err := invite(ctx, "viewer")
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		invitations, _, err := getInvitationsHandler.Handle(memberCtx, &project.GetInvitationsRequest{})
+		require.NoError(t, err)
+		require.Len(t, *invitations, 1)
+		assert.Equal(t, "Team", (*invitations)[0].ProjectName)
+		assert.Equal(t, domain.ProjectViewer, (*invitations)[0].Role)
+
+		_, code, err := acceptInvitationHandler.Handle(ctx, &project.AcceptInvitationRequest{Id: first})
+		assert.Equal(t, http.StatusNotFound, code, "only the invitee accepts")
+		assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+
+		accepted, _, err := acceptInvitationHandler.Handle(memberCtx, &project.AcceptInvitationRequest{Id: first})
+		require.NoError(t, err)
+		assert.Equal(t, team, accepted.ProjectId)
+
+		assert.Equal(t, []string{"plan the sprint"}, titles(memberCtx))
+		_, code, err = getTodoByIdHandler.Handle(memberCtx, &todo.GetTodoByIdRequest{Id: plan})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+
+		projects, _, err := getProjectsHandler.Handle(memberCtx, &project.GetProjectsRequest{})
+		require.NoError(t, err)
+		require.Len(t, *projects, 1)
+		assert.Equal(t, domain.ProjectViewer, (*projects)[0].Role)
+
+		code, err = toggle(memberCtx)
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.ErrorIs(t, err, domain.ErrTodoReadOnly)
+
+		_, code, err = createTodoHandler.Handle(memberCtx, &todo.CreateTodoRequest{Title: "sneaky", ProjectId: team})
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.ErrorIs(t, err, domain.ErrProjectReadOnly)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		_, _, err := updateMemberHandler.Handle(ctx, &project.UpdateMemberRequest{Id: team, UserId: memberId, Role: "editor"})
+		require.NoError(t, err)
+
+		code, err := toggle(memberCtx)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		_, code, err = createTodoHandler.Handle(memberCtx, &todo.CreateTodoRequest{Title: "review the plan", ProjectId: team})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+		assert.Contains(t, titles(ctx), "review the plan", "the owner sees the todos of the editor")
+
+		_, code, err = updateProjectHandler.Handle(memberCtx, &project.UpdateProjectRequest{Id: team, Name: "Mine"})
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.ErrorIs(t, err, domain.ErrNotProjectOwner)
+	})
+
+	t.Run("the members share the order of the project", func(t *testing.T) {
+		projectTitles := func(ctx context.Context) []string {
+			res, _, err := getProjectTodosHandler.Handle(ctx, &todo.GetProjectTodosRequest{Id: team})
+			require.NoError(t, err)
+			titles := []string{}
+			for _, td := range res.Todos {
+				titles = append(titles, td.Title)
+			}
+			return titles
+		}
+		review := findTodoId(t, ctx, getTodosHandler, "review the plan")
+		note := findTodoId(t, ctx, getTodosHandler, "private note")
+		require.Equal(t, []string{"plan the sprint", "review the plan"}, projectTitles(ctx))
+
+		_, code, err := reorderHandler.Handle(memberCtx, &todo.ReorderTodoRequest{Id: review, Before: plan})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []string{"review the plan", "plan the sprint"}, projectTitles(ctx))
+		assert.Equal(t, []string{"review the plan", "plan the sprint"}, projectTitles(memberCtx))
+
+		_, code, err = reorderHandler.Handle(ctx, &todo.ReorderTodoRequest{Id: plan, After: note})
+		assert.Equal(t, http.StatusNotFound, code, "the inbox is another list")
+		assert.ErrorIs(t, err, domain.ErrAnchorTodoNotFound)
+	})
+
+	t.Run("the last owner stays", func(t *testing.T) {
+		_, code, err := updateMemberHandler.Handle(ctx, &project.UpdateMemberRequest{Id: team, UserId: domain.TestUser.Id, Role: "editor"})
+		assert.Equal(t, http.StatusConflict, code)
+		assert.ErrorIs(t, err, domain.ErrLastProjectOwner)
+
+		_, code, err = removeMemberHandler.Handle(ctx, &project.RemoveMemberRequest{Id: team, UserId: domain.TestUser.Id})
+		assert.Equal(t, http.StatusConflict, code)
+		assert.ErrorIs(t, err, domain.ErrLastProjectOwner)
+
+		members, _, err := getMembersHandler.Handle(memberCtx, &project.GetMembersRequest{Id: team})
+		require.NoError(t, err)
+		require.Len(t, members.Members, 2)
+		assert.Equal(t, domain.TestUser.Id, members.Members[0].UserId)
+		assert.Equal(t, domain.ProjectOwner, members.Members[0].Role)
+	})
+
+	t.Run("tags stay personal", func(t *testing.T) {
+		tagPlan := func(ctx context.Context, name string) uuid.UUID {
+			res, _, err := createTagHandler.Handle(ctx, &tag.CreateTagRequest{Name: name})
+			require.NoError(t, err)
+			_, _, err = attachTagHandler.Handle(ctx, &todo.AttachTagRequest{Id: plan, TagId: res.Id})
+			require.NoError(t, err)
+			return res.Id
+		}
+		tagNames := func(ctx context.Context) []string {
+			res, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: plan})
+			require.NoError(t, err)
+			names := []string{}
+			for _, tg := range res.Tags {
+				names = append(names, tg.Name)
+			}
+			return names
+		}
+		ownerTag := tagPlan(ctx, "salary talks")
+		tagPlan(memberCtx, "boring")
+
+		assert.Equal(t, []string{"salary talks"}, tagNames(ctx))
+		assert.Equal(t, []string{"boring"}, tagNames(memberCtx))
+
+		res, _, err := getTodosHandler.Handle(memberCtx, &todo.GetTodosRequest{Tags: []string{"salary talks"}})
+		require.NoError(t, err)
+		assert.Empty(t, res.Todos, "the tag of another member finds nothing")
+
+		_, code, err := detachTagHandler.Handle(memberCtx, &todo.DetachTagRequest{Id: plan, TagId: ownerTag})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTagNotFound)
+		assert.Equal(t, []string{"salary talks"}, tagNames(ctx))
+	})
+
+	t.Run("a subtask in another project is left out", func(t *testing.T) {
+		_, _, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: "secret step", ParentId: plan})
+		require.NoError(t, err)
+		step := findTodoId(t, ctx, getTodosHandler, "secret step")
+
+		err = todoRepo.MoveTodo(ctx, step, domain.TestUser.Id, uuid.Nil)
+		assert.ErrorIs(t, err, domain.ErrSubtaskWithProject, "a subtask moves with its top-level todo")
+
+		// a tree split across two projects before subtasks stopped moving on their own
+		private, _, err := createProjectHandler.Handle(ctx, &project.CreateProjectRequest{Name: "Private"})
+		require.NoError(t, err)
+		db, err := sql.Open("postgres", connStr)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.ExecContext(ctx, `UPDATE todos SET project_id = $1 WHERE id = $2`, private.Id, step)
+		require.NoError(t, err)
+
+		subtaskTitles := func(ctx context.Context) []string {
+			res, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: plan})
+			require.NoError(t, err)
+			titles := []string{}
+			for _, subtask := range res.Subtasks {
+				titles = append(titles, subtask.Title)
+			}
+			return titles
+		}
+		assert.Contains(t, subtaskTitles(ctx), "secret step")
+		assert.NotContains(t, subtaskTitles(memberCtx), "secret step")
+	})
+
+	t.Run("revoking takes effect right away", func(t *testing.T) {
+		require.NotEmpty(t, titles(memberCtx))
+
+		_, code, err := removeMemberHandler.Handle(ctx, &project.RemoveMemberRequest{Id: team, UserId: memberId})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.Empty(t, titles(memberCtx))
+		_, code, err = getTodoByIdHandler.Handle(memberCtx, &todo.GetTodoByIdRequest{Id: plan})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+
+		code, err = toggle(memberCtx)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+
+		// the todos the member created stay in the project
+		assert.Contains(t, titles(ctx), "review the plan")
+	})
+}
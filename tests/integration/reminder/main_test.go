@@ -0,0 +1,33 @@
+package integrationtest_reminder
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("Running reminder integration tests...")
+
+	code := m.Run()
+
+	os.Exit(code)
+}
+
+func setupTestTodoWithDueAt(t *testing.T, connStr string, dueAt time.Time) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	query := "INSERT INTO todos (id, user_id, title, completed, due_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query, id, domain.TestUser.Id, domain.TestTodo.Title, false, dueAt)
+	require.NoError(t, err)
+	return id
+}
@@ -0,0 +1,80 @@
+package integrationtest_reminder
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/reminder"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerAcrossInstances(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	// NewRepository seeds domain.TestUser outside of production
+	repo := postgresRepo.NewRepository(connStr)
+
+	clock := testUtils.NewMockClock(time.Now().UTC())
+	dueAt := clock.Now().Add(2 * time.Hour)
+	todoId := setupTestTodoWithDueAt(t, connStr, dueAt)
+
+	const reminderCount = 20
+	for i := 0; i < reminderCount; i++ {
+		var r *domain.Reminder
+		var err error
+		if i%2 == 0 {
+			r, err = domain.NewAbsoluteReminder(todoId, clock.Now().Add(time.Hour), clock.Now())
+		} else {
+			r, err = domain.NewRelativeReminder(todoId, time.Hour, dueAt, clock.Now())
+		}
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateReminder(ctx, r))
+	}
+
+	emailService := testUtils.NewMockEmailService()
+	newInstance := func() *reminder.Scheduler {
+		// every instance gets its own connection pool, like separate API processes
+		return reminder.NewScheduler(postgresRepo.NewRepository(connStr), emailService, clock, testUtils.NewMockLogger(), time.Minute)
+	}
+
+	assert.Equal(t, 0, newInstance().RunOnce(ctx), "nothing is due yet")
+
+	clock.Advance(time.Hour)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	processed := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newInstance().RunOnce(ctx)
+			mu.Lock()
+			processed += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, reminderCount, processed)
+	assert.Len(t, emailService.SentReminders(), reminderCount, "every reminder must be sent exactly once")
+
+	// a restarted instance finds nothing left to send
+	clock.Advance(time.Hour)
+	assert.Equal(t, 0, newInstance().RunOnce(ctx))
+	assert.Len(t, emailService.SentReminders(), reminderCount)
+}
@@ -0,0 +1,51 @@
+package integrationtest_tag
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("Running tag integration tests...")
+
+	code := m.Run()
+
+	os.Exit(code)
+}
+
+func setupSecondTestUser(t *testing.T, connStr string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	hashedPassword, err := domain.HashPassword(domain.SecondTestUser.Password)
+	require.NoError(t, err)
+
+	query := "INSERT INTO users (id, fullname, email, password, role) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		domain.SecondTestUser.Id,
+		domain.SecondTestUser.FullName,
+		domain.SecondTestUser.Email,
+		hashedPassword,
+		domain.SecondTestUser.Role,
+	)
+	require.NoError(t, err)
+}
+
+func setupTestTodoWithTitle(t *testing.T, connStr, title string) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	query := "INSERT INTO todos (id, user_id, title) VALUES ($1, $2, $3)"
+	_, err = db.Exec(query, id, domain.TestUser.Id, title)
+	require.NoError(t, err)
+	return id
+}
@@ -0,0 +1,122 @@
+package integrationtest_tag
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/tag"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	// NewRepository seeds domain.TestUser outside of production
+	repo := postgresRepo.NewRepository(connStr)
+	setupSecondTestUser(t, connStr)
+
+	createTagHandler := tag.NewCreateTagHandler(repo)
+	deleteTagHandler := tag.NewDeleteTagHandler(repo)
+	attachTagHandler := todo.NewAttachTagHandler(repo)
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+
+	createTag := func(ctx context.Context, name string) (uuid.UUID, int, error) {
+		res, code, err := createTagHandler.Handle(ctx, &tag.CreateTagRequest{Name: name})
+		if err != nil {
+			return uuid.Nil, code, err
+		}
+		return res.Id, code, nil
+	}
+
+	work, code, err := createTag(ctx, "Work")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, code)
+	home, _, err := createTag(ctx, "home")
+	require.NoError(t, err)
+
+	t.Run("names are unique per user ignoring case", func(t *testing.T) {
+		_, code, err := createTag(ctx, "WORK")
+		assert.Equal(t, http.StatusConflict, code)
+		assert.ErrorIs(t, err, domain.ErrTagAlreadyExists)
+
+		_, code, err = createTag(otherCtx, "work")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+	})
+
+	workOnly := setupTestTodoWithTitle(t, connStr, "work only")
+	homeOnly := setupTestTodoWithTitle(t, connStr, "home only")
+	both := setupTestTodoWithTitle(t, connStr, "work and home")
+	setupTestTodoWithTitle(t, connStr, "untagged")
+
+	for _, attach := range []struct{ todoId, tagId uuid.UUID }{
+		{workOnly, work}, {homeOnly, home}, {both, work}, {both, home}, {both, home},
+	} {
+		_, code, err := attachTagHandler.Handle(ctx, &todo.AttachTagRequest{Id: attach.todoId, TagId: attach.tagId})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, code)
+	}
+
+	t.Run("another user cannot tag the todo", func(t *testing.T) {
+		_, code, err := attachTagHandler.Handle(otherCtx, &todo.AttachTagRequest{Id: workOnly, TagId: work})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  *todo.GetTodosRequest
+			want []uuid.UUID
+		}{
+			{"single tag", &todo.GetTodosRequest{Tags: []string{"work"}}, []uuid.UUID{workOnly, both}},
+			{"and", &todo.GetTodosRequest{Tags: []string{"WORK", "home"}}, []uuid.UUID{both}},
+			{"or", &todo.GetTodosRequest{Tags: []string{"work,home"}, TagMode: "or"}, []uuid.UUID{workOnly, homeOnly, both}},
+			{"and with unknown tag", &todo.GetTodosRequest{Tags: []string{"work", "garden"}}, []uuid.UUID{}},
+			{"or with unknown tag", &todo.GetTodosRequest{Tags: []string{"garden", "home"}, TagMode: "or"}, []uuid.UUID{homeOnly, both}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, code, err := getTodosHandler.Handle(ctx, tt.req)
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, code)
+
+				ids := []uuid.UUID{}
+				for _, td := range got.Todos {
+					ids = append(ids, td.Id)
+				}
+				assert.ElementsMatch(t, tt.want, ids)
+			})
+		}
+	})
+
+	t.Run("deleting a tag detaches it", func(t *testing.T) {
+		_, code, err := deleteTagHandler.Handle(ctx, &tag.DeleteTagRequest{Id: work})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, code)
+
+		got, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: both})
+		require.NoError(t, err)
+		require.Len(t, got.Tags, 1)
+		assert.Equal(t, "home", got.Tags[0].Name)
+	})
+}
@@ -0,0 +1,101 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTodos(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	batchHandler := todo.NewBatchTodosHandler(repo)
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+
+	titles := func() []string {
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{Sort: "title"})
+		require.NoError(t, err)
+		var titles []string
+		for _, td := range todos.Todos {
+			titles = append(titles, td.Title)
+		}
+		return titles
+	}
+
+	completed := true
+
+	t.Run("atomic batch is rolled back when an operation fails", func(t *testing.T) {
+		res, code, err := batchHandler.Handle(ctx, &todo.BatchTodosRequest{Operations: []todo.BatchOperationRequest{
+			{Op: "create", Title: "Rolled back"},
+			{Op: "update_title", Id: domain.TestTodo.Id, Title: "Rolled back too"},
+			{Op: "delete", Id: uuid.New()},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, http.StatusNotFound, res.Results[2].Status)
+		assert.Equal(t, []string{domain.TestTodo.Title}, titles())
+	})
+
+	t.Run("atomic batch", func(t *testing.T) {
+		res, code, err := batchHandler.Handle(ctx, &todo.BatchTodosRequest{Operations: []todo.BatchOperationRequest{
+			{Op: "create", Title: "Buy milk"},
+			{Op: "create", Title: "Call mom"},
+			{Op: "update_title", Id: domain.TestTodo.Id, Title: "Renamed todo"},
+			{Op: "set_completed", Id: domain.TestTodo.Id, Completed: &completed},
+			{Op: "move", Id: domain.TestTodo.Id},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []string{"Buy milk", "Call mom", "Renamed todo"}, titles())
+
+		renamed, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id})
+		require.NoError(t, err)
+		assert.True(t, renamed.Completed)
+		assert.False(t, renamed.CompletedAt.IsZero())
+
+		created, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: res.Results[0].Id})
+		require.NoError(t, err)
+		assert.Equal(t, "Buy milk", created.Title)
+	})
+
+	t.Run("per item batch skips the failing operations", func(t *testing.T) {
+		res, code, err := batchHandler.Handle(ctx, &todo.BatchTodosRequest{Mode: "per_item", Operations: []todo.BatchOperationRequest{
+			{Op: "move", Id: domain.TestTodo.Id, ProjectId: uuid.New()},
+			{Op: "create", Title: "Water plants"},
+			{Op: "create", Title: "x"},
+			{Op: "delete", Id: domain.TestTodo.Id},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusMultiStatus, code)
+
+		statuses := make([]int, len(res.Results))
+		for i, result := range res.Results {
+			statuses[i] = result.Status
+		}
+		assert.Equal(t, []int{http.StatusNotFound, http.StatusCreated, http.StatusBadRequest, http.StatusNoContent}, statuses)
+		assert.Equal(t, []string{"Buy milk", "Call mom", "Water plants"}, titles())
+	})
+}
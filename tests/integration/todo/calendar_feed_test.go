@@ -0,0 +1,65 @@
+package integrationtest_todo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	ts := testUtils.NewTestJWETokenService()
+	regenerate := todo.NewRegenerateFeedURLHandler(repo, ts)
+	revoke := todo.NewRevokeFeedURLHandler(repo)
+	feed := todo.NewGetCalendarFeedHandler(repo, repo, ts, domain.NewSystemClock(), testUtils.NewMockLogger())
+
+	resp, code, err := regenerate.Handle(ctx, &todo.RegenerateFeedURLRequest{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, code)
+	token := strings.TrimSuffix(strings.TrimPrefix(resp.URL, "/feeds/"), ".ics")
+
+	t.Run("feed lists the todos of the user", func(t *testing.T) {
+		file, code, err := feed.Handle(context.Background(), &todo.GetCalendarFeedRequest{Token: token})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		var body bytes.Buffer
+		_, err = body.ReadFrom(file.Body)
+		require.NoError(t, err)
+		assert.Contains(t, body.String(), "\r\nUID:"+domain.RealTodoId+"\r\n")
+		assert.Contains(t, body.String(), "\r\nSUMMARY:"+domain.TestTodo.Title+"\r\n")
+	})
+
+	t.Run("revoked feed is not found", func(t *testing.T) {
+		_, code, err := revoke.Handle(ctx, &todo.RevokeFeedURLRequest{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, code)
+
+		_, code, err = feed.Handle(context.Background(), &todo.GetCalendarFeedRequest{Token: token})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+}
@@ -28,7 +28,7 @@ func TestCreateTodoHandler(t *testing.T) {
 	runMigrations(t, connStr)
 	setupTestUser(t, connStr)
 
-	createTodoHandler := todo.NewCreateTodoHandler(repo, testUtils.NewMockCache(), testUtils.NewMockLogger())
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
 	ctx = context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
 
 	ctxWithFakeUserId := context.WithValue(context.Background(), domain.UserIDKey, domain.FakeUserId)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
 	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
 	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
 	"github.com/stretchr/testify/assert"
@@ -29,7 +30,7 @@ func TestGetTodoHandler(t *testing.T) {
 	setupTestUser(t, connStr)
 	setupTestTodo(t, connStr)
 
-	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo)
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
 
 	type args struct {
 		ctx context.Context
@@ -0,0 +1,322 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTodosHandlerDueFilters(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	now := time.Now().UTC()
+	overdueId := setupTestTodoWithDueAt(t, connStr, now.Add(-48*time.Hour), false)
+	completedId := setupTestTodoWithDueAt(t, connStr, now.Add(-24*time.Hour), true)
+	upcomingId := setupTestTodoWithDueAt(t, connStr, now.Add(24*time.Hour), false)
+	noDueDateId := setupTestTodoWithDueAt(t, connStr, time.Time{}, false)
+
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+
+	tests := []struct {
+		name    string
+		req     *todo.GetTodosRequest
+		want    []uuid.UUID
+		code    int
+		wantErr error
+	}{
+		{"no filter, ordered by due date", &todo.GetTodosRequest{Sort: "due_at"},
+			[]uuid.UUID{overdueId, completedId, upcomingId, noDueDateId}, http.StatusOK, nil},
+		{"overdue", &todo.GetTodosRequest{Overdue: true},
+			[]uuid.UUID{overdueId}, http.StatusOK, nil},
+		{"due before now", &todo.GetTodosRequest{DueBefore: now.Format(time.RFC3339)},
+			[]uuid.UUID{overdueId, completedId}, http.StatusOK, nil},
+		{"due after now", &todo.GetTodosRequest{DueAfter: now.Format(time.RFC3339)},
+			[]uuid.UUID{upcomingId}, http.StatusOK, nil},
+		{"range in another timezone", &todo.GetTodosRequest{
+			DueAfter:  now.Add(-36 * time.Hour).In(time.FixedZone("UTC+3", 3*60*60)).Format(time.RFC3339),
+			DueBefore: now.In(time.FixedZone("UTC-5", -5*60*60)).Format(time.RFC3339),
+		}, []uuid.UUID{completedId}, http.StatusOK, nil},
+		{"invalid due_before", &todo.GetTodosRequest{DueBefore: "tomorrow"},
+			nil, http.StatusBadRequest, domain.ErrInvalidDueFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code, err := getTodosHandler.Handle(ctx, tt.req)
+
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			ids := make([]uuid.UUID, 0, len(got.Todos))
+			for _, td := range got.Todos {
+				ids = append(ids, td.Id)
+				assert.Equal(t, td.Id == overdueId, td.Overdue)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestGetTodosHandlerOverdueInTheZoneOfTheDueDate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+
+	// the midnight that began the current day of the zone has passed, the day has not
+	// ended there yet
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	require.NoError(t, err)
+	zones := map[string]*time.Location{
+		"named zone": istanbul,
+		"east":       time.FixedZone("", 14*60*60),
+		"west":       time.FixedZone("", -(9*60+30)*60),
+	}
+	for name, loc := range zones {
+		year, month, day := time.Now().In(loc).Date()
+		for _, dueAt := range []time.Time{
+			time.Date(year, month, day, 0, 0, 0, 0, loc),
+			time.Date(year, month, day-1, 0, 0, 0, 0, loc),
+		} {
+			_, _, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: name, DueAt: dueAt})
+			require.NoError(t, err)
+		}
+	}
+
+	got, code, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{Overdue: true})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, got.Todos, len(zones), "only the days that have ended are overdue")
+	for _, td := range got.Todos {
+		loc := zones[td.Title]
+		assert.True(t, td.Overdue)
+		assert.Equal(t, time.Now().In(loc).AddDate(0, 0, -1).Format(time.DateOnly), td.DueAt.Format(time.DateOnly), td.Title)
+		_, offset := td.DueAt.Zone()
+		_, want := td.DueAt.In(loc).Zone()
+		assert.Equal(t, want, offset, "the due date keeps its zone")
+	}
+
+	got, _, err = getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+	require.NoError(t, err)
+	require.Len(t, got.Todos, 2*len(zones))
+	for _, td := range got.Todos {
+		assert.Equal(t, td.DueAt.Format(time.DateOnly) != time.Now().In(zones[td.Title]).Format(time.DateOnly), td.Overdue)
+	}
+}
+
+func TestGetTodosHandlerSorting(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	now := time.Now().UTC()
+	banana := setupTestTodoWithPriority(t, connStr, "banana", domain.PriorityLow, now.Add(time.Hour))
+	apple := setupTestTodoWithPriority(t, connStr, "Apple", domain.PriorityUrgent, time.Time{})
+	cherry := setupTestTodoWithPriority(t, connStr, "cherry", domain.PriorityMedium, now.Add(2*time.Hour))
+
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+
+	tests := []struct {
+		name string
+		req  *todo.GetTodosRequest
+		want []uuid.UUID
+	}{
+		{"default keeps todos without a position in creation order", &todo.GetTodosRequest{}, []uuid.UUID{banana, apple, cherry}},
+		{"due date", &todo.GetTodosRequest{Sort: "due_at"}, []uuid.UUID{banana, cherry, apple}},
+		{"due date descending keeps missing dates last", &todo.GetTodosRequest{Sort: "due_at", Order: "desc"}, []uuid.UUID{cherry, banana, apple}},
+		{"priority", &todo.GetTodosRequest{Sort: "priority"}, []uuid.UUID{apple, cherry, banana}},
+		{"priority ascending", &todo.GetTodosRequest{Sort: "priority", Order: "asc"}, []uuid.UUID{banana, cherry, apple}},
+		{"title ignores case", &todo.GetTodosRequest{Sort: "title"}, []uuid.UUID{apple, banana, cherry}},
+		{"created at", &todo.GetTodosRequest{Sort: "created_at", Order: "asc"}, []uuid.UUID{banana, apple, cherry}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code, err := getTodosHandler.Handle(ctx, tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, code)
+
+			ids := make([]uuid.UUID, 0, len(got.Todos))
+			for _, td := range got.Todos {
+				ids = append(ids, td.Id)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestGetTodosHandlerPagination(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	// equal priorities, titles and due dates, and missing due dates, make the pages cut
+	// through ties
+	now := time.Now().UTC().Truncate(time.Second)
+	setupTestTodoWithPriority(t, connStr, "Pay rent", domain.PriorityHigh, now.Add(time.Hour))
+	setupTestTodoWithPriority(t, connStr, "pay rent", domain.PriorityHigh, now.Add(time.Hour))
+	setupTestTodoWithPriority(t, connStr, "Buy groceries", domain.PriorityNone, time.Time{})
+	setupTestTodoWithPriority(t, connStr, "Call mom", domain.PriorityLow, now.Add(2*time.Hour))
+	setupTestTodoWithPriority(t, connStr, "Clean 100% of the kitchen", domain.PriorityHigh, time.Time{})
+	setupTestTodoWithPriority(t, connStr, "Water plants", domain.PriorityNone, now.Add(time.Hour))
+	setupTestTodoWithPriority(t, connStr, "Renew passport", domain.PriorityUrgent, time.Time{})
+
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+
+	for _, variant := range todo.SortVariants() {
+		t.Run(string(variant.Sort)+" "+string(variant.Order), func(t *testing.T) {
+			req := todo.GetTodosRequest{Sort: string(variant.Sort), Order: string(variant.Order)}
+			all, _, err := getTodosHandler.Handle(ctx, &req)
+			require.NoError(t, err)
+			require.Len(t, all.Todos, 7)
+			assert.Equal(t, 7, all.Total)
+			assert.Empty(t, all.NextCursor)
+
+			var paged []uuid.UUID
+			req.Limit = 2
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 4, "too many pages")
+				page, code, err := getTodosHandler.Handle(ctx, &req)
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, code)
+				assert.Equal(t, 7, page.Total)
+				for _, td := range page.Todos {
+					paged = append(paged, td.Id)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				req.Cursor = page.NextCursor
+			}
+
+			want := make([]uuid.UUID, 0, len(all.Todos))
+			for _, td := range all.Todos {
+				want = append(want, td.Id)
+			}
+			assert.Equal(t, want, paged)
+		})
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  *todo.GetTodosRequest
+			want int
+		}{
+			{"text query ignores case", &todo.GetTodosRequest{Q: "RENT"}, 2},
+			{"text query matches wildcards literally", &todo.GetTodosRequest{Q: "100%"}, 1},
+			{"open todos", &todo.GetTodosRequest{Completed: "false"}, 7},
+			{"completed todos", &todo.GetTodosRequest{Completed: "true"}, 0},
+			{"created before now", &todo.GetTodosRequest{CreatedBefore: time.Now().Add(time.Minute).Format(time.RFC3339)}, 7},
+			{"created after now", &todo.GetTodosRequest{CreatedAfter: time.Now().Add(time.Minute).Format(time.RFC3339)}, 0},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, _, err := getTodosHandler.Handle(ctx, tt.req)
+				require.NoError(t, err)
+				assert.Len(t, got.Todos, tt.want)
+				assert.Equal(t, tt.want, got.Total)
+			})
+		}
+	})
+}
+
+func setupTestTodoWithPriority(t *testing.T, connStr, title string, priority domain.Priority, dueAt time.Time) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// created_at only has microsecond precision, the sleep keeps the insertion order observable
+	time.Sleep(time.Millisecond)
+
+	id := uuid.New()
+	query := "INSERT INTO todos (id, user_id, title, priority, due_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		id,
+		domain.TestUser.Id,
+		title,
+		priority.Rank(),
+		sql.NullTime{Time: dueAt, Valid: !dueAt.IsZero()},
+	)
+	require.NoError(t, err)
+	return id
+}
+
+func setupTestTodoWithDueAt(t *testing.T, connStr string, dueAt time.Time, completed bool) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	query := "INSERT INTO todos (id, user_id, title, completed, due_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		id,
+		domain.TestUser.Id,
+		domain.TestTodo.Title,
+		completed,
+		sql.NullTime{Time: dueAt, Valid: !dueAt.IsZero()},
+	)
+	require.NoError(t, err)
+	return id
+}
@@ -0,0 +1,120 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	updateHandler := todo.NewUpdateTodoHandler(repo)
+	toggleHandler := todo.NewToggleCompletedTodoHandler(repo)
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+	historyHandler := todo.NewGetTodoHistoryHandler(repo)
+	revertHandler := todo.NewRevertTodoHandler(repo)
+
+	history := func() []todo.TodoRevision {
+		res, _, err := historyHandler.Handle(ctx, &todo.GetTodoHistoryRequest{Id: domain.TestTodo.Id})
+		require.NoError(t, err)
+		return res.Revisions
+	}
+	rename := func(title string) {
+		_, _, err := updateHandler.Handle(ctx, &todo.UpdateTodoRequest{Id: domain.TestTodo.Id, Title: title})
+		require.NoError(t, err)
+	}
+	revert := func(revisionId uuid.UUID) (int, error) {
+		_, code, err := revertHandler.Handle(ctx, &todo.RevertTodoRequest{Id: domain.TestTodo.Id, RevisionId: revisionId})
+		return code, err
+	}
+	current := func() *todo.GetTodoByIdResponse {
+		res, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id})
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Empty(t, history())
+
+	rename("Renamed once")
+	rename("Renamed once")
+	_, _, err := toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: domain.TestTodo.Id})
+	require.NoError(t, err)
+
+	revisions := history()
+	require.Len(t, revisions, 2, "an update that changes nothing is not recorded")
+	completion, renaming := revisions[0], revisions[1]
+	assert.Equal(t, domain.TestUser.Id, *renaming.ActorId)
+	require.Contains(t, renaming.Changes, "title")
+	assert.JSONEq(t, `"Test Todo"`, string(renaming.Changes["title"].Before))
+	assert.JSONEq(t, `"Renamed once"`, string(renaming.Changes["title"].After))
+	assert.Len(t, completion.Changes, 1)
+	assert.JSONEq(t, `true`, string(completion.Changes["completed"].After))
+
+	t.Run("a changed field cannot be reverted", func(t *testing.T) {
+		rename("Renamed twice")
+
+		code, err := revert(renaming.Id)
+		assert.Equal(t, http.StatusConflict, code)
+		assert.ErrorIs(t, err, domain.ErrRevisionConflict)
+		assert.Equal(t, "Renamed twice", current().Title)
+	})
+
+	t.Run("reverting newest first", func(t *testing.T) {
+		code, err := revert(history()[0].Id)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Equal(t, "Renamed once", current().Title)
+
+		_, err = revert(renaming.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Test Todo", current().Title)
+		assert.True(t, current().Completed, "the completion was not part of the revision")
+
+		_, err = revert(completion.Id)
+		require.NoError(t, err)
+		assert.False(t, current().Completed)
+
+		revisions := history()
+		assert.Len(t, revisions, 6, "every revert is recorded")
+		assert.JSONEq(t, `false`, string(revisions[0].Changes["completed"].After))
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		code, err := revert(uuid.New())
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrRevisionNotFound)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+		_, code, err := historyHandler.Handle(otherCtx, &todo.GetTodoHistoryRequest{Id: domain.TestTodo.Id})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+
+		_, code, _ = revertHandler.Handle(otherCtx, &todo.RevertTodoRequest{Id: domain.TestTodo.Id, RevisionId: completion.Id})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
@@ -0,0 +1,257 @@
+package integrationtest_todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportExportTodos(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	importHandler := todo.NewImportTodosHandler(repo)
+	exportHandler := todo.NewExportTodosHandler(repo, domain.NewSystemClock(), testUtils.NewMockLogger())
+
+	export := func(format string) []byte {
+		file, code, err := exportHandler.Handle(ctx, &todo.ExportTodosRequest{Format: format})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		var body bytes.Buffer
+		_, err = body.ReadFrom(file.Body)
+		require.NoError(t, err)
+		return body.Bytes()
+	}
+
+	t.Run("invalid rows roll back the whole import", func(t *testing.T) {
+		resp, code, err := importHandler.Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todos.csv", "title\nValid todo\nab\n"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Len(t, resp.Errors, 1)
+
+		rows, err := csv.NewReader(bytes.NewReader(export("csv"))).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, rows, 2, "only the header and the existing todo")
+	})
+
+	t.Run("csv is imported after the existing todos", func(t *testing.T) {
+		resp, code, err := importHandler.Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todos.csv", "title,description,priority,due_at,completed\n"+
+				"Buy milk,Oat,high,2030-06-01,true\n"+
+				"Call mom,,,,\n"+
+				"Pay rent,,urgent,2030-07-01T09:00:00Z,\n"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code, "%+v", resp)
+		assert.Equal(t, 3, resp.Imported)
+
+		rows, err := csv.NewReader(bytes.NewReader(export("csv"))).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 5)
+
+		var titles []string
+		for _, row := range rows[1:] {
+			titles = append(titles, row[1])
+		}
+		// the existing todo predates manual ordering and has no position
+		assert.Equal(t, []string{"Buy milk", "Call mom", "Pay rent", domain.TestTodo.Title}, titles)
+
+		milk := rows[1]
+		assert.Equal(t, "Oat", milk[2])
+		assert.Equal(t, "true", milk[3])
+		assert.Equal(t, "high", milk[4])
+		assert.Equal(t, "2030-06-01T00:00:00Z", milk[5])
+		assert.NotEmpty(t, milk[7], "completed todos are exported with their completion time")
+		assert.Empty(t, rows[2][7])
+	})
+
+	t.Run("dry run does not import", func(t *testing.T) {
+		_, code, err := importHandler.Handle(ctx, &todo.ImportTodosRequest{
+			DryRun: true,
+			File:   newFileHeader(t, "todos.json", `[{"title":"Dry run"}]`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.NotContains(t, string(export("json")), "Dry run")
+	})
+
+	t.Run("an export can be imported again", func(t *testing.T) {
+		exported := export("ndjson")
+
+		resp, code, err := importHandler.Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todos.ndjson", string(exported)),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code, "%+v", resp)
+		assert.Equal(t, 4, resp.Imported)
+
+		rows, err := csv.NewReader(bytes.NewReader(export("csv"))).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, rows, 9)
+	})
+
+	t.Run("imported todos are listed", func(t *testing.T) {
+		todos, code, err := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer()).Handle(ctx, &todo.GetTodosRequest{Sort: "due_at", Limit: 100})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		require.NotEmpty(t, todos.Todos)
+		assert.Equal(t, "Buy milk", todos.Todos[0].Title)
+		assert.Equal(t, time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), todos.Todos[0].DueAt.UTC())
+	})
+}
+
+// An export imported again copies the todos with their project, subtasks and tags.
+func TestImportExportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	project, err := domain.NewProject(domain.TestUser.Id, "Home", "", false, 0)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateProject(ctx, project))
+	tag, err := domain.NewTag(domain.TestUser.Id, "Errands", "")
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateTag(ctx, tag))
+
+	groceries, err := domain.NewTodo(domain.TestUser.Id, "Groceries", "", time.Time{}, domain.PriorityHigh)
+	require.NoError(t, err)
+	groceries.ProjectId = project.Id
+	require.NoError(t, repo.CreateTodo(ctx, groceries))
+	milk, err := domain.NewTodo(domain.TestUser.Id, "Buy milk", "", time.Time{}, "")
+	require.NoError(t, err)
+	milk.ParentId = groceries.Id
+	require.NoError(t, repo.CreateTodo(ctx, milk))
+	require.NoError(t, repo.AttachTag(ctx, milk.Id, tag.Id, domain.TestUser.Id))
+
+	exportHandler := todo.NewExportTodosHandler(repo, domain.NewSystemClock(), testUtils.NewMockLogger())
+	export := func() []todo.ExportedTodo {
+		file, code, err := exportHandler.Handle(ctx, &todo.ExportTodosRequest{Format: "json"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		var exported []todo.ExportedTodo
+		require.NoError(t, json.NewDecoder(file.Body).Decode(&exported))
+		require.NoError(t, file.Body.Close())
+		return exported
+	}
+
+	for _, format := range []string{"json", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			file, _, err := exportHandler.Handle(ctx, &todo.ExportTodosRequest{Format: format})
+			require.NoError(t, err)
+			var exported bytes.Buffer
+			_, err = exported.ReadFrom(file.Body)
+			require.NoError(t, err)
+			before := export()
+
+			resp, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+				File: newFileHeader(t, file.Name, exported.String()),
+			})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusCreated, code, "%+v", resp)
+
+			after := export()
+			require.Len(t, after, 2*len(before))
+			existing := make(map[uuid.UUID]bool, len(before))
+			for _, td := range before {
+				existing[td.Id] = true
+			}
+			copies := make(map[string]todo.ExportedTodo)
+			for _, td := range after {
+				if !existing[td.Id] {
+					copies[td.Title] = td
+				}
+			}
+
+			groceriesCopy, milkCopy := copies["Groceries"], copies["Buy milk"]
+			assert.NotEqual(t, groceries.Id, groceriesCopy.Id)
+			require.NotNil(t, groceriesCopy.ProjectId)
+			assert.Equal(t, project.Id, *groceriesCopy.ProjectId)
+			assert.Equal(t, domain.PriorityHigh, groceriesCopy.Priority)
+			assert.Nil(t, groceriesCopy.ParentId)
+
+			require.NotNil(t, milkCopy.ParentId)
+			assert.Equal(t, groceriesCopy.Id, *milkCopy.ParentId)
+			require.NotNil(t, milkCopy.ProjectId, "a subtask is in the project of its parent")
+			assert.Equal(t, project.Id, *milkCopy.ProjectId)
+			assert.Equal(t, []string{"Errands"}, milkCopy.Tags)
+		})
+	}
+
+	t.Run("tags that do not exist yet are created", func(t *testing.T) {
+		_, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todos.json", `[{"title":"Water plants","tags":["garden","ERRANDS"]}]`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code)
+
+		for _, td := range export() {
+			if td.Title == "Water plants" {
+				assert.Equal(t, []string{"Errands", "garden"}, td.Tags)
+			}
+		}
+	})
+
+	t.Run("the project must be editable", func(t *testing.T) {
+		_, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todos.json", `[{"title":"Water plants","project_id":"`+uuid.NewString()+`"}]`),
+		})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+	})
+}
+
+func newFileHeader(t *testing.T, name, content string) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() })
+	return form.File["file"][0]
+}
@@ -0,0 +1,126 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringTodos(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+	updateTodoHandler := todo.NewUpdateTodoHandler(repo)
+	toggleHandler := todo.NewToggleCompletedTodoHandler(repo)
+
+	// 09:00 in Istanbul
+	dueAt := time.Date(2030, 5, 6, 6, 0, 0, 0, time.UTC)
+	_, _, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{
+		Title:      "Weekly review",
+		DueAt:      dueAt,
+		Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+		Timezone:   "Europe/Istanbul",
+	})
+	require.NoError(t, err)
+
+	// openOccurrences returns the not completed todos of the series, oldest first
+	openOccurrences := func() []todo.Todo {
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		var open []todo.Todo
+		for _, td := range todos.Todos {
+			if td.Title == "Weekly review" && !td.Completed {
+				open = append(open, td)
+			}
+		}
+		return open
+	}
+	complete := func(id uuid.UUID) {
+		_, _, err := toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: id})
+		require.NoError(t, err)
+	}
+
+	first := openOccurrences()
+	require.Len(t, first, 1)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4;BYDAY=MO,WE", first[0].Recurrence)
+	assert.Equal(t, "Europe/Istanbul", first[0].Timezone)
+
+	var second todo.Todo
+	t.Run("completing creates the next occurrence", func(t *testing.T) {
+		complete(first[0].Id)
+
+		open := openOccurrences()
+		require.Len(t, open, 1)
+		second = open[0]
+		assert.True(t, dueAt.AddDate(0, 0, 2).Equal(second.DueAt))
+
+		res, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: second.Id})
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;COUNT=3;BYDAY=MO,WE", res.Recurrence)
+		assert.True(t, second.DueAt.Equal(res.OccurrenceAt))
+	})
+
+	t.Run("completing again does not repeat the series twice", func(t *testing.T) {
+		complete(first[0].Id)
+		require.Len(t, openOccurrences(), 2)
+		complete(first[0].Id)
+
+		open := openOccurrences()
+		require.Len(t, open, 1)
+		assert.Equal(t, second.Id, open[0].Id)
+	})
+
+	t.Run("this occurrence keeps the schedule", func(t *testing.T) {
+		moved := second.DueAt.Add(3 * time.Hour)
+		_, _, err := updateTodoHandler.Handle(ctx, &todo.UpdateTodoRequest{
+			Id: second.Id, Title: "Weekly review", DueAt: &moved, Scope: string(domain.RecurrenceScopeThis),
+		})
+		require.NoError(t, err)
+
+		complete(second.Id)
+		open := openOccurrences()
+		require.Len(t, open, 1)
+		assert.True(t, dueAt.AddDate(0, 0, 7).Equal(open[0].DueAt))
+	})
+
+	t.Run("all future restarts the series", func(t *testing.T) {
+		third := openOccurrences()[0]
+		rule := "FREQ=DAILY;COUNT=2"
+		restart := third.DueAt.Add(time.Hour)
+		_, _, err := updateTodoHandler.Handle(ctx, &todo.UpdateTodoRequest{
+			Id: third.Id, Title: "Weekly review", DueAt: &restart, Recurrence: &rule, Timezone: "Europe/Istanbul",
+		})
+		require.NoError(t, err)
+
+		complete(third.Id)
+		open := openOccurrences()
+		require.Len(t, open, 1)
+		assert.True(t, restart.AddDate(0, 0, 1).Equal(open[0].DueAt))
+
+		complete(open[0].Id)
+		assert.Empty(t, openOccurrences(), "the series should end after its count")
+	})
+}
@@ -0,0 +1,159 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorderTodo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	// inserted without a position, like the todos created before manual ordering
+	setupTestTodo(t, connStr)
+
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+	deleteHandler := todo.NewDeleteTodoHandler(repo)
+	reorderHandler := todo.NewReorderTodoHandler(repo)
+
+	order := func() []uuid.UUID {
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		ids := []uuid.UUID{}
+		for _, td := range todos.Todos {
+			ids = append(ids, td.Id)
+		}
+		return ids
+	}
+	create := func(title string) uuid.UUID {
+		_, _, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: title})
+		require.NoError(t, err)
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		for _, td := range todos.Todos {
+			if td.Title == title {
+				return td.Id
+			}
+		}
+		require.FailNow(t, "todo not found", title)
+		return uuid.Nil
+	}
+	move := func(id, after, before uuid.UUID) (int, error) {
+		_, code, err := reorderHandler.Handle(ctx, &todo.ReorderTodoRequest{Id: id, After: after, Before: before})
+		return code, err
+	}
+
+	legacy := domain.TestTodo.Id
+	a := create("A")
+	b := create("B")
+	c := create("C")
+
+	t.Run("new todos are appended", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{a, b, c, legacy}, order(), "the todo without a position comes last")
+	})
+
+	t.Run("move between two todos", func(t *testing.T) {
+		code, err := move(c, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []uuid.UUID{a, c, b, legacy}, order())
+	})
+
+	t.Run("move to the top and to the bottom", func(t *testing.T) {
+		_, err := move(b, uuid.Nil, a)
+		require.NoError(t, err)
+		_, err = move(a, legacy, uuid.Nil)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{b, c, legacy, a}, order())
+
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{Sort: "position", Order: "desc"})
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, td := range todos.Todos {
+			ids = append(ids, td.Id)
+		}
+		assert.Equal(t, []uuid.UUID{a, legacy, c, b}, ids)
+	})
+
+	t.Run("anchors that are no longer next to each other", func(t *testing.T) {
+		code, err := move(a, b, legacy)
+		assert.Equal(t, http.StatusConflict, code)
+		assert.ErrorIs(t, err, domain.ErrStaleMoveAnchors)
+
+		code, err = move(a, c, b)
+		assert.Equal(t, http.StatusConflict, code, "before must come after after")
+		assert.ErrorIs(t, err, domain.ErrStaleMoveAnchors)
+	})
+
+	t.Run("trashed todos do not count as neighbours", func(t *testing.T) {
+		_, _, err := deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: c})
+		require.NoError(t, err)
+
+		_, err = move(a, b, legacy)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{b, a, legacy}, order())
+
+		code, err := move(legacy, c, uuid.Nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrAnchorTodoNotFound)
+	})
+
+	t.Run("another user", func(t *testing.T) {
+		otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+		_, code, err := reorderHandler.Handle(otherCtx, &todo.ReorderTodoRequest{Id: a, After: b})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	})
+
+	t.Run("concurrent moves keep positions unique", func(t *testing.T) {
+		var moved []uuid.UUID
+		for i := 0; i < 8; i++ {
+			moved = append(moved, create(fmt.Sprintf("Concurrent %d", i)))
+		}
+
+		var wg sync.WaitGroup
+		for _, id := range moved {
+			wg.Add(1)
+			go func(id uuid.UUID) {
+				defer wg.Done()
+				_, err := move(id, b, uuid.Nil)
+				assert.NoError(t, err)
+			}(id)
+		}
+		wg.Wait()
+
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		require.Len(t, todos.Todos, 3+len(moved))
+		assert.Equal(t, b, todos.Todos[0].Id)
+		positions := map[string]bool{}
+		for _, td := range todos.Todos {
+			assert.NotEmpty(t, td.Position)
+			assert.False(t, positions[td.Position], "duplicate position %s", td.Position)
+			positions[td.Position] = true
+		}
+	})
+}
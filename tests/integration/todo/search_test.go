@@ -0,0 +1,141 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTodos(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	groceries := setupTestTodoWithDescription(t, connStr, domain.TestUser.Id, "Buy groceries", "Milk, eggs and bread from the market")
+	market := setupTestTodoWithDescription(t, connStr, domain.TestUser.Id, "Market day", "Check the prices before shopping")
+	setupTestTodoWithDescription(t, connStr, domain.TestUser.Id, "Call mom", "")
+	setupSecondTestUser(t, connStr)
+	setupTestTodoWithDescription(t, connStr, domain.SecondTestUser.Id, "Market research", "")
+
+	searchHandler := todo.NewSearchTodosHandler(repo)
+
+	search := func(q string) []todo.SearchResult {
+		res, code, err := searchHandler.Handle(ctx, &todo.SearchTodosRequest{Q: q})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		return res.Results
+	}
+	ids := func(results []todo.SearchResult) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, len(results))
+		for _, result := range results {
+			ids = append(ids, result.Id)
+		}
+		return ids
+	}
+
+	t.Run("prefix", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{groceries}, ids(search("groc")))
+	})
+
+	t.Run("every word must match", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{groceries}, ids(search("milk bread")))
+		assert.Empty(t, search("milk shopping"))
+	})
+
+	t.Run("title matches rank first and other users are not searched", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{market, groceries}, ids(search("mark")))
+
+		results := search("market")
+		require.Len(t, results, 2)
+		assert.Greater(t, results[0].Rank, results[1].Rank)
+		assert.Equal(t, "<mark>Market</mark> day", results[0].TitleHighlight)
+		assert.Contains(t, results[1].Snippet, "<mark>market</mark>")
+	})
+
+	t.Run("highlights", func(t *testing.T) {
+		results := search("BUY")
+		require.Len(t, results, 1)
+		assert.Equal(t, "<mark>Buy</mark> groceries", results[0].TitleHighlight)
+		assert.Equal(t, "Buy groceries", results[0].Title)
+	})
+
+	t.Run("a changed todo is searched by its new text", func(t *testing.T) {
+		updateHandler := todo.NewUpdateTodoHandler(repo)
+		_, _, err := updateHandler.Handle(ctx, &todo.UpdateTodoRequest{Id: market, Title: "Weekly budget"})
+		require.NoError(t, err)
+
+		assert.Empty(t, search("day"))
+		assert.Equal(t, []uuid.UUID{market}, ids(search("budget")))
+	})
+}
+
+func TestSearchTodosLanguage(t *testing.T) {
+	t.Setenv("SEARCH_LANGUAGE", "english")
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	running := setupTestTodoWithDescription(t, connStr, domain.TestUser.Id, "Go running", "")
+
+	searchHandler := todo.NewSearchTodosHandler(repo)
+	res, _, err := searchHandler.Handle(ctx, &todo.SearchTodosRequest{Q: "runs"})
+	require.NoError(t, err)
+	require.Len(t, res.Results, 1, "english stemming should match runs with running")
+	assert.Equal(t, running, res.Results[0].Id)
+}
+
+func setupSecondTestUser(t *testing.T, connStr string) {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := "INSERT INTO users (id, fullname, email, password, role) VALUES ($1, $2, $3, $4, $5)"
+	_, err = db.Exec(query,
+		domain.SecondTestUser.Id,
+		domain.SecondTestUser.FullName,
+		domain.SecondTestUser.Email,
+		domain.SecondTestUser.Password,
+		domain.SecondTestUser.Role,
+	)
+	require.NoError(t, err)
+}
+
+func setupTestTodoWithDescription(t *testing.T, connStr string, userId uuid.UUID, title, description string) uuid.UUID {
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	query := "INSERT INTO todos (id, user_id, title, description) VALUES ($1, $2, $3, $4)"
+	_, err = db.Exec(query, id, userId, title, description)
+	require.NoError(t, err)
+	return id
+}
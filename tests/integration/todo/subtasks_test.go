@@ -0,0 +1,120 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubtasks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+	toggleHandler := todo.NewToggleCompletedTodoHandler(repo)
+	deleteHandler := todo.NewDeleteTodoHandler(repo)
+
+	createSubtask := func(title string, parentId uuid.UUID) (uuid.UUID, int, error) {
+		_, code, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: title, ParentId: parentId})
+		if err != nil {
+			return uuid.Nil, code, err
+		}
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		for _, td := range todos.Todos {
+			if td.Title == title {
+				return td.Id, code, nil
+			}
+		}
+		require.FailNow(t, "subtask not found", title)
+		return uuid.Nil, code, nil
+	}
+
+	rootId := domain.TestTodo.Id
+	parentId := rootId
+	var levels []uuid.UUID
+	for _, title := range []string{"level one", "level two", "level three"} {
+		id, code, err := createSubtask(title, parentId)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code)
+		levels = append(levels, id)
+		parentId = id
+	}
+	sibling, _, err := createSubtask("sibling", rootId)
+	require.NoError(t, err)
+
+	t.Run("depth is capped", func(t *testing.T) {
+		_, code, err := createSubtask("level four", parentId)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrSubtaskTooDeep)
+	})
+
+	t.Run("subtree and progress", func(t *testing.T) {
+		_, _, err := toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: sibling})
+		require.NoError(t, err)
+
+		res, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: rootId})
+		require.NoError(t, err)
+		assert.Equal(t, domain.Progress{Done: 1, Total: 4}, res.Progress)
+		require.Len(t, res.Subtasks, 2)
+		assert.Equal(t, levels[0], res.Subtasks[0].Id)
+		assert.Equal(t, domain.Progress{Done: 0, Total: 2}, res.Subtasks[0].Progress)
+		require.Len(t, res.Subtasks[0].Subtasks, 1)
+		assert.Equal(t, levels[2], res.Subtasks[0].Subtasks[0].Subtasks[0].Id)
+
+		child, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: levels[1]})
+		require.NoError(t, err)
+		require.NotNil(t, child.ParentId)
+		assert.Equal(t, levels[0], *child.ParentId)
+	})
+
+	t.Run("completing a parent completes its children on request", func(t *testing.T) {
+		_, _, err := toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: levels[0]})
+		require.NoError(t, err)
+		res, _, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: levels[0]})
+		require.NoError(t, err)
+		assert.Equal(t, domain.Progress{Done: 0, Total: 2}, res.Progress)
+
+		// reopen, then complete together with the subtasks
+		_, _, err = toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: levels[0]})
+		require.NoError(t, err)
+		_, _, err = toggleHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: levels[0], CompleteSubtasks: true})
+		require.NoError(t, err)
+
+		res, _, err = getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: rootId})
+		require.NoError(t, err)
+		assert.Equal(t, domain.Progress{Done: 4, Total: 4}, res.Progress)
+	})
+
+	t.Run("deleting a parent deletes its subtasks", func(t *testing.T) {
+		_, _, err := deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: levels[0]})
+		require.NoError(t, err)
+
+		_, code, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: levels[2]})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	})
+}
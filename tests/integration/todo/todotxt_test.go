@@ -0,0 +1,107 @@
+package integrationtest_todo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncTodoTxt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	project, err := domain.NewProject(domain.TestUser.Id, "Home", "", false, 0)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateProject(ctx, project))
+
+	handler := todo.NewSyncTodoTxtHandler(repo, domain.NewSystemClock(), testUtils.NewMockLogger())
+	var etag string
+	syncTodoTxt := func(content, ifMatch string) []string {
+		file, code, err := handler.Handle(ctx, &todo.SyncTodoTxtRequest{File: newFileHeader(t, "todo.txt", content), IfMatch: ifMatch})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		etag = file.ETag
+		var body bytes.Buffer
+		_, err = body.ReadFrom(file.Body)
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSuffix(body.String(), "\n"), "\n")
+	}
+
+	var lines []string
+	t.Run("new and changed todos", func(t *testing.T) {
+		lines = syncTodoTxt("(A) Renamed +Home due:2030-06-15 id:"+domain.RealTodoId+"\n"+
+			"x 2030-05-03 2030-05-01 Buy milk @store\n", "")
+		require.Len(t, lines, 2)
+		assert.Regexp(t, `^\(A\) \d{4}-\d{2}-\d{2} Renamed \+Home due:2030-06-15 id:`+domain.RealTodoId+`$`, lines[0])
+		assert.Regexp(t, `^x 2030-05-03 2030-05-01 Buy milk @store id:[0-9a-f-]{36}$`, lines[1])
+
+		renamed, err := repo.GetById(ctx, domain.TestTodo.Id, domain.TestUser.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", renamed.Title, "+project and @context tokens are not part of the title")
+		require.NotNil(t, renamed.ProjectId)
+		assert.Equal(t, project.Id, *renamed.ProjectId)
+	})
+
+	t.Run("an unchanged file changes nothing", func(t *testing.T) {
+		require.Len(t, lines, 2)
+		before := etag
+		assert.Equal(t, lines, syncTodoTxt(strings.Join(lines, "\n")+"\n", etag))
+		assert.Equal(t, before, etag)
+	})
+
+	t.Run("todos left out of the file are kept without If-Match", func(t *testing.T) {
+		require.Len(t, lines, 2)
+		assert.Equal(t, lines, syncTodoTxt(lines[1]+"\n", ""))
+	})
+
+	t.Run("a stale If-Match deletes nothing", func(t *testing.T) {
+		require.Len(t, lines, 2)
+		stale := etag
+		require.NoError(t, repo.ToggleCompleted(ctx, domain.TestTodo.Id, domain.TestUser.Id, false, 0))
+
+		_, code, err := handler.Handle(ctx, &todo.SyncTodoTxtRequest{File: newFileHeader(t, "todo.txt", lines[1]+"\n"), IfMatch: stale})
+		assert.Equal(t, http.StatusPreconditionFailed, code)
+		assert.ErrorIs(t, err, domain.ErrTodoTxtChanged)
+		// the file puts the todo back as it was
+		assert.Equal(t, lines, syncTodoTxt(strings.Join(lines, "\n")+"\n", ""))
+	})
+
+	t.Run("todos left out of the file are trashed", func(t *testing.T) {
+		require.Len(t, lines, 2)
+		assert.Equal(t, lines[1:], syncTodoTxt(lines[1]+"\n", etag))
+
+		trash, err := repo.GetTrash(ctx, domain.TestUser.Id)
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, domain.TestTodo.Id, trash[0].Id)
+	})
+
+	t.Run("nothing changes when a line is invalid", func(t *testing.T) {
+		require.Len(t, lines, 2)
+		_, code, err := handler.Handle(ctx, &todo.SyncTodoTxtRequest{File: newFileHeader(t, "todo.txt", "ab\n")})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrTitleTooShort)
+		assert.Equal(t, lines[1:], syncTodoTxt(lines[1]+"\n", ""))
+	})
+}
@@ -0,0 +1,158 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	createTodoHandler := todo.NewCreateTodoHandler(repo)
+	getTodosHandler := todo.NewGetTodosHandler(repo, markdownInfra.NewRenderer())
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, markdownInfra.NewRenderer())
+	deleteHandler := todo.NewDeleteTodoHandler(repo)
+	getTrashHandler := todo.NewGetTrashHandler(repo, domain.TrashRetention())
+	restoreHandler := todo.NewRestoreTodoHandler(repo)
+	deleteTrashedHandler := todo.NewDeleteTrashedTodoHandler(repo)
+
+	liveTitles := func() []string {
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		var titles []string
+		for _, td := range todos.Todos {
+			titles = append(titles, td.Title)
+		}
+		return titles
+	}
+	trashedIds := func() []uuid.UUID {
+		trash, _, err := getTrashHandler.Handle(ctx, &todo.GetTrashRequest{})
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, td := range trash.Todos {
+			assert.Equal(t, td.DeletedAt.Add(domain.TrashRetention()), td.PurgeAt)
+			ids = append(ids, td.Id)
+		}
+		return ids
+	}
+	create := func(title string, parentId uuid.UUID) uuid.UUID {
+		_, _, err := createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: title, ParentId: parentId})
+		require.NoError(t, err)
+		todos, _, err := getTodosHandler.Handle(ctx, &todo.GetTodosRequest{})
+		require.NoError(t, err)
+		for _, td := range todos.Todos {
+			if td.Title == title {
+				return td.Id
+			}
+		}
+		require.FailNow(t, "todo not found", title)
+		return uuid.Nil
+	}
+
+	parent := create("Trash parent", uuid.Nil)
+	child := create("Trash child", parent)
+	grandchild := create("Trash grandchild", child)
+
+	t.Run("deleting moves the todo to the trash", func(t *testing.T) {
+		_, code, err := deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: grandchild})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		// keeps the deleted_at of the grandchild apart from the one of its parents
+		time.Sleep(10 * time.Millisecond)
+
+		_, _, err = deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: parent})
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{domain.TestTodo.Title}, liveTitles())
+		assert.Equal(t, []uuid.UUID{parent, grandchild}, trashedIds(), "the child was trashed with its parent")
+
+		_, code, err = getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: child})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+
+		_, code, err = deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: parent})
+		assert.Equal(t, http.StatusNotFound, code, "a trashed todo cannot be deleted again")
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+
+		_, _, err = createTodoHandler.Handle(ctx, &todo.CreateTodoRequest{Title: "Under the trash", ParentId: parent})
+		assert.ErrorIs(t, err, domain.ErrParentTodoNotFound)
+	})
+
+	t.Run("a subtask waits for its parent", func(t *testing.T) {
+		_, code, err := restoreHandler.Handle(ctx, &todo.RestoreTodoRequest{Id: grandchild})
+		assert.Equal(t, http.StatusConflict, code)
+		assert.ErrorIs(t, err, domain.ErrParentTodoInTrash)
+	})
+
+	t.Run("restoring brings back what was trashed together", func(t *testing.T) {
+		_, code, err := restoreHandler.Handle(ctx, &todo.RestoreTodoRequest{Id: parent})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.ElementsMatch(t, []string{domain.TestTodo.Title, "Trash parent", "Trash child"}, liveTitles())
+		assert.Equal(t, []uuid.UUID{grandchild}, trashedIds())
+
+		_, _, err = restoreHandler.Handle(ctx, &todo.RestoreTodoRequest{Id: grandchild})
+		require.NoError(t, err)
+		assert.Empty(t, trashedIds())
+
+		_, code, err = restoreHandler.Handle(ctx, &todo.RestoreTodoRequest{Id: parent})
+		assert.Equal(t, http.StatusNotFound, code, "only trashed todos can be restored")
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	})
+
+	t.Run("permanent deletion", func(t *testing.T) {
+		_, code, err := deleteTrashedHandler.Handle(ctx, &todo.DeleteTrashedTodoRequest{Id: parent})
+		assert.Equal(t, http.StatusNotFound, code, "a todo must be trashed first")
+		assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+
+		_, _, err = deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: parent})
+		require.NoError(t, err)
+		_, code, err = deleteTrashedHandler.Handle(ctx, &todo.DeleteTrashedTodoRequest{Id: parent})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.Empty(t, trashedIds())
+		_, code, _ = restoreHandler.Handle(ctx, &todo.RestoreTodoRequest{Id: grandchild})
+		assert.Equal(t, http.StatusNotFound, code, "the subtasks are gone as well")
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		_, _, err := deleteHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: domain.TestTodo.Id})
+		require.NoError(t, err)
+
+		purged, err := repo.PurgeTrash(ctx, time.Now().Add(-time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, purged, "the todo is not old enough")
+
+		purged, err = repo.PurgeTrash(ctx, time.Now().Add(time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		assert.Empty(t, trashedIds())
+	})
+}
@@ -0,0 +1,87 @@
+package integrationtest_todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionFollowsTagsAndSubtasks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+	setupTestTodo(t, connStr)
+
+	rootId := domain.TestTodo.Id
+	userId := domain.TestUser.Id
+	version := func() int {
+		res, err := repo.GetById(ctx, rootId, userId)
+		require.NoError(t, err)
+		return res.Version
+	}
+
+	tag, err := domain.NewTag(userId, "Work", "#1e90ff")
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateTag(ctx, tag))
+
+	child, err := domain.NewTodo(userId, "Child", "", time.Time{}, domain.PriorityNone)
+	require.NoError(t, err)
+	child.ParentId = rootId
+	grandchild, err := domain.NewTodo(userId, "Grandchild", "", time.Time{}, domain.PriorityNone)
+	require.NoError(t, err)
+	grandchild.ParentId = child.Id
+
+	steps := []struct {
+		name  string
+		write func() error
+	}{
+		{"attach a tag", func() error { return repo.AttachTag(ctx, rootId, tag.Id, userId) }},
+		{"rename the tag", func() error {
+			tag.Name = "Office"
+			return repo.UpdateTag(ctx, tag)
+		}},
+		{"create a subtask", func() error { return repo.CreateTodo(ctx, child) }},
+		{"create a nested subtask", func() error { return repo.CreateTodo(ctx, grandchild) }},
+		{"complete a nested subtask", func() error { return repo.ToggleCompleted(ctx, grandchild.Id, userId, false, 0) }},
+		{"delete a nested subtask", func() error { return repo.Delete(ctx, grandchild.Id, userId, 0) }},
+		{"restore a nested subtask", func() error { return repo.RestoreTodo(ctx, grandchild.Id, userId) }},
+		{"detach the tag", func() error { return repo.DetachTag(ctx, rootId, tag.Id, userId) }},
+		{"delete a tag the todo has", func() error {
+			require.NoError(t, repo.AttachTag(ctx, rootId, tag.Id, userId))
+			return repo.DeleteTag(ctx, tag.Id, userId)
+		}},
+	}
+
+	for _, step := range steps {
+		before := version()
+		require.NoError(t, step.write(), step.name)
+		assert.Greater(t, version(), before, step.name)
+	}
+
+	t.Run("attaching an attached tag keeps the version", func(t *testing.T) {
+		other, err := domain.NewTag(userId, "Home", "#ff6347")
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateTag(ctx, other))
+		require.NoError(t, repo.AttachTag(ctx, rootId, other.Id, userId))
+
+		before := version()
+		require.NoError(t, repo.AttachTag(ctx, rootId, other.Id, userId))
+		assert.Equal(t, before, version())
+	})
+}
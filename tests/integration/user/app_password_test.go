@@ -0,0 +1,89 @@
+package integrationtest_tuser
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/user"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	postgresRepo "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/postgres"
+	testUtils "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppPasswords(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	postgresContainer, connStr := testUtils.CreatePostgresTestContainer(t, ctx)
+	defer func() {
+		err := postgresContainer.Terminate(ctx)
+		require.NoError(t, err, "failed to terminate postgres container")
+	}()
+
+	repo := postgresRepo.NewRepository(connStr)
+	runMigrations(t, connStr)
+	setupTestUser(t, connStr)
+
+	userCtx := context.WithValue(ctx, domain.UserIDKey, domain.RealUserId)
+	secondCtx := context.WithValue(ctx, domain.UserIDKey, domain.SecondUserId)
+
+	created, code, err := user.NewCreateAppPasswordHandler(repo).Handle(userCtx, &user.CreateAppPasswordRequest{Name: "Thunderbird"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "Thunderbird", created.Name)
+	assert.NotEmpty(t, created.Password)
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, code, err := user.NewCreateAppPasswordHandler(repo).Handle(secondCtx, &user.CreateAppPasswordRequest{Name: "Thunderbird"})
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("authenticate", func(t *testing.T) {
+		userId, role, err := repo.AuthenticateAppPassword(ctx, domain.TestUser.Email, domain.HashAppPassword(created.Password))
+		require.NoError(t, err)
+		assert.Equal(t, domain.TestUser.Id, userId)
+		assert.Equal(t, domain.TestUser.Role, role)
+
+		_, _, err = repo.AuthenticateAppPassword(ctx, domain.TestUser.Email, domain.HashAppPassword(domain.TestUser.Password))
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+		_, _, err = repo.AuthenticateAppPassword(ctx, domain.SecondTestUser.Email, domain.HashAppPassword(created.Password))
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	})
+
+	t.Run("list records the last use", func(t *testing.T) {
+		list, code, err := user.NewGetAppPasswordsHandler(repo).Handle(userCtx, &user.GetAppPasswordsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, *list, 1)
+		assert.Equal(t, created.Id, (*list)[0].Id)
+		assert.False(t, (*list)[0].LastUsedAt.IsZero())
+
+		list, _, err = user.NewGetAppPasswordsHandler(repo).Handle(secondCtx, &user.GetAppPasswordsRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, *list)
+	})
+
+	t.Run("delete signs the client out", func(t *testing.T) {
+		deleteHandler := user.NewDeleteAppPasswordHandler(repo)
+
+		_, code, err := deleteHandler.Handle(secondCtx, &user.DeleteAppPasswordRequest{Id: created.Id})
+		assert.ErrorIs(t, err, domain.ErrAppPasswordNotFound)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		_, code, err = deleteHandler.Handle(userCtx, &user.DeleteAppPasswordRequest{Id: created.Id})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		_, _, err = repo.AuthenticateAppPassword(ctx, domain.TestUser.Email, domain.HashAppPassword(created.Password))
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+		_, code, err = deleteHandler.Handle(userCtx, &user.DeleteAppPasswordRequest{Id: uuid.New()})
+		assert.ErrorIs(t, err, domain.ErrAppPasswordNotFound)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
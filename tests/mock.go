@@ -2,14 +2,18 @@ package test
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/auth"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
-// MockEmailService
+// MockEmailService records the reminder emails, so tests can check what was sent.
 type MockEmailService struct {
+	mu        sync.Mutex
+	reminders []*domain.EmailClaims
+	Err       error
 }
 
 func NewMockEmailService() *MockEmailService {
@@ -28,6 +32,47 @@ func (m *MockEmailService) SendPasswordResetEmail(ctx context.Context, claims *d
 func (m *MockEmailService) SendVerificationEmail(ctx context.Context, claims *domain.EmailClaims) error {
 	return nil
 }
+func (m *MockEmailService) SendReminderEmail(ctx context.Context, claims *domain.EmailClaims) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	m.reminders = append(m.reminders, claims)
+	return nil
+}
+
+func (m *MockEmailService) SentReminders() []*domain.EmailClaims {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*domain.EmailClaims(nil), m.reminders...)
+}
+
+// MockClock is a fake domain.Clock that only moves when told to.
+type MockClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{now: now}
+}
+
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *MockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
 
 // MokcValidator
 type MockValidator struct{}
@@ -103,10 +148,69 @@ func (m *MockCache) Set(ctx context.Context, key string, value []byte, ttl time.
 	return nil
 }
 
-func (m *MockCache) Delete(ctx context.Context, key string) error {
+func (m *MockCache) Delete(ctx context.Context, keys ...string) error {
 	return nil
 }
 
+// MockMemoryCache keeps the entries in memory, so tests can check what was cached or invalidated.
+type MockMemoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	deletes int
+}
+
+func NewMockMemoryCache() *MockMemoryCache {
+	return &MockMemoryCache{entries: make(map[string][]byte)}
+}
+
+func (m *MockMemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entries[key], nil
+}
+
+func (m *MockMemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = value
+	return nil
+}
+
+func (m *MockMemoryCache) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deletes++
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// DeleteCalls counts the calls to Delete, however many keys each of them had.
+func (m *MockMemoryCache) DeleteCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deletes
+}
+
+func (m *MockMemoryCache) Has(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.entries[key]
+	return ok
+}
+
+// MockMarkdownRenderer
+type MockMarkdownRenderer struct{}
+
+func NewMockMarkdownRenderer() *MockMarkdownRenderer {
+	return &MockMarkdownRenderer{}
+}
+
+func (m *MockMarkdownRenderer) Render(markdown string) (string, error) {
+	return "<p>" + markdown + "</p>", nil
+}
+
 // MockCookie Service
 
 type MockCookieService struct {
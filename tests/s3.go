@@ -0,0 +1,137 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	s3Infra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/s3"
+)
+
+// S3StandIn is an in-memory stand-in for S3 or MinIO that serves the operations of the
+// S3 blob store. Like them, it rejects requests whose signature doesn't match.
+type S3StandIn struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	objects map[string]s3Object
+}
+
+type s3Object struct {
+	body        []byte
+	contentType string
+}
+
+const (
+	s3StandInBucket    = "attachments"
+	s3StandInRegion    = "eu-central-1"
+	s3StandInAccessKey = "standin-access-key"
+	s3StandInSecretKey = "standin-secret-key"
+)
+
+func NewS3StandIn(t *testing.T) *S3StandIn {
+	s := &S3StandIn{objects: map[string]s3Object{}}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *S3StandIn) Config() *s3Infra.Config {
+	return &s3Infra.Config{
+		Endpoint:  s.server.URL,
+		Region:    s3StandInRegion,
+		Bucket:    s3StandInBucket,
+		AccessKey: s3StandInAccessKey,
+		SecretKey: s3StandInSecretKey,
+	}
+}
+
+// Keys returns the keys of the stored objects, sorted.
+func (s *S3StandIn) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *S3StandIn) serve(w http.ResponseWriter, r *http.Request) {
+	if !s.verifySignature(r) {
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s3StandInBucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(body)) != r.ContentLength {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		s.objects[key] = s3Object{body: body, contentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, ok := s.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Write(object.body)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// verifySignature signs the request again, with the headers it claims to have signed
+// only, and compares the result.
+func (s *S3StandIn) verifySignature(r *http.Request) bool {
+	authorization := r.Header.Get("Authorization")
+	_, signedHeaders, ok := strings.Cut(authorization, "SignedHeaders=")
+	if !ok {
+		return false
+	}
+	signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+	date, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.Path, nil)
+	if err != nil {
+		return false
+	}
+	for _, name := range strings.Split(signedHeaders, ";") {
+		if name != "host" && name != "x-amz-date" {
+			req.Header.Set(name, r.Header.Get(name))
+		}
+	}
+	s3Infra.Sign(req, s3StandInAccessKey, s3StandInSecretKey, s3StandInRegion, date)
+	return req.Header.Get("Authorization") == authorization
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, "<Error><Code>"+code+"</Code></Error>")
+}
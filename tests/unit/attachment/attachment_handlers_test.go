@@ -0,0 +1,195 @@
+package unittest_attachment
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/attachment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngContent = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&buf, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func userContext(userId uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), domain.UserIDKey, userId.String())
+}
+
+// tokenOf extracts the token of a signed link.
+func tokenOf(t *testing.T, link string) string {
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	return u.Query().Get("token")
+}
+
+func TestUploadAttachmentHandler(t *testing.T) {
+	repo := &MockRepository{ViewerId: domain.SecondTestUser.Id}
+	blobs := NewMockBlobStore()
+	handler := attachment.NewUploadAttachmentHandler(repo, blobs, mock.NewTestJWETokenService(), mock.NewMockLogger())
+
+	tooLarge := fileHeader(t, "big.png", pngContent)
+	tooLarge.Size = domain.MaxAttachmentSize + 1
+
+	tests := []struct {
+		name    string
+		userId  uuid.UUID
+		todoId  uuid.UUID
+		file    *multipart.FileHeader
+		code    int
+		wantErr error
+	}{
+		{"image", domain.TestTodo.UserId, domain.TestTodo.Id, fileHeader(t, "pixel.png", pngContent), http.StatusCreated, nil},
+		{"text", domain.TestTodo.UserId, domain.TestTodo.Id, fileHeader(t, "notes.txt", []byte("buy milk")), http.StatusCreated, nil},
+		{"missing file", domain.TestTodo.UserId, domain.TestTodo.Id, nil, http.StatusBadRequest, domain.ErrMissingAttachment},
+		{"empty file", domain.TestTodo.UserId, domain.TestTodo.Id, fileHeader(t, "empty.txt", nil), http.StatusBadRequest, domain.ErrEmptyAttachment},
+		{"too large", domain.TestTodo.UserId, domain.TestTodo.Id, tooLarge, http.StatusRequestEntityTooLarge, domain.ErrAttachmentTooLarge},
+		{"type is sniffed, not taken from the name", domain.TestTodo.UserId, domain.TestTodo.Id, fileHeader(t, "page.png", []byte("<html><body>hi</body></html>")), http.StatusUnsupportedMediaType, domain.ErrUnsupportedAttachmentType},
+		{"viewer", domain.SecondTestUser.Id, domain.TestTodo.Id, fileHeader(t, "pixel.png", pngContent), http.StatusForbidden, domain.ErrTodoReadOnly},
+		{"stranger", uuid.New(), domain.TestTodo.Id, fileHeader(t, "pixel.png", pngContent), http.StatusNotFound, domain.ErrTodoNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code, err := handler.Handle(userContext(tt.userId), &attachment.UploadAttachmentRequest{TodoId: tt.todoId, File: tt.file})
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(got.URL, "/attachments/"+got.Id.String()+"?token="))
+		})
+	}
+
+	assert.Len(t, repo.attachments, 2)
+	assert.Len(t, blobs.Blobs, 2, "the blobs of rejected uploads are deleted")
+	assert.Equal(t, "image/png", repo.attachments[0].ContentType)
+	assert.Equal(t, pngContent, blobs.Blobs[repo.attachments[0].BlobKey()])
+}
+
+func TestDownloadAttachmentHandler(t *testing.T) {
+	repo := &MockRepository{}
+	blobs := NewMockBlobStore()
+	ts := mock.NewTestJWETokenService()
+	ctx := userContext(domain.TestTodo.UserId)
+
+	upload := attachment.NewUploadAttachmentHandler(repo, blobs, ts, mock.NewMockLogger())
+	first, _, err := upload.Handle(ctx, &attachment.UploadAttachmentRequest{TodoId: domain.TestTodo.Id, File: fileHeader(t, "first.txt", []byte("first"))})
+	require.NoError(t, err)
+	second, _, err := upload.Handle(ctx, &attachment.UploadAttachmentRequest{TodoId: domain.TestTodo.Id, File: fileHeader(t, "second.txt", []byte("second"))})
+	require.NoError(t, err)
+
+	handler := attachment.NewDownloadAttachmentHandler(repo, blobs, ts)
+	download := func(id uuid.UUID, token string) (*domain.File, int, error) {
+		return handler.Handle(context.Background(), &attachment.DownloadAttachmentRequest{Id: id, Token: token})
+	}
+
+	file, code, err := download(first.Id, tokenOf(t, first.URL))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "first.txt", file.Name)
+	assert.Equal(t, "text/plain", file.ContentType)
+	content, _ := io.ReadAll(file.Body)
+	assert.Equal(t, "first", string(content))
+
+	_, code, err = download(second.Id, tokenOf(t, first.URL))
+	assert.ErrorIs(t, err, domain.ErrInvalidToken, "a link only opens the attachment it was signed for")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	_, code, err = download(first.Id, "not-a-token")
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	_, _, err = attachment.NewDeleteAttachmentHandler(repo).Handle(ctx, &attachment.DeleteAttachmentRequest{TodoId: domain.TestTodo.Id, Id: second.Id})
+	require.NoError(t, err)
+	_, code, err = download(second.Id, tokenOf(t, second.URL))
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound, "links stop working once the attachment is deleted")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestGetAndDeleteAttachmentHandlers(t *testing.T) {
+	repo := &MockRepository{ViewerId: domain.SecondTestUser.Id}
+	blobs := NewMockBlobStore()
+	ts := mock.NewTestJWETokenService()
+	ownerCtx := userContext(domain.TestTodo.UserId)
+	viewerCtx := userContext(domain.SecondTestUser.Id)
+
+	uploaded, _, err := attachment.NewUploadAttachmentHandler(repo, blobs, ts, mock.NewMockLogger()).
+		Handle(ownerCtx, &attachment.UploadAttachmentRequest{TodoId: domain.TestTodo.Id, File: fileHeader(t, "pixel.png", pngContent)})
+	require.NoError(t, err)
+
+	list, code, err := attachment.NewGetAttachmentsHandler(repo, ts).Handle(viewerCtx, &attachment.GetAttachmentsRequest{TodoId: domain.TestTodo.Id})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	require.Len(t, *list, 1)
+	assert.Equal(t, "pixel.png", (*list)[0].Name)
+	assert.NotEmpty(t, tokenOf(t, (*list)[0].URL))
+
+	_, code, err = attachment.NewGetAttachmentsHandler(repo, ts).Handle(userContext(uuid.New()), &attachment.GetAttachmentsRequest{TodoId: domain.TestTodo.Id})
+	assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	deleteHandler := attachment.NewDeleteAttachmentHandler(repo)
+	req := &attachment.DeleteAttachmentRequest{TodoId: domain.TestTodo.Id, Id: uploaded.Id}
+
+	_, code, err = deleteHandler.Handle(viewerCtx, req)
+	assert.ErrorIs(t, err, domain.ErrTodoReadOnly)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	_, code, err = deleteHandler.Handle(ownerCtx, req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	_, code, err = deleteHandler.Handle(ownerCtx, req)
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestBlobJanitor(t *testing.T) {
+	repo := &MockRepository{}
+	blobs := NewMockBlobStore()
+	janitor := attachment.NewBlobJanitor(repo, blobs, mock.NewMockLogger(), 0)
+
+	for i := 0; i < 250; i++ {
+		key := "attachments/" + uuid.NewString()
+		blobs.Blobs[key] = []byte("x")
+		repo.OrphanedKeys = append(repo.OrphanedKeys, key)
+	}
+	blobs.Blobs["attachments/kept"] = []byte("x")
+
+	blobs.FailDeletes = true
+	assert.Equal(t, 0, janitor.RunOnce(context.Background()))
+	assert.Len(t, repo.OrphanedKeys, 250, "blobs that failed to be deleted are retried")
+
+	blobs.FailDeletes = false
+	assert.Equal(t, 250, janitor.RunOnce(context.Background()))
+	assert.Empty(t, repo.OrphanedKeys)
+	assert.Equal(t, map[string][]byte{"attachments/kept": []byte("x")}, blobs.Blobs)
+
+	assert.Equal(t, 0, janitor.RunOnce(context.Background()))
+}
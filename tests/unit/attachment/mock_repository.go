@@ -0,0 +1,139 @@
+package unittest_attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/attachment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// MockRepository keeps attachments in memory. The only todo is domain.TestTodo, its owner
+// can edit it and ViewerId can view it. Deleted attachments leave their blob key in
+// OrphanedKeys, like the trigger of the Postgres repository.
+type MockRepository struct {
+	ViewerId     uuid.UUID
+	OrphanedKeys []string
+	attachments  []*domain.Attachment
+}
+
+func (m *MockRepository) access(todoId, userId uuid.UUID, write bool) error {
+	switch {
+	case todoId != domain.TestTodo.Id:
+		return domain.ErrTodoNotFound
+	case userId == domain.TestTodo.UserId:
+		return nil
+	case userId == m.ViewerId && write:
+		return domain.ErrTodoReadOnly
+	case userId == m.ViewerId:
+		return nil
+	default:
+		return domain.ErrTodoNotFound
+	}
+}
+
+func (m *MockRepository) CreateAttachment(ctx context.Context, a *domain.Attachment) error {
+	if err := m.access(a.TodoId, a.UploaderId, true); err != nil {
+		return err
+	}
+	m.attachments = append(m.attachments, a)
+	return nil
+}
+
+func (m *MockRepository) GetAttachments(ctx context.Context, todoId, userId uuid.UUID) (*attachment.GetAttachmentsResponse, error) {
+	if err := m.access(todoId, userId, false); err != nil {
+		return nil, err
+	}
+	attachments := attachment.GetAttachmentsResponse{}
+	for _, a := range m.attachments {
+		uploaderId := a.UploaderId
+		attachments = append(attachments, attachment.Attachment{Id: a.Id, Name: a.Name, ContentType: a.ContentType, Size: a.Size, UploaderId: &uploaderId, CreatedAt: a.CreatedAt})
+	}
+	return &attachments, nil
+}
+
+func (m *MockRepository) GetAttachment(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	for _, a := range m.attachments {
+		if a.Id == id {
+			return a, nil
+		}
+	}
+	return nil, domain.ErrAttachmentNotFound
+}
+
+func (m *MockRepository) DeleteAttachment(ctx context.Context, id, todoId, userId uuid.UUID) error {
+	if err := m.access(todoId, userId, true); err != nil {
+		return err
+	}
+	for i, a := range m.attachments {
+		if a.Id == id {
+			m.attachments = append(m.attachments[:i], m.attachments[i+1:]...)
+			m.OrphanedKeys = append(m.OrphanedKeys, a.BlobKey())
+			return nil
+		}
+	}
+	return domain.ErrAttachmentNotFound
+}
+
+func (m *MockRepository) GetOrphanedBlobKeys(ctx context.Context, limit int) ([]string, error) {
+	keys := append([]string(nil), m.OrphanedKeys...)
+	sort.Strings(keys)
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+func (m *MockRepository) ForgetOrphanedBlobKeys(ctx context.Context, keys []string) error {
+	forget := map[string]bool{}
+	for _, key := range keys {
+		forget[key] = true
+	}
+	var kept []string
+	for _, key := range m.OrphanedKeys {
+		if !forget[key] {
+			kept = append(kept, key)
+		}
+	}
+	m.OrphanedKeys = kept
+	return nil
+}
+
+// MockBlobStore keeps blobs in memory, FailDeletes makes Delete fail.
+type MockBlobStore struct {
+	Blobs       map[string][]byte
+	FailDeletes bool
+}
+
+func NewMockBlobStore() *MockBlobStore {
+	return &MockBlobStore{Blobs: map[string][]byte{}}
+}
+
+func (s *MockBlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.Blobs[key] = content
+	return nil
+}
+
+func (s *MockBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	content, ok := s.Blobs[key]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *MockBlobStore) Delete(ctx context.Context, key string) error {
+	if s.FailDeletes {
+		return errors.New("storage is unavailable")
+	}
+	delete(s.Blobs, key)
+	return nil
+}
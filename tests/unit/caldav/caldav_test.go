@@ -0,0 +1,365 @@
+package unittest_caldav
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/caldav"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var createdTodoId = uuid.MustParse("0f6b2c1e-4d3a-4b8e-9c7d-5e4f3a2b1c0d")
+
+// loadFixture reads a request recorded from a CalDAV client. The fixtures are kept
+// without a Content-Length, so the body is set after the head is parsed.
+func loadFixture(t *testing.T, name string) *http.Request {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	head, body, _ := strings.Cut(string(raw), "\n\n")
+
+	r, err := http.ReadRequest(bufio.NewReader(strings.NewReader(head + "\n\n")))
+	require.NoError(t, err)
+	r.Body = io.NopCloser(strings.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return r
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func newHandler() (*caldav.Handler, *MockRepository) {
+	repo := NewMockRepository()
+	return caldav.NewHandler(repo, &MockAuthRepository{}, mock.NewMockLogger()), repo
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Status    string `xml:"DAV: status"`
+		Propstats []struct {
+			Prop struct {
+				Inner string `xml:",innerxml"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func readMultistatus(t *testing.T, w *httptest.ResponseRecorder) *multistatus {
+	t.Helper()
+
+	require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+	var ms multistatus
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &ms))
+	return &ms
+}
+
+func (ms *multistatus) hrefs() []string {
+	var hrefs []string
+	for _, r := range ms.Responses {
+		hrefs = append(hrefs, r.Href)
+	}
+	return hrefs
+}
+
+func objectHref(id uuid.UUID) string {
+	return caldav.Prefix + "calendars/todos/" + id.String() + ".ics"
+}
+
+func TestOptions(t *testing.T) {
+	h, _ := newHandler()
+
+	w := serve(h, loadFixture(t, "apple_options.http"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1, 3, calendar-access", w.Header().Get("DAV"))
+	assert.Equal(t, "OPTIONS, PROPFIND", w.Header().Get("Allow"))
+}
+
+func TestAuthentication(t *testing.T) {
+	h, _ := newHandler()
+
+	t.Run("missing credentials", func(t *testing.T) {
+		r := loadFixture(t, "apple_propfind_root.http")
+		r.Header.Del("Authorization")
+		w := serve(h, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+	})
+
+	t.Run("wrong app password", func(t *testing.T) {
+		r := loadFixture(t, "apple_propfind_root.http")
+		r.SetBasicAuth(domain.TestUser.Email, domain.TestUser.Password)
+		w := serve(h, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("app password with other formatting", func(t *testing.T) {
+		r := loadFixture(t, "apple_propfind_root.http")
+		r.SetBasicAuth(domain.TestUser.Email, "ABCD EFGH JKMN PQRS")
+		w := serve(h, r)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+	})
+}
+
+func TestDiscovery(t *testing.T) {
+	h, _ := newHandler()
+
+	t.Run("root points to the principal", func(t *testing.T) {
+		ms := readMultistatus(t, serve(h, loadFixture(t, "apple_propfind_root.http")))
+		require.Len(t, ms.Responses, 1)
+		assert.Equal(t, caldav.Prefix, ms.Responses[0].Href)
+		propstats := ms.Responses[0].Propstats
+		require.Len(t, propstats, 2)
+		assert.Equal(t, "HTTP/1.1 200 OK", propstats[0].Status)
+		assert.Contains(t, propstats[0].Prop.Inner, "/caldav/principal/")
+		assert.Equal(t, "HTTP/1.1 404 Not Found", propstats[1].Status)
+		assert.Contains(t, propstats[1].Prop.Inner, "principal-URL")
+	})
+
+	t.Run("principal points to the calendar home", func(t *testing.T) {
+		ms := readMultistatus(t, serve(h, loadFixture(t, "apple_propfind_principal.http")))
+		require.Len(t, ms.Responses, 1)
+		propstats := ms.Responses[0].Propstats
+		require.Len(t, propstats, 2)
+		assert.Equal(t, "HTTP/1.1 200 OK", propstats[0].Status)
+		assert.Contains(t, propstats[0].Prop.Inner, "/caldav/calendars/")
+		assert.Contains(t, propstats[0].Prop.Inner, domain.TestUser.Email)
+		assert.Equal(t, "HTTP/1.1 404 Not Found", propstats[1].Status)
+		assert.Contains(t, propstats[1].Prop.Inner, "calendar-user-address-set")
+		assert.Contains(t, propstats[1].Prop.Inner, "email-address-set")
+	})
+
+	t.Run("home lists the calendar of todos", func(t *testing.T) {
+		ms := readMultistatus(t, serve(h, loadFixture(t, "apple_propfind_home.http")))
+		assert.Equal(t, []string{"/caldav/calendars/", "/caldav/calendars/todos/"}, ms.hrefs())
+
+		calendar := ms.Responses[1].Propstats[0].Prop.Inner
+		assert.Contains(t, calendar, `<calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`)
+		assert.Contains(t, calendar, `name="VTODO"`)
+		assert.Contains(t, calendar, "Todos")
+		assert.Contains(t, calendar, "getctag")
+		assert.Contains(t, calendar, "write-content")
+		assert.Contains(t, ms.Responses[1].Propstats[1].Prop.Inner, "calendar-color")
+	})
+}
+
+func TestListCalendar(t *testing.T) {
+	h, repo := newHandler()
+
+	ms := readMultistatus(t, serve(h, loadFixture(t, "thunderbird_propfind_calendar.http")))
+	assert.Equal(t, []string{"/caldav/calendars/todos/", objectHref(domain.TestTodo.Id), objectHref(CompletedTodoId)}, ms.hrefs())
+
+	object := ms.Responses[1].Propstats[0].Prop.Inner
+	assert.Contains(t, object, "text/calendar; charset=utf-8; component=VTODO")
+	assert.Contains(t, object, "getetag")
+
+	t.Run("ETag of the listing is the one of GET", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, objectHref(domain.TestTodo.Id), nil)
+		r.SetBasicAuth(domain.TestUser.Email, TestPassword)
+		w := serve(h, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8; component=VTODO", w.Header().Get("Content-Type"))
+		assert.Contains(t, object, xmlText(w.Header().Get("ETag")))
+		assert.Contains(t, w.Body.String(), "UID:"+domain.RealTodoId+"\r\n")
+
+		r = httptest.NewRequest(http.MethodGet, objectHref(domain.TestTodo.Id), nil)
+		r.SetBasicAuth(domain.TestUser.Email, TestPassword)
+		r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		assert.Equal(t, http.StatusNotModified, serve(h, r).Code)
+	})
+
+	t.Run("ctag changes with the todos", func(t *testing.T) {
+		home := readMultistatus(t, serve(h, loadFixture(t, "apple_propfind_home.http")))
+		before := home.Responses[1].Propstats[0].Prop.Inner
+
+		repo.Todos[0].Title = "Renamed"
+		repo.Todos[0].Version++
+		home = readMultistatus(t, serve(h, loadFixture(t, "apple_propfind_home.http")))
+		assert.NotEqual(t, before, home.Responses[1].Propstats[0].Prop.Inner)
+	})
+}
+
+// xmlText escapes a value the way it appears in the body of a multistatus.
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func TestCalendarMultiget(t *testing.T) {
+	h, _ := newHandler()
+
+	ms := readMultistatus(t, serve(h, loadFixture(t, "thunderbird_report_multiget.http")))
+	require.Len(t, ms.Responses, 2)
+
+	assert.Equal(t, objectHref(domain.TestTodo.Id), ms.Responses[0].Href)
+	data := ms.Responses[0].Propstats[0].Prop.Inner
+	assert.Contains(t, data, "BEGIN:VTODO")
+	assert.Contains(t, data, "SUMMARY:Test Todo")
+	assert.Contains(t, data, "DUE:20300620T000000Z")
+
+	assert.Equal(t, "http://localhost:3000"+objectHref(domain.FakeTodoUuid), ms.Responses[1].Href)
+	assert.Equal(t, "HTTP/1.1 404 Not Found", ms.Responses[1].Status)
+}
+
+func TestCalendarQuery(t *testing.T) {
+	h, _ := newHandler()
+
+	t.Run("open todos", func(t *testing.T) {
+		ms := readMultistatus(t, serve(h, loadFixture(t, "apple_report_open_todos.http")))
+		assert.Equal(t, []string{objectHref(domain.TestTodo.Id)}, ms.hrefs())
+	})
+
+	t.Run("todos of a time range", func(t *testing.T) {
+		ms := readMultistatus(t, serve(h, loadFixture(t, "thunderbird_report_time_range.http")))
+		assert.Equal(t, []string{objectHref(domain.TestTodo.Id)}, ms.hrefs())
+	})
+
+	t.Run("todos completed in March", func(t *testing.T) {
+		r := loadFixture(t, "thunderbird_report_time_range.http")
+		body := strings.NewReplacer("20300601T000000Z", "20300301T000000Z", "20300701T000000Z", "20300401T000000Z").
+			Replace(readAll(t, r.Body))
+		r.Body, r.ContentLength = io.NopCloser(strings.NewReader(body)), int64(len(body))
+
+		ms := readMultistatus(t, serve(h, r))
+		assert.Equal(t, []string{objectHref(CompletedTodoId)}, ms.hrefs())
+	})
+
+	t.Run("unsupported collation", func(t *testing.T) {
+		r := loadFixture(t, "apple_report_open_todos.http")
+		body := strings.Replace(readAll(t, r.Body), `negate-condition="yes"`, `collation="i;unknown"`, 1)
+		r.Body, r.ContentLength = io.NopCloser(strings.NewReader(body)), int64(len(body))
+
+		w := serve(h, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "supported-collation")
+	})
+
+	t.Run("unsupported report", func(t *testing.T) {
+		r := loadFixture(t, "thunderbird_report_multiget.http")
+		body := `<?xml version="1.0"?><D:sync-collection xmlns:D="DAV:"><D:sync-token/></D:sync-collection>`
+		r.Body, r.ContentLength = io.NopCloser(strings.NewReader(body)), int64(len(body))
+
+		w := serve(h, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "supported-report")
+	})
+}
+
+func readAll(t *testing.T, body io.Reader) string {
+	t.Helper()
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPutCreatesTodo(t *testing.T) {
+	h, repo := newHandler()
+
+	w := serve(h, loadFixture(t, "apple_put_create.http"))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	require.Len(t, repo.Todos, 3)
+	created := repo.Todos[2]
+	assert.Equal(t, createdTodoId, created.Id)
+	assert.Equal(t, "Renew passport", created.Title)
+	assert.Equal(t, "Bring two photos, the old passport\nand the fee receipt", created.Description)
+	assert.Equal(t, domain.PriorityUrgent, created.Priority)
+	require.NotNil(t, created.DueAt)
+	assert.True(t, time.Date(2030, 6, 15, 15, 0, 0, 0, time.UTC).Equal(*created.DueAt))
+	assert.False(t, created.Completed)
+
+	t.Run("If-None-Match fails once it exists", func(t *testing.T) {
+		w := serve(h, loadFixture(t, "apple_put_create.http"))
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestPutUpdatesTodo(t *testing.T) {
+	h, repo := newHandler()
+
+	w := serve(h, loadFixture(t, "thunderbird_put_complete.http"))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	updated := repo.Todos[0]
+	assert.Equal(t, "Test Todo, renamed in Thunderbird", updated.Title)
+	assert.Equal(t, domain.PriorityMedium, updated.Priority)
+	assert.Nil(t, updated.DueAt)
+	assert.True(t, updated.Completed)
+	assert.Equal(t, 2, updated.Version, "the todo and its completion are written at once")
+
+	t.Run("a stale ETag fails", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodHead, objectHref(domain.TestTodo.Id), nil)
+		r.SetBasicAuth(domain.TestUser.Email, TestPassword)
+		etag := serve(h, r).Header().Get("ETag")
+		assert.Equal(t, domain.VersionETag(2), etag)
+
+		r = loadFixture(t, "thunderbird_put_complete.http")
+		r.Header.Set("If-Match", etag)
+		require.Equal(t, http.StatusNoContent, serve(h, r).Code)
+
+		r = loadFixture(t, "thunderbird_put_complete.http")
+		r.Header.Set("If-Match", etag)
+		assert.Equal(t, http.StatusPreconditionFailed, serve(h, r).Code)
+	})
+
+	t.Run("UID must match the name", func(t *testing.T) {
+		r := loadFixture(t, "thunderbird_put_complete.http")
+		r.URL.Path = objectHref(domain.FakeTodoUuid)
+		w := serve(h, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "no-uid-conflict")
+	})
+
+	t.Run("only todos", func(t *testing.T) {
+		r := loadFixture(t, "thunderbird_put_complete.http")
+		body := strings.ReplaceAll(readAll(t, r.Body), "VTODO", "VEVENT")
+		r.Body, r.ContentLength = io.NopCloser(strings.NewReader(body)), int64(len(body))
+		w := serve(h, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "supported-calendar-component")
+	})
+}
+
+func TestDelete(t *testing.T) {
+	h, repo := newHandler()
+
+	w := serve(h, loadFixture(t, "thunderbird_delete.http"))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Len(t, repo.Todos, 2)
+
+	r := httptest.NewRequest(http.MethodHead, objectHref(domain.TestTodo.Id), nil)
+	r.SetBasicAuth(domain.TestUser.Email, TestPassword)
+	head := serve(h, r)
+	require.Equal(t, http.StatusOK, head.Code)
+	assert.Empty(t, head.Body.String())
+
+	r = loadFixture(t, "thunderbird_delete.http")
+	r.Header.Set("If-Match", head.Header().Get("ETag"))
+	w = serve(h, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	require.Len(t, repo.Todos, 1)
+	assert.Equal(t, CompletedTodoId, repo.Todos[0].Id)
+
+	w = serve(h, loadFixture(t, "thunderbird_delete.http"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
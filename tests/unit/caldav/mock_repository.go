@@ -0,0 +1,178 @@
+package unittest_caldav
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// TestPassword is the app password of domain.TestUser the fixtures sign in with.
+const TestPassword = "abcd-efgh-jkmn-pqrs"
+
+// CompletedTodoId is a todo of domain.TestUser completed in March 2030, long before
+// domain.TestTodo is due.
+var CompletedTodoId = uuid.MustParse("7c3e9a1b-2d4f-4e6a-8b0c-9d1e2f3a4b5c")
+
+var (
+	TestCreatedAt = time.Date(2030, 4, 1, 9, 30, 0, 0, time.UTC)
+	TestDueAt     = time.Date(2030, 6, 20, 0, 0, 0, 0, time.UTC)
+)
+
+// MockRepository keeps the todos of domain.TestUser in memory, in their manual order.
+// Every write increments the version of the todo, the fixtures start at version 1.
+// The methods the CalDAV handler does not call are left to the embedded interface.
+type MockRepository struct {
+	todo.TodoRepository
+	Todos []todo.ExportedTodo
+}
+
+func NewMockRepository() *MockRepository {
+	due := TestDueAt
+	completedAt := time.Date(2030, 3, 5, 18, 0, 0, 0, time.UTC)
+	return &MockRepository{Todos: []todo.ExportedTodo{
+		{
+			Id:        domain.TestTodo.Id,
+			Title:     domain.TestTodo.Title,
+			Priority:  domain.PriorityHigh,
+			DueAt:     &due,
+			CreatedAt: TestCreatedAt,
+			Tags:      []string{},
+			Version:   1,
+		},
+		{
+			Id:          CompletedTodoId,
+			Title:       "File taxes",
+			Completed:   true,
+			Priority:    domain.PriorityNone,
+			CreatedAt:   time.Date(2030, 3, 1, 8, 0, 0, 0, time.UTC),
+			CompletedAt: &completedAt,
+			Tags:        []string{},
+			Version:     1,
+		},
+	}}
+}
+
+func (m *MockRepository) find(id, userId uuid.UUID) int {
+	if userId != domain.TestUser.Id {
+		return -1
+	}
+	for i, t := range m.Todos {
+		if t.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MockRepository) ExportTodos(ctx context.Context, userId uuid.UUID, fn func(todo.ExportedTodo) error) error {
+	if userId != domain.TestUser.Id {
+		return nil
+	}
+	for _, t := range m.Todos {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) ImportTodos(ctx context.Context, userId uuid.UUID, todos []*domain.Todo, tags map[uuid.UUID][]string) error {
+	for _, t := range todos {
+		if m.find(t.Id, domain.TestUser.Id) >= 0 {
+			return domain.ErrTodoAlreadyExists
+		}
+		exported := todo.ExportedTodo{
+			Id:          t.Id,
+			Title:       t.Title,
+			Description: t.Description,
+			Completed:   t.Completed,
+			Priority:    t.Priority,
+			CreatedAt:   t.CreatedAt,
+			Tags:        []string{},
+			Version:     1,
+		}
+		if !t.DueAt.IsZero() {
+			exported.DueAt = &t.DueAt
+		}
+		if t.Completed {
+			exported.CompletedAt = &t.CompletedAt
+		}
+		m.Todos = append(m.Todos, exported)
+	}
+	return nil
+}
+
+func (m *MockRepository) GetById(ctx context.Context, id, userId uuid.UUID) (*todo.GetTodoByIdResponse, error) {
+	i := m.find(id, userId)
+	if i < 0 {
+		return nil, domain.ErrTodoNotFound
+	}
+	return &todo.GetTodoByIdResponse{Id: id, Title: m.Todos[i].Title, Completed: m.Todos[i].Completed}, nil
+}
+
+func (m *MockRepository) ExportTodo(ctx context.Context, id, userId uuid.UUID) (*todo.ExportedTodo, error) {
+	i := m.find(id, userId)
+	if i < 0 {
+		return nil, domain.ErrTodoNotFound
+	}
+	exported := m.Todos[i]
+	return &exported, nil
+}
+
+// write returns the index of the todo the user writes to at the version, 0 for any.
+func (m *MockRepository) write(id, userId uuid.UUID, version int) (int, error) {
+	i := m.find(id, userId)
+	if i < 0 {
+		return -1, domain.ErrTodoNotFound
+	}
+	if version != 0 && version != m.Todos[i].Version {
+		return -1, domain.ErrTodoVersionMismatch
+	}
+	return i, nil
+}
+
+func (m *MockRepository) PatchTodo(ctx context.Context, t *domain.Todo) error {
+	i, err := m.write(t.Id, t.UserId, t.Version)
+	if err != nil {
+		return err
+	}
+	m.Todos[i].Title = t.Title
+	m.Todos[i].Description = t.Description
+	m.Todos[i].Priority = t.Priority
+	m.Todos[i].DueAt = nil
+	if !t.DueAt.IsZero() {
+		m.Todos[i].DueAt = &t.DueAt
+	}
+	if t.Completed != m.Todos[i].Completed {
+		m.Todos[i].Completed = t.Completed
+		m.Todos[i].CompletedAt = nil
+		if t.Completed {
+			completedAt := time.Now()
+			m.Todos[i].CompletedAt = &completedAt
+		}
+	}
+	m.Todos[i].Version++
+	return nil
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id, userId uuid.UUID, version int) error {
+	i, err := m.write(id, userId, version)
+	if err != nil {
+		return err
+	}
+	m.Todos = append(m.Todos[:i], m.Todos[i+1:]...)
+	return nil
+}
+
+// MockAuthRepository accepts domain.TestUser with TestPassword.
+type MockAuthRepository struct{}
+
+func (m *MockAuthRepository) AuthenticateAppPassword(ctx context.Context, email, hash string) (uuid.UUID, string, error) {
+	if email != domain.TestUser.Email || hash != domain.HashAppPassword(TestPassword) {
+		return uuid.Nil, "", domain.ErrInvalidCredentials
+	}
+	return domain.TestUser.Id, domain.TestUser.Role, nil
+}
@@ -0,0 +1,119 @@
+package unittest_comment
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/comment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func userContext(userId uuid.UUID, role string) context.Context {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, userId.String())
+	return context.WithValue(ctx, domain.RoleKey, role)
+}
+
+func TestCreateCommentHandler(t *testing.T) {
+	repo := &MockRepository{ViewerId: domain.SecondTestUser.Id}
+	handler := comment.NewCreateCommentHandler(repo)
+
+	tests := []struct {
+		name    string
+		userId  uuid.UUID
+		todoId  uuid.UUID
+		body    string
+		code    int
+		wantErr error
+	}{
+		{"owner", domain.TestTodo.UserId, domain.TestTodo.Id, "looks good", http.StatusCreated, nil},
+		{"viewer", domain.SecondTestUser.Id, domain.TestTodo.Id, "  can I help?  ", http.StatusCreated, nil},
+		{"at the limit", domain.TestTodo.UserId, domain.TestTodo.Id, strings.Repeat("ü", domain.MaxCommentLength), http.StatusCreated, nil},
+		{"empty", domain.TestTodo.UserId, domain.TestTodo.Id, " \n ", http.StatusBadRequest, domain.ErrEmptyComment},
+		{"too long", domain.TestTodo.UserId, domain.TestTodo.Id, strings.Repeat("a", domain.MaxCommentLength+1), http.StatusBadRequest, domain.ErrCommentTooLong},
+		{"todo of another user", uuid.New(), domain.TestTodo.Id, "hello", http.StatusNotFound, domain.ErrTodoNotFound},
+		{"unknown todo", domain.TestTodo.UserId, uuid.New(), "hello", http.StatusNotFound, domain.ErrTodoNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code, err := handler.Handle(userContext(tt.userId, "USER"), &comment.CreateCommentRequest{TodoId: tt.todoId, Body: tt.body})
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEqual(t, uuid.Nil, got.Id)
+		})
+	}
+
+	res, _, err := comment.NewGetCommentsHandler(repo).Handle(userContext(domain.SecondTestUser.Id, "USER"), &comment.GetCommentsRequest{TodoId: domain.TestTodo.Id})
+	require.NoError(t, err)
+	require.Len(t, *res, 3)
+	assert.Equal(t, "can I help?", (*res)[1].Body)
+}
+
+func TestUpdateAndDeleteCommentHandlers(t *testing.T) {
+	repo := &MockRepository{ViewerId: domain.SecondTestUser.Id}
+	ownerCtx := userContext(domain.TestTodo.UserId, "USER")
+	viewerCtx := userContext(domain.SecondTestUser.Id, "USER")
+	adminCtx := userContext(uuid.New(), domain.AdminRole)
+
+	createHandler := comment.NewCreateCommentHandler(repo)
+	updateHandler := comment.NewUpdateCommentHandler(repo)
+	deleteHandler := comment.NewDeleteCommentHandler(repo)
+
+	res, _, err := createHandler.Handle(viewerCtx, &comment.CreateCommentRequest{TodoId: domain.TestTodo.Id, Body: "first"})
+	require.NoError(t, err)
+	id := res.Id
+
+	update := func(ctx context.Context, body string) (int, error) {
+		_, code, err := updateHandler.Handle(ctx, &comment.UpdateCommentRequest{TodoId: domain.TestTodo.Id, Id: id, Body: body})
+		return code, err
+	}
+	remove := func(ctx context.Context, id uuid.UUID) (int, error) {
+		_, code, err := deleteHandler.Handle(ctx, &comment.DeleteCommentRequest{TodoId: domain.TestTodo.Id, Id: id})
+		return code, err
+	}
+
+	code, err := update(ownerCtx, "edited by the owner of the todo")
+	assert.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, err = update(viewerCtx, "  ")
+	assert.ErrorIs(t, err, domain.ErrEmptyComment)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, err = update(viewerCtx, " edited ")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, "edited", repo.comments[0].Body)
+
+	code, err = update(adminCtx, "moderated")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, "moderated", repo.comments[0].Body)
+
+	code, err = remove(ownerCtx, id)
+	assert.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, err = remove(userContext(uuid.New(), "USER"), id)
+	assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, err = remove(adminCtx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Empty(t, repo.comments)
+
+	code, err = remove(viewerCtx, id)
+	assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,77 @@
+package unittest_comment
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/comment"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// MockRepository keeps comments in memory. The only todo is domain.TestTodo, which its
+// owner and ViewerId can view.
+type MockRepository struct {
+	ViewerId uuid.UUID
+	comments []*domain.Comment
+}
+
+func (m *MockRepository) canView(todoId, userId uuid.UUID) bool {
+	return todoId == domain.TestTodo.Id && (userId == domain.TestTodo.UserId || userId == m.ViewerId)
+}
+
+func (m *MockRepository) CreateComment(ctx context.Context, c *domain.Comment) error {
+	if !m.canView(c.TodoId, c.AuthorId) {
+		return domain.ErrTodoNotFound
+	}
+	m.comments = append(m.comments, c)
+	return nil
+}
+
+func (m *MockRepository) GetComments(ctx context.Context, todoId, userId uuid.UUID) (*comment.GetCommentsResponse, error) {
+	if !m.canView(todoId, userId) {
+		return nil, domain.ErrTodoNotFound
+	}
+	comments := comment.GetCommentsResponse{}
+	for _, c := range m.comments {
+		comments = append(comments, comment.Comment{Id: c.Id, Body: c.Body, Author: &comment.CommentAuthor{Id: c.AuthorId}, CreatedAt: c.CreatedAt})
+	}
+	return &comments, nil
+}
+
+func (m *MockRepository) UpdateComment(ctx context.Context, id, todoId, userId uuid.UUID, asAdmin bool, body string) error {
+	c, err := m.find(id, todoId, userId, asAdmin)
+	if err != nil {
+		return err
+	}
+	c.Body = body
+	return nil
+}
+
+func (m *MockRepository) DeleteComment(ctx context.Context, id, todoId, userId uuid.UUID, asAdmin bool) error {
+	c, err := m.find(id, todoId, userId, asAdmin)
+	if err != nil {
+		return err
+	}
+	for i := range m.comments {
+		if m.comments[i] == c {
+			m.comments = append(m.comments[:i], m.comments[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) find(id, todoId, userId uuid.UUID, asAdmin bool) (*domain.Comment, error) {
+	if !asAdmin && !m.canView(todoId, userId) {
+		return nil, domain.ErrTodoNotFound
+	}
+	for _, c := range m.comments {
+		if c.Id == id && c.TodoId == todoId {
+			if !asAdmin && c.AuthorId != userId {
+				return nil, domain.ErrNotCommentAuthor
+			}
+			return c, nil
+		}
+	}
+	return nil, domain.ErrCommentNotFound
+}
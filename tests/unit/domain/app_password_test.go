@@ -0,0 +1,59 @@
+package unittest_domain
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAppPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		userId   uuid.UUID
+		appName  string
+		wantName string
+		wantErr  error
+	}{
+		{"valid", uuid.New(), "iPhone Reminders", "iPhone Reminders", nil},
+		{"trimmed name", uuid.New(), "  Thunderbird ", "Thunderbird", nil},
+		{"empty user id", uuid.Nil, "Thunderbird", "", domain.ErrUserIdCannotBeEmpty},
+		{"empty name", uuid.New(), "  ", "", domain.ErrEmptyAppPasswordName},
+		{"name too long", uuid.New(), strings.Repeat("a", domain.MaxAppPasswordNameLength+1), "", domain.ErrAppPasswordNameTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, password, err := domain.NewAppPassword(tt.userId, tt.appName)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				assert.Empty(t, password)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Regexp(t, regexp.MustCompile(`^[a-z]{4}-[a-z]{4}-[a-z]{4}-[a-z]{4}$`), password)
+			assert.Equal(t, domain.HashAppPassword(password), got.Hash)
+			assert.NotContains(t, got.Hash, password)
+		})
+	}
+}
+
+func TestNewAppPasswordIsRandom(t *testing.T) {
+	_, first, err := domain.NewAppPassword(uuid.New(), "first")
+	assert.NoError(t, err)
+	_, second, err := domain.NewAppPassword(uuid.New(), "second")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashAppPasswordIgnoresFormatting(t *testing.T) {
+	hash := domain.HashAppPassword("abcd-efgh-jkmn-pqrs")
+	assert.Equal(t, hash, domain.HashAppPassword("ABCD EFGH JKMN PQRS"))
+	assert.Equal(t, hash, domain.HashAppPassword("abcdefghjkmnpqrs"))
+	assert.NotEqual(t, hash, domain.HashAppPassword("abcd-efgh-jkmn-pqrt"))
+}
@@ -0,0 +1,66 @@
+package unittest_domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAttachment(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		contentType string
+		size        int64
+		wantName    string
+		wantErr     error
+	}{
+		{"valid", "report.pdf", "application/pdf", 1024, "report.pdf", nil},
+		{"largest", "photo.png", "image/png", domain.MaxAttachmentSize, "photo.png", nil},
+		{"path is dropped", `C:\Users\me\notes.txt`, "text/plain", 10, "notes.txt", nil},
+		{"unix path is dropped", "../../etc/notes.txt", "text/plain", 10, "notes.txt", nil},
+		{"empty file", "empty.txt", "text/plain", 0, "", domain.ErrEmptyAttachment},
+		{"too large", "video.png", "image/png", domain.MaxAttachmentSize + 1, "", domain.ErrAttachmentTooLarge},
+		{"unsupported type", "page.html", "text/html", 10, "", domain.ErrUnsupportedAttachmentType},
+		{"no name", "  ", "text/plain", 10, "", domain.ErrInvalidAttachmentName},
+		{"name too long", strings.Repeat("a", domain.MaxAttachmentNameLength+1), "text/plain", 10, "", domain.ErrInvalidAttachmentName},
+		{"control character", "a\r\nb.txt", "text/plain", 10, "", domain.ErrInvalidAttachmentName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.NewAttachment(domain.TestTodo.Id, domain.TestUser.Id, tt.fileName, tt.contentType, tt.size)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Equal(t, "attachments/"+got.Id.String(), got.BlobKey())
+			assert.NoError(t, domain.ValidateBlobKey(got.BlobKey()))
+		})
+	}
+
+	_, err := domain.NewAttachment(domain.TestTodo.Id, uuid.Nil, "a.txt", "text/plain", 1)
+	assert.ErrorIs(t, err, domain.ErrUserIdCannotBeEmpty)
+}
+
+func TestDetectAttachmentType(t *testing.T) {
+	assert.Equal(t, "image/png", domain.DetectAttachmentType([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")))
+	assert.Equal(t, "application/pdf", domain.DetectAttachmentType([]byte("%PDF-1.7\n")))
+	assert.Equal(t, "text/plain", domain.DetectAttachmentType([]byte("just some notes")))
+	assert.Equal(t, "text/html", domain.DetectAttachmentType([]byte("<html><script>alert(1)</script>")))
+}
+
+func TestValidateBlobKey(t *testing.T) {
+	for _, key := range []string{"a", "attachments/8a6c2b4e-52b1-4b5c-9a55-0f5e8c9d1e2f", "x/y_z/file.txt"} {
+		assert.NoError(t, domain.ValidateBlobKey(key), key)
+	}
+	for _, key := range []string{"", "/a", "a/", "a//b", "..", "a/../b", "./a", "a b", "a\\b"} {
+		assert.ErrorIs(t, domain.ValidateBlobKey(key), domain.ErrInvalidBlobKey, key)
+	}
+}
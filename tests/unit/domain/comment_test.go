@@ -0,0 +1,43 @@
+package unittest_domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		authorId uuid.UUID
+		body     string
+		wantBody string
+		wantErr  error
+	}{
+		{"valid", domain.TestUser.Id, "Looks good", "Looks good", nil},
+		{"trimmed", domain.TestUser.Id, "\n  Done? \t", "Done?", nil},
+		{"limit counts characters", domain.TestUser.Id, strings.Repeat("ş", domain.MaxCommentLength), strings.Repeat("ş", domain.MaxCommentLength), nil},
+		{"empty", domain.TestUser.Id, "   ", "", domain.ErrEmptyComment},
+		{"too long", domain.TestUser.Id, strings.Repeat("a", domain.MaxCommentLength+1), "", domain.ErrCommentTooLong},
+		{"no author", uuid.Nil, "hello", "", domain.ErrUserIdCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.NewComment(domain.TestTodo.Id, tt.authorId, tt.body)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBody, got.Body)
+			assert.Equal(t, domain.TestTodo.Id, got.TodoId)
+			assert.Equal(t, tt.authorId, got.AuthorId)
+			assert.NotEqual(t, uuid.Nil, got.Id)
+		})
+	}
+}
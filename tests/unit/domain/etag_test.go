@@ -0,0 +1,58 @@
+package unittest_domain
+
+import (
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		versions []int
+		wantErr  error
+	}{
+		{"", nil, nil},
+		{"*", nil, nil},
+		{`"3"`, []int{3}, nil},
+		{` "12" `, []int{12}, nil},
+		{`"3", "4"`, []int{3, 4}, nil},
+		{`"3",,"4",`, []int{3, 4}, nil},
+		{`W/"2", "3", "5d41402abc4b2a76"`, []int{3}, nil},
+		{`"3-html-overdue", "4-html"`, []int{3, 4}, nil},
+		{`W/"3"`, nil, domain.ErrTodoVersionMismatch},
+		{`"0"`, nil, domain.ErrTodoVersionMismatch},
+		{`"5d41402abc4b2a76"`, nil, domain.ErrTodoVersionMismatch},
+		{`W/"3", "0"`, nil, domain.ErrTodoVersionMismatch},
+		{"3", nil, domain.ErrInvalidIfMatch},
+		{`"3", 4`, nil, domain.ErrInvalidIfMatch},
+		{`"3", *`, nil, domain.ErrInvalidIfMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			versions, err := domain.ParseIfMatch(tt.header)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+
+	versions, err := domain.ParseIfMatch(domain.VersionETag(7))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7}, versions)
+
+	assert.Equal(t, `"7-html"`, domain.VersionETag(7, "html"))
+	versions, err = domain.ParseIfMatch(domain.VersionETag(7, "html"))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7}, versions)
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, domain.ETagMatches(`"3"`, `"3"`))
+	assert.True(t, domain.ETagMatches(`"2", W/"3"`, `"3"`))
+	assert.True(t, domain.ETagMatches("*", `"3"`))
+	assert.False(t, domain.ETagMatches(`"2"`, `"3"`))
+	assert.False(t, domain.ETagMatches("", `"3"`))
+	assert.False(t, domain.ETagMatches(`"3"`, `"3-html"`))
+}
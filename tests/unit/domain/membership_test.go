@@ -0,0 +1,73 @@
+package unittest_domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProjectRole(t *testing.T) {
+	tests := []struct {
+		role    string
+		want    domain.ProjectRole
+		wantErr error
+	}{
+		{"viewer", domain.ProjectViewer, nil},
+		{" Editor ", domain.ProjectEditor, nil},
+		{"OWNER", domain.ProjectOwner, nil},
+		{"admin", "", domain.ErrInvalidProjectRole},
+		{"", "", domain.ErrInvalidProjectRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			got, err := domain.ParseProjectRole(tt.role)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProjectRoleIncludes(t *testing.T) {
+	assert.True(t, domain.ProjectOwner.Includes(domain.ProjectEditor))
+	assert.True(t, domain.ProjectEditor.Includes(domain.ProjectEditor))
+	assert.False(t, domain.ProjectViewer.Includes(domain.ProjectEditor))
+
+	assert.Equal(t, []domain.ProjectRole{domain.ProjectEditor, domain.ProjectOwner}, domain.RolesIncluding(domain.ProjectEditor))
+	assert.Equal(t, []domain.ProjectRole{domain.ProjectViewer, domain.ProjectEditor, domain.ProjectOwner}, domain.RolesIncluding(domain.ProjectViewer))
+}
+
+func TestNewProjectInvitation(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		role      domain.ProjectRole
+		invitedBy uuid.UUID
+		wantEmail string
+		wantErr   error
+	}{
+		{"valid", "Jane@Example.com", domain.ProjectEditor, uuid.New(), "jane@example.com", nil},
+		{"trimmed", "  jane@example.com ", domain.ProjectViewer, uuid.New(), "jane@example.com", nil},
+		{"display name", "Jane <jane@example.com>", domain.ProjectViewer, uuid.New(), "", domain.ErrInvalidInvitationEmail},
+		{"not an email", "jane", domain.ProjectViewer, uuid.New(), "", domain.ErrInvalidInvitationEmail},
+		{"unknown role", "jane@example.com", "admin", uuid.New(), "", domain.ErrInvalidProjectRole},
+		{"no inviter", "jane@example.com", domain.ProjectViewer, uuid.Nil, "", domain.ErrUserIdCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.NewProjectInvitation(domain.TestProject.Id, tt.invitedBy, tt.email, tt.role)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEmail, got.Email)
+			assert.Equal(t, tt.role, got.Role)
+			assert.Equal(t, domain.TestProject.Id, got.ProjectId)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package unittest_domain
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", "a0"},
+		{"", "a0", "Zz"},
+		{"a0", "", "a1"},
+		{"a0", "a1", "a0V"},
+		{"a1", "a2", "a1V"},
+		{"a0V", "a1", "a0l"},
+		{"Zz", "a0", "ZzV"},
+		{"Zz", "a01", "a0"},
+		{"az", "", "b00"},
+		{"a0", "a0V", "a0G"},
+		{"b125", "b129", "b127"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got, err := domain.PositionBetween(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPositionBetweenRejectsInvalidKeys(t *testing.T) {
+	for _, pair := range [][2]string{{"a1", "a0"}, {"a0", "a0"}, {"a10", ""}, {"", "A00000000000000000000000000"}, {"a", ""}, {"a-", ""}} {
+		_, err := domain.PositionBetween(pair[0], pair[1])
+		assert.ErrorIs(t, err, domain.ErrInvalidPosition, pair)
+	}
+}
+
+func TestPositionBetweenKeepsOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{}
+
+	for i := 0; i < 2000; i++ {
+		at := rng.Intn(len(keys) + 1)
+		var before, after string
+		if at > 0 {
+			before = keys[at-1]
+		}
+		if at < len(keys) {
+			after = keys[at]
+		}
+
+		key, err := domain.PositionBetween(before, after)
+		require.NoError(t, err)
+		if before != "" {
+			require.Less(t, before, key)
+		}
+		if after != "" {
+			require.Less(t, key, after)
+		}
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+	assert.True(t, sort.StringsAreSorted(keys))
+
+	// appending keeps the keys short
+	last := ""
+	for i := 0; i < 10000; i++ {
+		var err error
+		last, err = domain.PositionBetween(last, "")
+		require.NoError(t, err)
+	}
+	assert.LessOrEqual(t, len(last), 4)
+}
@@ -0,0 +1,45 @@
+package unittest_domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectName string
+		color       string
+		position    int
+		wantName    string
+		wantColor   string
+		wantErr     error
+	}{
+		{"valid", "Home", "#10B981", 2, "Home", "#10b981", nil},
+		{"default color", "Work", "", 0, "Work", domain.DefaultProjectColor, nil},
+		{"trimmed name", "  Garden ", "", 0, "Garden", domain.DefaultProjectColor, nil},
+		{"empty name", "  ", "", 0, "", "", domain.ErrEmptyProjectName},
+		{"name too long", strings.Repeat("a", domain.MaxProjectNameLength+1), "", 0, "", "", domain.ErrProjectNameTooLong},
+		{"invalid color", "Home", "blue", 0, "", "", domain.ErrInvalidColor},
+		{"negative position", "Home", "", -1, "", "", domain.ErrInvalidProjectPosition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.NewProject(uuid.New(), tt.projectName, tt.color, false, tt.position)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Equal(t, tt.wantColor, got.Color)
+			assert.Equal(t, tt.position, got.Position)
+		})
+	}
+}
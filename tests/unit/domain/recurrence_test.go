@@ -0,0 +1,117 @@
+package unittest_domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		timezone string
+		wantErr  error
+	}{
+		{"valid", "FREQ=DAILY", "Europe/Istanbul", nil},
+		{"default timezone", "FREQ=WEEKLY;BYDAY=MO", "", nil},
+		{"invalid rule", "FREQ=HOURLY", "UTC", domain.ErrInvalidRRule},
+		{"unknown timezone", "FREQ=DAILY", "Mars/Olympus", domain.ErrInvalidTimezone},
+		{"local timezone", "FREQ=DAILY", "Local", domain.ErrInvalidTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.NewRecurrence(tt.rule, tt.timezone)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTodoSetRecurrence(t *testing.T) {
+	recurrence, err := domain.NewRecurrence("FREQ=DAILY", "UTC")
+	require.NoError(t, err)
+
+	t.Run("without a due date", func(t *testing.T) {
+		todo := &domain.Todo{}
+		assert.ErrorIs(t, todo.SetRecurrence(recurrence), domain.ErrRecurrenceWithoutDueAt)
+	})
+
+	t.Run("anchors the series at the due date", func(t *testing.T) {
+		dueAt := time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC)
+		todo := &domain.Todo{DueAt: dueAt}
+		require.NoError(t, todo.SetRecurrence(recurrence))
+		assert.Equal(t, dueAt, todo.OccurrenceAt)
+
+		require.NoError(t, todo.SetRecurrence(nil))
+		assert.Nil(t, todo.Recurrence)
+		assert.True(t, todo.OccurrenceAt.IsZero())
+	})
+}
+
+func TestTodoNextOccurrence(t *testing.T) {
+	dueAt := time.Date(2030, 3, 29, 6, 0, 0, 0, time.UTC) // 09:00 in Istanbul
+	newTodo := func(t *testing.T, rule string) *domain.Todo {
+		recurrence, err := domain.NewRecurrence(rule, "Europe/Istanbul")
+		require.NoError(t, err)
+		todo, err := domain.NewTodo(domain.TestUser.Id, "Water the plants", "", dueAt, domain.PriorityHigh)
+		require.NoError(t, err)
+		require.NoError(t, todo.SetRecurrence(recurrence))
+		return todo
+	}
+
+	t.Run("one-off todo", func(t *testing.T) {
+		todo, err := domain.NewTodo(domain.TestUser.Id, "Water the plants", "", dueAt, domain.PriorityHigh)
+		require.NoError(t, err)
+		assert.Nil(t, todo.NextOccurrence())
+	})
+
+	t.Run("copies the todo to the next slot", func(t *testing.T) {
+		todo := newTodo(t, "FREQ=DAILY")
+		next := todo.NextOccurrence()
+		require.NotNil(t, next)
+		assert.NotEqual(t, todo.Id, next.Id)
+		assert.Equal(t, todo.Title, next.Title)
+		assert.Equal(t, todo.Priority, next.Priority)
+		assert.Equal(t, dueAt.Add(24*time.Hour), next.DueAt)
+		assert.Equal(t, next.DueAt, next.OccurrenceAt)
+		assert.False(t, next.Completed)
+	})
+
+	t.Run("keeps the schedule when the occurrence was moved", func(t *testing.T) {
+		todo := newTodo(t, "FREQ=DAILY")
+		todo.DueAt = dueAt.Add(5 * time.Hour)
+		next := todo.NextOccurrence()
+		require.NotNil(t, next)
+		assert.Equal(t, dueAt.Add(24*time.Hour), next.DueAt)
+	})
+
+	t.Run("counts down the remaining occurrences", func(t *testing.T) {
+		todo := newTodo(t, "FREQ=DAILY;COUNT=2")
+		next := todo.NextOccurrence()
+		require.NotNil(t, next)
+		assert.Equal(t, 1, next.Recurrence.Rule.Count)
+		assert.Nil(t, next.NextOccurrence())
+	})
+
+	t.Run("stops at until", func(t *testing.T) {
+		todo := newTodo(t, "FREQ=DAILY;UNTIL=20300330")
+		next := todo.NextOccurrence()
+		require.NotNil(t, next)
+		assert.Nil(t, next.NextOccurrence())
+	})
+
+	t.Run("keeps the local time across DST", func(t *testing.T) {
+		recurrence, err := domain.NewRecurrence("FREQ=WEEKLY", "America/New_York")
+		require.NoError(t, err)
+		// 09:00 EST, the clocks move forward on 2030-03-10
+		todo := &domain.Todo{DueAt: time.Date(2030, 3, 4, 14, 0, 0, 0, time.UTC)}
+		require.NoError(t, todo.SetRecurrence(recurrence))
+		next := todo.NextOccurrence()
+		require.NotNil(t, next)
+		assert.Equal(t, time.Date(2030, 3, 11, 13, 0, 0, 0, time.UTC), next.DueAt)
+	})
+}
@@ -0,0 +1,53 @@
+package unittest_domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReminder(t *testing.T) {
+	now := time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC)
+	dueAt := now.Add(24 * time.Hour)
+	todoId := uuid.New()
+
+	t.Run("absolute in the future", func(t *testing.T) {
+		reminder, err := domain.NewAbsoluteReminder(todoId, now.Add(time.Hour), now)
+		require.NoError(t, err)
+		assert.False(t, reminder.Relative)
+		assert.Equal(t, now.Add(time.Hour), reminder.FireAt(time.Time{}))
+	})
+
+	t.Run("absolute in the past", func(t *testing.T) {
+		_, err := domain.NewAbsoluteReminder(todoId, now.Add(-time.Minute), now)
+		assert.ErrorIs(t, err, domain.ErrReminderInPast)
+	})
+
+	t.Run("relative follows the due date", func(t *testing.T) {
+		reminder, err := domain.NewRelativeReminder(todoId, 30*time.Minute, dueAt, now)
+		require.NoError(t, err)
+		assert.True(t, reminder.Relative)
+		assert.Equal(t, dueAt.Add(-30*time.Minute), reminder.FireAt(dueAt))
+		assert.Equal(t, dueAt.Add(time.Hour-30*time.Minute), reminder.FireAt(dueAt.Add(time.Hour)))
+		assert.True(t, reminder.FireAt(time.Time{}).IsZero())
+	})
+
+	t.Run("relative without a due date", func(t *testing.T) {
+		_, err := domain.NewRelativeReminder(todoId, 30*time.Minute, time.Time{}, now)
+		assert.ErrorIs(t, err, domain.ErrReminderWithoutDueAt)
+	})
+
+	t.Run("relative with negative offset", func(t *testing.T) {
+		_, err := domain.NewRelativeReminder(todoId, -time.Minute, dueAt, now)
+		assert.ErrorIs(t, err, domain.ErrInvalidReminderOffset)
+	})
+
+	t.Run("relative with too large offset", func(t *testing.T) {
+		_, err := domain.NewRelativeReminder(todoId, domain.MaxReminderOffset+time.Minute, dueAt, now)
+		assert.ErrorIs(t, err, domain.ErrInvalidReminderOffset)
+	})
+}
@@ -0,0 +1,72 @@
+package unittest_domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTodoRevision(t *testing.T) {
+	dueAt := time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := domain.TodoState{Title: "Buy milk", Priority: domain.PriorityNone}
+
+	revision, err := domain.NewTodoRevision(domain.TestTodo.Id, domain.TestUser.Id, before, before)
+	require.NoError(t, err)
+	assert.Nil(t, revision, "nothing changed")
+
+	after := before
+	after.Title = "Buy <oat> milk"
+	after.Completed = true
+	after.DueAt = &dueAt
+	revision, err = domain.NewTodoRevision(domain.TestTodo.Id, domain.TestUser.Id, before, after)
+	require.NoError(t, err)
+	require.NotNil(t, revision)
+	assert.Equal(t, domain.TestUser.Id, revision.ActorId)
+	assert.Len(t, revision.Changes, 3)
+	assert.JSONEq(t, `"Buy milk"`, string(revision.Changes["title"].Before))
+	assert.JSONEq(t, `"Buy <oat> milk"`, string(revision.Changes["title"].After))
+	assert.JSONEq(t, `null`, string(revision.Changes["due_at"].Before))
+	assert.JSONEq(t, `"2030-05-01T12:00:00Z"`, string(revision.Changes["due_at"].After))
+	assert.JSONEq(t, `true`, string(revision.Changes["completed"].After))
+}
+
+func TestTodoRevisionRevert(t *testing.T) {
+	projectId := uuid.New()
+	before := domain.TodoState{Title: "Buy milk", Priority: domain.PriorityLow}
+	after := domain.TodoState{Title: "Buy <oat> milk", Priority: domain.PriorityLow, ProjectId: &projectId}
+
+	revision, err := domain.NewTodoRevision(domain.TestTodo.Id, domain.TestUser.Id, before, after)
+	require.NoError(t, err)
+
+	// a revision read back from the database, where the escaping may differ
+	data, err := json.Marshal(revision.Changes)
+	require.NoError(t, err)
+	stored := domain.TodoRevision{Changes: map[string]domain.FieldChange{}}
+	require.NoError(t, json.Unmarshal(data, &stored.Changes))
+	stored.Changes["title"] = domain.FieldChange{Before: []byte(`"Buy milk"`), After: []byte(`"Buy <oat> milk"`)}
+
+	t.Run("sets the changed fields back", func(t *testing.T) {
+		current := after
+		current.Completed = true
+
+		reverted, err := stored.Revert(current)
+		require.NoError(t, err)
+		assert.Equal(t, "Buy milk", reverted.Title)
+		assert.Nil(t, reverted.ProjectId)
+		assert.True(t, reverted.Completed, "fields the revision did not change are kept")
+		assert.Equal(t, domain.PriorityLow, reverted.Priority)
+	})
+
+	t.Run("refuses when a field changed since", func(t *testing.T) {
+		current := after
+		current.Title = "Buy bread"
+
+		_, err := stored.Revert(current)
+		assert.ErrorIs(t, err, domain.ErrRevisionConflict)
+	})
+}
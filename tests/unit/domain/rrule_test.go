@@ -0,0 +1,215 @@
+package unittest_domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr error
+	}{
+		{"weekly on days", "FREQ=WEEKLY;BYDAY=MO,WE", "FREQ=WEEKLY;BYDAY=MO,WE", nil},
+		{"prefix and lower case", "rrule:freq=daily;interval=2", "FREQ=DAILY;INTERVAL=2", nil},
+		{"interval of one is implied", "FREQ=DAILY;INTERVAL=1", "FREQ=DAILY", nil},
+		{"count", "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=-1", "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=-1", nil},
+		{"until date time", "FREQ=DAILY;UNTIL=20300131T090000Z", "FREQ=DAILY;UNTIL=20300131T090000Z", nil},
+		{"until date", "FREQ=DAILY;UNTIL=20300131", "FREQ=DAILY;UNTIL=20300131", nil},
+		{"ordinal weekdays", "FREQ=MONTHLY;BYDAY=+1MO,-1FR", "FREQ=MONTHLY;BYDAY=1MO,-1FR", nil},
+		{"yearly ordinal", "FREQ=YEARLY;BYDAY=20MO", "FREQ=YEARLY;BYDAY=20MO", nil},
+		{"canonical order", "BYMONTH=1,7;FREQ=YEARLY;WKST=SU;BYDAY=TU", "FREQ=YEARLY;BYMONTH=1,7;BYDAY=TU;WKST=SU", nil},
+		{"empty", "  ", "", domain.ErrInvalidRRule},
+		{"missing freq", "INTERVAL=2", "", domain.ErrInvalidRRule},
+		{"unknown freq", "FREQ=FORTNIGHTLY", "", domain.ErrInvalidRRule},
+		{"hourly is not supported", "FREQ=HOURLY", "", domain.ErrInvalidRRule},
+		{"bysetpos is not supported", "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=1", "", domain.ErrInvalidRRule},
+		{"unknown part", "FREQ=DAILY;FOO=1", "", domain.ErrInvalidRRule},
+		{"duplicated part", "FREQ=DAILY;FREQ=WEEKLY", "", domain.ErrInvalidRRule},
+		{"not a pair", "FREQ=DAILY;COUNT", "", domain.ErrInvalidRRule},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0", "", domain.ErrInvalidRRule},
+		{"count and until", "FREQ=DAILY;COUNT=2;UNTIL=20300101", "", domain.ErrInvalidRRule},
+		{"bad until", "FREQ=DAILY;UNTIL=2030-01-01", "", domain.ErrInvalidRRule},
+		{"month out of range", "FREQ=YEARLY;BYMONTH=13", "", domain.ErrInvalidRRule},
+		{"month day zero", "FREQ=MONTHLY;BYMONTHDAY=0", "", domain.ErrInvalidRRule},
+		{"month day out of range", "FREQ=MONTHLY;BYMONTHDAY=32", "", domain.ErrInvalidRRule},
+		{"unknown weekday", "FREQ=WEEKLY;BYDAY=XX", "", domain.ErrInvalidRRule},
+		{"ordinal on weekly", "FREQ=WEEKLY;BYDAY=1MO", "", domain.ErrInvalidRRule},
+		{"monthly ordinal out of range", "FREQ=MONTHLY;BYDAY=6MO", "", domain.ErrInvalidRRule},
+		{"too long", "FREQ=DAILY;BYMONTHDAY=" + strings.Repeat("1,", domain.MaxRRuleLength), "", domain.ErrInvalidRRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := domain.ParseRRule(tt.rule)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, rule)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+
+			again, err := domain.ParseRRule(rule.String())
+			require.NoError(t, err)
+			assert.Equal(t, rule, again)
+		})
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	at := func(loc *time.Location, value string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		require.NoError(t, err)
+		return tm
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		loc     *time.Location
+		dtstart string
+		from    string
+		n       int
+		want    []string
+	}{
+		{"daily", "FREQ=DAILY", time.UTC, "2030-01-30 09:00", "", 4,
+			[]string{"2030-01-30 09:00", "2030-01-31 09:00", "2030-02-01 09:00", "2030-02-02 09:00"}},
+		{"every other day", "FREQ=DAILY;INTERVAL=2", time.UTC, "2030-01-30 09:00", "", 3,
+			[]string{"2030-01-30 09:00", "2030-02-01 09:00", "2030-02-03 09:00"}},
+		{"daily on weekdays only", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", time.UTC, "2030-01-04 18:30", "", 3,
+			[]string{"2030-01-04 18:30", "2030-01-07 18:30", "2030-01-08 18:30"}},
+		{"weekly defaults to the start weekday", "FREQ=WEEKLY", time.UTC, "2030-01-02 09:00", "", 3,
+			[]string{"2030-01-02 09:00", "2030-01-09 09:00", "2030-01-16 09:00"}},
+		{"weekly on monday and wednesday", "FREQ=WEEKLY;BYDAY=MO,WE", time.UTC, "2030-01-02 09:00", "", 4,
+			[]string{"2030-01-02 09:00", "2030-01-07 09:00", "2030-01-09 09:00", "2030-01-14 09:00"}},
+		{"biweekly keeps its phase", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", time.UTC, "2030-01-09 09:00", "", 4,
+			[]string{"2030-01-11 09:00", "2030-01-21 09:00", "2030-01-25 09:00", "2030-02-04 09:00"}},
+		{"week start changes biweekly sets", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU;WKST=SU", time.UTC, "1997-08-05 09:00", "", 4,
+			[]string{"1997-08-05 09:00", "1997-08-17 09:00", "1997-08-19 09:00", "1997-08-31 09:00"}},
+		{"monthly defaults to the start day", "FREQ=MONTHLY", time.UTC, "2030-01-15 09:00", "", 3,
+			[]string{"2030-01-15 09:00", "2030-02-15 09:00", "2030-03-15 09:00"}},
+		{"monthly on the 31st skips short months", "FREQ=MONTHLY", time.UTC, "2030-01-31 09:00", "", 3,
+			[]string{"2030-01-31 09:00", "2030-03-31 09:00", "2030-05-31 09:00"}},
+		{"last day of the month", "FREQ=MONTHLY;BYMONTHDAY=-1", time.UTC, "2032-01-10 09:00", "", 3,
+			[]string{"2032-01-31 09:00", "2032-02-29 09:00", "2032-03-31 09:00"}},
+		{"first monday and last friday", "FREQ=MONTHLY;BYDAY=1MO,-1FR", time.UTC, "2030-01-01 09:00", "", 4,
+			[]string{"2030-01-07 09:00", "2030-01-25 09:00", "2030-02-04 09:00", "2030-02-22 09:00"}},
+		{"friday the 13th", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", time.UTC, "2030-01-01 09:00", "", 3,
+			[]string{"2030-09-13 09:00", "2030-12-13 09:00", "2031-06-13 09:00"}},
+		{"yearly defaults to the start date", "FREQ=YEARLY", time.UTC, "2030-03-10 09:00", "", 2,
+			[]string{"2030-03-10 09:00", "2031-03-10 09:00"}},
+		{"leap day only in leap years", "FREQ=YEARLY", time.UTC, "2032-02-29 09:00", "", 2,
+			[]string{"2032-02-29 09:00", "2036-02-29 09:00"}},
+		{"yearly in months", "FREQ=YEARLY;BYMONTH=1,7", time.UTC, "2030-01-05 09:00", "", 3,
+			[]string{"2030-01-05 09:00", "2030-07-05 09:00", "2031-01-05 09:00"}},
+		{"thanksgiving", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", time.UTC, "2030-01-01 12:00", "", 2,
+			[]string{"2030-11-28 12:00", "2031-11-27 12:00"}},
+		{"20th monday of the year", "FREQ=YEARLY;BYDAY=20MO", time.UTC, "1997-05-19 09:00", "", 3,
+			[]string{"1997-05-19 09:00", "1998-05-18 09:00", "1999-05-17 09:00"}},
+		{"last day of the year", "FREQ=YEARLY;BYDAY=-1WE", time.UTC, "2030-01-01 09:00", "", 1,
+			[]string{"2030-12-25 09:00"}},
+		{"count", "FREQ=DAILY;COUNT=3", time.UTC, "2030-01-01 09:00", "", 10,
+			[]string{"2030-01-01 09:00", "2030-01-02 09:00", "2030-01-03 09:00"}},
+		{"count includes skipped occurrences", "FREQ=DAILY;COUNT=3", time.UTC, "2030-01-01 09:00", "2030-01-02 10:00", 10,
+			[]string{"2030-01-03 09:00"}},
+		{"until is inclusive", "FREQ=DAILY;UNTIL=20300103T090000Z", time.UTC, "2030-01-01 09:00", "", 10,
+			[]string{"2030-01-01 09:00", "2030-01-02 09:00", "2030-01-03 09:00"}},
+		{"until date is local", "FREQ=DAILY;UNTIL=20300102", istanbul, "2030-01-01 23:30", "", 10,
+			[]string{"2030-01-01 23:30", "2030-01-02 23:30"}},
+		{"from skips earlier occurrences", "FREQ=WEEKLY;BYDAY=MO", time.UTC, "2030-01-07 09:00", "2030-02-01 00:00", 2,
+			[]string{"2030-02-04 09:00", "2030-02-11 09:00"}},
+		{"start that does not match the rule", "FREQ=WEEKLY;BYDAY=MO", time.UTC, "2030-01-02 09:00", "", 1,
+			[]string{"2030-01-07 09:00"}},
+		{"wall clock in the todo timezone", "FREQ=DAILY", istanbul, "2030-01-01 00:30", "", 2,
+			[]string{"2030-01-01 00:30", "2030-01-02 00:30"}},
+		{"weekday in the todo timezone", "FREQ=WEEKLY;BYDAY=MO", istanbul, "2030-01-07 01:00", "", 2,
+			[]string{"2030-01-07 01:00", "2030-01-14 01:00"}},
+		{"across the spring DST change", "FREQ=DAILY", newYork, "2030-03-09 09:00", "", 3,
+			[]string{"2030-03-09 09:00", "2030-03-10 09:00", "2030-03-11 09:00"}},
+		{"across the fall DST change", "FREQ=WEEKLY", newYork, "2030-10-28 09:00", "", 2,
+			[]string{"2030-10-28 09:00", "2030-11-04 09:00"}},
+		{"inside the DST gap", "FREQ=DAILY", newYork, "2030-03-09 02:30", "", 3,
+			[]string{"2030-03-09 02:30", "2030-03-10 03:30", "2030-03-11 02:30"}},
+		{"never matches", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", time.UTC, "2030-01-01 09:00", "", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := domain.ParseRRule(tt.rule)
+			require.NoError(t, err)
+
+			dtstart := at(tt.loc, tt.dtstart)
+			from := dtstart
+			if tt.from != "" {
+				from = at(tt.loc, tt.from)
+			}
+
+			var got []string
+			for _, occurrence := range rule.Occurrences(dtstart, tt.loc, from, tt.n) {
+				assert.Equal(t, tt.loc, occurrence.Location())
+				got = append(got, occurrence.Format("2006-01-02 15:04"))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRRuleDSTKeepsLocalTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	rule, err := domain.ParseRRule("FREQ=DAILY")
+	require.NoError(t, err)
+
+	before := time.Date(2030, 3, 9, 9, 0, 0, 0, newYork)
+	occurrences := rule.Occurrences(before, newYork, before, 2)
+	require.Len(t, occurrences, 2)
+
+	// 09:00 EST is 14:00 UTC, 09:00 EDT is 13:00 UTC
+	assert.Equal(t, 14, occurrences[0].UTC().Hour())
+	assert.Equal(t, 13, occurrences[1].UTC().Hour())
+	assert.Equal(t, 23*time.Hour, occurrences[1].Sub(occurrences[0]))
+}
+
+func TestRRuleOccurrencesKeepFractionalStart(t *testing.T) {
+	rule, err := domain.ParseRRule("FREQ=DAILY")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2030, 1, 1, 9, 0, 0, 500_000_000, time.UTC)
+	occurrences := rule.Occurrences(dtstart, time.UTC, dtstart, 2)
+	require.Len(t, occurrences, 2)
+
+	assert.Equal(t, dtstart, occurrences[0])
+	assert.Equal(t, dtstart.AddDate(0, 0, 1), occurrences[1])
+}
+
+func TestRRuleNext(t *testing.T) {
+	rule, err := domain.ParseRRule("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+
+	next, ok := rule.Next(dtstart, time.UTC, dtstart)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2030, 1, 9, 9, 0, 0, 0, time.UTC), next)
+
+	next, ok = rule.Next(dtstart, time.UTC, time.Date(2030, 1, 9, 9, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2030, 1, 14, 9, 0, 0, 0, time.UTC), next)
+
+	_, ok = rule.Next(dtstart, time.UTC, next)
+	assert.False(t, ok, "the series ends after three occurrences")
+}
@@ -0,0 +1,44 @@
+package unittest_domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTag(t *testing.T) {
+	tests := []struct {
+		name      string
+		tagName   string
+		color     string
+		wantName  string
+		wantColor string
+		wantErr   error
+	}{
+		{"valid", "Work", "#1E90FF", "Work", "#1e90ff", nil},
+		{"default color", "Home", "", "Home", domain.DefaultTagColor, nil},
+		{"trimmed name", "  Errands ", "#aabbcc", "Errands", "#aabbcc", nil},
+		{"empty name", "   ", "#aabbcc", "", "", domain.ErrEmptyTagName},
+		{"name too long", strings.Repeat("a", domain.MaxTagNameLength+1), "", "", "", domain.ErrTagNameTooLong},
+		{"comma in name", "work,home", "", "", "", domain.ErrInvalidTagName},
+		{"named color", "Work", "red", "", "", domain.ErrInvalidColor},
+		{"short hex color", "Work", "#fff", "", "", domain.ErrInvalidColor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.NewTag(uuid.New(), tt.tagName, tt.color)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Equal(t, tt.wantColor, got.Color)
+		})
+	}
+}
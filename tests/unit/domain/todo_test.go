@@ -1,18 +1,23 @@
 package unittest_domain
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTodo(t *testing.T) {
 	type args struct {
-		userId uuid.UUID
-		title  string
+		userId      uuid.UUID
+		title       string
+		description string
+		dueAt       time.Time
+		priority    domain.Priority
 	}
 
 	tests := []struct {
@@ -52,11 +57,74 @@ func TestNewTodo(t *testing.T) {
 			},
 			domain.ErrTitleTooShort,
 		},
+		{
+			"markdown description",
+			args{
+				userId:      uuid.New(),
+				title:       "Buy groceries",
+				description: "- [ ] milk\n- [x] **bread**",
+			},
+			nil,
+		},
+		{
+			"description too long",
+			args{
+				userId:      uuid.New(),
+				title:       "Buy groceries",
+				description: strings.Repeat("a", domain.MaxDescriptionLength+1),
+			},
+			domain.ErrDescriptionTooLong,
+		},
+		{
+			"due date with offset",
+			args{
+				userId: uuid.New(),
+				title:  "Buy groceries",
+				dueAt:  time.Date(2030, 5, 1, 18, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)),
+			},
+			nil,
+		},
+		{
+			"past due date",
+			args{
+				userId: uuid.New(),
+				title:  "Buy groceries",
+				dueAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			nil,
+		},
+		{
+			"due date out of range",
+			args{
+				userId: uuid.New(),
+				title:  "Buy groceries",
+				dueAt:  time.Date(1, 1, 1, 0, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)),
+			},
+			domain.ErrInvalidDueAt,
+		},
+		{
+			"urgent priority",
+			args{
+				userId:   uuid.New(),
+				title:    "Buy groceries",
+				priority: domain.PriorityUrgent,
+			},
+			nil,
+		},
+		{
+			"unknown priority",
+			args{
+				userId:   uuid.New(),
+				title:    "Buy groceries",
+				priority: "critical",
+			},
+			domain.ErrInvalidPriority,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := domain.NewTodo(tt.args.userId, tt.args.title)
+			got, err := domain.NewTodo(tt.args.userId, tt.args.title, tt.args.description, tt.args.dueAt, tt.args.priority)
 			if err != nil {
 				assert.Equal(t, tt.wantErr, err)
 			} else {
@@ -65,11 +133,94 @@ func TestNewTodo(t *testing.T) {
 				assert.NotEmpty(t, got.Id)
 				assert.Equal(t, tt.args.userId, got.UserId)
 				assert.Equal(t, tt.args.title, got.Title)
+				assert.Equal(t, tt.args.description, got.Description)
 				assert.False(t, got.Completed)
 				assert.WithinDuration(t, time.Now(), got.CreatedAt, time.Second)
 				assert.Equal(t, time.Time{}, got.CompletedAt)
+				assert.True(t, tt.args.dueAt.Equal(got.DueAt))
+				assert.Equal(t, tt.args.dueAt.Location(), got.DueAt.Location(), "the due date keeps its zone")
+				if tt.args.priority == "" {
+					assert.Equal(t, domain.PriorityNone, got.Priority)
+				} else {
+					assert.Equal(t, tt.args.priority, got.Priority)
+				}
 			}
 
 		})
 	}
 }
+
+func TestIsOverdue(t *testing.T) {
+	now := time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		dueAt     time.Time
+		completed bool
+		want      bool
+	}{
+		{"no due date", time.Time{}, false, false},
+		{"due in the future", now.Add(time.Hour), false, false},
+		{"due in the past", now.Add(-time.Hour), false, true},
+		{"completed after the due date", now.Add(-time.Hour), true, false},
+		{"same instant in another zone", now.In(time.FixedZone("UTC-5", -5*60*60)).Add(-time.Minute), false, true},
+		{"all day, today in its zone", time.Date(2030, 5, 1, 0, 0, 0, 0, time.FixedZone("", 3*60*60)), false, false},
+		{"all day, yesterday in its zone", time.Date(2030, 4, 30, 0, 0, 0, 0, time.FixedZone("", 3*60*60)), false, true},
+		{"all day, still today west of UTC", time.Date(2030, 4, 30, 0, 0, 0, 0, time.FixedZone("", -13*60*60)), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, domain.IsOverdue(tt.dueAt, tt.completed, now))
+		})
+	}
+}
+
+func TestDueTimezone(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		dueAt time.Time
+		want  string
+	}{
+		{"named zone", time.Date(2030, 5, 1, 9, 0, 0, 0, istanbul), "Europe/Istanbul"},
+		{"offset", time.Date(2030, 5, 1, 9, 0, 0, 0, time.FixedZone("", -(5*60+30)*60)), "-05:30"},
+		{"UTC", time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC), "UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := domain.DueTimezone(tt.dueAt)
+			assert.Equal(t, tt.want, got)
+
+			loc, err := domain.LoadDueTimezone(got)
+			require.NoError(t, err)
+			back := tt.dueAt.In(loc)
+			assert.Equal(t, tt.dueAt.Format(time.RFC3339), back.Format(time.RFC3339), "the zone gives back the wall clock time")
+		})
+	}
+}
+
+func TestPriorityRank(t *testing.T) {
+	for rank, priority := range domain.Priorities {
+		assert.Equal(t, rank, priority.Rank())
+
+		got, err := domain.PriorityFromRank(rank)
+		assert.NoError(t, err)
+		assert.Equal(t, priority, got)
+	}
+
+	assert.Less(t, domain.PriorityLow.Rank(), domain.PriorityUrgent.Rank())
+	assert.Equal(t, -1, domain.Priority("critical").Rank())
+
+	_, err := domain.PriorityFromRank(len(domain.Priorities))
+	assert.ErrorIs(t, err, domain.ErrInvalidPriority)
+}
+
+func TestValidateSubtaskDepth(t *testing.T) {
+	assert.NoError(t, domain.ValidateSubtaskDepth(1))
+	assert.NoError(t, domain.ValidateSubtaskDepth(domain.MaxSubtaskDepth))
+	assert.ErrorIs(t, domain.ValidateSubtaskDepth(domain.MaxSubtaskDepth+1), domain.ErrSubtaskTooDeep)
+}
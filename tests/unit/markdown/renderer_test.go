@@ -0,0 +1,64 @@
+package unittest_markdown
+
+import (
+	"testing"
+
+	markdownInfra "github.com/muhammedkucukaslan/advanced-todo-api/infrastructure/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer(t *testing.T) {
+	renderer := markdownInfra.NewRenderer()
+
+	tests := []struct {
+		name        string
+		markdown    string
+		contains    []string
+		notContains []string
+	}{
+		{
+			"basic formatting",
+			"**bold** and _italic_",
+			[]string{"<strong>bold</strong>", "<em>italic</em>"},
+			nil,
+		},
+		{
+			"task list",
+			"- [x] done\n- [ ] open",
+			[]string{`<input checked="" disabled="" type="checkbox"`, `<input disabled="" type="checkbox"`},
+			nil,
+		},
+		{
+			"script tag",
+			"hello <script>alert(1)</script>",
+			[]string{"hello"},
+			[]string{"<script"},
+		},
+		{
+			"javascript link",
+			"[click](javascript:alert(1))",
+			nil,
+			[]string{"javascript:"},
+		},
+		{
+			"event handler attribute",
+			`<img src="x" onerror="alert(1)">`,
+			nil,
+			[]string{"onerror"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := renderer.Render(tt.markdown)
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, html, want)
+			}
+			for _, unwanted := range tt.notContains {
+				assert.NotContains(t, html, unwanted)
+			}
+		})
+	}
+}
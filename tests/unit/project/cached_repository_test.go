@@ -0,0 +1,124 @@
+package unittest_project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockProjectRepository reports the caller and SecondTestUser as the members of every
+// project.
+type mockProjectRepository struct {
+	err         error
+	deletedWith domain.ProjectDeleteMode
+}
+
+func (m *mockProjectRepository) CreateProject(ctx context.Context, project *domain.Project) error {
+	return m.err
+}
+func (m *mockProjectRepository) GetProjectsByUserID(ctx context.Context, userId uuid.UUID, includeArchived bool) (*project.GetProjectsResponse, error) {
+	return &project.GetProjectsResponse{}, m.err
+}
+func (m *mockProjectRepository) UpdateProject(ctx context.Context, project *domain.Project) error {
+	return m.err
+}
+func (m *mockProjectRepository) DeleteProject(ctx context.Context, id, userId uuid.UUID, mode domain.ProjectDeleteMode) error {
+	m.deletedWith = mode
+	return m.err
+}
+func (m *mockProjectRepository) InviteMember(ctx context.Context, invitation *domain.ProjectInvitation) error {
+	return m.err
+}
+func (m *mockProjectRepository) GetMembers(ctx context.Context, projectId, userId uuid.UUID) (*project.GetMembersResponse, error) {
+	return &project.GetMembersResponse{Members: []project.Member{
+		{UserId: userId, Role: domain.ProjectOwner},
+		{UserId: domain.SecondTestUser.Id, Role: domain.ProjectEditor},
+	}}, nil
+}
+func (m *mockProjectRepository) UpdateMemberRole(ctx context.Context, projectId, memberId uuid.UUID, role domain.ProjectRole, userId uuid.UUID) error {
+	return m.err
+}
+func (m *mockProjectRepository) RemoveMember(ctx context.Context, projectId, memberId, userId uuid.UUID) error {
+	return m.err
+}
+func (m *mockProjectRepository) GetInvitations(ctx context.Context, userId uuid.UUID) (*project.GetInvitationsResponse, error) {
+	return &project.GetInvitationsResponse{}, m.err
+}
+func (m *mockProjectRepository) AcceptInvitation(ctx context.Context, id, userId uuid.UUID) (uuid.UUID, error) {
+	if m.err != nil {
+		return uuid.Nil, m.err
+	}
+	return domain.TestProject.Id, nil
+}
+func (m *mockProjectRepository) DeleteInvitation(ctx context.Context, id, userId uuid.UUID) error {
+	return m.err
+}
+
+type mockTodoListInvalidator struct {
+	invalidated []uuid.UUID
+}
+
+func (m *mockTodoListInvalidator) InvalidateTodoLists(userId uuid.UUID) {
+	m.invalidated = append(m.invalidated, userId)
+}
+
+func TestCachedProjectRepository(t *testing.T) {
+	ctx := context.Background()
+	userId := domain.TestUser.Id
+	memberId := domain.SecondTestUser.Id
+
+	tests := []struct {
+		name            string
+		repoErr         error
+		call            func(repo project.ProjectRepository) error
+		wantInvalidated []uuid.UUID
+	}{
+		{"create does not touch todo lists", nil, func(repo project.ProjectRepository) error {
+			return repo.CreateProject(ctx, domain.TestProject)
+		}, nil},
+		{"update does not touch todo lists", nil, func(repo project.ProjectRepository) error {
+			return repo.UpdateProject(ctx, domain.TestProject)
+		}, nil},
+		{"delete invalidates every member", nil, func(repo project.ProjectRepository) error {
+			return repo.DeleteProject(ctx, domain.TestProject.Id, userId, domain.ProjectDeleteMoveToInbox)
+		}, []uuid.UUID{userId, memberId}},
+		{"failed delete", domain.ErrProjectNotFound, func(repo project.ProjectRepository) error {
+			return repo.DeleteProject(ctx, domain.TestProject.Id, userId, domain.ProjectDeleteCascade)
+		}, nil},
+		{"invite does not touch todo lists", nil, func(repo project.ProjectRepository) error {
+			return repo.InviteMember(ctx, &domain.ProjectInvitation{})
+		}, nil},
+		{"role change does not touch todo lists", nil, func(repo project.ProjectRepository) error {
+			return repo.UpdateMemberRole(ctx, domain.TestProject.Id, memberId, domain.ProjectViewer, userId)
+		}, nil},
+		{"remove invalidates the removed member", nil, func(repo project.ProjectRepository) error {
+			return repo.RemoveMember(ctx, domain.TestProject.Id, memberId, userId)
+		}, []uuid.UUID{memberId}},
+		{"failed remove", domain.ErrNotProjectOwner, func(repo project.ProjectRepository) error {
+			return repo.RemoveMember(ctx, domain.TestProject.Id, memberId, userId)
+		}, nil},
+		{"accept invalidates the new member", nil, func(repo project.ProjectRepository) error {
+			_, err := repo.AcceptInvitation(ctx, uuid.New(), memberId)
+			return err
+		}, []uuid.UUID{memberId}},
+		{"failed accept", domain.ErrInvitationNotFound, func(repo project.ProjectRepository) error {
+			_, err := repo.AcceptInvitation(ctx, uuid.New(), memberId)
+			return err
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidator := &mockTodoListInvalidator{}
+			repo := project.NewCachedProjectRepository(&mockProjectRepository{err: tt.repoErr}, invalidator)
+
+			err := tt.call(repo)
+			assert.ErrorIs(t, err, tt.repoErr)
+			assert.Equal(t, tt.wantInvalidated, invalidator.invalidated)
+		})
+	}
+}
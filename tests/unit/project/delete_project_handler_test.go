@@ -0,0 +1,40 @@
+package unittest_project
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteProjectHandlerMode(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	tests := []struct {
+		name     string
+		todos    string
+		code     int
+		wantMode domain.ProjectDeleteMode
+		wantErr  error
+	}{
+		{"inbox by default", "", http.StatusNoContent, domain.ProjectDeleteMoveToInbox, nil},
+		{"inbox", "inbox", http.StatusNoContent, domain.ProjectDeleteMoveToInbox, nil},
+		{"cascade", "cascade", http.StatusNoContent, domain.ProjectDeleteCascade, nil},
+		{"unknown mode", "archive", http.StatusBadRequest, "", domain.ErrInvalidDeleteMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockProjectRepository{}
+			handler := project.NewDeleteProjectHandler(repo)
+
+			_, code, err := handler.Handle(ctx, &project.DeleteProjectRequest{Id: domain.TestProject.Id, Todos: tt.todos})
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantMode, repo.deletedWith)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package unittest_project
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/project"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInviteMemberHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	tests := []struct {
+		name    string
+		email   string
+		role    string
+		repoErr error
+		code    int
+		wantErr error
+	}{
+		{"invited", "second@user.com", "editor", nil, http.StatusCreated, nil},
+		{"invalid email", "second", "editor", nil, http.StatusBadRequest, domain.ErrInvalidInvitationEmail},
+		{"invalid role", "second@user.com", "admin", nil, http.StatusBadRequest, domain.ErrInvalidProjectRole},
+		{"not an owner", "second@user.com", "viewer", domain.ErrNotProjectOwner, http.StatusForbidden, domain.ErrNotProjectOwner},
+		{"not a member", "second@user.com", "viewer", domain.ErrProjectNotFound, http.StatusNotFound, domain.ErrProjectNotFound},
+		{"already a member", "second@user.com", "viewer", domain.ErrAlreadyProjectMember, http.StatusConflict, domain.ErrAlreadyProjectMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := project.NewInviteMemberHandler(&mockProjectRepository{err: tt.repoErr})
+
+			resp, code, err := handler.Handle(ctx, &project.InviteMemberRequest{Id: domain.TestProject.Id, Email: tt.email, Role: tt.role})
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.NotNil(t, resp)
+			}
+		})
+	}
+}
+
+func TestMemberHandlersErrors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	tests := []struct {
+		name    string
+		repoErr error
+		code    int
+	}{
+		{"done", nil, http.StatusNoContent},
+		{"not a member", domain.ErrProjectNotFound, http.StatusNotFound},
+		{"unknown member", domain.ErrMemberNotFound, http.StatusNotFound},
+		{"not an owner", domain.ErrNotProjectOwner, http.StatusForbidden},
+		{"last owner", domain.ErrLastProjectOwner, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockProjectRepository{err: tt.repoErr}
+
+			_, code, err := project.NewUpdateMemberHandler(repo).Handle(ctx, &project.UpdateMemberRequest{
+				Id: domain.TestProject.Id, UserId: domain.SecondTestUser.Id, Role: "viewer",
+			})
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.repoErr)
+
+			_, code, err = project.NewRemoveMemberHandler(repo).Handle(ctx, &project.RemoveMemberRequest{
+				Id: domain.TestProject.Id, UserId: domain.SecondTestUser.Id,
+			})
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.repoErr)
+		})
+	}
+}
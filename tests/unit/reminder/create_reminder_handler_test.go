@@ -0,0 +1,55 @@
+package unittest_reminder
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/reminder"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReminderHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	clock := mock.NewMockClock(time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	withDueAt := reminder.NewCreateReminderHandler(&MockRepository{DueAt: clock.Now().Add(24 * time.Hour)}, clock)
+	withoutDueAt := reminder.NewCreateReminderHandler(&MockRepository{}, clock)
+
+	minutes := func(m int) *int { return &m }
+
+	tests := []struct {
+		name    string
+		handler *reminder.CreateReminderHandler
+		req     *reminder.CreateReminderRequest
+		code    int
+		wantErr error
+	}{
+		{"absolute", withDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id, RemindAt: clock.Now().Add(time.Hour)}, http.StatusCreated, nil},
+		{"relative", withDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id, MinutesBeforeDue: minutes(15)}, http.StatusCreated, nil},
+		{"at the due time", withDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id, MinutesBeforeDue: minutes(0)}, http.StatusCreated, nil},
+		{"neither", withDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id}, http.StatusBadRequest, domain.ErrInvalidReminder},
+		{"both", withDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id, RemindAt: clock.Now().Add(time.Hour), MinutesBeforeDue: minutes(15)}, http.StatusBadRequest, domain.ErrInvalidReminder},
+		{"absolute in the past", withDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id, RemindAt: clock.Now().Add(-time.Hour)}, http.StatusBadRequest, domain.ErrReminderInPast},
+		{"relative without due date", withoutDueAt, &reminder.CreateReminderRequest{TodoId: domain.TestTodo.Id, MinutesBeforeDue: minutes(15)}, http.StatusBadRequest, domain.ErrReminderWithoutDueAt},
+		{"unknown todo", withDueAt, &reminder.CreateReminderRequest{TodoId: uuid.New(), MinutesBeforeDue: minutes(15)}, http.StatusNotFound, domain.ErrTodoNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code, err := tt.handler.Handle(ctx, tt.req)
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, got.Id)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package unittest_reminder
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/reminder"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+)
+
+// MockRepository keeps reminders in memory and claims them like the Postgres
+// repository does. Todos are only known through domain.TestTodo.
+type MockRepository struct {
+	mu        sync.Mutex
+	DueAt     time.Time
+	Completed bool
+	reminders []*mockReminder
+}
+
+type mockReminder struct {
+	reminder      *domain.Reminder
+	attempts      int
+	lastAttemptAt time.Time
+}
+
+func (m *MockRepository) GetTodoDueAt(ctx context.Context, todoId, userId uuid.UUID) (time.Time, error) {
+	if todoId != domain.TestTodo.Id || userId != domain.TestTodo.UserId {
+		return time.Time{}, domain.ErrTodoNotFound
+	}
+	return m.DueAt, nil
+}
+
+func (m *MockRepository) CreateReminder(ctx context.Context, r *domain.Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reminders = append(m.reminders, &mockReminder{reminder: r})
+	return nil
+}
+
+func (m *MockRepository) GetRemindersByTodoID(ctx context.Context, todoId, userId uuid.UUID) (*reminder.GetRemindersResponse, error) {
+	return &reminder.GetRemindersResponse{}, nil
+}
+
+func (m *MockRepository) DeleteReminder(ctx context.Context, id, todoId, userId uuid.UUID) error {
+	return domain.ErrReminderNotFound
+}
+
+func (m *MockRepository) ProcessNextDueReminder(ctx context.Context, now time.Time, deliver func(ctx context.Context, reminder *reminder.DueReminder) error) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*mockReminder
+	for _, r := range m.reminders {
+		fireAt := r.reminder.FireAt(m.DueAt)
+		if !r.reminder.SentAt.IsZero() || fireAt.IsZero() || fireAt.After(now) {
+			continue
+		}
+		if r.attempts >= domain.MaxReminderAttempts || (!r.lastAttemptAt.IsZero() && r.lastAttemptAt.After(now.Add(-domain.ReminderRetryDelay))) {
+			continue
+		}
+		due = append(due, r)
+	}
+	if len(due) == 0 {
+		return false, nil
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].reminder.FireAt(m.DueAt).Before(due[j].reminder.FireAt(m.DueAt))
+	})
+
+	claimed := due[0]
+	err := deliver(ctx, &reminder.DueReminder{
+		Id:            claimed.reminder.Id,
+		TodoId:        domain.TestTodo.Id,
+		TodoTitle:     domain.TestTodo.Title,
+		TodoCompleted: m.Completed,
+		DueAt:         m.DueAt,
+		UserFullName:  domain.TestUser.FullName,
+		UserEmail:     domain.TestUser.Email,
+	})
+	if err != nil {
+		claimed.attempts++
+		claimed.lastAttemptAt = now
+	} else {
+		claimed.reminder.SentAt = now
+	}
+	return true, nil
+}
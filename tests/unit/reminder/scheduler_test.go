@@ -0,0 +1,85 @@
+package unittest_reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/reminder"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerFiresEachReminderOnce(t *testing.T) {
+	ctx := context.Background()
+	clock := mock.NewMockClock(time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC))
+	repo := &MockRepository{DueAt: clock.Now().Add(2 * time.Hour)}
+	emailService := mock.NewMockEmailService()
+	scheduler := reminder.NewScheduler(repo, emailService, clock, mock.NewMockLogger(), time.Minute)
+
+	absolute, err := domain.NewAbsoluteReminder(domain.TestTodo.Id, clock.Now().Add(30*time.Minute), clock.Now())
+	require.NoError(t, err)
+	relative, err := domain.NewRelativeReminder(domain.TestTodo.Id, time.Hour, repo.DueAt, clock.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateReminder(ctx, absolute))
+	require.NoError(t, repo.CreateReminder(ctx, relative))
+
+	assert.Equal(t, 0, scheduler.RunOnce(ctx), "nothing is due yet")
+
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, 1, scheduler.RunOnce(ctx))
+	assert.Equal(t, 0, scheduler.RunOnce(ctx), "a sent reminder must not fire again")
+
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, 1, scheduler.RunOnce(ctx))
+
+	clock.Advance(24 * time.Hour)
+	assert.Equal(t, 0, scheduler.RunOnce(ctx))
+
+	sent := emailService.SentReminders()
+	require.Len(t, sent, 2)
+	assert.Equal(t, domain.TestUser.Email, sent[0].To)
+	assert.Equal(t, domain.NewReminderEmailSubject(domain.TestTodo.Title), sent[0].Subject)
+}
+
+func TestSchedulerRetriesFailedDelivery(t *testing.T) {
+	ctx := context.Background()
+	clock := mock.NewMockClock(time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC))
+	repo := &MockRepository{}
+	emailService := mock.NewMockEmailService()
+	emailService.Err = errors.New("mail provider is down")
+	scheduler := reminder.NewScheduler(repo, emailService, clock, mock.NewMockLogger(), time.Minute)
+
+	r, err := domain.NewAbsoluteReminder(domain.TestTodo.Id, clock.Now().Add(time.Minute), clock.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateReminder(ctx, r))
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, 1, scheduler.RunOnce(ctx))
+	assert.Equal(t, 0, scheduler.RunOnce(ctx), "a failed reminder waits for the retry delay")
+
+	emailService.Err = nil
+	clock.Advance(domain.ReminderRetryDelay)
+	assert.Equal(t, 1, scheduler.RunOnce(ctx))
+	assert.Len(t, emailService.SentReminders(), 1)
+}
+
+func TestSchedulerSkipsCompletedTodos(t *testing.T) {
+	ctx := context.Background()
+	clock := mock.NewMockClock(time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC))
+	repo := &MockRepository{Completed: true}
+	emailService := mock.NewMockEmailService()
+	scheduler := reminder.NewScheduler(repo, emailService, clock, mock.NewMockLogger(), time.Minute)
+
+	r, err := domain.NewAbsoluteReminder(domain.TestTodo.Id, clock.Now().Add(time.Minute), clock.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateReminder(ctx, r))
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, 1, scheduler.RunOnce(ctx))
+	assert.Equal(t, 0, scheduler.RunOnce(ctx))
+	assert.Empty(t, emailService.SentReminders())
+}
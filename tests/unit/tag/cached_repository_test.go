@@ -0,0 +1,71 @@
+package unittest_tag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/tag"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTagRepository struct {
+	err error
+}
+
+func (m *mockTagRepository) CreateTag(ctx context.Context, tag *domain.Tag) error { return m.err }
+func (m *mockTagRepository) GetTagsByUserID(ctx context.Context, userId uuid.UUID) (*tag.GetTagsResponse, error) {
+	return &tag.GetTagsResponse{}, m.err
+}
+func (m *mockTagRepository) UpdateTag(ctx context.Context, tag *domain.Tag) error      { return m.err }
+func (m *mockTagRepository) DeleteTag(ctx context.Context, id, userId uuid.UUID) error { return m.err }
+
+type mockTodoListInvalidator struct {
+	invalidated []uuid.UUID
+}
+
+func (m *mockTodoListInvalidator) InvalidateTodoLists(userId uuid.UUID) {
+	m.invalidated = append(m.invalidated, userId)
+}
+
+func TestCachedTagRepository(t *testing.T) {
+	ctx := context.Background()
+	userId := domain.TestUser.Id
+
+	tests := []struct {
+		name            string
+		repoErr         error
+		call            func(repo tag.TagRepository) error
+		wantInvalidated bool
+	}{
+		{"create does not touch todo lists", nil, func(repo tag.TagRepository) error {
+			return repo.CreateTag(ctx, domain.TestTag)
+		}, false},
+		{"update", nil, func(repo tag.TagRepository) error {
+			return repo.UpdateTag(ctx, domain.TestTag)
+		}, true},
+		{"delete", nil, func(repo tag.TagRepository) error {
+			return repo.DeleteTag(ctx, domain.TestTag.Id, userId)
+		}, true},
+		{"failed delete", domain.ErrTagNotFound, func(repo tag.TagRepository) error {
+			return repo.DeleteTag(ctx, domain.TestTag.Id, userId)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidator := &mockTodoListInvalidator{}
+			repo := tag.NewCachedTagRepository(&mockTagRepository{err: tt.repoErr}, invalidator)
+
+			err := tt.call(repo)
+			assert.ErrorIs(t, err, tt.repoErr)
+
+			if tt.wantInvalidated {
+				assert.Equal(t, []uuid.UUID{userId}, invalidator.invalidated)
+			} else {
+				assert.Empty(t, invalidator.invalidated)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTodosHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	handler := todo.NewBatchTodosHandler(&MockRepository{})
+
+	completed := true
+	create := todo.BatchOperationRequest{Op: "create", Title: "Batch Todo"}
+	rename := todo.BatchOperationRequest{Op: "update_title", Id: domain.TestTodo.Id, Title: "Renamed Todo"}
+	complete := todo.BatchOperationRequest{Op: "set_completed", Id: domain.TestTodo.Id, Completed: &completed}
+	move := todo.BatchOperationRequest{Op: "move", Id: domain.TestTodo.Id, ProjectId: domain.TestProject.Id}
+	remove := todo.BatchOperationRequest{Op: "delete", Id: domain.TestTodo.Id}
+	unknownTodo := todo.BatchOperationRequest{Op: "delete", Id: domain.FakeTodoUuid}
+	invalidTitle := todo.BatchOperationRequest{Op: "update_title", Id: domain.TestTodo.Id, Title: "ab"}
+
+	tests := []struct {
+		name         string
+		req          *todo.BatchTodosRequest
+		code         int
+		wantErr      error
+		wantStatuses []int
+	}{
+		{"every operation", &todo.BatchTodosRequest{Operations: []todo.BatchOperationRequest{create, rename, complete, move, remove}},
+			http.StatusOK, nil, []int{http.StatusCreated, http.StatusNoContent, http.StatusNoContent, http.StatusNoContent, http.StatusNoContent}},
+		{"atomic with a missing todo", &todo.BatchTodosRequest{Operations: []todo.BatchOperationRequest{create, unknownTodo, rename}},
+			http.StatusNotFound, nil, []int{http.StatusFailedDependency, http.StatusNotFound, http.StatusFailedDependency}},
+		{"atomic with an invalid operation", &todo.BatchTodosRequest{Operations: []todo.BatchOperationRequest{create, invalidTitle}},
+			http.StatusBadRequest, nil, []int{http.StatusFailedDependency, http.StatusBadRequest}},
+		{"per item with a missing todo", &todo.BatchTodosRequest{Mode: "per_item", Operations: []todo.BatchOperationRequest{create, unknownTodo, rename}},
+			http.StatusMultiStatus, nil, []int{http.StatusCreated, http.StatusNotFound, http.StatusNoContent}},
+		{"per item with an invalid operation", &todo.BatchTodosRequest{Mode: "per_item", Operations: []todo.BatchOperationRequest{invalidTitle, rename}},
+			http.StatusMultiStatus, nil, []int{http.StatusBadRequest, http.StatusNoContent}},
+		{"unknown op", &todo.BatchTodosRequest{Mode: "per_item", Operations: []todo.BatchOperationRequest{{Op: "archive", Id: domain.TestTodo.Id}}},
+			http.StatusMultiStatus, nil, []int{http.StatusBadRequest}},
+		{"missing id", &todo.BatchTodosRequest{Mode: "per_item", Operations: []todo.BatchOperationRequest{{Op: "delete"}}},
+			http.StatusMultiStatus, nil, []int{http.StatusBadRequest}},
+		{"missing completed", &todo.BatchTodosRequest{Mode: "per_item", Operations: []todo.BatchOperationRequest{{Op: "set_completed", Id: domain.TestTodo.Id}}},
+			http.StatusMultiStatus, nil, []int{http.StatusBadRequest}},
+		{"empty batch", &todo.BatchTodosRequest{}, http.StatusBadRequest, domain.ErrEmptyBatch, nil},
+		{"too many operations", &todo.BatchTodosRequest{Operations: make([]todo.BatchOperationRequest, todo.MaxBatchOperations+1)},
+			http.StatusBadRequest, domain.ErrTooManyOperations, nil},
+		{"unknown mode", &todo.BatchTodosRequest{Mode: "best_effort", Operations: []todo.BatchOperationRequest{create}},
+			http.StatusBadRequest, domain.ErrInvalidBatchMode, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, code, err := handler.Handle(ctx, tt.req)
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			statuses := make([]int, len(res.Results))
+			for i, result := range res.Results {
+				statuses[i] = result.Status
+				assert.Equal(t, result.Status >= http.StatusBadRequest, result.Error != "", "result %d", i)
+			}
+			assert.Equal(t, tt.wantStatuses, statuses)
+		})
+	}
+
+	t.Run("created todos get an id", func(t *testing.T) {
+		res, _, err := handler.Handle(ctx, &todo.BatchTodosRequest{Operations: []todo.BatchOperationRequest{create}})
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, res.Results[0].Id)
+	})
+}
@@ -0,0 +1,206 @@
+package unittest_todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedTodoRepository(t *testing.T) {
+	ctx := context.Background()
+	ownerId := domain.TestUser.Id
+	otherUserId := domain.SecondTestUser.Id
+	cacheKey := domain.NewTodoCacheKey(ownerId)
+
+	newTodo, err := domain.NewTodo(ownerId, "New Test Todo", "", time.Time{}, domain.PriorityNone)
+	require.NoError(t, err)
+
+	updatedTodo, err := domain.NewTodo(ownerId, "Updated Test Todo", "", time.Time{}, domain.PriorityNone)
+	require.NoError(t, err)
+	updatedTodo.Id = domain.TestTodo.Id
+
+	tests := []struct {
+		name            string
+		write           func(repo todo.TodoRepository) error
+		wantInvalidated bool
+	}{
+		{"create", func(repo todo.TodoRepository) error {
+			return repo.CreateTodo(ctx, newTodo)
+		}, true},
+		{"update", func(repo todo.TodoRepository) error {
+			return repo.UpdateTodo(ctx, updatedTodo)
+		}, true},
+		{"delete", func(repo todo.TodoRepository) error {
+			return repo.Delete(ctx, domain.TestTodo.Id, ownerId, 0)
+		}, true},
+		{"toggle completed", func(repo todo.TodoRepository) error {
+			return repo.ToggleCompleted(ctx, domain.TestTodo.Id, ownerId, false, 0)
+		}, true},
+		{"attach tag", func(repo todo.TodoRepository) error {
+			return repo.AttachTag(ctx, domain.TestTodo.Id, domain.TestTag.Id, ownerId)
+		}, true},
+		{"detach tag", func(repo todo.TodoRepository) error {
+			return repo.DetachTag(ctx, domain.TestTodo.Id, domain.TestTag.Id, ownerId)
+		}, true},
+		{"move to project", func(repo todo.TodoRepository) error {
+			return repo.MoveTodo(ctx, domain.TestTodo.Id, ownerId, domain.TestProject.Id)
+		}, true},
+		{"move to inbox", func(repo todo.TodoRepository) error {
+			return repo.MoveTodo(ctx, domain.TestTodo.Id, ownerId, uuid.Nil)
+		}, true},
+		{"batch", func(repo todo.TodoRepository) error {
+			_, err := repo.ApplyBatch(ctx, ownerId, []todo.TodoOperation{{Type: todo.OpDelete, Id: domain.TestTodo.Id}}, true)
+			return err
+		}, true},
+		{"import", func(repo todo.TodoRepository) error {
+			return repo.ImportTodos(ctx, ownerId, []*domain.Todo{newTodo}, nil)
+		}, true},
+		{"restore", func(repo todo.TodoRepository) error {
+			return repo.RestoreTodo(ctx, domain.TestTodo.Id, ownerId)
+		}, true},
+		{"revert", func(repo todo.TodoRepository) error {
+			return repo.RevertTodo(ctx, domain.TestTodo.Id, TestRevisionId, ownerId)
+		}, true},
+		{"reorder", func(repo todo.TodoRepository) error {
+			_, err := repo.ReorderTodo(ctx, domain.TestTodo.Id, ownerId, TestUpperTodoId, TestLowerTodoId)
+			return err
+		}, true},
+		{"permanent delete keeps the cache", func(repo todo.TodoRepository) error {
+			return repo.DeleteTrashedTodo(ctx, domain.TestTodo.Id, ownerId)
+		}, false},
+		{"empty trash keeps the cache", func(repo todo.TodoRepository) error {
+			return repo.EmptyTrash(ctx, ownerId)
+		}, false},
+		{"failed write keeps the cache", func(repo todo.TodoRepository) error {
+			return repo.Delete(ctx, domain.TestTodo.Id, otherUserId, 0)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := mock.NewMockMemoryCache()
+			repo := todo.NewCachedTodoRepository(&MockRepository{}, cache, mock.NewMockLogger(), time.Minute)
+
+			todos, err := repo.GetTodosByUserID(ctx, ownerId, todo.GetTodosQuery{Sort: todo.DefaultTodoSort, Order: todo.SortAsc, Limit: todo.DefaultTodoPageSize})
+			require.NoError(t, err)
+			require.Len(t, todos.Todos, 1)
+			require.True(t, cache.Has(cacheKey), "list should be cached after the first read")
+
+			_ = tt.write(repo)
+
+			assert.Equal(t, !tt.wantInvalidated, cache.Has(cacheKey))
+		})
+	}
+}
+
+func TestCachedTodoRepositoryDoesNotCacheFilteredLists(t *testing.T) {
+	ctx := context.Background()
+	ownerId := domain.TestUser.Id
+
+	cache := mock.NewMockMemoryCache()
+	repo := todo.NewCachedTodoRepository(&MockRepository{}, cache, mock.NewMockLogger(), time.Minute)
+
+	_, err := repo.GetTodosByUserID(ctx, ownerId, todo.GetTodosQuery{Overdue: true, Sort: todo.DefaultTodoSort, Order: todo.SortAsc, Limit: todo.DefaultTodoPageSize})
+	require.NoError(t, err)
+
+	assert.False(t, cache.Has(domain.NewTodoCacheKey(ownerId)))
+}
+
+func TestCachedTodoRepositoryOnlyCachesTheFirstPage(t *testing.T) {
+	ctx := context.Background()
+	ownerId := domain.TestUser.Id
+
+	firstPage := todo.GetTodosQuery{Sort: todo.DefaultTodoSort, Order: todo.SortAsc, Limit: todo.DefaultTodoPageSize}
+	smallerPage := firstPage
+	smallerPage.Limit = 10
+	nextPage := firstPage
+	nextPage.After = &todo.TodoCursor{Sort: todo.DefaultTodoSort, Order: todo.SortAsc, Id: domain.TestTodo.Id}
+
+	for _, query := range []todo.GetTodosQuery{smallerPage, nextPage} {
+		cache := mock.NewMockMemoryCache()
+		repo := todo.NewCachedTodoRepository(&MockRepository{}, cache, mock.NewMockLogger(), time.Minute)
+
+		_, err := repo.GetTodosByUserID(ctx, ownerId, query)
+		require.NoError(t, err)
+		assert.False(t, cache.Has(domain.NewTodoCacheKey(ownerId)))
+	}
+}
+
+func TestCachedTodoRepositoryInvalidatesOncePerBatch(t *testing.T) {
+	ctx := context.Background()
+	ownerId := domain.TestUser.Id
+
+	cache := mock.NewMockMemoryCache()
+	repo := todo.NewCachedTodoRepository(&MockRepository{}, cache, mock.NewMockLogger(), time.Minute)
+
+	newTodo, err := domain.NewTodo(ownerId, "New Test Todo", "", time.Time{}, domain.PriorityNone)
+	require.NoError(t, err)
+
+	_, err = repo.ApplyBatch(ctx, ownerId, []todo.TodoOperation{
+		{Type: todo.OpCreate, Todo: newTodo, Id: newTodo.Id},
+		{Type: todo.OpUpdateTitle, Id: domain.TestTodo.Id, Title: "Renamed"},
+		{Type: todo.OpSetCompleted, Id: domain.TestTodo.Id, Completed: true},
+		{Type: todo.OpMove, Id: domain.TestTodo.Id, ProjectId: domain.TestProject.Id},
+	}, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, cache.DeleteCalls())
+}
+
+func TestCachedTodoRepositorySortVariants(t *testing.T) {
+	ctx := context.Background()
+	ownerId := domain.TestUser.Id
+
+	cache := mock.NewMockMemoryCache()
+	repo := todo.NewCachedTodoRepository(&MockRepository{}, cache, mock.NewMockLogger(), time.Minute)
+
+	defaultSort := todo.GetTodosQuery{Sort: todo.DefaultTodoSort, Order: todo.SortAsc, Limit: todo.DefaultTodoPageSize}
+	byPriority := todo.GetTodosQuery{Sort: todo.SortByPriority, Order: todo.SortDesc, Limit: todo.DefaultTodoPageSize}
+	byTitle := todo.GetTodosQuery{Sort: todo.SortByTitle, Order: todo.SortAsc, Limit: todo.DefaultTodoPageSize}
+
+	for _, query := range []todo.GetTodosQuery{defaultSort, byPriority, byTitle} {
+		_, err := repo.GetTodosByUserID(ctx, ownerId, query)
+		require.NoError(t, err)
+	}
+
+	defaultKey := domain.NewTodoCacheKey(ownerId)
+	priorityKey := domain.NewTodoVariantCacheKey(ownerId, "priority:desc")
+	titleKey := domain.NewTodoVariantCacheKey(ownerId, "title:asc")
+	for _, key := range []string{defaultKey, priorityKey, titleKey} {
+		assert.True(t, cache.Has(key), "%s should be cached", key)
+	}
+
+	require.NoError(t, repo.ToggleCompleted(ctx, domain.TestTodo.Id, ownerId, false, 0))
+
+	for _, key := range []string{defaultKey, priorityKey, titleKey} {
+		assert.False(t, cache.Has(key), "%s should be invalidated", key)
+	}
+}
+
+func TestCachedTodoRepositoryInvalidatesCollaborators(t *testing.T) {
+	ctx := context.Background()
+	ownerId := domain.TestUser.Id
+	collaboratorId := domain.SecondTestUser.Id
+
+	cache := mock.NewMockMemoryCache()
+	repo := todo.NewCachedTodoRepository(&MockRepository{Collaborators: []uuid.UUID{collaboratorId}}, cache, mock.NewMockLogger(), time.Minute)
+
+	for _, userId := range []uuid.UUID{ownerId, collaboratorId} {
+		_, err := repo.GetTodosByUserID(ctx, userId, todo.GetTodosQuery{Sort: todo.DefaultTodoSort, Order: todo.SortAsc, Limit: todo.DefaultTodoPageSize})
+		require.NoError(t, err)
+		require.True(t, cache.Has(domain.NewTodoCacheKey(userId)))
+	}
+
+	require.NoError(t, repo.ToggleCompleted(ctx, domain.TestTodo.Id, ownerId, false, 0))
+
+	assert.False(t, cache.Has(domain.NewTodoCacheKey(ownerId)))
+	assert.False(t, cache.Has(domain.NewTodoCacheKey(collaboratorId)), "the collaborator sees the same shared todos")
+	assert.Equal(t, 1, cache.DeleteCalls())
+}
@@ -0,0 +1,209 @@
+package unittest_todo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportRepository exports the given todos to every user.
+type exportRepository struct {
+	MockRepository
+	todos []todo.ExportedTodo
+}
+
+func (r *exportRepository) ExportTodos(ctx context.Context, userId uuid.UUID, fn func(todo.ExportedTodo) error) error {
+	for _, exported := range r.todos {
+		if err := fn(exported); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportICal(t *testing.T, repo todo.TodoRepository) string {
+	t.Helper()
+
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	file, code, err := todo.NewExportTodosHandler(repo, mock.NewMockClock(TestExportedAt), mock.NewMockLogger()).Handle(ctx, &todo.ExportTodosRequest{Format: "ics"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "todos.ics", file.Name)
+	assert.Equal(t, "text/calendar; charset=utf-8", file.ContentType)
+
+	body, err := io.ReadAll(file.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestICalExport(t *testing.T) {
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//advanced-todo-api//Todos//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VTODO",
+		"UID:" + domain.RealTodoId,
+		"DTSTAMP:20300502T080000Z",
+		"CREATED:20300401T093000Z",
+		"SUMMARY:Test Todo",
+		"STATUS:NEEDS-ACTION",
+		"CATEGORIES:Work",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), exportICal(t, &MockRepository{}))
+}
+
+func TestICalExportFields(t *testing.T) {
+	dueAt := time.Date(2030, 6, 1, 10, 0, 0, 0, time.FixedZone("", 2*60*60))
+	completedAt := time.Date(2030, 5, 30, 18, 45, 0, 0, time.UTC)
+	parentId := uuid.MustParse(domain.FakeTodoId)
+
+	body := exportICal(t, &exportRepository{todos: []todo.ExportedTodo{{
+		Id:          domain.TestTodo.Id,
+		Title:       "Buy milk, eggs; bread",
+		Description: "Oat milk\nfrom the shop at C:\\corner",
+		Completed:   true,
+		Priority:    domain.PriorityHigh,
+		DueAt:       &dueAt,
+		CreatedAt:   TestCreatedAt,
+		CompletedAt: &completedAt,
+		ParentId:    &parentId,
+		Tags:        []string{"Home; errands", "Shop"},
+	}}})
+
+	for _, line := range []string{
+		`SUMMARY:Buy milk\, eggs\; bread`,
+		`DESCRIPTION:Oat milk\nfrom the shop at C:\\corner`,
+		"PRIORITY:3",
+		"DUE:20300601T080000Z",
+		"STATUS:COMPLETED",
+		"PERCENT-COMPLETE:100",
+		"COMPLETED:20300530T184500Z",
+		`CATEGORIES:Home\; errands,Shop`,
+		"RELATED-TO:" + domain.FakeTodoId,
+	} {
+		assert.Contains(t, body, "\r\n"+line+"\r\n")
+	}
+}
+
+func TestICalExportFoldsLongLines(t *testing.T) {
+	description := strings.Repeat("Çok uzun bir açıklama, ", 40)
+
+	body := exportICal(t, &exportRepository{todos: []todo.ExportedTodo{{
+		Id:          domain.TestTodo.Id,
+		Title:       "Long description",
+		Description: description,
+		CreatedAt:   TestCreatedAt,
+	}}})
+
+	lines := strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75, "content lines are folded at 75 octets")
+		assert.True(t, utf8.ValidString(line), "lines are not split inside a character")
+	}
+
+	unfolded := strings.ReplaceAll(body, "\r\n ", "")
+	assert.Contains(t, unfolded, "\r\nDESCRIPTION:"+strings.ReplaceAll(description, ",", `\,`)+"\r\n")
+}
+
+func TestCalendarFeed(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	repo := &MockRepository{}
+	ts := mock.NewTestJWETokenService()
+
+	getURL := todo.NewGetFeedURLHandler(repo, ts)
+	regenerate := todo.NewRegenerateFeedURLHandler(repo, ts)
+	revoke := todo.NewRevokeFeedURLHandler(repo)
+	feed := todo.NewGetCalendarFeedHandler(repo, repo, ts, mock.NewMockClock(TestExportedAt), mock.NewMockLogger())
+
+	tokenOf := func(url string) string {
+		t.Helper()
+		require.True(t, strings.HasPrefix(url, "/feeds/") && strings.HasSuffix(url, ".ics"), url)
+		return strings.TrimSuffix(strings.TrimPrefix(url, "/feeds/"), ".ics")
+	}
+	fetch := func(token string) (string, int, error) {
+		// calendar apps are not authenticated
+		file, code, err := feed.Handle(context.Background(), &todo.GetCalendarFeedRequest{Token: token})
+		if err != nil {
+			return "", code, err
+		}
+		body, readErr := io.ReadAll(file.Body)
+		require.NoError(t, readErr)
+		return string(body), code, nil
+	}
+
+	t.Run("no feed before it is generated", func(t *testing.T) {
+		_, code, err := getURL.Handle(ctx, &todo.GetFeedURLRequest{})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+
+	var first string
+	t.Run("generated feed serves the todos of the user", func(t *testing.T) {
+		resp, code, err := regenerate.Handle(ctx, &todo.RegenerateFeedURLRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+		first = tokenOf(resp.URL)
+
+		body, code, err := fetch(first)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "\r\nUID:"+domain.RealTodoId+"\r\n")
+	})
+
+	t.Run("every link of the feed works", func(t *testing.T) {
+		resp, code, err := getURL.Handle(ctx, &todo.GetFeedURLRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+
+		_, code, err = fetch(tokenOf(resp.URL))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("regenerating revokes the old links", func(t *testing.T) {
+		resp, _, err := regenerate.Handle(ctx, &todo.RegenerateFeedURLRequest{})
+		require.NoError(t, err)
+
+		_, code, err := fetch(first)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+
+		_, code, err = fetch(tokenOf(resp.URL))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		first = tokenOf(resp.URL)
+	})
+
+	t.Run("revoking turns the feed off", func(t *testing.T) {
+		_, code, err := revoke.Handle(ctx, &todo.RevokeFeedURLRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		_, code, err = fetch(first)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+
+		_, code, _ = getURL.Handle(ctx, &todo.GetFeedURLRequest{})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, code, err := fetch("not-a-token")
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	})
+}
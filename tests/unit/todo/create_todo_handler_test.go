@@ -8,14 +8,13 @@ import (
 
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
-	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateTodoHandler(t *testing.T) {
 	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
 
-	createTodoHandler := todo.NewCreateTodoHandler(&MockRepository{}, mock.NewMockCache(), mock.NewMockLogger())
+	createTodoHandler := todo.NewCreateTodoHandler(&MockRepository{})
 
 	validCreateTodoRequest := &todo.CreateTodoRequest{
 		Title: "Test Todo",
@@ -62,6 +61,21 @@ func TestCreateTodoHandler(t *testing.T) {
 			req: tooLongCreateTodoRequest,
 		}, http.StatusBadRequest, domain.ErrTitleTooLong,
 		},
+		{"subtask", args{
+			ctx: ctx,
+			req: &todo.CreateTodoRequest{Title: "Test Subtask", ParentId: domain.TestTodo.Id},
+		}, http.StatusCreated, nil,
+		},
+		{"subtask of an unknown todo", args{
+			ctx: ctx,
+			req: &todo.CreateTodoRequest{Title: "Test Subtask", ParentId: domain.FakeTodoUuid},
+		}, http.StatusNotFound, domain.ErrParentTodoNotFound,
+		},
+		{"subtask with its own project", args{
+			ctx: ctx,
+			req: &todo.CreateTodoRequest{Title: "Test Subtask", ParentId: domain.TestTodo.Id, ProjectId: domain.TestProject.Id},
+		}, http.StatusBadRequest, domain.ErrSubtaskWithProject,
+		},
 	}
 
 	for _, tt := range tests {
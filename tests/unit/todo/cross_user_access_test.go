@@ -0,0 +1,75 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossUserTodoAccess(t *testing.T) {
+	ownerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	repo := &MockRepository{}
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, mock.NewMockMarkdownRenderer())
+	updateTodoHandler := todo.NewUpdateTodoHandler(repo)
+	deleteTodoHandler := todo.NewDeleteTodoHandler(repo)
+	toggleCompletedTodoHandler := todo.NewToggleCompletedTodoHandler(repo)
+	getTodoByIdForAdminHandler := todo.NewGetTodoByIdForAdminHandler(repo)
+
+	get := func(ctx context.Context) (int, error) {
+		_, code, err := getTodoByIdHandler.Handle(ctx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id})
+		return code, err
+	}
+	update := func(ctx context.Context) (int, error) {
+		_, code, err := updateTodoHandler.Handle(ctx, &todo.UpdateTodoRequest{Id: domain.TestTodo.Id, Title: "Updated Test Todo"})
+		return code, err
+	}
+	remove := func(ctx context.Context) (int, error) {
+		_, code, err := deleteTodoHandler.Handle(ctx, &todo.DeleteTodoRequest{Id: domain.TestTodo.Id})
+		return code, err
+	}
+	toggle := func(ctx context.Context) (int, error) {
+		_, code, err := toggleCompletedTodoHandler.Handle(ctx, &todo.ToggleCompletedTodoRequest{Id: domain.TestTodo.Id})
+		return code, err
+	}
+	getForAdmin := func(ctx context.Context) (int, error) {
+		_, code, err := getTodoByIdForAdminHandler.Handle(ctx, &todo.GetTodoByIdForAdminRequest{Id: domain.TestTodo.Id})
+		return code, err
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(ctx context.Context) (int, error)
+		code    int
+		wantErr error
+	}{
+		{"owner gets todo", ownerCtx, get, http.StatusOK, nil},
+		{"other user gets todo", otherCtx, get, http.StatusNotFound, domain.ErrTodoNotFound},
+		{"owner updates todo", ownerCtx, update, http.StatusNoContent, nil},
+		{"other user updates todo", otherCtx, update, http.StatusNotFound, domain.ErrTodoNotFound},
+		{"owner deletes todo", ownerCtx, remove, http.StatusNoContent, nil},
+		{"other user deletes todo", otherCtx, remove, http.StatusNotFound, domain.ErrTodoNotFound},
+		{"owner toggles todo", ownerCtx, toggle, http.StatusNoContent, nil},
+		{"other user toggles todo", otherCtx, toggle, http.StatusNotFound, domain.ErrTodoNotFound},
+		{"admin path ignores owner", otherCtx, getForAdmin, http.StatusOK, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.call(tt.ctx)
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTodosHandlerQuery(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	getTodosHandler := todo.NewGetTodosHandler(&MockRepository{}, mock.NewMockMarkdownRenderer())
+
+	last := todo.Todo{Id: domain.TestTodo.Id, Title: "Last", CreatedAt: time.Now(), Position: "a0"}
+	positionCursor := todo.GetTodosQuery{Sort: todo.SortByPosition, Order: todo.SortAsc}.NextCursor(last)
+	dueAtCursor := todo.GetTodosQuery{Sort: todo.SortByDueAt, Order: todo.SortAsc}.NextCursor(last)
+	titleCursor := todo.GetTodosQuery{Sort: todo.SortByTitle, Order: todo.SortAsc}.NextCursor(last)
+
+	tests := []struct {
+		name    string
+		req     *todo.GetTodosRequest
+		code    int
+		wantErr error
+	}{
+		{"no filter", &todo.GetTodosRequest{}, http.StatusOK, nil},
+		{"utc timestamps", &todo.GetTodosRequest{DueAfter: "2030-01-01T00:00:00Z", DueBefore: "2030-02-01T00:00:00Z"}, http.StatusOK, nil},
+		{"offset timestamp", &todo.GetTodosRequest{DueBefore: "2030-01-01T09:00:00-05:00"}, http.StatusOK, nil},
+		{"unescaped plus in offset", &todo.GetTodosRequest{DueBefore: "2030-01-01T09:00:00 03:00"}, http.StatusOK, nil},
+		{"date without time", &todo.GetTodosRequest{DueAfter: "2030-01-01"}, http.StatusBadRequest, domain.ErrInvalidDueFilter},
+		{"missing offset", &todo.GetTodosRequest{DueBefore: "2030-01-01T09:00:00"}, http.StatusBadRequest, domain.ErrInvalidDueFilter},
+		{"sort by priority", &todo.GetTodosRequest{Sort: "priority"}, http.StatusOK, nil},
+		{"manual order descending", &todo.GetTodosRequest{Sort: "position", Order: "desc"}, http.StatusOK, nil},
+		{"sort by title descending", &todo.GetTodosRequest{Sort: "title", Order: "desc"}, http.StatusOK, nil},
+		{"order without sort", &todo.GetTodosRequest{Order: "desc"}, http.StatusOK, nil},
+		{"unknown sort", &todo.GetTodosRequest{Sort: "id"}, http.StatusBadRequest, domain.ErrInvalidSort},
+		{"sql in sort", &todo.GetTodosRequest{Sort: "title; DROP TABLE todos"}, http.StatusBadRequest, domain.ErrInvalidSort},
+		{"tags with and", &todo.GetTodosRequest{Tags: []string{"work", "Home"}}, http.StatusOK, nil},
+		{"comma separated tags with or", &todo.GetTodosRequest{Tags: []string{"work,home"}, TagMode: "or"}, http.StatusOK, nil},
+		{"unknown tag mode", &todo.GetTodosRequest{Tags: []string{"work"}, TagMode: "xor"}, http.StatusBadRequest, domain.ErrInvalidTagMode},
+		{"unknown order", &todo.GetTodosRequest{Sort: "title", Order: "random"}, http.StatusBadRequest, domain.ErrInvalidSortOrder},
+		{"created range", &todo.GetTodosRequest{CreatedAfter: "2030-01-01T00:00:00Z", CreatedBefore: "2030-02-01T00:00:00Z"}, http.StatusOK, nil},
+		{"invalid created filter", &todo.GetTodosRequest{CreatedAfter: "yesterday"}, http.StatusBadRequest, domain.ErrInvalidCreatedFilter},
+		{"completed only", &todo.GetTodosRequest{Completed: "true"}, http.StatusOK, nil},
+		{"open only", &todo.GetTodosRequest{Completed: "false"}, http.StatusOK, nil},
+		{"invalid completed filter", &todo.GetTodosRequest{Completed: "maybe"}, http.StatusBadRequest, domain.ErrInvalidCompletedFilter},
+		{"text query", &todo.GetTodosRequest{Q: "groceries"}, http.StatusOK, nil},
+		{"too long text query", &todo.GetTodosRequest{Q: strings.Repeat("a", todo.MaxSearchLength+1)}, http.StatusBadRequest, domain.ErrSearchQueryTooLong},
+		{"limit", &todo.GetTodosRequest{Limit: todo.MaxTodoPageSize}, http.StatusOK, nil},
+		{"too large limit", &todo.GetTodosRequest{Limit: todo.MaxTodoPageSize + 1}, http.StatusBadRequest, domain.ErrInvalidPageLimit},
+		{"negative limit", &todo.GetTodosRequest{Limit: -1}, http.StatusBadRequest, domain.ErrInvalidPageLimit},
+		{"cursor", &todo.GetTodosRequest{Cursor: positionCursor}, http.StatusOK, nil},
+		{"cursor of a requested sort", &todo.GetTodosRequest{Sort: "due_at", Cursor: dueAtCursor}, http.StatusOK, nil},
+		{"cursor of another sort", &todo.GetTodosRequest{Cursor: titleCursor}, http.StatusBadRequest, domain.ErrInvalidCursor},
+		{"cursor of another order", &todo.GetTodosRequest{Sort: "title", Order: "desc", Cursor: titleCursor}, http.StatusBadRequest, domain.ErrInvalidCursor},
+		{"malformed cursor", &todo.GetTodosRequest{Cursor: "not a cursor"}, http.StatusBadRequest, domain.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code, err := getTodosHandler.Handle(ctx, tt.req)
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
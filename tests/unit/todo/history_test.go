@@ -0,0 +1,58 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTodoHistoryHandler(t *testing.T) {
+	handler := todo.NewGetTodoHistoryHandler(&MockRepository{})
+
+	ownerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	res, code, err := handler.Handle(ownerCtx, &todo.GetTodoHistoryRequest{Id: domain.TestTodo.Id})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	require.Len(t, res.Revisions, 1)
+	assert.Contains(t, res.Revisions[0].Changes, "title")
+
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+	_, code, err = handler.Handle(otherCtx, &todo.GetTodoHistoryRequest{Id: domain.TestTodo.Id})
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+}
+
+func TestRevertTodoHandler(t *testing.T) {
+	ownerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		revisionId uuid.UUID
+		code       int
+		wantErr    error
+	}{
+		{"owner reverts", ownerCtx, TestRevisionId, http.StatusNoContent, nil},
+		{"unknown revision", ownerCtx, uuid.New(), http.StatusNotFound, domain.ErrRevisionNotFound},
+		{"other user reverts", otherCtx, TestRevisionId, http.StatusNotFound, domain.ErrTodoNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code, err := todo.NewRevertTodoHandler(&MockRepository{}).Handle(tt.ctx, &todo.RevertTodoRequest{Id: domain.TestTodo.Id, RevisionId: tt.revisionId})
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
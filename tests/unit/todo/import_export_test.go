@@ -0,0 +1,337 @@
+package unittest_todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTodosHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		format          string
+		wantName        string
+		wantContentType string
+		wantBody        string
+	}{
+		{"json by default", ctx, "", "todos.json", "application/json",
+			`[{"id":"` + domain.RealTodoId + `","title":"Test Todo","description":"","completed":false,"priority":"none","due_at":null,"created_at":"2030-04-01T09:30:00Z","completed_at":null,"project_id":null,"parent_id":null,"tags":["Work"]}]` + "\n"},
+		{"csv", ctx, "csv", "todos.csv", "text/csv; charset=utf-8",
+			"id,title,description,completed,priority,due_at,created_at,completed_at,project_id,parent_id,tags\n" +
+				domain.RealTodoId + ",Test Todo,,false,none,,2030-04-01T09:30:00Z,,,,Work\n"},
+		{"ndjson", ctx, "ndjson", "todos.ndjson", "application/x-ndjson",
+			`{"id":"` + domain.RealTodoId + `","title":"Test Todo","description":"","completed":false,"priority":"none","due_at":null,"created_at":"2030-04-01T09:30:00Z","completed_at":null,"project_id":null,"parent_id":null,"tags":["Work"]}` + "\n"},
+		{"empty json", otherCtx, "json", "todos.json", "application/json", "[]\n"},
+		{"empty csv", otherCtx, "csv", "todos.csv", "text/csv; charset=utf-8",
+			"id,title,description,completed,priority,due_at,created_at,completed_at,project_id,parent_id,tags\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := todo.NewExportTodosHandler(&MockRepository{}, mock.NewMockClock(TestExportedAt), mock.NewMockLogger())
+
+			file, code, err := handler.Handle(tt.ctx, &todo.ExportTodosRequest{Format: tt.format})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, tt.wantName, file.Name)
+			assert.Equal(t, tt.wantContentType, file.ContentType)
+			assert.Equal(t, int64(-1), file.Size)
+			assert.Empty(t, file.ETag, "only todo.txt files have an ETag")
+
+			body, err := io.ReadAll(file.Body)
+			require.NoError(t, err)
+			require.NoError(t, file.Body.Close())
+			assert.Equal(t, tt.wantBody, string(body))
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		handler := todo.NewExportTodosHandler(&MockRepository{}, mock.NewMockClock(TestExportedAt), mock.NewMockLogger())
+
+		_, code, err := handler.Handle(ctx, &todo.ExportTodosRequest{Format: "xml"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrInvalidTodoFormat)
+	})
+}
+
+func TestImportTodosHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	tests := []struct {
+		name         string
+		fileName     string
+		content      string
+		format       string
+		dryRun       bool
+		code         int
+		wantErr      error
+		wantTitles   []string
+		wantRowErrs  []todo.ImportRowError
+		wantImported bool
+	}{
+		{"csv from a spreadsheet", "todos.csv",
+			"\ufeffTitle,Priority,Due_At,Completed,Notes\n Buy milk ,HIGH,2030-06-01,TRUE,ignored\nCall mom\n",
+			"", false, http.StatusCreated, nil, []string{"Buy milk", "Call mom"}, nil, true},
+		{"json", "todos.json",
+			`[{"title":"Buy milk","priority":"low","due_at":"2030-06-01T10:00:00+02:00"},{"title":"Call mom","completed":true,"notes":"ignored"}]`,
+			"", false, http.StatusCreated, nil, []string{"Buy milk", "Call mom"}, nil, true},
+		{"ndjson with blank lines", "todos.jsonl",
+			"{\"title\":\"Buy milk\"}\n\n{\"title\":\"Call mom\"}\n",
+			"", false, http.StatusCreated, nil, []string{"Buy milk", "Call mom"}, nil, true},
+		{"format overrides the extension", "export.txt",
+			"title\nBuy milk\n",
+			"csv", false, http.StatusCreated, nil, []string{"Buy milk"}, nil, true},
+		{"dry run", "todos.csv",
+			"title\nBuy milk\n",
+			"", true, http.StatusOK, nil, nil, nil, false},
+		{"invalid rows", "todos.csv",
+			"title,priority,completed,due_at\nBuy milk,,,\nab,,,\nCall mom,someday,,\nPay rent,,maybe,\nWater plants,,,tomorrow\n",
+			"", false, http.StatusBadRequest, nil, nil, []todo.ImportRowError{
+				{Row: 2, Error: domain.ErrTitleTooShort.Error()},
+				{Row: 3, Error: domain.ErrInvalidPriority.Error()},
+				{Row: 4, Error: domain.ErrInvalidCompleted.Error()},
+				{Row: 5, Error: domain.ErrInvalidDueAtFormat.Error()},
+			}, false},
+		{"invalid ids and tags", "todos.csv",
+			"id,title,project_id,parent_id,tags\nfirst,Buy milk,,,\n,Call mom,inbox,,\n,Pay rent,,first,\n,Water plants,,,\"home, ,garden\"\n",
+			"", false, http.StatusBadRequest, nil, nil, []todo.ImportRowError{
+				{Row: 1, Error: domain.ErrInvalidImportedId.Error()},
+				{Row: 2, Error: domain.ErrInvalidImportedId.Error()},
+				{Row: 3, Error: domain.ErrInvalidImportedId.Error()},
+				{Row: 4, Error: domain.ErrEmptyTagName.Error()},
+			}, false},
+		{"invalid rows in a dry run", "todos.ndjson",
+			"{\"title\":\"Buy milk\"}\n{\"title\":\"Call mom\",\"completed\":\"yes\"}\nnot json\n",
+			"", true, http.StatusBadRequest, nil, nil, []todo.ImportRowError{
+				{Row: 2, Error: domain.ErrInvalidCompleted.Error()},
+				{Row: 3, Error: "invalid character 'o' in literal null (expecting 'u')"},
+			}, false},
+		{"malformed json stops the import", "todos.json",
+			`[{"title":"Buy milk"},{"title":}]`,
+			"", false, http.StatusBadRequest, nil, nil, []todo.ImportRowError{
+				{Row: 2, Error: "invalid character '}' after array element"},
+			}, false},
+		{"json that is not an array", "todos.json", `{"title":"Buy milk"}`,
+			"", false, http.StatusBadRequest, domain.ErrInvalidJSONImport, nil, nil, false},
+		{"csv without a title column", "todos.csv", "name\nBuy milk\n",
+			"", false, http.StatusBadRequest, domain.ErrMissingTitleColumn, nil, nil, false},
+		{"empty csv", "todos.csv", "",
+			"", false, http.StatusBadRequest, domain.ErrEmptyImport, nil, nil, false},
+		{"empty json", "todos.json", "[]",
+			"", false, http.StatusBadRequest, domain.ErrEmptyImport, nil, nil, false},
+		{"too many todos", "todos.csv", "title\n" + strings.Repeat("Buy milk\n", todo.MaxImportedTodos+1),
+			"", false, http.StatusBadRequest, domain.ErrTooManyImportedTodos, nil, nil, false},
+		{"unknown extension", "todos.xlsx", "title\nBuy milk\n",
+			"", false, http.StatusBadRequest, domain.ErrInvalidTodoFormat, nil, nil, false},
+		{"icalendar", "todos.ics", "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n",
+			"", false, http.StatusBadRequest, domain.ErrInvalidImportFormat, nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			handler := todo.NewImportTodosHandler(repo)
+
+			resp, code, err := handler.Handle(ctx, &todo.ImportTodosRequest{
+				Format: tt.format,
+				DryRun: tt.dryRun,
+				File:   newFileHeader(t, tt.fileName, tt.content),
+			})
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, repo.Imported)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.dryRun, resp.DryRun)
+
+			if tt.wantRowErrs != nil {
+				assert.Equal(t, tt.wantRowErrs, resp.Errors)
+				assert.Zero(t, resp.Imported)
+			} else {
+				assert.Empty(t, resp.Errors)
+			}
+
+			if !tt.wantImported {
+				assert.Nil(t, repo.Imported)
+				return
+			}
+			assert.Equal(t, len(tt.wantTitles), resp.Imported)
+			var titles []string
+			for _, imported := range repo.Imported {
+				assert.Equal(t, domain.TestUser.Id, imported.UserId)
+				titles = append(titles, imported.Title)
+			}
+			assert.Equal(t, tt.wantTitles, titles)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, code, err := todo.NewImportTodosHandler(&MockRepository{}).Handle(ctx, &todo.ImportTodosRequest{})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrMissingImportFile)
+	})
+}
+
+func TestImportedTodoFields(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	repo := &MockRepository{}
+
+	_, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+		File: newFileHeader(t, "todos.csv", "title,description,priority,due_at,completed\n"+
+			"Buy milk,\"Oat, not soy\",HIGH,2030-06-01,true\n"+
+			"Call mom,,,2030-06-01T10:00:00+02:00,\n"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, code)
+	require.Len(t, repo.Imported, 2)
+
+	milk, mom := repo.Imported[0], repo.Imported[1]
+	assert.Equal(t, "Oat, not soy", milk.Description)
+	assert.Equal(t, domain.PriorityHigh, milk.Priority)
+	assert.Equal(t, time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), milk.DueAt)
+	assert.True(t, milk.Completed)
+	assert.False(t, milk.CompletedAt.IsZero())
+
+	assert.Equal(t, domain.PriorityNone, mom.Priority)
+	assert.True(t, time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC).Equal(mom.DueAt))
+	assert.Equal(t, "+02:00", domain.DueTimezone(mom.DueAt), "the due date keeps the offset of the client")
+	assert.False(t, mom.Completed)
+	assert.True(t, mom.CompletedAt.IsZero())
+}
+
+func TestImportSubtasksAndTags(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	parentId, subtaskId, projectId := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("subtasks point at the new id of their parent", func(t *testing.T) {
+		repo := &MockRepository{}
+		_, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todos.csv", "id,title,project_id,parent_id,tags\n"+
+				subtaskId.String()+",Buy milk,,"+parentId.String()+",\"Errands, errands,Home\"\n"+
+				parentId.String()+",Groceries,"+projectId.String()+",,\n"+
+				",Call mom,,"+domain.RealTodoId+",\n"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, code)
+		require.Len(t, repo.Imported, 3)
+
+		milk, groceries, mom := repo.Imported[0], repo.Imported[1], repo.Imported[2]
+		assert.NotEqual(t, parentId, groceries.Id, "imported todos get a new id")
+		assert.Equal(t, groceries.Id, milk.ParentId)
+		assert.Equal(t, projectId, groceries.ProjectId)
+		assert.Equal(t, domain.TestTodo.Id, mom.ParentId, "a parent outside the file is an existing todo")
+		assert.Equal(t, map[uuid.UUID][]string{milk.Id: {"Errands", "Home"}}, repo.ImportedTags)
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		rowErrs []todo.ImportRowError
+	}{
+		{"missing parent", "title,parent_id\nBuy milk," + uuid.NewString() + "\n",
+			[]todo.ImportRowError{{Row: 1, Error: domain.ErrParentTodoNotFound.Error()}}},
+		{"duplicate id", "id,title\n" + parentId.String() + ",Buy milk\n" + parentId.String() + ",Call mom\n",
+			[]todo.ImportRowError{{Row: 2, Error: domain.ErrDuplicateImportedId.Error()}}},
+		{"a parent that is its own subtask", "id,title,parent_id\n" +
+			parentId.String() + ",Buy milk," + subtaskId.String() + "\n" +
+			subtaskId.String() + ",Call mom," + parentId.String() + "\n",
+			[]todo.ImportRowError{
+				{Row: 1, Error: domain.ErrSubtaskTooDeep.Error()},
+				{Row: 2, Error: domain.ErrSubtaskTooDeep.Error()},
+			}},
+		{"nested too deep", "id,title,parent_id\n" +
+			parentId.String() + ",Level one," + domain.RealTodoId + "\n" +
+			subtaskId.String() + ",Level two," + parentId.String() + "\n" +
+			projectId.String() + ",Level three," + subtaskId.String() + "\n" +
+			",Level four," + projectId.String() + "\n",
+			[]todo.ImportRowError{{Row: 4, Error: domain.ErrSubtaskTooDeep.Error()}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			resp, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+				DryRun: true,
+				File:   newFileHeader(t, "todos.csv", tt.content),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Equal(t, tt.rowErrs, resp.Errors)
+			assert.Nil(t, repo.Imported)
+		})
+	}
+}
+
+// An export is imported as is, in every format.
+func TestExportRoundTrip(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	for _, format := range []todo.TodoFormat{todo.TodoFormatJSON, todo.TodoFormatCSV, todo.TodoFormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			repo := &MockRepository{}
+
+			file, _, err := todo.NewExportTodosHandler(repo, mock.NewMockClock(TestExportedAt), mock.NewMockLogger()).Handle(ctx, &todo.ExportTodosRequest{Format: string(format)})
+			require.NoError(t, err)
+			exported, err := io.ReadAll(file.Body)
+			require.NoError(t, err)
+
+			resp, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+				File: newFileHeader(t, file.Name, string(exported)),
+			})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusCreated, code, "%+v", resp)
+			require.Len(t, repo.Imported, 1)
+			assert.Equal(t, domain.TestTodo.Title, repo.Imported[0].Title)
+			assert.NotEqual(t, domain.TestTodo.Id, repo.Imported[0].Id, "imported todos get a new id")
+		})
+	}
+}
+
+func TestExportedTodoJSON(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	file, _, err := todo.NewExportTodosHandler(&MockRepository{}, mock.NewMockClock(TestExportedAt), mock.NewMockLogger()).Handle(ctx, &todo.ExportTodosRequest{})
+	require.NoError(t, err)
+
+	var exported []todo.ExportedTodo
+	require.NoError(t, json.NewDecoder(file.Body).Decode(&exported))
+	require.Len(t, exported, 1)
+	assert.Equal(t, domain.TestTodo.Id, exported[0].Id)
+	assert.Equal(t, []string{domain.TestTag.Name}, exported[0].Tags)
+	assert.True(t, exported[0].CreatedAt.Equal(TestCreatedAt))
+}
+
+func newFileHeader(t *testing.T, name, content string) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() })
+	return form.File["file"][0]
+}
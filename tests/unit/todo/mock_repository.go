@@ -2,39 +2,341 @@ package unittest_todo
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
 	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
 )
 
+// MockRepository only knows domain.TestTodo, which is owned by domain.TestUser.
+// The trash methods treat it as trashed at TrashedAt, with a trashed parent when
+// ParentTrashed is set. ViewerId can read the todo, as if it was in a project shared
+// with that user as a viewer, but not change it. Collaborators share a project with
+// every user. Imported and ImportedTags keep the todos and the tags of the last import,
+// Updated and Patched the todo of the last update and patch and FeedIds the calendar feed of each user.
 type MockRepository struct {
+	ParentTrashed bool
+	ViewerId      uuid.UUID
+	Collaborators []uuid.UUID
+	Imported      []*domain.Todo
+	ImportedTags  map[uuid.UUID][]string
+	Updated       *domain.Todo
+	Patched       *domain.Todo
+	FeedIds       map[uuid.UUID]uuid.UUID
 }
 
+var TrashedAt = time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// TestCreatedAt is when domain.TestTodo was created, as far as ExportTodos knows.
+var TestCreatedAt = time.Date(2030, 4, 1, 9, 30, 0, 0, time.UTC)
+
+// TestExportedAt is the time the mock clock of the export tests is set to.
+var TestExportedAt = time.Date(2030, 5, 2, 8, 0, 0, 0, time.UTC)
+
+// TestVersion is the version domain.TestTodo is at.
+const TestVersion = 3
+
+// TestRevisionId is the only revision of domain.TestTodo, it renamed the todo.
+var TestRevisionId = uuid.MustParse("6f1c2a4e-8b3d-4c5a-9e7f-1a2b3c4d5e6f")
+
+// TestUpperTodoId and TestLowerTodoId are the anchors ReorderTodo knows, they are next
+// to each other in the manual order, at TestUpperPosition and TestLowerPosition. A
+// single anchor is treated as the first or the last todo.
+var (
+	TestUpperTodoId   = uuid.MustParse("0b8e2f4a-3c1d-4e5f-8a9b-0c1d2e3f4a5b")
+	TestLowerTodoId   = uuid.MustParse("1c9f3a5b-4d2e-4f6a-9b0c-1d2e3f4a5b6c")
+	TestUpperPosition = "a1"
+	TestLowerPosition = "a2"
+)
+
 func (m *MockRepository) CreateTodo(ctx context.Context, todo *domain.Todo) error {
 
 	return nil
 }
 
-func (m *MockRepository) UpdateTodo(ctx context.Context, id uuid.UUID, title string) error {
-	if id == uuid.Nil || title == "" {
+func (m *MockRepository) UpdateTodo(ctx context.Context, todo *domain.Todo) error {
+	if todo.Id == uuid.Nil || todo.Title == "" {
 		return domain.ErrInvalidRequest
 	}
+	if err := m.writeTestTodo(todo.Id, todo.UserId, todo.Version); err != nil {
+		return err
+	}
+	m.Updated = todo
+	return nil
+}
+
+func (m *MockRepository) PatchTodo(ctx context.Context, todo *domain.Todo) error {
+	if err := m.writeTestTodo(todo.Id, todo.UserId, todo.Version); err != nil {
+		return err
+	}
+	m.Patched = todo
+	return nil
+}
+
+func (m *MockRepository) GetById(ctx context.Context, id, userId uuid.UUID) (*todo.GetTodoByIdResponse, error) {
+	if !isOwnedTestTodo(id, userId) && !m.isViewer(id, userId) {
+		return nil, domain.ErrTodoNotFound
+	}
+	return &todo.GetTodoByIdResponse{
+		Id:        domain.TestTodo.Id,
+		Title:     domain.TestTodo.Title,
+		Completed: domain.TestTodo.Completed,
+		Version:   TestVersion,
+	}, nil
+}
+
+// domain.TestTodo is a top-level todo.
+func (m *MockRepository) GetTodoDepth(ctx context.Context, id, userId uuid.UUID) (int, error) {
+	if !isOwnedTestTodo(id, userId) {
+		return 0, domain.ErrTodoNotFound
+	}
+	return 0, nil
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id, userId uuid.UUID, version int) error {
+	return m.writeTestTodo(id, userId, version)
+}
+
+func (m *MockRepository) GetTrash(ctx context.Context, userId uuid.UUID) ([]todo.TrashedTodo, error) {
+	trash := []todo.TrashedTodo{}
+	if userId == domain.TestTodo.UserId {
+		trash = append(trash, todo.TrashedTodo{
+			Id:        domain.TestTodo.Id,
+			Title:     domain.TestTodo.Title,
+			DeletedAt: TrashedAt,
+		})
+	}
+	return trash, nil
+}
+
+func (m *MockRepository) RestoreTodo(ctx context.Context, id, userId uuid.UUID) error {
+	if !isOwnedTestTodo(id, userId) {
+		return domain.ErrTodoNotFound
+	}
+	if m.ParentTrashed {
+		return domain.ErrParentTodoInTrash
+	}
+	return nil
+}
+
+func (m *MockRepository) DeleteTrashedTodo(ctx context.Context, id, userId uuid.UUID) error {
+	if !isOwnedTestTodo(id, userId) {
+		return domain.ErrTodoNotFound
+	}
+	return nil
+}
+
+func (m *MockRepository) EmptyTrash(ctx context.Context, userId uuid.UUID) error {
+	return nil
+}
+
+func (m *MockRepository) GetTodoHistory(ctx context.Context, id, userId uuid.UUID) (*todo.GetTodoHistoryResponse, error) {
+	if !isOwnedTestTodo(id, userId) {
+		return nil, domain.ErrTodoNotFound
+	}
+	return &todo.GetTodoHistoryResponse{Revisions: []todo.TodoRevision{{
+		Id:      TestRevisionId,
+		ActorId: &userId,
+		Changes: map[string]domain.FieldChange{
+			"title": {Before: []byte(`"Old title"`), After: []byte(`"` + domain.TestTodo.Title + `"`)},
+		},
+	}}}, nil
+}
+
+func (m *MockRepository) RevertTodo(ctx context.Context, id, revisionId, userId uuid.UUID) error {
+	if !isOwnedTestTodo(id, userId) {
+		return domain.ErrTodoNotFound
+	}
+	if revisionId != TestRevisionId {
+		return domain.ErrRevisionNotFound
+	}
+	return nil
+}
+
+func (m *MockRepository) GetTodosByUserID(ctx context.Context, userID uuid.UUID, query todo.GetTodosQuery) (*todo.GetTodosResponse, error) {
+	todos := todo.GetTodosResponse{Todos: []todo.Todo{}}
+	if userID == domain.TestTodo.UserId {
+		todos.Total = 1
+		todos.Todos = append(todos.Todos, todo.Todo{
+			Id:        domain.TestTodo.Id,
+			Title:     domain.TestTodo.Title,
+			Completed: domain.TestTodo.Completed,
+		})
+	}
+	return &todos, nil
+}
+
+// SearchTodos matches domain.TestTodo by the first term, the highlights wrap the whole title.
+func (m *MockRepository) SearchTodos(ctx context.Context, userId uuid.UUID, query todo.SearchTodosQuery) (*todo.SearchTodosResponse, error) {
+	res := todo.SearchTodosResponse{Results: []todo.SearchResult{}}
+	if userId == domain.TestTodo.UserId && strings.HasPrefix(strings.ToLower(domain.TestTodo.Title), query.Terms[0]) {
+		res.Results = append(res.Results, todo.SearchResult{
+			Id:             domain.TestTodo.Id,
+			Title:          domain.TestTodo.Title,
+			TitleHighlight: todo.HighlightStart + domain.TestTodo.Title + todo.HighlightStop,
+			Snippet:        "<b>" + todo.HighlightStart + "bold" + todo.HighlightStop + "</b>",
+		})
+	}
+	return &res, nil
+}
+
+func (m *MockRepository) ToggleCompleted(ctx context.Context, id, userId uuid.UUID, completeSubtasks bool, version int) error {
+	return m.writeTestTodo(id, userId, version)
+}
+
+func (m *MockRepository) GetByIdForAdmin(ctx context.Context, id uuid.UUID) (*todo.GetTodoByIdForAdminResponse, error) {
+	if id != domain.TestTodo.Id {
+		return nil, domain.ErrTodoNotFound
+	}
+	return &todo.GetTodoByIdForAdminResponse{
+		Id:     domain.TestTodo.Id,
+		UserId: domain.TestTodo.UserId,
+		Title:  domain.TestTodo.Title,
+	}, nil
+}
+
+func isOwnedTestTodo(id, userId uuid.UUID) bool {
+	return id == domain.TestTodo.Id && userId == domain.TestTodo.UserId
+}
+
+func (m *MockRepository) isViewer(id, userId uuid.UUID) bool {
+	return id == domain.TestTodo.Id && m.ViewerId != uuid.Nil && userId == m.ViewerId
+}
+
+func (m *MockRepository) writeTestTodo(id, userId uuid.UUID, version int) error {
+	if m.isViewer(id, userId) {
+		return domain.ErrTodoReadOnly
+	}
+	if !isOwnedTestTodo(id, userId) {
+		return domain.ErrTodoNotFound
+	}
+	if version != 0 && version != TestVersion {
+		return domain.ErrTodoVersionMismatch
+	}
+	return nil
+}
+
+// Only domain.TestTag can be attached, it belongs to domain.TestUser as well.
+func (m *MockRepository) AttachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error {
+	if !isOwnedTestTodo(todoId, userId) {
+		return domain.ErrTodoNotFound
+	}
+	if tagId != domain.TestTag.Id {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
+
+// Only domain.TestProject, which belongs to domain.TestUser, is known besides the inbox.
+func (m *MockRepository) MoveTodo(ctx context.Context, id, userId, projectId uuid.UUID) error {
+	if !isOwnedTestTodo(id, userId) {
+		return domain.ErrTodoNotFound
+	}
+	if projectId != uuid.Nil && projectId != domain.TestProject.Id {
+		return domain.ErrProjectNotFound
+	}
+	return nil
+}
+
+func (m *MockRepository) ReorderTodo(ctx context.Context, id, userId, after, before uuid.UUID) (string, error) {
+	if !isOwnedTestTodo(id, userId) {
+		return "", domain.ErrTodoNotFound
+	}
+	positions := map[uuid.UUID]string{uuid.Nil: "", TestUpperTodoId: TestUpperPosition, TestLowerTodoId: TestLowerPosition}
+	lower, ok := positions[after]
+	if !ok {
+		return "", domain.ErrAnchorTodoNotFound
+	}
+	upper, ok := positions[before]
+	if !ok {
+		return "", domain.ErrAnchorTodoNotFound
+	}
+	if after != uuid.Nil && before != uuid.Nil && upper <= lower {
+		return "", domain.ErrStaleMoveAnchors
+	}
+	return domain.PositionBetween(lower, upper)
+}
+
+func (m *MockRepository) DetachTag(ctx context.Context, todoId, tagId, userId uuid.UUID) error {
+	if !isOwnedTestTodo(todoId, userId) || tagId != domain.TestTag.Id {
+		return domain.ErrTagNotFound
+	}
 	return nil
 }
 
-func (m *MockRepository) GetById(ctx context.Context, id uuid.UUID) (*todo.GetTodoByIdResponse, error) {
-	return nil, nil
+// ApplyBatch creates every todo and applies the other operations to domain.TestTodo
+// only, moves follow MoveTodo.
+func (m *MockRepository) ApplyBatch(ctx context.Context, userId uuid.UUID, ops []todo.TodoOperation, atomic bool) ([]error, error) {
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		switch {
+		case op.Type == todo.OpCreate:
+		case op.Type == todo.OpMove:
+			errs[i] = m.MoveTodo(ctx, op.Id, userId, op.ProjectId)
+		case !isOwnedTestTodo(op.Id, userId):
+			errs[i] = domain.ErrTodoNotFound
+		}
+		if errs[i] != nil && atomic {
+			break
+		}
+	}
+	return errs, nil
+}
+
+// ExportTodos exports domain.TestTodo, with domain.TestTag, to its owner.
+func (m *MockRepository) ExportTodos(ctx context.Context, userId uuid.UUID, fn func(todo.ExportedTodo) error) error {
+	if userId != domain.TestTodo.UserId {
+		return nil
+	}
+	return fn(exportedTestTodo())
 }
 
-func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockRepository) ExportTodo(ctx context.Context, id, userId uuid.UUID) (*todo.ExportedTodo, error) {
+	if !isOwnedTestTodo(id, userId) {
+		return nil, domain.ErrTodoNotFound
+	}
+	exported := exportedTestTodo()
+	return &exported, nil
+}
+
+func exportedTestTodo() todo.ExportedTodo {
+	return todo.ExportedTodo{
+		Id:        domain.TestTodo.Id,
+		Title:     domain.TestTodo.Title,
+		Completed: domain.TestTodo.Completed,
+		Priority:  domain.PriorityNone,
+		CreatedAt: TestCreatedAt,
+		Tags:      []string{domain.TestTag.Name},
+		Version:   TestVersion,
+	}
+}
+
+func (m *MockRepository) ImportTodos(ctx context.Context, userId uuid.UUID, todos []*domain.Todo, tags map[uuid.UUID][]string) error {
+	m.Imported, m.ImportedTags = todos, tags
 	return nil
 }
 
-func (m *MockRepository) GetTodosByUserID(ctx context.Context, userID uuid.UUID) (*todo.GetTodosResponse, error) {
-	return nil, nil
+func (m *MockRepository) GetProjectNames(ctx context.Context, userId uuid.UUID) ([]todo.ProjectName, error) {
+	if userId != domain.TestUser.Id {
+		return nil, nil
+	}
+	return []todo.ProjectName{{Id: domain.TestProject.Id, Name: domain.TestProject.Name}}, nil
+}
+
+func (m *MockRepository) GetCalendarFeedId(ctx context.Context, userId uuid.UUID) (uuid.UUID, error) {
+	return m.FeedIds[userId], nil
 }
 
-func (m *MockRepository) ToggleCompleted(ctx context.Context, id uuid.UUID) error {
+func (m *MockRepository) SetCalendarFeedId(ctx context.Context, userId, feedId uuid.UUID) error {
+	if m.FeedIds == nil {
+		m.FeedIds = map[uuid.UUID]uuid.UUID{}
+	}
+	m.FeedIds[userId] = feedId
 	return nil
 }
+
+func (m *MockRepository) GetCollaboratorIds(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	return append([]uuid.UUID{userId}, m.Collaborators...), nil
+}
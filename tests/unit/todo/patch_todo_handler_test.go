@@ -0,0 +1,97 @@
+package unittest_todo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchTodoHandler(t *testing.T) {
+	dueAt := time.Date(2030, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		userId  string
+		body    string
+		ifMatch string
+		code    int
+		wantErr error
+		check   func(t *testing.T, patched *domain.Todo)
+	}{
+		{
+			name: "title only", body: `{"title":"Patched title"}`, code: http.StatusNoContent,
+			check: func(t *testing.T, patched *domain.Todo) {
+				assert.Equal(t, "Patched title", patched.Title)
+				assert.Equal(t, domain.TestTodo.Completed, patched.Completed)
+				assert.True(t, patched.DueAt.IsZero())
+			},
+		},
+		{
+			name: "completed", body: `{"completed":true}`, code: http.StatusNoContent,
+			check: func(t *testing.T, patched *domain.Todo) {
+				assert.Equal(t, domain.TestTodo.Title, patched.Title)
+				assert.True(t, patched.Completed)
+			},
+		},
+		{
+			name: "due date and priority", body: `{"due_at":"2030-06-01T10:00:00Z","priority":"urgent"}`, code: http.StatusNoContent,
+			check: func(t *testing.T, patched *domain.Todo) {
+				assert.Equal(t, dueAt, patched.DueAt)
+				assert.Equal(t, domain.PriorityUrgent, patched.Priority)
+			},
+		},
+		{
+			name: "null due date and priority", body: `{"due_at":null,"priority":null}`, code: http.StatusNoContent,
+			check: func(t *testing.T, patched *domain.Todo) {
+				assert.True(t, patched.DueAt.IsZero())
+				assert.Equal(t, domain.PriorityNone, patched.Priority)
+			},
+		},
+		{
+			name: "current version", body: `{"completed":true}`, ifMatch: domain.VersionETag(TestVersion), code: http.StatusNoContent,
+			check: func(t *testing.T, patched *domain.Todo) {
+				assert.Equal(t, TestVersion, patched.Version)
+			},
+		},
+		{name: "null title", body: `{"title":null}`, code: http.StatusBadRequest, wantErr: domain.ErrEmptyTitle},
+		{name: "null completed", body: `{"completed":null}`, code: http.StatusBadRequest, wantErr: domain.ErrInvalidCompleted},
+		{name: "invalid priority", body: `{"priority":"someday"}`, code: http.StatusBadRequest, wantErr: domain.ErrInvalidPriority},
+		{name: "invalid due date", body: `{"due_at":"1990-01-01T00:00:00Z"}`, code: http.StatusBadRequest, wantErr: domain.ErrInvalidDueAt},
+		{name: "older version", body: `{"completed":true}`, ifMatch: domain.VersionETag(TestVersion - 1), code: http.StatusPreconditionFailed, wantErr: domain.ErrTodoVersionMismatch},
+		{name: "todo of another user", userId: uuid.NewString(), body: `{"completed":true}`, code: http.StatusNotFound, wantErr: domain.ErrTodoNotFound},
+		{name: "viewer", userId: domain.SecondTestUser.Id.String(), body: `{"completed":true}`, code: http.StatusForbidden, wantErr: domain.ErrTodoReadOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userId := tt.userId
+			if userId == "" {
+				userId = domain.RealUserId
+			}
+			ctx := context.WithValue(context.Background(), domain.UserIDKey, userId)
+			repo := &MockRepository{ViewerId: domain.SecondTestUser.Id}
+
+			req := &todo.PatchTodoRequest{Id: domain.TestTodo.Id, IfMatch: tt.ifMatch}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), req))
+
+			_, code, err := todo.NewPatchTodoHandler(repo).Handle(ctx, req)
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.check == nil {
+				assert.Nil(t, repo.Patched)
+				return
+			}
+			require.NotNil(t, repo.Patched)
+			assert.Equal(t, domain.TestTodo.Id, repo.Patched.Id)
+			tt.check(t, repo.Patched)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewRecurrenceHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	now := time.Date(2030, 5, 1, 12, 0, 0, 0, time.UTC)
+	handler := todo.NewPreviewRecurrenceHandler(mock.NewMockClock(now))
+
+	tests := []struct {
+		name    string
+		req     *todo.PreviewRecurrenceRequest
+		code    int
+		wantErr error
+		want    []time.Time
+	}{
+		{"defaults to five occurrences from now", &todo.PreviewRecurrenceRequest{RRule: "FREQ=DAILY"}, http.StatusOK, nil, []time.Time{
+			now, now.AddDate(0, 0, 1), now.AddDate(0, 0, 2), now.AddDate(0, 0, 3), now.AddDate(0, 0, 4),
+		}},
+		{"start with an unescaped offset", &todo.PreviewRecurrenceRequest{
+			RRule: "FREQ=WEEKLY;BYDAY=MO,WE", Start: "2030-05-06T09:00:00 03:00", Timezone: "Europe/Istanbul", Count: 3,
+		}, http.StatusOK, nil, []time.Time{
+			time.Date(2030, 5, 6, 6, 0, 0, 0, time.UTC),
+			time.Date(2030, 5, 8, 6, 0, 0, 0, time.UTC),
+			time.Date(2030, 5, 13, 6, 0, 0, 0, time.UTC),
+		}},
+		{"ended series", &todo.PreviewRecurrenceRequest{RRule: "FREQ=DAILY;UNTIL=20200101"}, http.StatusOK, nil, []time.Time{}},
+		{"invalid rule", &todo.PreviewRecurrenceRequest{RRule: "FREQ=SECONDLY"}, http.StatusBadRequest, domain.ErrInvalidRRule, nil},
+		{"invalid timezone", &todo.PreviewRecurrenceRequest{RRule: "FREQ=DAILY", Timezone: "Nowhere"}, http.StatusBadRequest, domain.ErrInvalidTimezone, nil},
+		{"too many occurrences", &todo.PreviewRecurrenceRequest{RRule: "FREQ=DAILY", Count: domain.MaxPreviewCount + 1}, http.StatusBadRequest, domain.ErrInvalidPreviewCount, nil},
+		{"invalid start", &todo.PreviewRecurrenceRequest{RRule: "FREQ=DAILY", Start: "tomorrow"}, http.StatusBadRequest, domain.ErrInvalidRecurrenceStart, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, code, err := handler.Handle(ctx, tt.req)
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Len(t, resp.Occurrences, len(tt.want))
+			for i, want := range tt.want {
+				assert.True(t, want.Equal(resp.Occurrences[i]), "occurrence %d: want %s, got %s", i, want, resp.Occurrences[i])
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorderTodoHandler(t *testing.T) {
+	ownerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+	positions := map[uuid.UUID]string{TestUpperTodoId: TestUpperPosition, TestLowerTodoId: TestLowerPosition}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		after   uuid.UUID
+		before  uuid.UUID
+		code    int
+		wantErr error
+	}{
+		{"between two todos", ownerCtx, TestUpperTodoId, TestLowerTodoId, http.StatusOK, nil},
+		{"after a todo", ownerCtx, TestLowerTodoId, uuid.Nil, http.StatusOK, nil},
+		{"before a todo", ownerCtx, uuid.Nil, TestUpperTodoId, http.StatusOK, nil},
+		{"no anchor", ownerCtx, uuid.Nil, uuid.Nil, http.StatusBadRequest, domain.ErrMissingMoveAnchor},
+		{"next to itself", ownerCtx, domain.TestTodo.Id, uuid.Nil, http.StatusBadRequest, domain.ErrInvalidMoveAnchor},
+		{"same anchors", ownerCtx, TestUpperTodoId, TestUpperTodoId, http.StatusBadRequest, domain.ErrInvalidMoveAnchor},
+		{"unknown anchor", ownerCtx, uuid.New(), uuid.Nil, http.StatusNotFound, domain.ErrAnchorTodoNotFound},
+		{"swapped anchors", ownerCtx, TestLowerTodoId, TestUpperTodoId, http.StatusConflict, domain.ErrStaleMoveAnchors},
+		{"other user reorders", otherCtx, TestUpperTodoId, TestLowerTodoId, http.StatusNotFound, domain.ErrTodoNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &todo.ReorderTodoRequest{Id: domain.TestTodo.Id, After: tt.after, Before: tt.before}
+			res, code, err := todo.NewReorderTodoHandler(&MockRepository{}).Handle(tt.ctx, req)
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			if tt.after != uuid.Nil {
+				assert.Greater(t, res.Position, positions[tt.after])
+			}
+			if tt.before != uuid.Nil {
+				assert.Less(t, res.Position, positions[tt.before])
+			}
+		})
+	}
+}
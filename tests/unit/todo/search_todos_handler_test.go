@@ -0,0 +1,59 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTodosHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondTestUser.Id.String())
+
+	handler := todo.NewSearchTodosHandler(&MockRepository{})
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		req         *todo.SearchTodosRequest
+		code        int
+		wantErr     error
+		wantResults int
+	}{
+		{"prefix", ctx, &todo.SearchTodosRequest{Q: "tes"}, http.StatusOK, nil, 1},
+		{"operators are ignored", ctx, &todo.SearchTodosRequest{Q: "!(TEST | & todo):*"}, http.StatusOK, nil, 1},
+		{"no match", ctx, &todo.SearchTodosRequest{Q: "groceries"}, http.StatusOK, nil, 0},
+		{"todos of other users", otherCtx, &todo.SearchTodosRequest{Q: "test"}, http.StatusOK, nil, 0},
+		{"only punctuation", ctx, &todo.SearchTodosRequest{Q: "&|!:*"}, http.StatusBadRequest, domain.ErrEmptySearchQuery, 0},
+		{"empty", ctx, &todo.SearchTodosRequest{}, http.StatusBadRequest, domain.ErrEmptySearchQuery, 0},
+		{"too long", ctx, &todo.SearchTodosRequest{Q: strings.Repeat("a", todo.MaxSearchLength+1)}, http.StatusBadRequest, domain.ErrSearchQueryTooLong, 0},
+		{"too large limit", ctx, &todo.SearchTodosRequest{Q: "test", Limit: todo.MaxSearchLimit + 1}, http.StatusBadRequest, domain.ErrInvalidSearchLimit, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, code, err := handler.Handle(tt.ctx, tt.req)
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, res.Results, tt.wantResults)
+		})
+	}
+
+	t.Run("highlights are escaped HTML", func(t *testing.T) {
+		res, _, err := handler.Handle(ctx, &todo.SearchTodosRequest{Q: "test"})
+		require.NoError(t, err)
+		require.Len(t, res.Results, 1)
+		assert.Equal(t, "<mark>Test Todo</mark>", res.Results[0].TitleHighlight)
+		assert.Equal(t, "&lt;b&gt;<mark>bold</mark>&lt;/b&gt;", res.Results[0].Snippet)
+	})
+}
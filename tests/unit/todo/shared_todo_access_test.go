@@ -0,0 +1,58 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedTodoViewerAccess(t *testing.T) {
+	viewerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	repo := &MockRepository{ViewerId: domain.SecondTestUser.Id}
+	getTodoByIdHandler := todo.NewGetTodoByIdHandler(repo, mock.NewMockMarkdownRenderer())
+	updateTodoHandler := todo.NewUpdateTodoHandler(repo)
+	deleteTodoHandler := todo.NewDeleteTodoHandler(repo)
+	toggleCompletedTodoHandler := todo.NewToggleCompletedTodoHandler(repo)
+
+	tests := []struct {
+		name    string
+		call    func() (int, error)
+		code    int
+		wantErr error
+	}{
+		{"viewer gets todo", func() (int, error) {
+			_, code, err := getTodoByIdHandler.Handle(viewerCtx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id})
+			return code, err
+		}, http.StatusOK, nil},
+		{"viewer updates todo", func() (int, error) {
+			_, code, err := updateTodoHandler.Handle(viewerCtx, &todo.UpdateTodoRequest{Id: domain.TestTodo.Id, Title: "Updated Test Todo"})
+			return code, err
+		}, http.StatusForbidden, domain.ErrTodoReadOnly},
+		{"viewer deletes todo", func() (int, error) {
+			_, code, err := deleteTodoHandler.Handle(viewerCtx, &todo.DeleteTodoRequest{Id: domain.TestTodo.Id})
+			return code, err
+		}, http.StatusForbidden, domain.ErrTodoReadOnly},
+		{"viewer toggles todo", func() (int, error) {
+			_, code, err := toggleCompletedTodoHandler.Handle(viewerCtx, &todo.ToggleCompletedTodoRequest{Id: domain.TestTodo.Id})
+			return code, err
+		}, http.StatusForbidden, domain.ErrTodoReadOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.call()
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package unittest_todo
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSubtaskTree(t *testing.T) {
+	rootId := uuid.New()
+	pack := todo.Subtask{Id: uuid.New(), ParentId: rootId, Title: "pack", Completed: false}
+	clothes := todo.Subtask{Id: uuid.New(), ParentId: pack.Id, Title: "clothes", Completed: true}
+	charger := todo.Subtask{Id: uuid.New(), ParentId: pack.Id, Title: "charger", Completed: false}
+	tickets := todo.Subtask{Id: uuid.New(), ParentId: rootId, Title: "tickets", Completed: true}
+	passport := todo.Subtask{Id: uuid.New(), ParentId: rootId, Title: "passport", Completed: true}
+
+	subtasks, progress := todo.NewSubtaskTree(rootId, []todo.Subtask{pack, clothes, charger, tickets, passport})
+
+	assert.Equal(t, domain.Progress{Done: 3, Total: 5}, progress)
+	require.Len(t, subtasks, 3)
+	assert.Equal(t, []string{"pack", "tickets", "passport"}, []string{subtasks[0].Title, subtasks[1].Title, subtasks[2].Title})
+
+	assert.Equal(t, domain.Progress{Done: 1, Total: 2}, subtasks[0].Progress)
+	require.Len(t, subtasks[0].Subtasks, 2)
+	assert.Equal(t, clothes.Id, subtasks[0].Subtasks[0].Id)
+	assert.Empty(t, subtasks[1].Subtasks)
+	assert.NotNil(t, subtasks[1].Subtasks, "leaves encode as an empty list")
+
+	leaves, progress := todo.NewSubtaskTree(rootId, nil)
+	assert.NotNil(t, leaves)
+	assert.Empty(t, leaves)
+	assert.Equal(t, domain.Progress{}, progress)
+}
@@ -0,0 +1,314 @@
+package unittest_todo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	passportId = uuid.MustParse("0f6b2c1e-5a4d-4b3c-8e2f-1a0b9c8d7e6f")
+	callMomId  = uuid.MustParse("b1c8f0d2-3e4a-4f5b-9c6d-7e8f9a0b1c2d")
+	payRentId  = uuid.MustParse("2d3e4f5a-6b7c-4d8e-9f0a-1b2c3d4e5f6a")
+	cleanUpId  = uuid.MustParse("3e4f5a6b-7c8d-4e9f-8a1b-2c3d4e5f6a7b")
+	windowsId  = uuid.MustParse("4f5a6b7c-8d9e-4f0a-9b2c-3d4e5f6a7b8c")
+)
+
+// todoTxtTodos are the todos of domain.TestUser in the todo.txt tests, calling mom is
+// in domain.TestProject and the windows are a subtask of cleaning up.
+func todoTxtTodos() []todo.ExportedTodo {
+	passportDue := time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC)
+	rentDue := time.Date(2030, 6, 1, 10, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2030, 5, 2, 18, 0, 0, 0, time.UTC)
+	return []todo.ExportedTodo{
+		{Id: passportId, Title: "Renew passport", Priority: domain.PriorityUrgent, DueAt: &passportDue, CreatedAt: TestCreatedAt, Tags: []string{"errands", "long trip"}},
+		{Id: callMomId, Title: "Call Mom", Completed: true, Priority: domain.PriorityHigh, CreatedAt: TestCreatedAt, CompletedAt: &completedAt,
+			ProjectId: &domain.TestProject.Id, Project: domain.TestProject.Name},
+		{Id: payRentId, Title: "Pay\nrent  at 10:30", Priority: domain.PriorityNone, DueAt: &rentDue, CreatedAt: TestCreatedAt},
+		{Id: cleanUpId, Title: "Clean up", Priority: domain.PriorityLow, CreatedAt: TestCreatedAt, Version: 3},
+		{Id: windowsId, Title: "Windows", Priority: domain.PriorityNone, CreatedAt: TestCreatedAt, ParentId: &cleanUpId},
+	}
+}
+
+func TestExportTodoTxt(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	repo := &exportRepository{todos: todoTxtTodos()}
+
+	file, code, err := todo.NewExportTodosHandler(repo, mock.NewMockClock(TestExportedAt), mock.NewMockLogger()).Handle(ctx, &todo.ExportTodosRequest{Format: "txt"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "todos.txt", file.Name)
+	assert.Equal(t, "text/plain; charset=utf-8", file.ContentType)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, file.ETag)
+
+	body, err := io.ReadAll(file.Body)
+	require.NoError(t, err)
+	require.NoError(t, file.Body.Close())
+	assert.Equal(t, "(A) 2030-04-01 Renew passport @errands @long_trip due:2030-06-15 id:"+passportId.String()+"\n"+
+		"x 2030-05-02 2030-04-01 Call Mom +Home pri:B id:"+callMomId.String()+"\n"+
+		"2030-04-01 Pay rent at 10:30 due:2030-06-01T10:00:00Z id:"+payRentId.String()+"\n"+
+		"(D) 2030-04-01 Clean up id:"+cleanUpId.String()+"\n"+
+		"2030-04-01 Windows id:"+windowsId.String()+"\n", string(body))
+}
+
+func TestImportTodoTxt(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	repo := &MockRepository{}
+
+	resp, code, err := todo.NewImportTodosHandler(repo).Handle(ctx, &todo.ImportTodosRequest{
+		File: newFileHeader(t, "todo.txt", "\ufeff(A) 2030-04-01 Renew passport @errands @Errands @long_trip due:2030-06-15 id:"+passportId.String()+"\n"+
+			"\n"+
+			"x 2030-05-02 2030-04-01 Call Mom +home pri:B\n"+
+			"(F) Water plants t:2030-05-01 id:7\n"+
+			"x (C) Pay rent at 10:30 https://example.com\n"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 4, resp.Imported)
+	require.Len(t, repo.Imported, 4)
+
+	passport, mom, plants, rent := repo.Imported[0], repo.Imported[1], repo.Imported[2], repo.Imported[3]
+	assert.NotEqual(t, passportId, passport.Id)
+	assert.Equal(t, "Renew passport", passport.Title)
+	assert.Equal(t, domain.PriorityUrgent, passport.Priority)
+	assert.Equal(t, time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC), passport.DueAt)
+	assert.Equal(t, time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), passport.CreatedAt)
+	assert.False(t, passport.Completed)
+	assert.Equal(t, map[uuid.UUID][]string{passport.Id: {"errands", "long_trip"}}, repo.ImportedTags)
+
+	assert.Equal(t, "Call Mom", mom.Title)
+	assert.Equal(t, domain.TestProject.Id, mom.ProjectId)
+	assert.Equal(t, domain.PriorityHigh, mom.Priority)
+	assert.True(t, mom.Completed)
+	assert.Equal(t, time.Date(2030, 5, 2, 0, 0, 0, 0, time.UTC), mom.CompletedAt)
+
+	assert.Equal(t, "Water plants", plants.Title, "the extensions the API does not know are dropped")
+	assert.Equal(t, domain.PriorityLow, plants.Priority)
+	assert.True(t, plants.DueAt.IsZero())
+
+	assert.Equal(t, "Pay rent at 10:30 https://example.com", rent.Title)
+	assert.Equal(t, domain.PriorityMedium, rent.Priority, "the priority may follow the completion mark")
+	assert.True(t, rent.Completed)
+	assert.Equal(t, rent.CreatedAt, rent.CompletedAt)
+
+	t.Run("unknown project", func(t *testing.T) {
+		resp, code, err := todo.NewImportTodosHandler(&MockRepository{}).Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todo.txt", "Buy milk +Groceries\nPay rent +Home +Bills\n"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, []todo.ImportRowError{
+			{Row: 1, Error: domain.ErrProjectNotFound.Error()},
+			{Row: 2, Error: domain.ErrTodoTxtProjects.Error()},
+		}, resp.Errors)
+	})
+
+	t.Run("invalid due date", func(t *testing.T) {
+		resp, code, err := todo.NewImportTodosHandler(&MockRepository{}).Handle(ctx, &todo.ImportTodosRequest{
+			File: newFileHeader(t, "todo.txt", "Buy milk\nPay rent due:someday\n"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, []todo.ImportRowError{{Row: 2, Error: domain.ErrInvalidDueAtFormat.Error()}}, resp.Errors)
+	})
+}
+
+// syncRepository holds todoTxtTodos and keeps the operations ApplyBatch is called with,
+// the operation at FailAt fails with Err.
+type syncRepository struct {
+	exportRepository
+	Ops    []todo.TodoOperation
+	FailAt int
+	Err    error
+}
+
+func newSyncRepository() *syncRepository {
+	return &syncRepository{exportRepository: exportRepository{todos: todoTxtTodos()}, FailAt: -1}
+}
+
+func (r *syncRepository) ApplyBatch(ctx context.Context, userId uuid.UUID, ops []todo.TodoOperation, atomic bool) ([]error, error) {
+	r.Ops = ops
+	errs := make([]error, len(ops))
+	if r.FailAt >= 0 {
+		errs[r.FailAt] = r.Err
+	}
+	return errs, nil
+}
+
+func TestSyncTodoTxtHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	syncTodoTxt := func(repo *syncRepository, content, ifMatch string) (*domain.File, int, error) {
+		handler := todo.NewSyncTodoTxtHandler(repo, mock.NewMockClock(TestExportedAt), mock.NewMockLogger())
+		return handler.Handle(ctx, &todo.SyncTodoTxtRequest{File: newFileHeader(t, "todo.txt", content), IfMatch: ifMatch})
+	}
+	exportTodoTxt := func(repo *syncRepository) (string, string) {
+		file, code, err := todo.NewExportTodosHandler(repo, mock.NewMockClock(TestExportedAt), mock.NewMockLogger()).Handle(ctx, &todo.ExportTodosRequest{Format: "txt"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		exported, err := io.ReadAll(file.Body)
+		require.NoError(t, err)
+		require.NoError(t, file.Body.Close())
+		return string(exported), file.ETag
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		repo := newSyncRepository()
+		_, etag := exportTodoTxt(repo)
+
+		file, code, err := syncTodoTxt(repo, "(B) 2030-04-01 Renew passport @errands @long_trip due:2030-06-15 id:"+passportId.String()+"\n"+
+			"2030-04-01 Call Mom (B) id:"+callMomId.String()+"\n"+
+			"2030-04-01 Pay rent at 10:30 due:2030-06-01T12:00:00+02:00 id:"+payRentId.String()+"\n"+
+			"x 2030-05-03 2030-05-01 Buy milk @store +home pri:C\n", etag)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "todos.txt", file.Name)
+		assert.Equal(t, etag, file.ETag, "the mock repository does not apply the operations")
+		require.NoError(t, file.Body.Close())
+
+		require.Len(t, repo.Ops, 6)
+		assert.Equal(t, todo.TodoOperation{
+			Type:     todo.OpUpdate,
+			Id:       passportId,
+			Title:    "Renew passport",
+			DueAt:    time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC),
+			Priority: domain.PriorityHigh,
+			Tags:     []string{"errands", "long trip"},
+		}, repo.Ops[0])
+		assert.Equal(t, todo.TodoOperation{Type: todo.OpUpdate, Id: callMomId, Title: "Call Mom (B)", Priority: domain.PriorityNone}, repo.Ops[1])
+		assert.Equal(t, todo.TodoOperation{Type: todo.OpMove, Id: callMomId}, repo.Ops[2], "a line without a project moves the todo to the inbox")
+		assert.Equal(t, todo.TodoOperation{Type: todo.OpSetCompleted, Id: callMomId}, repo.Ops[3])
+
+		create := repo.Ops[4]
+		assert.Equal(t, todo.OpCreate, create.Type)
+		assert.Equal(t, create.Todo.Id, create.Id)
+		assert.Equal(t, domain.TestUser.Id, create.Todo.UserId)
+		assert.Equal(t, "Buy milk", create.Todo.Title)
+		assert.Equal(t, domain.TestProject.Id, create.Todo.ProjectId)
+		assert.Equal(t, []string{"store"}, create.Tags)
+		assert.Equal(t, domain.PriorityMedium, create.Todo.Priority)
+		assert.True(t, create.Todo.Completed)
+		assert.Equal(t, time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC), create.Todo.CreatedAt)
+		assert.Equal(t, time.Date(2030, 5, 3, 0, 0, 0, 0, time.UTC), create.Todo.CompletedAt)
+
+		// the windows go to the trash with the cleaning up
+		assert.Equal(t, todo.TodoOperation{Type: todo.OpDelete, Id: cleanUpId, Version: 3}, repo.Ops[5])
+	})
+
+	t.Run("unchanged file", func(t *testing.T) {
+		repo := newSyncRepository()
+		exported, etag := exportTodoTxt(repo)
+
+		file, code, err := syncTodoTxt(repo, exported, etag)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		synced, err := io.ReadAll(file.Body)
+		require.NoError(t, err)
+		require.NoError(t, file.Body.Close())
+		assert.Nil(t, repo.Ops)
+		assert.Equal(t, exported, string(synced))
+	})
+
+	t.Run("todos left out are kept without If-Match", func(t *testing.T) {
+		repo := newSyncRepository()
+		file, code, err := syncTodoTxt(repo, "(D) 2030-04-01 Clean up id:"+cleanUpId.String()+"\n", "")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		require.NoError(t, file.Body.Close())
+		assert.Nil(t, repo.Ops)
+	})
+
+	t.Run("a stale If-Match deletes nothing", func(t *testing.T) {
+		repo := newSyncRepository()
+		_, etag := exportTodoTxt(repo)
+		repo.todos = append(repo.todos, todo.ExportedTodo{Id: uuid.New(), Title: "Added elsewhere", Priority: domain.PriorityNone, CreatedAt: TestCreatedAt})
+
+		_, code, err := syncTodoTxt(repo, "(D) 2030-04-01 Clean up id:"+cleanUpId.String()+"\n", etag)
+		assert.Equal(t, http.StatusPreconditionFailed, code)
+		assert.ErrorIs(t, err, domain.ErrTodoTxtChanged)
+		assert.Nil(t, repo.Ops)
+	})
+
+	t.Run("a changed version makes If-Match stale", func(t *testing.T) {
+		repo := newSyncRepository()
+		_, etag := exportTodoTxt(repo)
+		repo.todos[0].Version++
+
+		_, code, err := syncTodoTxt(repo, "", `"other", `+etag)
+		assert.Equal(t, http.StatusPreconditionFailed, code)
+		assert.ErrorIs(t, err, domain.ErrTodoTxtChanged)
+	})
+
+	t.Run("invalid If-Match", func(t *testing.T) {
+		_, code, err := syncTodoTxt(newSyncRepository(), "", "abc")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrInvalidIfMatch)
+	})
+
+	t.Run("a subtask stays when its parent is kept", func(t *testing.T) {
+		repo := newSyncRepository()
+		_, etag := exportTodoTxt(repo)
+		file, _, err := syncTodoTxt(repo, "(D) 2030-04-01 Clean up id:"+cleanUpId.String()+"\n", etag)
+		require.NoError(t, err)
+		require.NoError(t, file.Body.Close())
+
+		var deleted []uuid.UUID
+		for _, op := range repo.Ops {
+			require.Equal(t, todo.OpDelete, op.Type)
+			deleted = append(deleted, op.Id)
+		}
+		assert.Equal(t, []uuid.UUID{passportId, callMomId, payRentId, windowsId}, deleted)
+	})
+
+	errorTests := []struct {
+		name    string
+		content string
+		ifMatch string
+		failAt  int
+		err     error
+		code    int
+		wantErr string
+	}{
+		{"duplicate id", "Call Mom id:" + callMomId.String() + "\nCall Dad id:" + callMomId.String() + "\n", "", -1, nil,
+			http.StatusBadRequest, "line 2: " + domain.ErrDuplicateTodoTxtId.Error()},
+		{"unknown id", "\nBuy milk id:" + domain.TestTodo.Id.String() + "\n", "", -1, nil,
+			http.StatusNotFound, "line 2: " + domain.ErrTodoNotFound.Error()},
+		{"invalid title", "ab\n", "", -1, nil,
+			http.StatusBadRequest, "line 1: " + domain.ErrTitleTooShort.Error()},
+		{"invalid due date", "Buy milk due:someday\n", "", -1, nil,
+			http.StatusBadRequest, "line 1: " + domain.ErrInvalidDueAtFormat.Error()},
+		{"unknown project", "\nBuy milk +Groceries\n", "", -1, nil,
+			http.StatusNotFound, "line 2: " + domain.ErrProjectNotFound.Error()},
+		{"read-only todo", "Renew passport id:" + passportId.String() + "\n", "", 0, domain.ErrTodoReadOnly,
+			http.StatusForbidden, "line 1: " + domain.ErrTodoReadOnly.Error()},
+		{"deletion of a read-only todo", "", "*", 0, domain.ErrTodoReadOnly,
+			http.StatusForbidden, domain.ErrTodoReadOnly.Error()},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newSyncRepository()
+			repo.FailAt, repo.Err = tt.failAt, tt.err
+
+			_, code, err := syncTodoTxt(repo, tt.content, tt.ifMatch)
+			assert.Equal(t, tt.code, code)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		handler := todo.NewSyncTodoTxtHandler(newSyncRepository(), mock.NewMockClock(TestExportedAt), mock.NewMockLogger())
+		_, code, err := handler.Handle(ctx, &todo.SyncTodoTxtRequest{})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.ErrorIs(t, err, domain.ErrMissingImportFile)
+	})
+}
@@ -0,0 +1,144 @@
+package unittest_todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTrashHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	handler := todo.NewGetTrashHandler(&MockRepository{}, 7*24*time.Hour)
+
+	res, code, err := handler.Handle(ctx, &todo.GetTrashRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	require.Len(t, res.Todos, 1)
+	assert.Equal(t, domain.TestTodo.Id, res.Todos[0].Id)
+	assert.Equal(t, TrashedAt.AddDate(0, 0, 7), res.Todos[0].PurgeAt)
+
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+	res, _, err = handler.Handle(otherCtx, &todo.GetTrashRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Todos)
+}
+
+func TestRestoreTodoHandler(t *testing.T) {
+	ownerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		repo    *MockRepository
+		code    int
+		wantErr error
+	}{
+		{"owner restores todo", ownerCtx, &MockRepository{}, http.StatusNoContent, nil},
+		{"other user restores todo", otherCtx, &MockRepository{}, http.StatusNotFound, domain.ErrTodoNotFound},
+		{"parent still in the trash", ownerCtx, &MockRepository{ParentTrashed: true}, http.StatusConflict, domain.ErrParentTodoInTrash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code, err := todo.NewRestoreTodoHandler(tt.repo).Handle(tt.ctx, &todo.RestoreTodoRequest{Id: domain.TestTodo.Id})
+			assert.Equal(t, tt.code, code)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeleteTrashedTodoHandler(t *testing.T) {
+	handler := todo.NewDeleteTrashedTodoHandler(&MockRepository{})
+
+	ownerCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	_, code, err := handler.Handle(ownerCtx, &todo.DeleteTrashedTodoRequest{Id: domain.TestTodo.Id})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	otherCtx := context.WithValue(context.Background(), domain.UserIDKey, domain.SecondUserId)
+	_, code, err = handler.Handle(otherCtx, &todo.DeleteTrashedTodoRequest{Id: domain.TestTodo.Id})
+	assert.ErrorIs(t, err, domain.ErrTodoNotFound)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+// mockTrash holds the deletion times of trashed todos.
+type mockTrash struct {
+	mu        sync.Mutex
+	deletedAt []time.Time
+	err       error
+}
+
+func (m *mockTrash) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return 0, m.err
+	}
+	kept := m.deletedAt[:0]
+	purged := 0
+	for _, deletedAt := range m.deletedAt {
+		if deletedAt.Before(before) && purged < limit {
+			purged++
+			continue
+		}
+		kept = append(kept, deletedAt)
+	}
+	m.deletedAt = kept
+	return purged, nil
+}
+
+func TestTrashPurgerHonoursRetention(t *testing.T) {
+	ctx := context.Background()
+	clock := mock.NewMockClock(TrashedAt)
+	repo := &mockTrash{deletedAt: []time.Time{TrashedAt.Add(-time.Hour), TrashedAt, TrashedAt.Add(24 * time.Hour)}}
+	purger := todo.NewTrashPurger(repo, clock, mock.NewMockLogger(), 30*24*time.Hour, time.Hour)
+
+	assert.Equal(t, 0, purger.RunOnce(ctx), "nothing is old enough yet")
+
+	clock.Advance(30 * 24 * time.Hour)
+	assert.Equal(t, 1, purger.RunOnce(ctx))
+	assert.Equal(t, 0, purger.RunOnce(ctx))
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, 1, purger.RunOnce(ctx))
+
+	clock.Advance(24 * time.Hour)
+	assert.Equal(t, 1, purger.RunOnce(ctx))
+	assert.Empty(t, repo.deletedAt)
+}
+
+func TestTrashPurgerPurgesInBatches(t *testing.T) {
+	ctx := context.Background()
+	clock := mock.NewMockClock(TrashedAt.AddDate(1, 0, 0))
+	repo := &mockTrash{}
+	for i := 0; i < 1234; i++ {
+		repo.deletedAt = append(repo.deletedAt, TrashedAt)
+	}
+	purger := todo.NewTrashPurger(repo, clock, mock.NewMockLogger(), 30*24*time.Hour, time.Hour)
+
+	assert.Equal(t, 1234, purger.RunOnce(ctx))
+	assert.Empty(t, repo.deletedAt)
+}
+
+func TestTrashPurgerStopsOnError(t *testing.T) {
+	repo := &mockTrash{deletedAt: []time.Time{TrashedAt}, err: errors.New("database is down")}
+	purger := todo.NewTrashPurger(repo, mock.NewMockClock(TrashedAt.AddDate(1, 0, 0)), mock.NewMockLogger(), time.Hour, time.Hour)
+
+	assert.Equal(t, 0, purger.RunOnce(context.Background()))
+	assert.Len(t, repo.deletedAt, 1)
+}
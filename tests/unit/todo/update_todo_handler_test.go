@@ -0,0 +1,94 @@
+package unittest_todo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storedTodoRepository reads domain.TestTodo with a description, a due date and a
+// priority, for the update to keep or replace.
+type storedTodoRepository struct {
+	MockRepository
+}
+
+var storedDueAt = time.Date(2030, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func (r *storedTodoRepository) GetById(ctx context.Context, id, userId uuid.UUID) (*todo.GetTodoByIdResponse, error) {
+	current, err := r.MockRepository.GetById(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	current.Description = "Stored **description**"
+	current.DueAt = storedDueAt
+	current.Priority = domain.PriorityHigh
+	return current, nil
+}
+
+func TestUpdateTodoHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	newDueAt := time.Date(2030, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		body    string
+		code    int
+		wantErr error
+		check   func(t *testing.T, updated *domain.Todo)
+	}{
+		{
+			name: "title only keeps the other fields", body: `{"title":"Updated title"}`, code: http.StatusNoContent,
+			check: func(t *testing.T, updated *domain.Todo) {
+				assert.Equal(t, "Updated title", updated.Title)
+				assert.Equal(t, "Stored **description**", updated.Description)
+				assert.Equal(t, storedDueAt, updated.DueAt)
+				assert.Equal(t, domain.PriorityHigh, updated.Priority)
+			},
+		},
+		{
+			name: "null fields keep their value", body: `{"title":"Updated title","description":null,"due_at":null,"priority":null}`, code: http.StatusNoContent,
+			check: func(t *testing.T, updated *domain.Todo) {
+				assert.Equal(t, "Stored **description**", updated.Description)
+				assert.Equal(t, storedDueAt, updated.DueAt)
+				assert.Equal(t, domain.PriorityHigh, updated.Priority)
+			},
+		},
+		{
+			name: "every field", body: `{"title":"Updated title","description":"","due_at":"2030-07-01T09:00:00Z","priority":"none"}`, code: http.StatusNoContent,
+			check: func(t *testing.T, updated *domain.Todo) {
+				assert.Empty(t, updated.Description)
+				assert.Equal(t, newDueAt, updated.DueAt)
+				assert.Equal(t, domain.PriorityNone, updated.Priority)
+			},
+		},
+		{name: "invalid priority", body: `{"title":"Updated title","priority":"someday"}`, code: http.StatusBadRequest, wantErr: domain.ErrInvalidPriority},
+		{name: "scope this with a recurrence", body: `{"title":"Updated title","recurrence":"FREQ=DAILY","scope":"this"}`, code: http.StatusBadRequest, wantErr: domain.ErrRecurrenceScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &storedTodoRepository{}
+			req := &todo.UpdateTodoRequest{Id: domain.TestTodo.Id}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), req))
+
+			_, code, err := todo.NewUpdateTodoHandler(repo).Handle(ctx, req)
+			assert.Equal(t, tt.code, code)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.check == nil {
+				assert.Nil(t, repo.Updated)
+				return
+			}
+			require.NotNil(t, repo.Updated)
+			assert.Equal(t, domain.TestTodo.Id, repo.Updated.Id)
+			tt.check(t, repo.Updated)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package unittest_todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/muhammedkucukaslan/advanced-todo-api/app/todo"
+	"github.com/muhammedkucukaslan/advanced-todo-api/domain"
+	mock "github.com/muhammedkucukaslan/advanced-todo-api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+	current := domain.VersionETag(TestVersion)
+
+	handlers := map[string]func(ifMatch string) (int, error){
+		"update": func(ifMatch string) (int, error) {
+			_, code, err := todo.NewUpdateTodoHandler(&MockRepository{}).Handle(ctx, &todo.UpdateTodoRequest{
+				Id: domain.TestTodo.Id, Title: "Updated title", IfMatch: ifMatch,
+			})
+			return code, err
+		},
+		"toggle": func(ifMatch string) (int, error) {
+			_, code, err := todo.NewToggleCompletedTodoHandler(&MockRepository{}).Handle(ctx, &todo.ToggleCompletedTodoRequest{
+				Id: domain.TestTodo.Id, IfMatch: ifMatch,
+			})
+			return code, err
+		},
+		"delete": func(ifMatch string) (int, error) {
+			_, code, err := todo.NewDeleteTodoHandler(&MockRepository{}).Handle(ctx, &todo.DeleteTodoRequest{
+				Id: domain.TestTodo.Id, IfMatch: ifMatch,
+			})
+			return code, err
+		},
+	}
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		code    int
+		wantErr error
+	}{
+		{"without If-Match", "", http.StatusNoContent, nil},
+		{"any version", "*", http.StatusNoContent, nil},
+		{"current version", current, http.StatusNoContent, nil},
+		{"older version", domain.VersionETag(TestVersion - 1), http.StatusPreconditionFailed, domain.ErrTodoVersionMismatch},
+		{"weak ETag", "W/" + current, http.StatusPreconditionFailed, domain.ErrTodoVersionMismatch},
+		{"list with the current version", domain.VersionETag(TestVersion-1) + ", " + current, http.StatusNoContent, nil},
+		{"list of older versions", domain.VersionETag(TestVersion-2) + ", " + domain.VersionETag(TestVersion-1), http.StatusPreconditionFailed, domain.ErrTodoVersionMismatch},
+		{"malformed list", current + ", 4", http.StatusBadRequest, domain.ErrInvalidIfMatch},
+	}
+
+	for name, handle := range handlers {
+		for _, tt := range tests {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				code, err := handle(tt.ifMatch)
+				assert.Equal(t, tt.code, code)
+				assert.ErrorIs(t, err, tt.wantErr)
+			})
+		}
+	}
+}
+
+func TestGetTodoByIdETag(t *testing.T) {
+	ctx := context.WithValue(context.Background(), domain.UserIDKey, domain.RealUserId)
+
+	res, code, err := todo.NewGetTodoByIdHandler(&MockRepository{}, nil).Handle(ctx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `"3"`, res.ETag())
+
+	t.Run("the HTML is another variant", func(t *testing.T) {
+		handler := todo.NewGetTodoByIdHandler(&storedTodoRepository{}, mock.NewMockMarkdownRenderer())
+		plain, _, err := handler.Handle(ctx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id})
+		require.NoError(t, err)
+		html, _, err := handler.Handle(ctx, &todo.GetTodoByIdRequest{Id: domain.TestTodo.Id, IncludeHTML: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, `"3"`, plain.ETag())
+		assert.Equal(t, `"3-html"`, html.ETag())
+	})
+
+	t.Run("an overdue todo is another variant", func(t *testing.T) {
+		overdue := &todo.GetTodoByIdResponse{Version: TestVersion, Overdue: true}
+		assert.Equal(t, `"3-overdue"`, overdue.ETag())
+	})
+}
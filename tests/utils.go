@@ -66,8 +66,11 @@ func NewTestJWETokenService() *jweInfra.Service {
 		AccessTokenEncryptionKey:  "12345678901234567890123456789012",
 		SecureEmailEncryptionKey:  "12345678901234567890123456789012",
 		RefreshTokenEncryptionKey: "12345678901234567890123456789012",
+		SignedURLEncryptionKey:    "12345678901234567890123456789012",
+		FeedEncryptionKey:         "12345678901234567890123456789012",
 		AuthAccessTokenDuration:   time.Minute * 3,
 		AuthRefreshTokenDuration:  time.Hour * 24,
 		SecureEmailTokenDuration:  time.Minute * 10,
+		SignedURLTokenDuration:    time.Minute * 5,
 	})
 }